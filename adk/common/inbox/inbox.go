@@ -0,0 +1,492 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package inbox answers the interrupts of the approval, review-edit and follow-up tools of
+// likeeino/adk/common/tool from the web instead of a terminal: it runs an adk.Runner, keeps
+// the interrupts awaiting a reviewer, and resumes the checkpoint once they are decided.
+package inbox
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/schema"
+	"github.com/google/uuid"
+
+	"likeeino/adk/common/store"
+	"likeeino/adk/common/tool"
+)
+
+var (
+	ErrNotFound        = errors.New("interrupt not found")
+	ErrAlreadyDecided  = errors.New("interrupt already decided")
+	ErrInvalidDecision = errors.New("invalid decision")
+	ErrRunning         = errors.New("checkpoint is already running")
+)
+
+type Kind string
+
+const (
+	KindApproval   Kind = "approval"
+	KindReviewEdit Kind = "review_edit"
+	KindFollowUp   Kind = "follow_up"
+)
+
+// Interrupt is a tool call, or questions, waiting for a reviewer.
+type Interrupt struct {
+	ID           string `json:"id"`
+	CheckPointID string `json:"checkpoint_id"`
+	// Address locates the interrupt point in the agent, the key of its resume data.
+	Address    string    `json:"address"`
+	Kind       Kind      `json:"kind"`
+	ToolName   string    `json:"tool_name,omitempty"`
+	ToolCallID string    `json:"tool_call_id,omitempty"`
+	Arguments  string    `json:"arguments,omitempty"`
+	Questions  []string  `json:"questions,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	// Decision is set once decided. The run resumes when every interrupt of its checkpoint is.
+	Decision *Decision `json:"decision,omitempty"`
+}
+
+type Action string
+
+const (
+	// ActionApprove runs the tool call as it is.
+	ActionApprove Action = "approve"
+	// ActionReject skips the tool call, telling the agent Reason; it declines questions.
+	ActionReject Action = "reject"
+	// ActionEdit runs a review-edit tool call with Arguments instead.
+	ActionEdit Action = "edit"
+	// ActionAnswer answers follow-up questions with Answer.
+	ActionAnswer Action = "answer"
+)
+
+type Decision struct {
+	Action    Action    `json:"action"`
+	Reason    string    `json:"reason,omitempty"`
+	Arguments string    `json:"arguments,omitempty"`
+	Answer    string    `json:"answer,omitempty"`
+	DecidedAt time.Time `json:"decided_at"`
+}
+
+type EventType string
+
+const (
+	EventInterrupt EventType = "interrupt"
+	EventDecided   EventType = "decided"
+	EventResumed   EventType = "resumed"
+	EventCompleted EventType = "completed"
+	EventFailed    EventType = "failed"
+)
+
+// Event tells subscribers about an interrupt arriving or being decided, and about runs.
+type Event struct {
+	Seq          int64      `json:"seq"`
+	Type         EventType  `json:"type"`
+	CheckPointID string     `json:"checkpoint_id"`
+	Interrupt    *Interrupt `json:"interrupt,omitempty"`
+	// Output is the last answer of the agent of a completed run.
+	Output string    `json:"output,omitempty"`
+	Error  string    `json:"error,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+// pendingKey is where the pending interrupts are saved in Config.Store.
+const pendingKey = "inbox:pending"
+
+type Config struct {
+	// Runner must have a CheckPointStore.
+	Runner *adk.Runner
+	// Store keeps the pending interrupts across restarts, usually the CheckPointStore of Runner.
+	Store store.Store
+}
+
+type Inbox struct {
+	runner *adk.Runner
+	store  store.Store
+
+	mu      sync.Mutex
+	pending map[string]*Interrupt
+	running map[string]bool
+
+	hubMu sync.Mutex
+	seq   int64
+	subs  map[chan Event]struct{}
+}
+
+// New creates an inbox, loading the interrupts left pending by a previous process from
+// cfg.Store.
+func New(ctx context.Context, cfg *Config) (*Inbox, error) {
+	if cfg.Runner == nil {
+		return nil, errors.New("runner is required")
+	}
+	b := &Inbox{
+		runner:  cfg.Runner,
+		store:   cfg.Store,
+		pending: make(map[string]*Interrupt),
+		running: make(map[string]bool),
+		subs:    make(map[chan Event]struct{}),
+	}
+	if b.store == nil {
+		return b, nil
+	}
+	data, ok, err := b.store.Get(ctx, pendingKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pending interrupts: %w", err)
+	}
+	if ok {
+		var pending []*Interrupt
+		if err := json.Unmarshal(data, &pending); err != nil {
+			return nil, fmt.Errorf("invalid pending interrupts: %w", err)
+		}
+		for _, it := range pending {
+			b.pending[it.ID] = it
+		}
+	}
+	return b, nil
+}
+
+// Start runs the agent on messages under checkPointID, a new one when empty, and returns it.
+// Its interrupts are then listed until decided.
+func (b *Inbox) Start(ctx context.Context, checkPointID string, messages []adk.Message) (string, error) {
+	if checkPointID == "" {
+		checkPointID = uuid.NewString()
+	}
+	b.mu.Lock()
+	if b.running[checkPointID] || b.hasPending(checkPointID) {
+		b.mu.Unlock()
+		return "", ErrRunning
+	}
+	b.running[checkPointID] = true
+	b.mu.Unlock()
+
+	iter := b.runner.Run(context.WithoutCancel(ctx), messages, adk.WithCheckPointID(checkPointID))
+	go b.drain(checkPointID, iter)
+	return checkPointID, nil
+}
+
+// List returns the pending interrupts, of one checkpoint when checkPointID is not empty,
+// oldest first.
+func (b *Inbox) List(checkPointID string) []*Interrupt {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	res := make([]*Interrupt, 0, len(b.pending))
+	for _, it := range b.pending {
+		if checkPointID == "" || it.CheckPointID == checkPointID {
+			res = append(res, it)
+		}
+	}
+	slices.SortFunc(res, func(x, y *Interrupt) int {
+		return cmp.Or(x.CreatedAt.Compare(y.CreatedAt), strings.Compare(x.ID, y.ID))
+	})
+	return res
+}
+
+func (b *Inbox) Get(id string) (*Interrupt, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	it, ok := b.pending[id]
+	return it, ok
+}
+
+// Decide records the decision on interrupt id. Once every interrupt of its checkpoint is
+// decided, the run resumes in the background with all of them.
+func (b *Inbox) Decide(ctx context.Context, id string, d Decision) (*Interrupt, error) {
+	b.mu.Lock()
+	it, ok := b.pending[id]
+	if !ok {
+		b.mu.Unlock()
+		return nil, ErrNotFound
+	}
+	if it.Decision != nil {
+		b.mu.Unlock()
+		return nil, ErrAlreadyDecided
+	}
+	if _, err := resumeData(it, d); err != nil {
+		b.mu.Unlock()
+		return nil, err
+	}
+	d.DecidedAt = time.Now()
+	it.Decision = &d
+
+	targets := make(map[string]any)
+	var decided []*Interrupt
+	for _, other := range b.pending {
+		if other.CheckPointID != it.CheckPointID {
+			continue
+		}
+		if other.Decision == nil {
+			targets = nil
+			break
+		}
+		data, _ := resumeData(other, *other.Decision)
+		targets[other.Address] = data
+		decided = append(decided, other)
+	}
+	if targets != nil {
+		for _, other := range decided {
+			delete(b.pending, other.ID)
+		}
+		b.running[it.CheckPointID] = true
+	}
+	b.save(ctx)
+	b.mu.Unlock()
+
+	b.publish(Event{Type: EventDecided, CheckPointID: it.CheckPointID, Interrupt: it})
+	if targets != nil {
+		b.publish(Event{Type: EventResumed, CheckPointID: it.CheckPointID})
+		go b.resume(context.WithoutCancel(ctx), it.CheckPointID, targets, decided)
+	}
+	return it, nil
+}
+
+// resume resumes checkPointID with the decisions on decided. When the run cannot resume, the
+// interrupts are listed again undecided, so they can be decided once more.
+func (b *Inbox) resume(ctx context.Context, checkPointID string, targets map[string]any, decided []*Interrupt) {
+	iter, err := b.runner.ResumeWithParams(ctx, checkPointID, &adk.ResumeParams{Targets: targets})
+	if err != nil {
+		its := make([]*Interrupt, 0, len(decided))
+		for _, it := range decided {
+			undecided := *it
+			undecided.Decision = nil
+			its = append(its, &undecided)
+		}
+		b.mu.Lock()
+		for _, it := range its {
+			b.pending[it.ID] = it
+		}
+		b.save(ctx)
+		b.mu.Unlock()
+		b.finish(checkPointID, nil, "", fmt.Errorf("failed to resume: %w", err))
+		return
+	}
+	b.drain(checkPointID, iter)
+}
+
+// drain reads the events of a run to its end, and lists the interrupts it ended with.
+func (b *Inbox) drain(checkPointID string, iter *adk.AsyncIterator[*adk.AgentEvent]) {
+	var (
+		output      string
+		interrupted *adk.InterruptInfo
+	)
+	for {
+		event, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if event.Err != nil {
+			b.finish(checkPointID, nil, "", event.Err)
+			return
+		}
+		if event.Action != nil && event.Action.Interrupted != nil {
+			interrupted = event.Action.Interrupted
+		}
+		if event.Output != nil && event.Output.MessageOutput != nil && event.Output.MessageOutput.Role == schema.Assistant {
+			msg, err := event.Output.MessageOutput.GetMessage()
+			if err != nil {
+				b.finish(checkPointID, nil, "", err)
+				return
+			}
+			if msg.Content != "" {
+				output = msg.Content
+			}
+		}
+	}
+	if interrupted == nil {
+		b.finish(checkPointID, nil, output, nil)
+		return
+	}
+
+	var its []*Interrupt
+	for _, ic := range interrupted.InterruptContexts {
+		if !ic.IsRootCause {
+			continue
+		}
+		it := newInterrupt(checkPointID, ic)
+		if it == nil {
+			b.finish(checkPointID, nil, "", fmt.Errorf("unsupported interrupt %T at %s", ic.Info, ic.ID))
+			return
+		}
+		its = append(its, it)
+	}
+	b.finish(checkPointID, its, "", nil)
+}
+
+// finish records how a run ended: failed with err, interrupted with its, or completed with
+// output.
+func (b *Inbox) finish(checkPointID string, its []*Interrupt, output string, err error) {
+	b.mu.Lock()
+	delete(b.running, checkPointID)
+	for _, it := range its {
+		b.pending[it.ID] = it
+	}
+	if len(its) > 0 {
+		b.save(context.Background())
+	}
+	b.mu.Unlock()
+
+	switch {
+	case err != nil:
+		log.Printf("[inbox] run %s failed: %v\n", checkPointID, err)
+		b.publish(Event{Type: EventFailed, CheckPointID: checkPointID, Error: err.Error()})
+	case len(its) > 0:
+		for _, it := range its {
+			b.publish(Event{Type: EventInterrupt, CheckPointID: checkPointID, Interrupt: it})
+		}
+	default:
+		b.publish(Event{Type: EventCompleted, CheckPointID: checkPointID, Output: output})
+	}
+}
+
+// hasPending tells whether checkPointID waits for decisions. The caller holds b.mu.
+func (b *Inbox) hasPending(checkPointID string) bool {
+	for _, it := range b.pending {
+		if it.CheckPointID == checkPointID {
+			return true
+		}
+	}
+	return false
+}
+
+// save writes the pending interrupts to the store. The caller holds b.mu.
+func (b *Inbox) save(ctx context.Context) {
+	if b.store == nil {
+		return
+	}
+	pending := make([]*Interrupt, 0, len(b.pending))
+	for _, it := range b.pending {
+		pending = append(pending, it)
+	}
+	data, err := json.Marshal(pending)
+	if err == nil {
+		err = b.store.Set(ctx, pendingKey, data)
+	}
+	if err != nil {
+		log.Printf("[inbox] failed to save pending interrupts: %v\n", err)
+	}
+}
+
+// Subscribe returns a channel receiving every event from now on, and a function to stop.
+// A subscriber that does not keep up misses events rather than blocking runs.
+func (b *Inbox) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, 64)
+	b.hubMu.Lock()
+	b.subs[ch] = struct{}{}
+	b.hubMu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.hubMu.Lock()
+			delete(b.subs, ch)
+			b.hubMu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+func (b *Inbox) publish(e Event) {
+	b.hubMu.Lock()
+	defer b.hubMu.Unlock()
+	b.seq++
+	e.Seq = b.seq
+	e.At = time.Now()
+	for ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+			log.Printf("[inbox] dropped event %d for a slow subscriber\n", e.Seq)
+		}
+	}
+}
+
+// newInterrupt describes an interrupt of the tools of likeeino/adk/common/tool, nil for others.
+func newInterrupt(checkPointID string, ic *adk.InterruptCtx) *Interrupt {
+	it := &Interrupt{
+		ID:           uuid.NewString(),
+		CheckPointID: checkPointID,
+		Address:      ic.ID,
+		CreatedAt:    time.Now(),
+	}
+	switch info := ic.Info.(type) {
+	case *tool.ApprovalInfo:
+		it.Kind, it.ToolName, it.ToolCallID, it.Arguments = KindApproval, info.ToolName, info.ToolCallID, info.ArgumentsInJSON
+	case *tool.ReviewEditInfo:
+		it.Kind, it.ToolName, it.ToolCallID, it.Arguments = KindReviewEdit, info.ToolName, info.ToolCallID, info.ArgumentsInJSON
+	case *tool.FollowUpInfo:
+		it.Kind, it.Questions = KindFollowUp, info.Questions
+	default:
+		return nil
+	}
+	return it
+}
+
+// resumeData turns a decision into what the tool that interrupted expects to resume with.
+func resumeData(it *Interrupt, d Decision) (any, error) {
+	var reason *string
+	if d.Reason != "" {
+		reason = &d.Reason
+	}
+	switch {
+	case it.Kind == KindApproval && d.Action == ActionApprove:
+		return &tool.ApprovalResult{Approved: true}, nil
+	case it.Kind == KindApproval && d.Action == ActionReject:
+		return &tool.ApprovalResult{DisapproveReason: reason}, nil
+	case it.Kind == KindReviewEdit:
+		result := &tool.ReviewEditResult{}
+		switch d.Action {
+		case ActionApprove:
+			result.NoNeedToEdit = true
+		case ActionReject:
+			result.Disapproved, result.DisapproveReason = true, reason
+		case ActionEdit:
+			if !json.Valid([]byte(d.Arguments)) {
+				return nil, fmt.Errorf("%w: arguments must be valid JSON", ErrInvalidDecision)
+			}
+			result.EditedArgumentsInJSON = &d.Arguments
+		default:
+			return nil, fmt.Errorf("%w: %q does not apply to a %s interrupt", ErrInvalidDecision, d.Action, it.Kind)
+		}
+		return &tool.ReviewEditInfo{
+			ToolName:        it.ToolName,
+			ArgumentsInJSON: it.Arguments,
+			ToolCallID:      it.ToolCallID,
+			ReviewResult:    result,
+		}, nil
+	case it.Kind == KindFollowUp && d.Action == ActionAnswer:
+		if d.Answer == "" {
+			return nil, fmt.Errorf("%w: answer is empty", ErrInvalidDecision)
+		}
+		return &tool.FollowUpInfo{Questions: it.Questions, UserAnswer: d.Answer}, nil
+	case it.Kind == KindFollowUp && d.Action == ActionReject:
+		answer := "The user declined to answer."
+		if reason != nil {
+			answer += " Reason: " + *reason
+		}
+		return &tool.FollowUpInfo{Questions: it.Questions, UserAnswer: answer}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q does not apply to a %s interrupt", ErrInvalidDecision, d.Action, it.Kind)
+	}
+}
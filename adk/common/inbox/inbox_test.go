@@ -0,0 +1,313 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package inbox
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/components/model"
+	einotool "github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/hertz-contrib/sse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"likeeino/adk/common/store"
+	"likeeino/adk/common/tool"
+)
+
+// bookingModel calls BookTicket with the user's message as location, then answers with the
+// tool result.
+type bookingModel struct{}
+
+func (m bookingModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	last := input[len(input)-1]
+	if last.Role == schema.Tool {
+		return schema.AssistantMessage("result: "+last.Content, nil), nil
+	}
+	return schema.AssistantMessage("", []schema.ToolCall{{
+		ID:       "call_1",
+		Function: schema.FunctionCall{Name: "BookTicket", Arguments: `{"location":"` + last.Content + `"}`},
+	}}), nil
+}
+
+func (m bookingModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, err := m.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+}
+
+func (m bookingModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	return m, nil
+}
+
+func newRunner(t *testing.T, checkPoints compose.CheckPointStore, wrap func(einotool.InvokableTool) einotool.BaseTool) *adk.Runner {
+	type bookInput struct {
+		Location string `json:"location"`
+	}
+	book, err := utils.InferTool("BookTicket", "book a ticket", func(ctx context.Context, in bookInput) (string, error) {
+		return "booked to " + in.Location, nil
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+	a, err := adk.NewChatModelAgent(ctx, &adk.ChatModelAgentConfig{
+		Name:        "booker",
+		Description: "books tickets",
+		Model:       bookingModel{},
+		ToolsConfig: adk.ToolsConfig{ToolsNodeConfig: compose.ToolsNodeConfig{Tools: []einotool.BaseTool{wrap(book)}}},
+	})
+	require.NoError(t, err)
+	return adk.NewRunner(ctx, adk.RunnerConfig{Agent: a, CheckPointStore: checkPoints})
+}
+
+func approvable(t einotool.InvokableTool) einotool.BaseTool {
+	return &tool.InvokableApprovableTool{InvokableTool: t}
+}
+
+func reviewable(t einotool.InvokableTool) einotool.BaseTool {
+	return &tool.InvokableReviewEditTool{InvokableTool: t}
+}
+
+// next waits for the next event of type typ.
+func next(t *testing.T, events <-chan Event, typ EventType) Event {
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e := <-events:
+			if e.Type == EventFailed && typ != EventFailed {
+				t.Fatalf("run failed: %s", e.Error)
+			}
+			if e.Type == typ {
+				return e
+			}
+		case <-timeout:
+			t.Fatalf("no %s event", typ)
+		}
+	}
+}
+
+func TestApproval(t *testing.T) {
+	ctx := context.Background()
+	b, err := New(ctx, &Config{Runner: newRunner(t, store.NewInMemoryStore(), approvable)})
+	require.NoError(t, err)
+	events, stop := b.Subscribe()
+	defer stop()
+
+	for _, tc := range []struct {
+		decision Decision
+		output   string
+	}{
+		{Decision{Action: ActionApprove}, "result: booked to Beijing"},
+		{Decision{Action: ActionReject, Reason: "too expensive"}, "result: tool 'BookTicket' disapproved, reason: too expensive"},
+	} {
+		id, err := b.Start(ctx, "", []adk.Message{schema.UserMessage("Beijing")})
+		require.NoError(t, err)
+		it := next(t, events, EventInterrupt).Interrupt
+		assert.Equal(t, id, it.CheckPointID)
+		assert.Equal(t, KindApproval, it.Kind)
+		assert.Equal(t, "BookTicket", it.ToolName)
+		assert.JSONEq(t, `{"location":"Beijing"}`, it.Arguments)
+		assert.Equal(t, []*Interrupt{it}, b.List(id))
+
+		_, err = b.Start(ctx, id, []adk.Message{schema.UserMessage("again")})
+		assert.ErrorIs(t, err, ErrRunning)
+		_, err = b.Decide(ctx, it.ID, Decision{Action: ActionEdit, Arguments: "{}"})
+		assert.ErrorIs(t, err, ErrInvalidDecision)
+
+		_, err = b.Decide(ctx, it.ID, tc.decision)
+		require.NoError(t, err)
+		assert.Equal(t, tc.output, next(t, events, EventCompleted).Output)
+		assert.Empty(t, b.List(""))
+		_, err = b.Decide(ctx, it.ID, tc.decision)
+		assert.ErrorIs(t, err, ErrNotFound)
+	}
+}
+
+func TestReviewEdit(t *testing.T) {
+	ctx := context.Background()
+	b, err := New(ctx, &Config{Runner: newRunner(t, store.NewInMemoryStore(), reviewable)})
+	require.NoError(t, err)
+	events, stop := b.Subscribe()
+	defer stop()
+
+	_, err = b.Start(ctx, "", []adk.Message{schema.UserMessage("Beijing")})
+	require.NoError(t, err)
+	it := next(t, events, EventInterrupt).Interrupt
+	assert.Equal(t, KindReviewEdit, it.Kind)
+
+	_, err = b.Decide(ctx, it.ID, Decision{Action: ActionEdit, Arguments: "not json"})
+	assert.ErrorIs(t, err, ErrInvalidDecision)
+	_, err = b.Decide(ctx, it.ID, Decision{Action: ActionEdit, Arguments: `{"location":"Shanghai"}`})
+	require.NoError(t, err)
+	assert.Contains(t, next(t, events, EventCompleted).Output, "final result: booked to Shanghai")
+}
+
+func TestPendingSurviveRestart(t *testing.T) {
+	ctx := context.Background()
+	checkPoints, err := store.NewSQLiteStore(t.TempDir()+"/checkpoints.db", store.Config{})
+	require.NoError(t, err)
+	defer checkPoints.Close()
+
+	b, err := New(ctx, &Config{Runner: newRunner(t, checkPoints, approvable), Store: checkPoints})
+	require.NoError(t, err)
+	events, stop := b.Subscribe()
+	_, err = b.Start(ctx, "trip", []adk.Message{schema.UserMessage("Beijing")})
+	require.NoError(t, err)
+	it := next(t, events, EventInterrupt).Interrupt
+	stop()
+
+	// a new process lists the interrupt and resumes the checkpoint
+	restarted, err := New(ctx, &Config{Runner: newRunner(t, checkPoints, approvable), Store: checkPoints})
+	require.NoError(t, err)
+	pending := restarted.List("trip")
+	require.Len(t, pending, 1)
+	assert.Equal(t, it.ID, pending[0].ID)
+
+	events, stop = restarted.Subscribe()
+	defer stop()
+	_, err = restarted.Decide(ctx, it.ID, Decision{Action: ActionApprove})
+	require.NoError(t, err)
+	assert.Equal(t, "result: booked to Beijing", next(t, events, EventCompleted).Output)
+
+	reloaded, err := New(ctx, &Config{Runner: newRunner(t, checkPoints, approvable), Store: checkPoints})
+	require.NoError(t, err)
+	assert.Empty(t, reloaded.List(""))
+}
+
+// flakyStore fails reading checkpoints while failing is set.
+type flakyStore struct {
+	store.Store
+	failing atomic.Bool
+}
+
+func (s *flakyStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	if s.failing.Load() {
+		return nil, false, errors.New("store unavailable")
+	}
+	return s.Store.Get(ctx, key)
+}
+
+func TestFailedResumeKeepsInterrupts(t *testing.T) {
+	ctx := context.Background()
+	checkPoints := &flakyStore{Store: store.NewInMemoryStore()}
+	b, err := New(ctx, &Config{Runner: newRunner(t, checkPoints, approvable), Store: checkPoints})
+	require.NoError(t, err)
+	events, stop := b.Subscribe()
+	defer stop()
+
+	_, err = b.Start(ctx, "trip", []adk.Message{schema.UserMessage("Beijing")})
+	require.NoError(t, err)
+	it := next(t, events, EventInterrupt).Interrupt
+
+	checkPoints.failing.Store(true)
+	_, err = b.Decide(ctx, it.ID, Decision{Action: ActionApprove})
+	require.NoError(t, err)
+	assert.Contains(t, next(t, events, EventFailed).Error, "store unavailable")
+
+	// the interrupt is listed again, undecided, and is decided once the store is back
+	pending := b.List("trip")
+	require.Len(t, pending, 1)
+	assert.Equal(t, it.ID, pending[0].ID)
+	assert.Nil(t, pending[0].Decision)
+	checkPoints.failing.Store(false)
+	_, err = b.Decide(ctx, it.ID, Decision{Action: ActionApprove})
+	require.NoError(t, err)
+	assert.Equal(t, "result: booked to Beijing", next(t, events, EventCompleted).Output)
+}
+
+func TestResumeData(t *testing.T) {
+	followUp := &Interrupt{Kind: KindFollowUp, Questions: []string{"when?"}}
+	data, err := resumeData(followUp, Decision{Action: ActionAnswer, Answer: "tomorrow"})
+	require.NoError(t, err)
+	assert.Equal(t, &tool.FollowUpInfo{Questions: []string{"when?"}, UserAnswer: "tomorrow"}, data)
+
+	data, err = resumeData(followUp, Decision{Action: ActionReject, Reason: "private"})
+	require.NoError(t, err)
+	assert.Equal(t, "The user declined to answer. Reason: private", data.(*tool.FollowUpInfo).UserAnswer)
+
+	for _, d := range []Decision{{Action: ActionAnswer}, {Action: ActionApprove}} {
+		_, err = resumeData(followUp, d)
+		assert.ErrorIs(t, err, ErrInvalidDecision)
+	}
+
+	data, err = resumeData(&Interrupt{Kind: KindReviewEdit, ToolName: "t", Arguments: "{}"}, Decision{Action: ActionApprove})
+	require.NoError(t, err)
+	assert.True(t, data.(*tool.ReviewEditInfo).ReviewResult.NoNeedToEdit)
+}
+
+func TestRoutes(t *testing.T) {
+	ctx := context.Background()
+	b, err := New(ctx, &Config{Runner: newRunner(t, store.NewInMemoryStore(), approvable)})
+	require.NoError(t, err)
+	events, stop := b.Subscribe()
+	defer stop()
+
+	engine := route.NewEngine(config.NewOptions(nil))
+	b.BindRoutes(engine.Group("/inbox"))
+
+	body := `{"checkpoint_id":"c1","query":"Beijing"}`
+	w := ut.PerformRequest(engine, "POST", "/inbox/api/runs", &ut.Body{Body: strings.NewReader(body), Len: len(body)})
+	assert.Equal(t, 202, w.Code)
+	it := next(t, events, EventInterrupt).Interrupt
+
+	w = ut.PerformRequest(engine, "GET", "/inbox/api/interrupts?checkpoint_id=c1", nil)
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), it.ID)
+
+	w = ut.PerformRequest(engine, "POST", "/inbox/api/interrupts/missing/approve", nil)
+	assert.Equal(t, 404, w.Code)
+	w = ut.PerformRequest(engine, "POST", "/inbox/api/interrupts/"+it.ID+"/answer", nil)
+	assert.Equal(t, 400, w.Code)
+
+	body = `{"reason":"no"}`
+	w = ut.PerformRequest(engine, "POST", "/inbox/api/interrupts/"+it.ID+"/reject", &ut.Body{Body: strings.NewReader(body), Len: len(body)})
+	assert.Equal(t, 202, w.Code)
+	assert.Contains(t, next(t, events, EventCompleted).Output, "reason: no")
+
+	w = ut.PerformRequest(engine, "GET", "/inbox/", nil)
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), "Approval Inbox")
+}
+
+func TestRelayEvents(t *testing.T) {
+	events := make(chan Event, 2)
+	events <- Event{Seq: 7, Type: EventInterrupt, CheckPointID: "c1", Interrupt: &Interrupt{ID: "i1"}}
+	close(events)
+
+	var got []*sse.Event
+	require.NoError(t, relayEvents(context.Background(), events, func(e *sse.Event) error {
+		got = append(got, e)
+		return nil
+	}))
+	require.Len(t, got, 1)
+	assert.Equal(t, "7", got[0].ID)
+	assert.Equal(t, "interrupt", got[0].Event)
+	assert.Contains(t, string(got[0].Data), `"checkpoint_id":"c1"`)
+}
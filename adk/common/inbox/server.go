@@ -0,0 +1,201 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package inbox
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"log"
+	"mime"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/schema"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/hertz-contrib/sse"
+)
+
+//go:embed web
+var webContent embed.FS
+
+// BindRoutes serves the inbox:
+//
+//	GET  /                          the reviewer page
+//	GET  /api/interrupts            pending interrupts: checkpoint_id
+//	GET  /api/interrupts/:id
+//	POST /api/interrupts/:id/approve
+//	POST /api/interrupts/:id/reject {"reason": "..."}
+//	POST /api/interrupts/:id/edit   {"arguments": "{...}"}, review-edit interrupts
+//	POST /api/interrupts/:id/answer {"answer": "..."}, follow-up interrupts
+//	POST /api/runs                  {"checkpoint_id": "...", "query": "..."} starts a run
+//	GET  /api/events                SSE feed of interrupts, decisions and runs
+func (b *Inbox) BindRoutes(r *route.RouterGroup) {
+	r.GET("/api/interrupts", b.handleList)
+	r.GET("/api/interrupts/:id", b.handleGet)
+	r.POST("/api/interrupts/:id/approve", b.handleDecide(ActionApprove))
+	r.POST("/api/interrupts/:id/reject", b.handleDecide(ActionReject))
+	r.POST("/api/interrupts/:id/edit", b.handleDecide(ActionEdit))
+	r.POST("/api/interrupts/:id/answer", b.handleDecide(ActionAnswer))
+	r.POST("/api/runs", b.handleStart)
+	r.GET("/api/events", b.handleEvents)
+
+	r.GET("/", func(ctx context.Context, c *app.RequestContext) {
+		serveWeb(c, "index.html")
+	})
+	r.GET("/:file", func(ctx context.Context, c *app.RequestContext) {
+		serveWeb(c, c.Param("file"))
+	})
+}
+
+func serveWeb(c *app.RequestContext, file string) {
+	content, err := webContent.ReadFile("web/" + file)
+	if err != nil {
+		c.String(consts.StatusNotFound, "File not found")
+		return
+	}
+	contentType := mime.TypeByExtension(filepath.Ext(file))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Type", contentType)
+	c.Write(content)
+}
+
+func (b *Inbox) handleList(ctx context.Context, c *app.RequestContext) {
+	c.JSON(consts.StatusOK, map[string]interface{}{
+		"interrupts": b.List(c.Query("checkpoint_id")),
+	})
+}
+
+func (b *Inbox) handleGet(ctx context.Context, c *app.RequestContext) {
+	it, ok := b.Get(c.Param("id"))
+	if !ok {
+		writeError(c, consts.StatusNotFound, ErrNotFound)
+		return
+	}
+	c.JSON(consts.StatusOK, it)
+}
+
+func (b *Inbox) handleDecide(action Action) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		d := Decision{}
+		if body := c.Request.Body(); len(body) > 0 {
+			if err := json.Unmarshal(body, &d); err != nil {
+				writeError(c, consts.StatusBadRequest, errors.New("invalid request body: "+err.Error()))
+				return
+			}
+		}
+		d.Action = action
+		it, err := b.Decide(ctx, c.Param("id"), d)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			writeError(c, consts.StatusNotFound, err)
+		case errors.Is(err, ErrAlreadyDecided):
+			writeError(c, consts.StatusConflict, err)
+		case errors.Is(err, ErrInvalidDecision):
+			writeError(c, consts.StatusBadRequest, err)
+		case err != nil:
+			writeError(c, consts.StatusInternalServerError, err)
+		default:
+			c.JSON(consts.StatusAccepted, it)
+		}
+	}
+}
+
+type startRequest struct {
+	CheckPointID string `json:"checkpoint_id"`
+	Query        string `json:"query"`
+}
+
+func (b *Inbox) handleStart(ctx context.Context, c *app.RequestContext) {
+	req := &startRequest{}
+	if err := json.Unmarshal(c.Request.Body(), req); err != nil {
+		writeError(c, consts.StatusBadRequest, errors.New("invalid request body: "+err.Error()))
+		return
+	}
+	if req.Query == "" {
+		writeError(c, consts.StatusBadRequest, errors.New("missing query"))
+		return
+	}
+	id, err := b.Start(ctx, req.CheckPointID, []adk.Message{schema.UserMessage(req.Query)})
+	if errors.Is(err, ErrRunning) {
+		writeError(c, consts.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		writeError(c, consts.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(consts.StatusAccepted, map[string]string{
+		"checkpoint_id": id,
+	})
+}
+
+func (b *Inbox) handleEvents(ctx context.Context, c *app.RequestContext) {
+	events, stop := b.Subscribe()
+	defer stop()
+
+	s := sse.NewStream(c)
+	if err := relayEvents(ctx, events, s.Publish); err != nil {
+		log.Printf("[inbox] failed to publish event: %v\n", err)
+	}
+}
+
+// relayEvents publishes events until ctx is done or the feed stops, pinging while quiet.
+func relayEvents(ctx context.Context, events <-chan Event, publish func(*sse.Event) error) error {
+	ping := time.NewTicker(30 * time.Second)
+	defer ping.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ping.C:
+			if err := publish(&sse.Event{Event: "ping", Data: []byte("{}")}); err != nil {
+				return err
+			}
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+			data, err := json.Marshal(e)
+			if err != nil {
+				log.Printf("[inbox] failed to marshal event: %v\n", err)
+				continue
+			}
+			if err := publish(&sse.Event{
+				ID:    strconv.FormatInt(e.Seq, 10),
+				Event: string(e.Type),
+				Data:  data,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func writeError(c *app.RequestContext, status int, err error) {
+	c.JSON(status, map[string]string{
+		"status": "error",
+		"error":  err.Error(),
+	})
+}
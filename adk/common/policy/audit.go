@@ -0,0 +1,85 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// AuditRecord is one decision of a policy on a tool call.
+type AuditRecord struct {
+	Time       time.Time `json:"time"`
+	Tool       string    `json:"tool"`
+	ToolCallID string    `json:"tool_call_id,omitempty"`
+	Arguments  string    `json:"arguments"`
+	Decision
+	// Resumed is set when the call is decided again as the run resumes from its interrupt.
+	Resumed bool `json:"resumed,omitempty"`
+}
+
+// AuditLog records the decisions of a policy. Failing to record must not fail the call, so
+// implementations log their errors.
+type AuditLog interface {
+	Record(ctx context.Context, r *AuditRecord)
+}
+
+// JSONLAudit writes one JSON record per line.
+type JSONLAudit struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewJSONLAudit(w io.Writer) *JSONLAudit {
+	return &JSONLAudit{w: w}
+}
+
+// OpenAuditFile appends records to the file at path, created if needed. Close the file when
+// done.
+func OpenAuditFile(path string) (*JSONLAudit, *os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create audit dir: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return NewJSONLAudit(f), f, nil
+}
+
+func (a *JSONLAudit) Record(ctx context.Context, r *AuditRecord) {
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		log.Printf("[policy] failed to marshal audit record: %v\n", err)
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.w.Write(append(b, '\n')); err != nil {
+		log.Printf("[policy] failed to write audit record: %v\n", err)
+	}
+}
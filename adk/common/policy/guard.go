@@ -0,0 +1,167 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+
+	tool2 "likeeino/adk/common/tool"
+)
+
+// Wrap guards every tool with the policy, recording its decisions in audit, which may be nil.
+// A streamable tool that needs approval or a review streams its whole result once it ran.
+func (p *Policy) Wrap(tools []tool.BaseTool, audit AuditLog) ([]tool.BaseTool, error) {
+	res := make([]tool.BaseTool, 0, len(tools))
+	for _, t := range tools {
+		g := guard{policy: p, audit: audit}
+		switch t := t.(type) {
+		case tool.InvokableTool:
+			res = append(res, &guardedTool{InvokableTool: t, guard: g})
+		case tool.StreamableTool:
+			res = append(res, &guardedStreamTool{StreamableTool: t, guard: g})
+		default:
+			info, err := t.Info(context.Background())
+			if err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("tool %s is neither invokable nor streamable, it cannot be guarded", info.Name)
+		}
+	}
+	return res, nil
+}
+
+type guard struct {
+	policy *Policy
+	audit  AuditLog
+}
+
+// decide decides the call again when the run resumes from its interrupt, which gives the same
+// decision since the arguments are the same.
+func (g guard) decide(ctx context.Context, name, argumentsInJSON string) Decision {
+	d := g.policy.Evaluate(name, argumentsInJSON)
+	if g.audit != nil {
+		wasInterrupted, _, _ := compose.GetInterruptState[any](ctx)
+		g.audit.Record(ctx, &AuditRecord{
+			Tool:       name,
+			ToolCallID: compose.GetToolCallID(ctx),
+			Arguments:  argumentsInJSON,
+			Decision:   d,
+			Resumed:    wasInterrupted,
+		})
+	}
+	return d
+}
+
+func denied(name string, d Decision) string {
+	msg := fmt.Sprintf("tool '%s' denied by policy", name)
+	if d.Reason != "" {
+		msg += ", reason: " + d.Reason
+	}
+	return msg
+}
+
+type guardedTool struct {
+	tool.InvokableTool
+	guard
+}
+
+func (g *guardedTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return g.InvokableTool.Info(ctx)
+}
+
+func (g *guardedTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	info, err := g.Info(ctx)
+	if err != nil {
+		return "", err
+	}
+	d := g.decide(ctx, info.Name, argumentsInJSON)
+	switch d.Effect {
+	case EffectDeny:
+		return denied(info.Name, d), nil
+	case EffectApproval:
+		return tool2.InvokableApprovableTool{InvokableTool: g.InvokableTool}.InvokableRun(ctx, argumentsInJSON, opts...)
+	case EffectReviewEdit:
+		return tool2.InvokableReviewEditTool{InvokableTool: g.InvokableTool}.InvokableRun(ctx, argumentsInJSON, opts...)
+	default:
+		return g.InvokableTool.InvokableRun(ctx, argumentsInJSON, opts...)
+	}
+}
+
+type guardedStreamTool struct {
+	tool.StreamableTool
+	guard
+}
+
+func (g *guardedStreamTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return g.StreamableTool.Info(ctx)
+}
+
+func (g *guardedStreamTool) StreamableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (*schema.StreamReader[string], error) {
+	info, err := g.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+	d := g.decide(ctx, info.Name, argumentsInJSON)
+	var res string
+	switch d.Effect {
+	case EffectDeny:
+		res = denied(info.Name, d)
+	case EffectApproval:
+		res, err = tool2.InvokableApprovableTool{InvokableTool: collectedTool{g.StreamableTool}}.InvokableRun(ctx, argumentsInJSON, opts...)
+	case EffectReviewEdit:
+		res, err = tool2.InvokableReviewEditTool{InvokableTool: collectedTool{g.StreamableTool}}.InvokableRun(ctx, argumentsInJSON, opts...)
+	default:
+		return g.StreamableTool.StreamableRun(ctx, argumentsInJSON, opts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]string{res}), nil
+}
+
+// collectedTool runs a streamable tool as an invokable one, so the approval and review
+// wrappers can interrupt before it runs.
+type collectedTool struct {
+	tool.StreamableTool
+}
+
+func (c collectedTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	sr, err := c.StreamableRun(ctx, argumentsInJSON, opts...)
+	if err != nil {
+		return "", err
+	}
+	defer sr.Close()
+	var sb strings.Builder
+	for {
+		chunk, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			return sb.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(chunk)
+	}
+}
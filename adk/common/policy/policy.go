@@ -0,0 +1,309 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package policy decides from declarative rules, instead of a wrapper per tool, whether a
+// tool call runs, is denied, or interrupts for approval or review-edit through the wrappers
+// of likeeino/adk/common/tool.
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Effect string
+
+const (
+	// EffectAllow runs the tool call.
+	EffectAllow Effect = "allow"
+	// EffectApproval interrupts for an approval, see tool.InvokableApprovableTool.
+	EffectApproval Effect = "approval"
+	// EffectReviewEdit interrupts for a review that may edit the arguments, see
+	// tool.InvokableReviewEditTool.
+	EffectReviewEdit Effect = "review_edit"
+	// EffectDeny answers the model that the call is denied, without running it.
+	EffectDeny Effect = "deny"
+)
+
+func (e Effect) valid() bool {
+	switch e {
+	case EffectAllow, EffectApproval, EffectReviewEdit, EffectDeny:
+		return true
+	}
+	return false
+}
+
+// Policy is an ordered list of rules, the first rule matching a call deciding its effect.
+//
+//	default: allow
+//	rules:
+//	  - name: no-recursive-delete
+//	    tool: bash
+//	    when:
+//	      - arg: command
+//	        regex: '(?i)\brm\s+-\w*(rf|fr)'
+//	    effect: deny
+//	    reason: recursive deletes are not allowed
+//	  - name: clone-from-allowlist
+//	    tool: gitclone
+//	    when:
+//	      - arg: url
+//	        host_in: [github.com, gitee.com]
+//	        not: true
+//	    effect: review_edit
+//	  - name: task-delete
+//	    tool: task_manager
+//	    when:
+//	      - arg: action
+//	        equals: delete
+//	    effect: approval
+type Policy struct {
+	// Default is the effect of calls no rule matches, allow when empty.
+	Default Effect `yaml:"default"`
+	Rules   []Rule `yaml:"rules"`
+}
+
+// Rule matches calls of the tools named by Tool, a path.Match pattern ("*" for all), whose
+// arguments meet every condition of When.
+type Rule struct {
+	Name   string      `yaml:"name"`
+	Tool   string      `yaml:"tool"`
+	When   []Condition `yaml:"when"`
+	Effect Effect      `yaml:"effect"`
+	// Reason explains the effect, to the model when the call is denied and in the audit log.
+	Reason string `yaml:"reason"`
+}
+
+// Condition tests one argument with exactly one of Equals, In, Regex or HostIn. A missing
+// argument fails the test, so it meets a negated condition: `not: true` with host_in catches
+// a call that leaves the URL out as well as one to another host.
+type Condition struct {
+	// Arg is the dotted path of the argument in the JSON arguments, e.g. "task.id" or
+	// "items.0"; empty tests the whole JSON. Strings are compared as they are, other values
+	// as JSON.
+	Arg    string   `yaml:"arg"`
+	Equals *string  `yaml:"equals"`
+	In     []string `yaml:"in"`
+	Regex  string   `yaml:"regex"`
+	// HostIn matches URLs, including scp-like git@host:repo ones, on these hosts or their
+	// subdomains.
+	HostIn []string `yaml:"host_in"`
+	// Not negates the condition.
+	Not bool `yaml:"not"`
+
+	re *regexp.Regexp
+}
+
+// Decision is the effect of a policy on one call, and the rule that decided it, empty for
+// the default.
+type Decision struct {
+	Effect Effect `json:"effect"`
+	Rule   string `json:"rule,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// LoadPolicy reads a YAML policy.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+	return ParsePolicy(data)
+}
+
+func ParsePolicy(data []byte) (*Policy, error) {
+	var p Policy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	if err := p.Compile(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Compile validates the policy and prepares its conditions. Policies built in code must be
+// compiled before use, ParsePolicy does it.
+func (p *Policy) Compile() error {
+	if p.Default == "" {
+		p.Default = EffectAllow
+	}
+	if !p.Default.valid() {
+		return fmt.Errorf("invalid default effect %q", p.Default)
+	}
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		if !r.Effect.valid() {
+			return fmt.Errorf("rule %s: invalid effect %q", r.Name, r.Effect)
+		}
+		if r.Tool == "" {
+			r.Tool = "*"
+		}
+		if _, err := path.Match(r.Tool, ""); err != nil {
+			return fmt.Errorf("rule %s: invalid tool pattern %q", r.Name, r.Tool)
+		}
+		for j := range r.When {
+			if err := r.When[j].compile(); err != nil {
+				return fmt.Errorf("rule %s: condition %d: %w", r.Name, j+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Condition) compile() error {
+	tests := 0
+	for _, set := range []bool{c.Equals != nil, len(c.In) > 0, c.Regex != "", len(c.HostIn) > 0} {
+		if set {
+			tests++
+		}
+	}
+	if tests != 1 {
+		return fmt.Errorf("exactly one of equals, in, regex and host_in is required")
+	}
+	if c.Regex != "" {
+		re, err := regexp.Compile(c.Regex)
+		if err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+		c.re = re
+	}
+	return nil
+}
+
+// Evaluate decides a call of tool with argumentsInJSON. Arguments that are not valid JSON
+// only meet conditions on the whole arguments.
+func (p *Policy) Evaluate(tool, argumentsInJSON string) Decision {
+	var args any
+	if err := json.Unmarshal([]byte(argumentsInJSON), &args); err != nil {
+		args = nil
+	}
+	for _, r := range p.Rules {
+		if ok, _ := path.Match(r.Tool, tool); !ok {
+			continue
+		}
+		if r.matches(args, argumentsInJSON) {
+			return Decision{Effect: r.Effect, Rule: r.Name, Reason: r.Reason}
+		}
+	}
+	return Decision{Effect: p.Default}
+}
+
+func (r *Rule) matches(args any, raw string) bool {
+	for i := range r.When {
+		if !r.When[i].matches(args, raw) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Condition) matches(args any, raw string) bool {
+	value, ok := raw, true
+	if c.Arg != "" {
+		value, ok = lookup(args, c.Arg)
+	}
+	if !ok {
+		return c.Not
+	}
+	var met bool
+	switch {
+	case c.Equals != nil:
+		met = value == *c.Equals
+	case len(c.In) > 0:
+		for _, v := range c.In {
+			met = met || value == v
+		}
+	case c.re != nil:
+		met = c.re.MatchString(value)
+	default:
+		met = hostIn(value, c.HostIn)
+	}
+	return met != c.Not
+}
+
+// lookup finds the argument at a dotted path, as a string.
+func lookup(args any, arg string) (string, bool) {
+	cur := args
+	for _, key := range strings.Split(arg, ".") {
+		switch v := cur.(type) {
+		case map[string]any:
+			next, ok := v[key]
+			if !ok {
+				return "", false
+			}
+			cur = next
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return "", false
+			}
+			cur = v[i]
+		default:
+			return "", false
+		}
+	}
+	switch v := cur.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	default:
+		b, _ := json.Marshal(v)
+		return string(b), true
+	}
+}
+
+func hostIn(rawURL string, hosts []string) bool {
+	host := urlHost(rawURL)
+	if host == "" {
+		return false
+	}
+	for _, h := range hosts {
+		h = strings.ToLower(h)
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+func urlHost(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		return strings.ToLower(u.Hostname())
+	}
+	// scp-like syntax: [user@]host:path
+	if i := strings.Index(rawURL, ":"); i > 0 && !strings.Contains(rawURL[:i], "/") {
+		host := rawURL[:i]
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+		return strings.ToLower(host)
+	}
+	return ""
+}
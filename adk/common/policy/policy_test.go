@@ -0,0 +1,224 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"likeeino/adk/common/store"
+	tool2 "likeeino/adk/common/tool"
+)
+
+func TestEvaluate(t *testing.T) {
+	p, err := LoadPolicy("testdata/policy.yaml")
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		tool, args string
+		effect     Effect
+		rule       string
+	}{
+		{"bash", `{"command":"rm -rf /tmp/x"}`, EffectDeny, "no-recursive-delete"},
+		{"bash", `{"command":"cd /tmp && RM -Fr x"}`, EffectDeny, "no-recursive-delete"},
+		{"bash", `{"command":"rm -f x"}`, EffectAllow, ""},
+		{"gitclone", `{"url":"https://github.com/cloudwego/eino","action":"clone"}`, EffectAllow, ""},
+		{"gitclone", `{"url":"git@gitee.com:org/repo.git"}`, EffectAllow, ""},
+		{"gitclone", `{"url":"https://github.com.evil.io/x"}`, EffectReviewEdit, "clone-from-allowlist"},
+		// a missing argument fails the test, so it meets a negated condition only
+		{"gitclone", `{"action":"pull"}`, EffectReviewEdit, "clone-from-allowlist"},
+		{"gitclone", `not json`, EffectReviewEdit, "clone-from-allowlist"},
+		{"task_manager", `{"id":"1"}`, EffectAllow, ""},
+		{"task_manager", `{"action":"delete","id":"1"}`, EffectApproval, "task-delete"},
+		{"task_manager", `{"action":"list"}`, EffectAllow, ""},
+		{"task_manager", `{"action":"import","options":{"dry_run":false}}`, EffectApproval, "bulk-import"},
+		{"task_manager", `{"action":"import","options":{"dry_run":true}}`, EffectAllow, ""},
+		{"task_manager", `not json`, EffectAllow, ""},
+	} {
+		d := p.Evaluate(tc.tool, tc.args)
+		assert.Equal(t, tc.effect, d.Effect, tc.args)
+		assert.Equal(t, tc.rule, d.Rule, tc.args)
+	}
+}
+
+func TestParsePolicyErrors(t *testing.T) {
+	for _, data := range []string{
+		`default: maybe`,
+		`rules: [{effect: allowed}]`,
+		`rules: [{effect: deny, tool: "[", when: [{equals: x}]}]`,
+		`rules: [{effect: deny, when: [{arg: a}]}]`,
+		`rules: [{effect: deny, when: [{arg: a, equals: x, regex: x}]}]`,
+		`rules: [{effect: deny, when: [{arg: a, regex: "("}]}]`,
+	} {
+		_, err := ParsePolicy([]byte(data))
+		assert.Error(t, err, data)
+	}
+
+	p, err := ParsePolicy([]byte(`rules: [{effect: deny, when: [{equals: "{}"}]}]`))
+	require.NoError(t, err)
+	assert.Equal(t, EffectAllow, p.Default)
+	assert.Equal(t, "rule-1", p.Rules[0].Name)
+	assert.Equal(t, Decision{Effect: EffectDeny, Rule: "rule-1"}, p.Evaluate("any", "{}"))
+}
+
+func TestWrap(t *testing.T) {
+	ctx := context.Background()
+	p, err := LoadPolicy("testdata/policy.yaml")
+	require.NoError(t, err)
+
+	type input struct {
+		Command string `json:"command"`
+		Action  string `json:"action"`
+	}
+	var ran []string
+	newTool := func(name string) tool.BaseTool {
+		it, err := utils.InferTool(name, name, func(ctx context.Context, in input) (string, error) {
+			ran = append(ran, name+":"+in.Command+in.Action)
+			return "ok", nil
+		})
+		require.NoError(t, err)
+		return it
+	}
+	var buf bytes.Buffer
+	tools, err := p.Wrap([]tool.BaseTool{newTool("bash"), newTool("task_manager")}, NewJSONLAudit(&buf))
+	require.NoError(t, err)
+
+	node, err := compose.NewToolNode(ctx, &compose.ToolsNodeConfig{Tools: tools})
+	require.NoError(t, err)
+	g := compose.NewGraph[*schema.Message, []*schema.Message]()
+	require.NoError(t, g.AddToolsNode("tools", node))
+	require.NoError(t, g.AddEdge(compose.START, "tools"))
+	require.NoError(t, g.AddEdge("tools", compose.END))
+	r, err := g.Compile(ctx, compose.WithCheckPointStore(store.NewInMemoryStore()))
+	require.NoError(t, err)
+
+	call := func(id, name, args string) *schema.Message {
+		return schema.AssistantMessage("", []schema.ToolCall{{ID: id, Function: schema.FunctionCall{Name: name, Arguments: args}}})
+	}
+
+	// allowed and denied calls run, or not, without any interrupt
+	out, err := r.Invoke(ctx, call("c1", "bash", `{"command":"ls"}`), compose.WithCheckPointID("1"))
+	require.NoError(t, err)
+	assert.Equal(t, "ok", out[0].Content)
+	out, err = r.Invoke(ctx, call("c2", "bash", `{"command":"rm -rf /"}`), compose.WithCheckPointID("2"))
+	require.NoError(t, err)
+	assert.Equal(t, "tool 'bash' denied by policy, reason: recursive deletes are not allowed", out[0].Content)
+	assert.Equal(t, []string{"bash:ls"}, ran)
+
+	// a call requiring approval interrupts, and runs once approved
+	_, err = r.Invoke(ctx, call("c3", "task_manager", `{"action":"delete"}`), compose.WithCheckPointID("3"))
+	info, ok := compose.ExtractInterruptInfo(err)
+	require.True(t, ok, "expected an interrupt, got %v", err)
+	var interruptID string
+	for _, ic := range info.InterruptContexts {
+		if ic.IsRootCause {
+			interruptID = ic.ID
+			assert.Equal(t, "task_manager", ic.Info.(*tool2.ApprovalInfo).ToolName)
+		}
+	}
+	require.NotEmpty(t, interruptID)
+	resumeCtx := compose.ResumeWithData(ctx, interruptID, &tool2.ApprovalResult{Approved: true})
+	out, err = r.Invoke(resumeCtx, call("c3", "task_manager", `{"action":"delete"}`), compose.WithCheckPointID("3"))
+	require.NoError(t, err)
+	assert.Equal(t, "ok", out[0].Content)
+	assert.Equal(t, []string{"bash:ls", "task_manager:delete"}, ran)
+
+	var records []AuditRecord
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var rec AuditRecord
+		require.NoError(t, json.Unmarshal([]byte(line), &rec))
+		records = append(records, rec)
+	}
+	require.Len(t, records, 4)
+	assert.Equal(t, EffectAllow, records[0].Effect)
+	assert.Equal(t, "c2", records[1].ToolCallID)
+	assert.Equal(t, "no-recursive-delete", records[1].Rule)
+	assert.Equal(t, EffectApproval, records[2].Effect)
+	assert.False(t, records[2].Resumed)
+	assert.True(t, records[3].Resumed)
+}
+
+func TestWrapStreamable(t *testing.T) {
+	ctx := context.Background()
+	p, err := LoadPolicy("testdata/policy.yaml")
+	require.NoError(t, err)
+
+	type input struct {
+		Command string `json:"command"`
+		Action  string `json:"action"`
+	}
+	var ran []string
+	newTool := func(name string) tool.BaseTool {
+		st, err := utils.InferStreamTool(name, name, func(ctx context.Context, in input) (*schema.StreamReader[string], error) {
+			ran = append(ran, name+":"+in.Command+in.Action)
+			return schema.StreamReaderFromArray([]string{"o", "k"}), nil
+		})
+		require.NoError(t, err)
+		return st
+	}
+	tools, err := p.Wrap([]tool.BaseTool{newTool("bash"), newTool("task_manager")}, nil)
+	require.NoError(t, err)
+
+	node, err := compose.NewToolNode(ctx, &compose.ToolsNodeConfig{Tools: tools})
+	require.NoError(t, err)
+	g := compose.NewGraph[*schema.Message, []*schema.Message]()
+	require.NoError(t, g.AddToolsNode("tools", node))
+	require.NoError(t, g.AddEdge(compose.START, "tools"))
+	require.NoError(t, g.AddEdge("tools", compose.END))
+	r, err := g.Compile(ctx, compose.WithCheckPointStore(store.NewInMemoryStore()))
+	require.NoError(t, err)
+
+	call := func(id, name, args string) *schema.Message {
+		return schema.AssistantMessage("", []schema.ToolCall{{ID: id, Function: schema.FunctionCall{Name: name, Arguments: args}}})
+	}
+
+	out, err := r.Invoke(ctx, call("c1", "bash", `{"command":"ls"}`), compose.WithCheckPointID("1"))
+	require.NoError(t, err)
+	assert.Equal(t, "ok", out[0].Content)
+	out, err = r.Invoke(ctx, call("c2", "bash", `{"command":"rm -rf /"}`), compose.WithCheckPointID("2"))
+	require.NoError(t, err)
+	assert.Equal(t, "tool 'bash' denied by policy, reason: recursive deletes are not allowed", out[0].Content)
+	assert.Equal(t, []string{"bash:ls"}, ran)
+
+	// a streamable call requiring approval interrupts before it runs too
+	_, err = r.Invoke(ctx, call("c3", "task_manager", `{"action":"delete"}`), compose.WithCheckPointID("3"))
+	info, ok := compose.ExtractInterruptInfo(err)
+	require.True(t, ok, "expected an interrupt, got %v", err)
+	assert.Equal(t, []string{"bash:ls"}, ran)
+	var interruptID string
+	for _, ic := range info.InterruptContexts {
+		if ic.IsRootCause {
+			interruptID = ic.ID
+		}
+	}
+	require.NotEmpty(t, interruptID)
+	resumeCtx := compose.ResumeWithData(ctx, interruptID, &tool2.ApprovalResult{Approved: true})
+	out, err = r.Invoke(resumeCtx, call("c3", "task_manager", `{"action":"delete"}`), compose.WithCheckPointID("3"))
+	require.NoError(t, err)
+	assert.Equal(t, "ok", out[0].Content)
+	assert.Equal(t, []string{"bash:ls", "task_manager:delete"}, ran)
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package store
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// ErrDecrypt is returned by Get for a checkpoint that does not decrypt with the store's
+// cipher: another key, a tampered value, or one stored under another key.
+var ErrDecrypt = errors.New("failed to decrypt checkpoint")
+
+// Cipher encrypts checkpoints. The checkpoint id is passed as associated data, so a value
+// only decrypts under the id it was stored with.
+type Cipher interface {
+	Seal(key string, plaintext []byte) ([]byte, error)
+	Open(key string, ciphertext []byte) ([]byte, error)
+}
+
+type aesCipher struct {
+	aead cipher.AEAD
+}
+
+// NewAESCipher encrypts with AES-GCM, key being 16, 24 or 32 bytes. Values are stored as the
+// random nonce followed by the ciphertext.
+func NewAESCipher(key []byte) (Cipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid checkpoint key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gcm: %w", err)
+	}
+	return &aesCipher{aead: aead}, nil
+}
+
+// DecodeKey decodes a base64 encoded 32 byte key, such as one made by `openssl rand -base64 32`.
+// Passphrases are refused: hashed as is, they are as easy to guess as the passphrase.
+func DecodeKey(s string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid checkpoint key: not base64: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("invalid checkpoint key: %d bytes, want 32", len(key))
+	}
+	return key, nil
+}
+
+func (c *aesCipher) Seal(key string, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return c.aead.Seal(nonce, nonce, plaintext, []byte(key)), nil
+}
+
+func (c *aesCipher) Open(key string, ciphertext []byte) ([]byte, error) {
+	n := c.aead.NonceSize()
+	if len(ciphertext) < n {
+		return nil, ErrDecrypt
+	}
+	plaintext, err := c.aead.Open(nil, ciphertext[:n], ciphertext[n:], []byte(key))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+func seal(c Cipher, key string, value []byte) ([]byte, error) {
+	if c == nil {
+		return value, nil
+	}
+	return c.Seal(key, value)
+}
+
+func open(c Cipher, key string, value []byte) ([]byte, error) {
+	if c == nil {
+		return value, nil
+	}
+	return c.Open(key, value)
+}
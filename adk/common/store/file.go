@@ -0,0 +1,203 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const checkpointExt = ".ckpt"
+
+// FileStore keeps one file per checkpoint in a directory, named after the SHA-256 of its id.
+// A file is the expiry (unix nanoseconds, big endian, 0 for never) followed by the value, and
+// is replaced atomically, so a crash never leaves a torn checkpoint.
+type FileStore struct {
+	dir  string
+	cfg  Config
+	stop func()
+
+	// mu orders writers of this process; readers rely on the atomic renames.
+	mu sync.Mutex
+}
+
+func NewFileStore(dir string, cfg Config) (*FileStore, error) {
+	if dir == "" {
+		dir = "data/checkpoints"
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint dir: %w", err)
+	}
+	s := &FileStore{dir: dir, cfg: cfg}
+	s.stop = gcLoop(cfg, "file", s.GC)
+	return s, nil
+}
+
+func (s *FileStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+checkpointExt)
+}
+
+func (s *FileStore) Set(ctx context.Context, key string, value []byte) error {
+	sealed, err := seal(s.cfg.Cipher, key, value)
+	if err != nil {
+		return err
+	}
+	var expiresAt int64
+	if at := s.cfg.expiry(); !at.IsZero() {
+		expiresAt = at.UnixNano()
+	}
+	b := binary.BigEndian.AppendUint64(make([]byte, 0, 8+len(sealed)), uint64(expiresAt))
+	b = append(b, sealed...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return writeFileAtomic(s.path(key), b)
+}
+
+func (s *FileStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	b, err := os.ReadFile(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	expiresAt, value, err := decodeCheckpointFile(b)
+	if err != nil {
+		return nil, false, err
+	}
+	if expired(expiresAt) {
+		return nil, false, nil
+	}
+	value, err = open(s.cfg.Cipher, key, value)
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (s *FileStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete checkpoint: %w", err)
+	}
+	return nil
+}
+
+// GC removes the files of expired checkpoints, and temporary files left by a crash.
+func (s *FileStore) GC(ctx context.Context) (int, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, e := range entries {
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+		path := filepath.Join(s.dir, e.Name())
+		if strings.HasSuffix(e.Name(), ".tmp") {
+			if info, err := e.Info(); err == nil && now().Sub(info.ModTime()) > time.Hour {
+				os.Remove(path)
+			}
+			continue
+		}
+		if !strings.HasSuffix(e.Name(), checkpointExt) {
+			continue
+		}
+		b, err := readHeader(path)
+		if err != nil {
+			continue
+		}
+		if at, _, err := decodeCheckpointFile(b); err == nil && expired(at) {
+			if err := os.Remove(path); err == nil {
+				n++
+			}
+		}
+	}
+	return n, nil
+}
+
+func (s *FileStore) Close() error {
+	s.stop()
+	return nil
+}
+
+func readHeader(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	b := make([]byte, 8)
+	if _, err := io.ReadFull(f, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func decodeCheckpointFile(b []byte) (time.Time, []byte, error) {
+	if len(b) < 8 {
+		return time.Time{}, nil, errors.New("corrupted checkpoint file")
+	}
+	var at time.Time
+	if ns := int64(binary.BigEndian.Uint64(b)); ns != 0 {
+		at = time.Unix(0, ns)
+	}
+	return at, b[8:], nil
+}
+
+// writeFileAtomic writes data to a temporary file, syncs it and renames it over path.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to sync checkpoint: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace checkpoint: %w", err)
+	}
+	return nil
+}
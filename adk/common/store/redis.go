@@ -0,0 +1,85 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const defaultRedisCheckpointPrefix = "eino:checkpoint:"
+
+// RedisStore keeps every checkpoint in a string key <prefix><id>. Redis expires the keys
+// itself, so GC has nothing to do.
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+	cfg    Config
+}
+
+// NewRedisStore wraps a client, usually created with likeeino/pkg/redis.NewClient, which Close
+// closes. An empty prefix defaults to "eino:checkpoint:".
+func NewRedisStore(client *redis.Client, prefix string, cfg Config) *RedisStore {
+	if prefix == "" {
+		prefix = defaultRedisCheckpointPrefix
+	}
+	return &RedisStore{client: client, prefix: prefix, cfg: cfg}
+}
+
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte) error {
+	sealed, err := seal(s.cfg.Cipher, key, value)
+	if err != nil {
+		return err
+	}
+	if err := s.client.Set(ctx, s.prefix+key, sealed, max(s.cfg.TTL, 0)).Err(); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	b, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+	value, err := open(s.cfg.Cipher, key, b)
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (s *RedisStore) Delete(ctx context.Context, key string) error {
+	if err := s.client.Del(ctx, s.prefix+key).Err(); err != nil {
+		return fmt.Errorf("failed to delete checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisStore) GC(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
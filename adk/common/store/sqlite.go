@@ -0,0 +1,123 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	_ "modernc.org/sqlite"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS checkpoints (
+	id         TEXT PRIMARY KEY,
+	body       BLOB NOT NULL,
+	expires_at INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS checkpoints_expires_at ON checkpoints (expires_at) WHERE expires_at > 0;`
+
+// SQLiteStore keeps checkpoints in one embedded SQLite database file, which can be shared by
+// several processes on the same host. expires_at is in unix nanoseconds, 0 for never.
+type SQLiteStore struct {
+	db   *sql.DB
+	cfg  Config
+	stop func()
+}
+
+func NewSQLiteStore(path string, cfg Config) (*SQLiteStore, error) {
+	if path == "" {
+		path = "data/checkpoints/checkpoints.db"
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create sqlite dir: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite: %w", err)
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to init sqlite schema: %w", err)
+	}
+	s := &SQLiteStore{db: db, cfg: cfg}
+	s.stop = gcLoop(cfg, "sqlite", s.GC)
+	return s, nil
+}
+
+func (s *SQLiteStore) Set(ctx context.Context, key string, value []byte) error {
+	sealed, err := seal(s.cfg.Cipher, key, value)
+	if err != nil {
+		return err
+	}
+	var expiresAt int64
+	if at := s.cfg.expiry(); !at.IsZero() {
+		expiresAt = at.UnixNano()
+	}
+	if _, err := s.db.ExecContext(ctx, `
+INSERT INTO checkpoints (id, body, expires_at) VALUES (?, ?, ?)
+ON CONFLICT (id) DO UPDATE SET body = excluded.body, expires_at = excluded.expires_at`,
+		key, sealed, expiresAt); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	var body []byte
+	err := s.db.QueryRowContext(ctx,
+		`SELECT body FROM checkpoints WHERE id = ? AND (expires_at = 0 OR expires_at > ?)`,
+		key, now().UnixNano()).Scan(&body)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+	value, err := open(s.cfg.Cipher, key, body)
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (s *SQLiteStore) Delete(ctx context.Context, key string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM checkpoints WHERE id = ?`, key); err != nil {
+		return fmt.Errorf("failed to delete checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) GC(ctx context.Context) (int, error) {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM checkpoints WHERE expires_at > 0 AND expires_at <= ?`, now().UnixNano())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired checkpoints: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func (s *SQLiteStore) Close() error {
+	s.stop()
+	return s.db.Close()
+}
@@ -14,30 +14,222 @@
  * limitations under the License.
  */
 
+// Package store provides compose.CheckPointStore implementations for interrupted agents: in
+// memory, in files, in SQLite and in Redis. Checkpoints may expire after a TTL and be
+// encrypted at rest.
 package store
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
 
 	"github.com/cloudwego/eino/compose"
+
+	redispkg "likeeino/pkg/redis"
 )
 
-func NewInMemoryStore() compose.CheckPointStore {
-	return &inMemoryStore{
-		mem: map[string][]byte{},
+// Store is a compose.CheckPointStore that can also drop checkpoints, e.g. once an interrupt
+// has been resumed. Implementations are safe for concurrent use.
+type Store interface {
+	compose.CheckPointStore
+	Delete(ctx context.Context, key string) error
+	// GC removes the expired checkpoints and returns how many it removed. Expired checkpoints
+	// are never returned by Get, GC only reclaims their space.
+	GC(ctx context.Context) (int, error)
+	// Close stops the background GC and releases the backend.
+	Close() error
+}
+
+type Config struct {
+	// TTL is how long a checkpoint is kept after it was last set, forever when 0.
+	TTL time.Duration
+	// GCInterval runs GC in the background, never when 0 or without a TTL.
+	GCInterval time.Duration
+	// Cipher encrypts checkpoints at rest, see NewAESCipher.
+	Cipher Cipher
+}
+
+// now is the clock of expiry, replaced in tests.
+var now = time.Now
+
+// expiry is when a checkpoint set now expires, the zero time for never.
+func (c Config) expiry() time.Time {
+	if c.TTL <= 0 {
+		return time.Time{}
+	}
+	return now().Add(c.TTL)
+}
+
+func expired(at time.Time) bool {
+	return !at.IsZero() && !now().Before(at)
+}
+
+// gcLoop runs gc every cfg.GCInterval until the returned stop func is called.
+func gcLoop(cfg Config, name string, gc func(context.Context) (int, error)) (stop func()) {
+	if cfg.GCInterval <= 0 || cfg.TTL <= 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(cfg.GCInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				n, err := gc(context.Background())
+				if err != nil {
+					log.Printf("[checkpoint] %s gc failed: %v\n", name, err)
+				} else if n > 0 {
+					log.Printf("[checkpoint] %s gc removed %d expired checkpoints\n", name, n)
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// NewFromEnv creates the store chosen by CHECKPOINT_STORE: "memory" (default), "file"
+// (CHECKPOINT_DIR), "sqlite" (CHECKPOINT_SQLITE_PATH) or "redis" (REDIS_ADDR,
+// CHECKPOINT_REDIS_PREFIX). CHECKPOINT_TTL (a duration such as 24h) expires checkpoints, GC
+// running every tenth of it, and CHECKPOINT_KEY, a base64 encoded 32 byte key, encrypts them.
+func NewFromEnv() (Store, error) {
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return NewFromEnvConfig(cfg)
+}
+
+// ConfigFromEnv reads the Config of NewFromEnv from CHECKPOINT_TTL and CHECKPOINT_KEY.
+func ConfigFromEnv() (Config, error) {
+	var cfg Config
+	if v := os.Getenv("CHECKPOINT_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid CHECKPOINT_TTL: %w", err)
+		}
+		cfg.TTL, cfg.GCInterval = ttl, ttl/10
+	}
+	if v := os.Getenv("CHECKPOINT_KEY"); v != "" {
+		key, err := DecodeKey(v)
+		if err != nil {
+			return Config{}, err
+		}
+		c, err := NewAESCipher(key)
+		if err != nil {
+			return Config{}, err
+		}
+		cfg.Cipher = c
+	}
+	return cfg, nil
+}
+
+// NewFromEnvConfig creates the store chosen by CHECKPOINT_STORE like NewFromEnv, but with cfg,
+// e.g. to keep records that must not expire in the same backend.
+func NewFromEnvConfig(cfg Config) (Store, error) {
+	switch kind := os.Getenv("CHECKPOINT_STORE"); kind {
+	case "", "memory":
+		return NewMemoryStore(cfg), nil
+	case "file":
+		return NewFileStore(os.Getenv("CHECKPOINT_DIR"), cfg)
+	case "sqlite":
+		return NewSQLiteStore(os.Getenv("CHECKPOINT_SQLITE_PATH"), cfg)
+	case "redis":
+		addr := os.Getenv("REDIS_ADDR")
+		if addr == "" {
+			addr = "localhost:6379"
+		}
+		client := redispkg.NewClient(addr)
+		if err := client.Ping(context.Background()).Err(); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("failed to connect to redis: %w", err)
+		}
+		return NewRedisStore(client, os.Getenv("CHECKPOINT_REDIS_PREFIX"), cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown CHECKPOINT_STORE %q", kind)
+	}
+}
+
+// NewInMemoryStore creates a MemoryStore keeping checkpoints forever.
+func NewInMemoryStore() *MemoryStore {
+	return NewMemoryStore(Config{})
+}
+
+// MemoryStore keeps checkpoints in a map, they are lost when the process exits.
+type MemoryStore struct {
+	cfg  Config
+	stop func()
+
+	mu  sync.RWMutex
+	mem map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+func NewMemoryStore(cfg Config) *MemoryStore {
+	s := &MemoryStore{cfg: cfg, mem: map[string]memoryEntry{}}
+	s.stop = gcLoop(cfg, "memory", s.GC)
+	return s
+}
+
+func (s *MemoryStore) Set(ctx context.Context, key string, value []byte) error {
+	sealed, err := seal(s.cfg.Cipher, key, value)
+	if err != nil {
+		return err
 	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// keep a copy, the caller may reuse value
+	s.mem[key] = memoryEntry{value: bytes.Clone(sealed), expiresAt: s.cfg.expiry()}
+	return nil
 }
 
-type inMemoryStore struct {
-	mem map[string][]byte
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.RLock()
+	e, ok := s.mem[key]
+	s.mu.RUnlock()
+	if !ok || expired(e.expiresAt) {
+		return nil, false, nil
+	}
+	value, err := open(s.cfg.Cipher, key, e.value)
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
 }
 
-func (i *inMemoryStore) Set(ctx context.Context, key string, value []byte) error {
-	i.mem[key] = value
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.mem, key)
 	return nil
 }
 
-func (i *inMemoryStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
-	v, ok := i.mem[key]
-	return v, ok, nil
+func (s *MemoryStore) GC(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for key, e := range s.mem {
+		if expired(e.expiresAt) {
+			delete(s.mem, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (s *MemoryStore) Close() error {
+	s.stop()
+	return nil
 }
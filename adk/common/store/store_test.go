@@ -0,0 +1,301 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	redispkg "likeeino/pkg/redis"
+)
+
+// a backend returns a new store and a func moving its clock forward
+var storeBackends = map[string]func(t *testing.T, cfg Config) (Store, func(time.Duration)){
+	"memory": func(t *testing.T, cfg Config) (Store, func(time.Duration)) {
+		return NewMemoryStore(cfg), fakeClock(t)
+	},
+	"file": func(t *testing.T, cfg Config) (Store, func(time.Duration)) {
+		s, err := NewFileStore(t.TempDir(), cfg)
+		require.NoError(t, err)
+		return s, fakeClock(t)
+	},
+	"sqlite": func(t *testing.T, cfg Config) (Store, func(time.Duration)) {
+		s, err := NewSQLiteStore(filepath.Join(t.TempDir(), "checkpoints.db"), cfg)
+		require.NoError(t, err)
+		return s, fakeClock(t)
+	},
+	"redis": func(t *testing.T, cfg Config) (Store, func(time.Duration)) {
+		mr := miniredis.RunT(t)
+		return NewRedisStore(redispkg.NewClient(mr.Addr()), "", cfg), mr.FastForward
+	},
+}
+
+func fakeClock(t *testing.T) func(time.Duration) {
+	var (
+		mu  sync.Mutex
+		cur = time.Unix(1700000000, 0)
+	)
+	now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return cur
+	}
+	t.Cleanup(func() { now = time.Now })
+	return func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		cur = cur.Add(d)
+	}
+}
+
+func TestCheckPointStores(t *testing.T) {
+	for name, newStore := range storeBackends {
+		t.Run(name, func(t *testing.T) {
+			t.Run("lifecycle", func(t *testing.T) { testStoreLifecycle(t, newStore) })
+			t.Run("ttl", func(t *testing.T) { testStoreTTL(t, newStore) })
+			t.Run("encryption", func(t *testing.T) { testStoreEncryption(t, newStore) })
+			t.Run("concurrent", func(t *testing.T) { testStoreConcurrent(t, newStore) })
+		})
+	}
+}
+
+func testStoreLifecycle(t *testing.T, newStore func(*testing.T, Config) (Store, func(time.Duration))) {
+	ctx := context.Background()
+	s, _ := newStore(t, Config{})
+	defer s.Close()
+
+	_, ok, err := s.Get(ctx, "missing")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	value := []byte("state")
+	require.NoError(t, s.Set(ctx, "a", value))
+	value[0] = 'S' // the store keeps its own copy
+	got, ok, err := s.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("state"), got)
+
+	require.NoError(t, s.Set(ctx, "a", []byte("resumed")))
+	got, _, err = s.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("resumed"), got)
+
+	require.NoError(t, s.Set(ctx, "ids/may contain:anything", []byte{}))
+	_, ok, err = s.Get(ctx, "ids/may contain:anything")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	require.NoError(t, s.Delete(ctx, "a"))
+	require.NoError(t, s.Delete(ctx, "a"))
+	_, ok, err = s.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func testStoreTTL(t *testing.T, newStore func(*testing.T, Config) (Store, func(time.Duration))) {
+	ctx := context.Background()
+	s, advance := newStore(t, Config{TTL: time.Minute})
+	defer s.Close()
+
+	require.NoError(t, s.Set(ctx, "old", []byte("1")))
+	advance(40 * time.Second)
+	require.NoError(t, s.Set(ctx, "new", []byte("2")))
+	advance(30 * time.Second)
+
+	// Get hides expired checkpoints before GC removes them
+	_, ok, err := s.Get(ctx, "old")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	_, ok, err = s.Get(ctx, "new")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = s.GC(ctx)
+	assert.NoError(t, err)
+	_, ok, _ = s.Get(ctx, "new")
+	assert.True(t, ok)
+
+	// setting a checkpoint again renews it
+	require.NoError(t, s.Set(ctx, "new", []byte("3")))
+	advance(50 * time.Second)
+	got, ok, err := s.Get(ctx, "new")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("3"), got)
+}
+
+func testStoreEncryption(t *testing.T, newStore func(*testing.T, Config) (Store, func(time.Duration))) {
+	ctx := context.Background()
+	c, err := NewAESCipher(testKey(1))
+	require.NoError(t, err)
+	s, _ := newStore(t, Config{Cipher: c})
+	defer s.Close()
+
+	require.NoError(t, s.Set(ctx, "a", []byte("approve booking")))
+	got, ok, err := s.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("approve booking"), got)
+}
+
+func testStoreConcurrent(t *testing.T, newStore func(*testing.T, Config) (Store, func(time.Duration))) {
+	ctx := context.Background()
+	s, _ := newStore(t, Config{TTL: time.Hour})
+	defer s.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				key := fmt.Sprintf("k%d", j%3)
+				assert.NoError(t, s.Set(ctx, key, []byte(fmt.Sprintf("v%d", i))))
+				_, _, err := s.Get(ctx, key)
+				assert.NoError(t, err)
+			}
+			_, err := s.GC(ctx)
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	for j := 0; j < 3; j++ {
+		got, ok, err := s.Get(ctx, fmt.Sprintf("k%d", j))
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Regexp(t, `^v\d$`, string(got))
+	}
+}
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func TestDecodeKey(t *testing.T) {
+	key, err := DecodeKey(base64.StdEncoding.EncodeToString(testKey(1)))
+	assert.NoError(t, err)
+	assert.Equal(t, testKey(1), key)
+
+	// a passphrase or a short key is refused
+	_, err = DecodeKey("correct horse battery staple")
+	assert.Error(t, err)
+	_, err = DecodeKey(base64.StdEncoding.EncodeToString([]byte("0123456789abcdef")))
+	assert.Error(t, err)
+}
+
+func TestAESCipher(t *testing.T) {
+	c, err := NewAESCipher(testKey(1))
+	require.NoError(t, err)
+	sealed, err := c.Seal("a", []byte("state"))
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(sealed, []byte("state")))
+
+	got, err := c.Open("a", sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("state"), got)
+
+	// a value moved to another id, a tampered one or another key do not decrypt
+	_, err = c.Open("b", sealed)
+	assert.ErrorIs(t, err, ErrDecrypt)
+	sealed[len(sealed)-1] ^= 1
+	_, err = c.Open("a", sealed)
+	assert.ErrorIs(t, err, ErrDecrypt)
+	other, err := NewAESCipher(testKey(2))
+	require.NoError(t, err)
+	sealed[len(sealed)-1] ^= 1
+	_, err = other.Open("a", sealed)
+	assert.ErrorIs(t, err, ErrDecrypt)
+
+	_, err = NewAESCipher([]byte("short"))
+	assert.Error(t, err)
+}
+
+func TestFileStoreAtRest(t *testing.T) {
+	ctx := context.Background()
+	advance := fakeClock(t)
+	c, err := NewAESCipher(testKey(1))
+	require.NoError(t, err)
+	dir := t.TempDir()
+	s, err := NewFileStore(dir, Config{TTL: time.Minute, Cipher: c})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Set(ctx, "a", []byte("approve booking")))
+	files, err := filepath.Glob(filepath.Join(dir, "*"+checkpointExt))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	b, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(b, []byte("approve booking")))
+
+	// a store reopened with another key cannot read it
+	other, err := NewAESCipher(testKey(2))
+	require.NoError(t, err)
+	reopened, err := NewFileStore(dir, Config{Cipher: other})
+	require.NoError(t, err)
+	_, _, err = reopened.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrDecrypt)
+
+	advance(2 * time.Minute)
+	n, err := s.GC(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	files, _ = filepath.Glob(filepath.Join(dir, "*"))
+	assert.Empty(t, files)
+}
+
+func TestNewFromEnv(t *testing.T) {
+	t.Setenv("CHECKPOINT_STORE", "sqlite")
+	t.Setenv("CHECKPOINT_SQLITE_PATH", filepath.Join(t.TempDir(), "checkpoints.db"))
+	t.Setenv("CHECKPOINT_TTL", "1h")
+	t.Setenv("CHECKPOINT_KEY", "secret")
+	_, err := NewFromEnv()
+	assert.ErrorContains(t, err, "invalid checkpoint key")
+
+	t.Setenv("CHECKPOINT_KEY", base64.StdEncoding.EncodeToString(testKey(1)))
+	s, err := NewFromEnv()
+	require.NoError(t, err)
+	defer s.Close()
+	assert.IsType(t, &SQLiteStore{}, s)
+	assert.Equal(t, time.Hour, s.(*SQLiteStore).cfg.TTL)
+	assert.NotNil(t, s.(*SQLiteStore).cfg.Cipher)
+
+	cfg, err := ConfigFromEnv()
+	require.NoError(t, err)
+	cfg.TTL, cfg.GCInterval = 0, 0
+	forever, err := NewFromEnvConfig(cfg)
+	require.NoError(t, err)
+	defer forever.Close()
+	assert.Zero(t, forever.(*SQLiteStore).cfg.TTL)
+	assert.NotNil(t, forever.(*SQLiteStore).cfg.Cipher)
+
+	t.Setenv("CHECKPOINT_STORE", "etcd")
+	_, err = NewFromEnv()
+	assert.Error(t, err)
+}
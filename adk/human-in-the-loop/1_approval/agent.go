@@ -4,8 +4,8 @@ import (
 	"context"
 	"fmt"
 	"github.com/cloudwego/eino/schema"
-	"likeeino/adk/common/model"
 	"likeeino/internal/logs"
+	"likeeino/pkg/model"
 	"log"
 
 	"github.com/cloudwego/eino/adk"
@@ -40,12 +40,16 @@ func NewTicketBookingAgent() adk.Agent {
 		log.Fatal(err)
 	}
 	//构建ChatModelAgent,主要包括大模型配置和工具
+	cm, err := model.NewChatModel(ctx)
+	if err != nil {
+		log.Fatal(fmt.Errorf("failed to create chat model: %w", err))
+	}
 	a, err := adk.NewChatModelAgent(ctx, &adk.ChatModelAgentConfig{
 		Name:        "TicketBooker",
 		Description: "An agent that can book tickets",
 		Instruction: `You are an expert ticket booker.
 Based on the user's request, use the "BookTicket" tool to book tickets.`,
-		Model: model.NewChatModel(),
+		Model: cm,
 		ToolsConfig: adk.ToolsConfig{
 			ToolsNodeConfig: compose.ToolsNodeConfig{
 				Tools: []tool.BaseTool{
@@ -20,14 +20,19 @@ func main() {
 	ctx := context.Background()
 	//创建机票agent
 	a := NewTicketBookingAgent()
+	// CHECKPOINT_STORE=file|sqlite|redis keeps the checkpoints across restarts, see store.NewFromEnv
+	checkPointStore, err := store.NewFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer checkPointStore.Close()
 	runner := adk.NewRunner(ctx, adk.RunnerConfig{
 		EnableStreaming: true, // you can disable streaming here
 		Agent:           a,
 
 		// provide a CheckPointStore for eino to persist the execution state of the agent for later resumption.
-		// Here we use an in-memory store for simplicity.
-		// In the real world, you can use a distributed store like Redis to persist the checkpoints.
-		CheckPointStore: store.NewInMemoryStore(),
+		// It is in memory by default; in the real world, use a durable or distributed one like Redis.
+		CheckPointStore: checkPointStore,
 	})
 	iter := runner.Query(ctx, "book a ticket for Martin, to Beijing, on 2025-12-01, the phone number is 1234567. directly call tool.", adk.WithCheckPointID("1"))
 	var lastEvent *adk.AgentEvent
@@ -87,7 +92,7 @@ func main() {
 	// can happen in different processes or machines, as long as you use the same `CheckPointID`,
 	// and you provided a distributed `CheckPointStore` when creating the `Runner` instance.
 	//恢复执行,即传入检查点ID和恢复标识,并将修改的参数apResult传入
-	iter, err := runner.ResumeWithParams(ctx, "1", &adk.ResumeParams{
+	iter, err = runner.ResumeWithParams(ctx, "1", &adk.ResumeParams{
 		Targets: map[string]any{
 			interruptID: apResult,
 		},
@@ -9,8 +9,8 @@ import (
 	"fmt"
 	"github.com/cloudwego/eino/callbacks"
 	"github.com/cloudwego/eino/schema"
-	"likeeino/adk/common/model"
 	"likeeino/internal/logs"
+	"likeeino/pkg/model"
 	"log"
 
 	"github.com/cloudwego/eino/adk"
@@ -24,13 +24,17 @@ func NewTicketAgent() adk.Agent {
 	// 注册为全局 handler，这样后续的工具节点都会触发
 	callbacks.AppendGlobalHandlers(&loggerCallbacks{})
 
+	cm, err := model.NewChatModel(ctx)
+	if err != nil {
+		log.Fatal(fmt.Errorf("failed to create chat model: %w", err))
+	}
 	a, err := adk.NewChatModelAgent(ctx, &adk.ChatModelAgentConfig{
 		Name:        "TicketBooker",
 		Description: "An agent that can book tickets",
 		Instruction: `You are an expert ticket booker. Your goal is to book a ticket for a user.
 If you have enough information (e.g., location, passenger name, etc.), use the 'BookTicket' tool to book the ticket.
 If you are missing information, you should ask the user for it.`,
-		Model: model.NewChatModel(),
+		Model: cm,
 		ToolsConfig: adk.ToolsConfig{
 			ToolsNodeConfig: compose.ToolsNodeConfig{
 				Tools: []tool.BaseTool{&tool2.InvokableReviewEditTool{InvokableTool: NewBookTicketTool()}},
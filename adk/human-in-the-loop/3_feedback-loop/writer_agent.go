@@ -19,7 +19,7 @@ package main
 import (
 	"context"
 	"fmt"
-	"likeeino/adk/common/model"
+	"likeeino/pkg/model"
 	"log"
 
 	"github.com/cloudwego/eino/adk"
@@ -28,13 +28,17 @@ import (
 func NewWriterAgent() adk.Agent {
 	ctx := context.Background()
 
+	cm, err := model.NewChatModel(ctx)
+	if err != nil {
+		log.Fatal(fmt.Errorf("failed to create chat model: %w", err))
+	}
 	a, err := adk.NewChatModelAgent(ctx, &adk.ChatModelAgentConfig{
 		Name:        "WriterAgent",
 		Description: "An agent that can write poems",
 		Instruction: `You are an expert writer that can write poems. 
 If feedback is received for the previous version of your poem, you need to modify the poem according to the feedback.
 Your response should ALWAYS contain ONLY the poem, and nothing else.`,
-		Model: model.NewChatModel(),
+		Model: cm,
 		//将代理的响应存储在会话中,OutputKey为mp中的key,value为代理的响应数据
 		OutputKey: "content_to_review",
 	})
@@ -19,7 +19,7 @@ package main
 import (
 	"context"
 	"fmt"
-	"likeeino/adk/common/model"
+	"likeeino/pkg/model"
 	"log"
 
 	"github.com/cloudwego/eino/adk"
@@ -41,6 +41,10 @@ import (
 func NewFollowUpAgent() adk.Agent {
 	ctx := context.Background()
 
+	cm, err := model.NewChatModel(ctx)
+	if err != nil {
+		log.Fatal(fmt.Errorf("failed to create chat model: %w", err))
+	}
 	a, err := adk.NewChatModelAgent(ctx, &adk.ChatModelAgentConfig{
 		Name: "FollowUpAgent",
 		//一个可以提问并从答案中提取信息的代理
@@ -54,7 +58,7 @@ Until one of the following conditions is met:
 After that, you should summarize the information extracted and return it in a matter of fact tone.
 In your final response, DO NOT make any suggestions, just summarize the information extracted.
 DO NOT ask more than 3 questions at a time.`,
-		Model: model.NewChatModel(),
+		Model: cm,
 		ToolsConfig: adk.ToolsConfig{
 			ToolsNodeConfig: compose.ToolsNodeConfig{
 				Tools: []tool.BaseTool{tool2.GetFollowUpTool()},
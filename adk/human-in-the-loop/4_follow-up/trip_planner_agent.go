@@ -19,7 +19,7 @@ package main
 import (
 	"context"
 	"fmt"
-	"likeeino/adk/common/model"
+	"likeeino/pkg/model"
 	"log"
 
 	"github.com/cloudwego/eino/adk"
@@ -30,13 +30,17 @@ import (
 func NewItineraryAgent() adk.Agent {
 	ctx := context.Background()
 
+	cm, err := model.NewChatModel(ctx)
+	if err != nil {
+		log.Fatal(fmt.Errorf("failed to create chat model: %w", err))
+	}
 	a, err := adk.NewChatModelAgent(ctx, &adk.ChatModelAgentConfig{
 		Name:        "ItineraryAgent",
 		Description: "An agent that can plan trips.",
 		Instruction: `You are an expert trip planner. Your goal is to create a personalized itinerary.
 If you have enough information (e.g., location and interests), create the plan.
 If you are missing information, you MUST use the 'FollowUpAgent' tool to ask the user for their interests.`,
-		Model: model.NewChatModel(),
+		Model: cm,
 		ToolsConfig: adk.ToolsConfig{
 			ToolsNodeConfig: compose.ToolsNodeConfig{
 				Tools: []tool.BaseTool{
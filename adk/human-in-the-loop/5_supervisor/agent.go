@@ -31,8 +31,8 @@ import (
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
 
-	commonModel "likeeino/adk/common/model"
 	tool2 "likeeino/adk/common/tool"
+	commonModel "likeeino/pkg/model"
 )
 
 type rateLimitedModel struct {
@@ -70,20 +70,27 @@ func getRateLimitDelay() time.Duration {
 	return time.Duration(ms) * time.Millisecond
 }
 
-func newRateLimitedModel() model.ToolCallingChatModel {
+func newRateLimitedModel(ctx context.Context) (model.ToolCallingChatModel, error) {
+	//使用ark模型,deepseek模型进过验证可能会有问题
+	m, err := commonModel.NewChatModel(ctx, commonModel.WithProvider("ark"))
+	if err != nil {
+		return nil, err
+	}
 	delay := getRateLimitDelay()
 	if delay == 0 {
-		//使用ark模型,deepseek模型进过验证可能会有问题
-		return commonModel.NewChatModel("ark")
+		return m, nil
 	}
 	return &rateLimitedModel{
-		m:     commonModel.NewChatModel("ark"),
+		m:     m,
 		delay: delay,
-	}
+	}, nil
 }
 
 func buildAccountAgent(ctx context.Context) (adk.Agent, error) {
-	m := newRateLimitedModel()
+	m, err := newRateLimitedModel(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	type balanceReq struct {
 		AccountID string `json:"account_id" jsonschema_description:"The account ID to check balance for"`
@@ -145,7 +152,10 @@ INSTRUCTIONS:
 
 func buildTransactionAgent(ctx context.Context) (adk.Agent, error) {
 	//包装了下,使其支持延迟执行
-	m := newRateLimitedModel()
+	m, err := newRateLimitedModel(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	type transferReq struct {
 		FromAccount string  `json:"from_account" jsonschema_description:"Source account ID"`
@@ -205,7 +215,10 @@ INSTRUCTIONS:
 }
 
 func buildFinancialSupervisor(ctx context.Context) (adk.Agent, error) {
-	m := newRateLimitedModel()
+	m, err := newRateLimitedModel(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	sv, err := adk.NewChatModelAgent(ctx, &adk.ChatModelAgentConfig{
 		Name:        "financial_supervisor",
@@ -31,7 +31,7 @@ import (
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
 
-	commonModel "likeeino/adk/common/model"
+	commonModel "likeeino/pkg/model"
 )
 
 type rateLimitedModel struct {
@@ -69,20 +69,28 @@ func getRateLimitDelay() time.Duration {
 	return time.Duration(ms) * time.Millisecond
 }
 
-func newRateLimitedModel() model.ToolCallingChatModel {
+func newRateLimitedModel(ctx context.Context) (model.ToolCallingChatModel, error) {
+	m, err := commonModel.NewChatModel(ctx)
+	if err != nil {
+		return nil, err
+	}
 	delay := getRateLimitDelay()
 	if delay == 0 {
-		return commonModel.NewChatModel()
+		return m, nil
 	}
 	return &rateLimitedModel{
-		m:     commonModel.NewChatModel(),
+		m:     m,
 		delay: delay,
-	}
+	}, nil
 }
 
 func NewPlanner(ctx context.Context) (adk.Agent, error) {
+	m, err := newRateLimitedModel(ctx)
+	if err != nil {
+		return nil, err
+	}
 	return planexecute.NewPlanner(ctx, &planexecute.PlannerConfig{
-		ToolCallingChatModel: newRateLimitedModel(),
+		ToolCallingChatModel: m,
 	})
 }
 
@@ -120,9 +128,13 @@ func NewExecutor(ctx context.Context) (adk.Agent, error) {
 	if err != nil {
 		return nil, err
 	}
+	m, err := newRateLimitedModel(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	return planexecute.NewExecutor(ctx, &planexecute.ExecutorConfig{
-		Model: newRateLimitedModel(),
+		Model: m,
 		ToolsConfig: adk.ToolsConfig{
 			ToolsNodeConfig: compose.ToolsNodeConfig{
 				Tools: travelTools,
@@ -153,8 +165,12 @@ func NewExecutor(ctx context.Context) (adk.Agent, error) {
 }
 
 func NewReplanner(ctx context.Context) (adk.Agent, error) {
+	m, err := newRateLimitedModel(ctx)
+	if err != nil {
+		return nil, err
+	}
 	return planexecute.NewReplanner(ctx, &planexecute.ReplannerConfig{
-		ChatModel: newRateLimitedModel(),
+		ChatModel: m,
 	})
 }
 
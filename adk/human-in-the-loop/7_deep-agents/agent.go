@@ -30,8 +30,8 @@ import (
 	"github.com/cloudwego/eino/schema"
 
 	"github.com/cloudwego/eino-examples/components/tool/middlewares/errorremover"
-	commonModel "likeeino/adk/common/model"
 	tool2 "likeeino/adk/common/tool"
+	commonModel "likeeino/pkg/model"
 )
 
 type rateLimitedModel struct {
@@ -69,15 +69,19 @@ func getRateLimitDelay() time.Duration {
 	return time.Duration(ms) * time.Millisecond
 }
 
-func newRateLimitedModel() model.ToolCallingChatModel {
+func newRateLimitedModel(ctx context.Context) (model.ToolCallingChatModel, error) {
+	m, err := commonModel.NewChatModel(ctx)
+	if err != nil {
+		return nil, err
+	}
 	delay := getRateLimitDelay()
 	if delay == 0 {
-		return commonModel.NewChatModel()
+		return m, nil
 	}
 	return &rateLimitedModel{
-		m:     commonModel.NewChatModel(),
+		m:     m,
 		delay: delay,
-	}
+	}, nil
 }
 
 func buildResearchAgent(ctx context.Context, m model.ToolCallingChatModel) (adk.Agent, error) {
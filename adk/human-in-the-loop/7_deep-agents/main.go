@@ -37,7 +37,11 @@ func main() {
 	ctx := context.Background()
 	traceCloseFn, startSpanFn := trace.AppendCozeLoopCallbackIfConfigured(ctx)
 	defer traceCloseFn(ctx)
-	agent, err := NewDataAnalysisDeepAgent(ctx, newRateLimitedModel())
+	m, err := newRateLimitedModel(ctx)
+	if err != nil {
+		log.Fatalf("failed to create chat model: %v", err)
+	}
+	agent, err := NewDataAnalysisDeepAgent(ctx, m)
 	if err != nil {
 		log.Fatalf("failed to create deep agent: %v", err)
 	}
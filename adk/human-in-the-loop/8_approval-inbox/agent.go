@@ -0,0 +1,120 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/compose"
+
+	"likeeino/adk/common/policy"
+	tool2 "likeeino/adk/common/tool"
+	"likeeino/pkg/model"
+)
+
+// 订票 agent: 订票需要审批, 改签的参数可以由审核人修改, 信息不足时向用户追问
+
+func NewTravelAgent(ctx context.Context) adk.Agent {
+	type bookInput struct {
+		Location             string `json:"location"`
+		PassengerName        string `json:"passenger_name"`
+		PassengerPhoneNumber string `json:"passenger_phone_number"`
+	}
+	book, err := utils.InferTool(
+		"BookTicket",
+		"this tool can book ticket of the specific location",
+		func(ctx context.Context, input bookInput) (string, error) {
+			return fmt.Sprintf("ticket to %s booked for %s", input.Location, input.PassengerName), nil
+		})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	type changeInput struct {
+		PassengerName string `json:"passenger_name"`
+		NewDate       string `json:"new_date"`
+	}
+	change, err := utils.InferTool(
+		"ChangeTicket",
+		"this tool changes the date of a booked ticket",
+		func(ctx context.Context, input changeInput) (string, error) {
+			return fmt.Sprintf("ticket of %s changed to %s", input.PassengerName, input.NewDate), nil
+		})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tools := []tool.BaseTool{
+		&tool2.InvokableApprovableTool{InvokableTool: book},
+		&tool2.InvokableReviewEditTool{InvokableTool: change},
+	}
+	// POLICY_FILE 指定规则文件时, 由规则决定每次调用是直接执行、审批、审核编辑还是拒绝
+	if path := os.Getenv("POLICY_FILE"); path != "" {
+		tools = guardTools(path, []tool.BaseTool{book, change})
+	}
+
+	cm, err := model.NewChatModel(ctx)
+	if err != nil {
+		log.Fatal(fmt.Errorf("failed to create chat model: %w", err))
+	}
+	a, err := adk.NewChatModelAgent(ctx, &adk.ChatModelAgentConfig{
+		Name:        "TravelAgent",
+		Description: "An agent that books and changes tickets",
+		Instruction: `You are an expert ticket booker.
+Use the "BookTicket" tool to book tickets and the "ChangeTicket" tool to change their date.
+If the passenger name, phone number, destination or date is missing, use the "FollowUpTool" to ask for it first.`,
+		Model: cm,
+		ToolsConfig: adk.ToolsConfig{
+			ToolsNodeConfig: compose.ToolsNodeConfig{
+				Tools: append(tools, tool2.GetFollowUpTool()),
+			},
+		},
+		MaxIterations: 10,
+	})
+	if err != nil {
+		log.Fatal(fmt.Errorf("failed to create chatmodel: %w", err))
+	}
+	return a
+}
+
+// guardTools wraps tools with the policy at path, auditing to POLICY_AUDIT_LOG
+// (data/policy_audit.jsonl by default).
+func guardTools(path string, tools []tool.BaseTool) []tool.BaseTool {
+	p, err := policy.LoadPolicy(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	auditPath := os.Getenv("POLICY_AUDIT_LOG")
+	if auditPath == "" {
+		auditPath = "data/policy_audit.jsonl"
+	}
+	audit, _, err := policy.OpenAuditFile(auditPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	guarded, err := p.Wrap(tools, audit)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return guarded
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/joho/godotenv"
+
+	"likeeino/adk/common/inbox"
+	"likeeino/adk/common/store"
+)
+
+func main() {
+	ctx := context.Background()
+
+	// 检查点与待审批列表保存在同一个 store 中, CHECKPOINT_STORE=file|sqlite|redis 时重启后仍可继续审批
+	checkPointStore, err := store.NewFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer checkPointStore.Close()
+
+	runner := adk.NewRunner(ctx, adk.RunnerConfig{
+		EnableStreaming: true,
+		Agent:           NewTravelAgent(ctx),
+		CheckPointStore: checkPointStore,
+	})
+	b, err := inbox.New(ctx, &inbox.Config{Runner: runner, Store: checkPointStore})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+	h := server.Default(server.WithHostPorts(":" + port))
+	b.BindRoutes(h.Group("/inbox"))
+	h.GET("/", func(ctx context.Context, c *app.RequestContext) {
+		c.Redirect(302, []byte("/inbox/"))
+	})
+	log.Printf("approval inbox on http://localhost:%s/inbox/\n", port)
+	h.Spin()
+}
+
+func init() {
+	// 加载 .env 文件
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: Error loading .env file: %v\n", err)
+	}
+}
@@ -25,18 +25,22 @@ import (
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/compose"
 
-	"likeeino/adk/common/model"
+	"likeeino/pkg/model"
 )
 
 func NewBookRecommendAgent() adk.Agent {
 	ctx := context.Background()
 
+	cm, err := model.NewChatModel(ctx)
+	if err != nil {
+		log.Fatal(fmt.Errorf("failed to create chat model: %w", err))
+	}
 	a, err := adk.NewChatModelAgent(ctx, &adk.ChatModelAgentConfig{
 		Name:        "BookRecommender",
 		Description: "An agent that can recommend books",
 		Instruction: `You are an expert book recommender.
 Based on the user's request, use the "search_book" tool to find relevant books. Finally, present the results to the user.`,
-		Model: model.NewChatModel(),
+		Model: cm,
 		ToolsConfig: adk.ToolsConfig{
 			ToolsNodeConfig: compose.ToolsNodeConfig{
 				Tools: []tool.BaseTool{NewBookRecommender(), NewAskForClarificationTool()},
@@ -18,6 +18,8 @@ package executor
 
 import (
 	"context"
+	"likeeino/adk/multiagent/integration-excel-agent/agents"
+	"likeeino/adk/multiagent/integration-excel-agent/tools"
 	"likeeino/adk/multiagent/integration-excel-agent/utils"
 
 	"github.com/cloudwego/eino-ext/components/tool/commandline"
@@ -34,10 +36,12 @@ var executorPrompt = prompt.FromMessages(schema.FString,
 	schema.SystemMessage(`你是一个勤奋细致的执行代理人。遵循既定计划，仔细彻底地执行任务。
 
 Available Tools:
+- 表格工具 read_sheet_range / filter_rows / group_aggregate / pivot_table / join_tables / deduplicate_rows / write_sheet / add_chart: 直接读取、过滤、分组聚合、透视、连接、去重、写入 csv/xlsx 表格并插入图表，无需编写代码。处理前先用 read_sheet_range 确认列名，每一步的结果写入新的文件或工作表，供后续步骤使用。
 - CodeAgent: 此工具是专门用于Excel文件处理的代码代理。它采取循序渐进的计划，通过生成Python代码（利用pandas进行数据分析/操作，利用matplotlib进行绘图/可视化，利用openpyxl进行Excel读/写）来处理每个任务，并按顺序执行任务。当需要对Excel操作进行逐步Python编码时，React代理应该调用它，以确保精确、高效地完成任务。
 
 Notice:
 - 不要转移到其他代理，只能使用工具。
+- 优先使用表格工具；只有表格工具无法完成的步骤（如复杂的文本解析、格式转换）才调用 CodeAgent。
 `),
 	schema.UserMessage(`## OBJECTIVE
 {input}
@@ -71,15 +75,26 @@ func NewExecutor(ctx context.Context, operator commandline.Operator) (adk.Agent,
 		return nil, err
 	}
 
+	//表格工具, 常见的表格操作不再经过 python
+	dfTools, err := tools.NewDataFrameTools(operator)
+	if err != nil {
+		return nil, err
+	}
+	executorTools := []tool.BaseTool{
+		//执行器将该代理作为工具进行使用
+		adk.NewAgentTool(ctx, ca),
+		adk.NewAgentTool(ctx, sa),
+	}
+	preprocess := []tools.ToolRequestPreprocess{tools.ToolRequestRepairJSON}
+	for _, t := range dfTools {
+		executorTools = append(executorTools, tools.NewWrapTool(t, preprocess, nil))
+	}
+
 	a, err := planexecute.NewExecutor(ctx, &planexecute.ExecutorConfig{
 		Model: cm,
 		ToolsConfig: adk.ToolsConfig{
 			ToolsNodeConfig: compose.ToolsNodeConfig{
-				Tools: []tool.BaseTool{
-					//执行器将该代理作为工具进行使用
-					adk.NewAgentTool(ctx, ca),
-					adk.NewAgentTool(ctx, sa),
-				},
+				Tools: executorTools,
 			},
 		},
 		MaxIterations: 20,
@@ -102,5 +117,6 @@ func NewExecutor(ctx context.Context, operator commandline.Operator) (adk.Agent,
 		return nil, err
 	}
 
-	return a, nil
+	//包装函数: 记录每个步骤前后工作目录的文件变更
+	return agents.NewArtifactWrapper(a), nil
 }
@@ -56,10 +56,10 @@ var (
 {
   "steps": [
     {
-      "instruction": "将“sales_data.xlsx”文件读入pandas DataFrame。"
+      "instruction": "读取“sales_data.xlsx”文件，确认“产品类别”和“销售额”列。"
     },
     {
-      "instruction": "按“产品类别”对DataFrame进行分组，并计算每个组的“销售额”列的平均值。"
+      "instruction": "按“产品类别”分组，计算每个组的“销售额”列的平均值，并将结果写入“category_avg.xlsx”。"
     },
     {
       "instruction": "总结每个产品类别的平均销售额，并将结果显示在表格中。"
@@ -69,6 +69,7 @@ var (
 
 **5. Restrictions:**
 - 不要直接在计划中生成代码。
+- 执行代理可以直接完成读取区域、过滤行、分组聚合、数据透视、表格连接、去重、写入工作表和插入图表，这些步骤不需要编写Python代码。
 - 确保该计划合乎逻辑且可实现。
 - 最后一步应该始终是生成报告或提供最终结果。
 `),
@@ -100,8 +101,8 @@ func NewPlanner(ctx context.Context, op commandline.Operator) (adk.Agent, error)
 	if err != nil {
 		return nil, err
 	}
-	//包装函数
-	return agents.NewWrite2PlanMDWrapper(a, op), nil
+	//包装函数: 写入 plan.md, 任务中断后恢复时跳过规划
+	return agents.NewWrite2PlanMDWrapper(agents.NewResumablePlanner(a), op), nil
 }
 
 func newPlannerChatModel(ctx context.Context) (model.ToolCallingChatModel, error) {
@@ -55,10 +55,10 @@ User Request: "Please calculate the average sales for each product category in t
 {
   "steps": [
     {
-      "instruction": "Read the 'sales_data.xlsx' file into a pandas DataFrame."
+      "instruction": "Read the 'sales_data.xlsx' file and confirm the 'Product Category' and 'Sales' columns."
     },
     {
-      "instruction": "Group the DataFrame by 'Product Category' and calculate the mean of the 'Sales' column for each group."
+      "instruction": "Group by 'Product Category', calculate the mean of the 'Sales' column for each group and write the result to 'category_avg.xlsx'."
     },
     {
       "instruction": "Summarize the average sales for each product category and present the results in a table."
@@ -68,6 +68,7 @@ User Request: "Please calculate the average sales for each product category in t
 
 **5. 限制:**
 - 不要直接在计划中生成代码。
+- 执行代理可以直接完成读取区域、过滤行、分组聚合、数据透视、表格连接、去重、写入工作表和插入图表，这些步骤不需要编写Python代码。
 - 确保该计划合乎逻辑且可实现。
 - 最后一步应该始终是生成报告或提供最终结果。
 
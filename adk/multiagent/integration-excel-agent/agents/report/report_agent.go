@@ -91,7 +91,8 @@ func NewReportAgent(ctx context.Context, operator commandline.Operator) (adk.Age
 3.总结主要发现和见解。
 4.生成一份清晰简洁的报告，回答用户的询问。
 5.如果有任何图表或可视化，请在报告中参考。
-6.如果工作已经完成，必须在完成之前调用SubmitResult工具。
+6.根据“File Changes”说明相对原始输入新建、修改和删除了哪些文件，表格的关键单元格变化要列出。
+7.如果工作已经完成，必须在完成之前调用SubmitResult工具。
 `,
 		Model: cm,
 		ToolsConfig: adk.ToolsConfig{
@@ -132,6 +133,15 @@ func NewReportAgent(ctx context.Context, operator commandline.Operator) (adk.Age
 				return nil, err
 			}
 
+			//执行过程相对原始输入的文件变更, 报告中据此说明新建和修改了哪些文件
+			manifests, _ := utils.GetSessionValue[[]*generic.Manifest](ctx, generic.StepManifestsSessionKey)
+			baseline, _ := utils.GetSessionValue[*generic.Snapshot](ctx, generic.BaselineSnapshotSessionKey)
+			final, _ := generic.TakeSnapshot(wd)
+			changes := generic.OverallManifest(baseline, final, manifests).String()
+			if changes == "" {
+				changes = "无"
+			}
+
 			tpl := prompt.FromMessages(schema.Jinja2,
 				schema.SystemMessage(instruction),
 				schema.UserMessage(`
@@ -143,6 +153,9 @@ Current Time: {{ current_time }}
 
 **Plan Details:**
 {{ plan }}
+
+**File Changes:**
+{{ file_changes }}
 `))
 
 			msgs, err := tpl.Format(ctx, map[string]any{
@@ -151,6 +164,7 @@ Current Time: {{ current_time }}
 				"work_dir_files": utils.ToJSONString(files),
 				"user_query":     utils.FormatInput(planExecuteResult),
 				"plan":           string(planStr),
+				"file_changes":   changes,
 				"current_time":   utils.GetCurrentTime(),
 			})
 			if err != nil {
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package agents
+
+import (
+	"context"
+	"fmt"
+	"likeeino/adk/multiagent/integration-excel-agent/generic"
+	"likeeino/adk/multiagent/integration-excel-agent/params"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/adk/prebuilt/planexecute"
+	"github.com/cloudwego/eino/schema"
+)
+
+// NewResumablePlanner 包装规划代理: 上下文中带有 params.ResumeStateKey 且计划还有剩余步骤时不再重新规划,
+// 而是把保存的用户输入、剩余计划、已完成步骤及其文件变更放回会话, 执行代理从剩余计划的第一步继续.
+func NewResumablePlanner(a adk.Agent) adk.Agent {
+	return &resumableAgent{a: a, skip: func(s *generic.RunState) bool {
+		return !s.Finished && s.Plan != nil && len(s.Plan.Steps) > 0
+	}}
+}
+
+// NewResumablePlanExecute 包装规划-执行-重规划代理: 保存的进度表明计划已执行完毕时整体跳过, 只恢复会话,
+// 后续的报告代理直接生成报告.
+func NewResumablePlanExecute(a adk.Agent) adk.Agent {
+	return &resumableAgent{a: a, skip: func(s *generic.RunState) bool {
+		return s.Finished
+	}}
+}
+
+type resumableAgent struct {
+	a    adk.Agent
+	skip func(state *generic.RunState) bool
+}
+
+func (r *resumableAgent) Name(ctx context.Context) string {
+	return r.a.Name(ctx)
+}
+
+func (r *resumableAgent) Description(ctx context.Context) string {
+	return r.a.Description(ctx)
+}
+
+func (r *resumableAgent) Run(ctx context.Context, input *adk.AgentInput, options ...adk.AgentRunOption) *adk.AsyncIterator[*adk.AgentEvent] {
+	state, ok := params.GetTypedContextParams[*generic.RunState](ctx, params.ResumeStateKey)
+	if !ok || state == nil || !r.skip(state) {
+		return r.a.Run(ctx, input, options...)
+	}
+
+	userInput := state.UserInput
+	if len(userInput) == 0 {
+		userInput = input.Messages
+	}
+	plan := &generic.Plan{}
+	if state.Plan != nil {
+		plan.Steps = state.Plan.Steps
+	}
+	adk.AddSessionValue(ctx, planexecute.UserInputSessionKey, userInput)
+	adk.AddSessionValue(ctx, planexecute.PlanSessionKey, planexecute.Plan(plan))
+	adk.AddSessionValue(ctx, planexecute.ExecutedStepsSessionKey, state.ExecutedSteps)
+	adk.AddSessionValue(ctx, generic.StepManifestsSessionKey, state.Manifests)
+	if state.Baseline != nil {
+		adk.AddSessionValue(ctx, generic.BaselineSnapshotSessionKey, state.Baseline)
+	}
+
+	content := fmt.Sprintf("已完成 %d 步, 从第 %d 步继续执行", len(state.ExecutedSteps), len(state.ExecutedSteps)+1)
+	if state.Finished {
+		content = fmt.Sprintf("计划的 %d 步已全部完成, 继续生成报告", len(state.ExecutedSteps))
+	}
+	iter, gen := adk.NewAsyncIteratorPair[*adk.AgentEvent]()
+	gen.Send(adk.EventFromMessage(schema.AssistantMessage(content, nil), nil, schema.Assistant, ""))
+	gen.Close()
+	return iter
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package agents
+
+import (
+	"context"
+	"likeeino/adk/multiagent/integration-excel-agent/generic"
+	"likeeino/adk/multiagent/integration-excel-agent/params"
+	"likeeino/adk/multiagent/integration-excel-agent/utils"
+	"log"
+	"runtime/debug"
+
+	"github.com/cloudwego/eino/adk"
+)
+
+// NewArtifactWrapper 包装执行代理: 每次执行一个步骤前后对工作目录做快照, 把两者的差异追加到会话的
+// generic.StepManifestsSessionKey 中, 随后重规划代理记录的已完成步骤与之一一对应.
+// 第一个步骤之前的快照记录在 generic.BaselineSnapshotSessionKey 中, 用于计算整体变更, 其中的表格另存一份副本.
+func NewArtifactWrapper(a adk.Agent) adk.Agent {
+	return &artifactWrapper{a: a}
+}
+
+type artifactWrapper struct {
+	a adk.Agent
+}
+
+func (r *artifactWrapper) Name(ctx context.Context) string {
+	return r.a.Name(ctx)
+}
+
+func (r *artifactWrapper) Description(ctx context.Context) string {
+	return r.a.Description(ctx)
+}
+
+func (r *artifactWrapper) Run(ctx context.Context, input *adk.AgentInput, options ...adk.AgentRunOption) *adk.AsyncIterator[*adk.AgentEvent] {
+	wd, _ := params.GetTypedContextParams[string](ctx, params.WorkDirSessionKey)
+	before, err := generic.TakeSnapshot(wd)
+	if err != nil {
+		// 快照失败不影响执行, 只是这一步没有文件变更记录
+		log.Printf("[artifactWrapper] snapshot before step failed: %v", err)
+	} else if _, ok := utils.GetSessionValue[*generic.Snapshot](ctx, generic.BaselineSnapshotSessionKey); !ok {
+		// 保留原始表格的副本, 恢复执行后仍能比较单元格, 失败时只比较哈希
+		if err = before.Preserve(); err != nil {
+			log.Printf("[artifactWrapper] preserve baseline failed: %v", err)
+		}
+		adk.AddSessionValue(ctx, generic.BaselineSnapshotSessionKey, before)
+	}
+
+	iter := r.a.Run(ctx, input, options...)
+	nIter, gen := adk.NewAsyncIteratorPair[*adk.AgentEvent]()
+	go func() {
+		defer func() {
+			if e := recover(); e != nil {
+				log.Printf("[artifactWrapper] exec panic recover:%+v, stack: %s", e, string(debug.Stack()))
+			}
+			gen.Close()
+		}()
+
+		for {
+			e, ok := iter.Next()
+			if !ok {
+				break
+			}
+			gen.Send(e)
+		}
+
+		// 必须在关闭迭代器之前写入会话, 循环代理随后才会运行重规划代理
+		manifests, _ := utils.GetSessionValue[[]*generic.Manifest](ctx, generic.StepManifestsSessionKey)
+		var manifest *generic.Manifest
+		if before != nil {
+			after, err := generic.TakeSnapshot(wd)
+			if err != nil {
+				log.Printf("[artifactWrapper] snapshot after step failed: %v", err)
+			} else {
+				manifest = generic.DiffSnapshots(before, after)
+			}
+		}
+		adk.AddSessionValue(ctx, generic.StepManifestsSessionKey, append(manifests, manifest))
+	}()
+
+	return nIter
+}
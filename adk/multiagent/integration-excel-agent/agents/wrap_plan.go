@@ -25,6 +25,7 @@ import (
 	"likeeino/adk/multiagent/integration-excel-agent/utils"
 	"log"
 	"runtime/debug"
+	"slices"
 
 	"github.com/cloudwego/eino-ext/components/tool/commandline"
 	"github.com/cloudwego/eino/adk"
@@ -61,13 +62,18 @@ func (r *write2PlanMDWrapper) Run(ctx context.Context, input *adk.AgentInput, op
 			gen.Close()
 		}()
 
+		// 重规划代理跳出循环, 说明计划已执行完毕
+		finished := false
 		for {
 			e, ok := iter.Next()
 			if !ok {
 				break
 			}
+			if e.Action != nil && e.Action.BreakLoop != nil {
+				finished = true
+			}
 			if e.Action != nil && e.Action.Exit {
-				err := write2PlanMD(ctx, r.op)
+				err := write2PlanMD(ctx, r.op, finished)
 				gen.Send(e)
 				if err != nil {
 					log.Print("write plan failed", err)
@@ -78,7 +84,7 @@ func (r *write2PlanMDWrapper) Run(ctx context.Context, input *adk.AgentInput, op
 			gen.Send(e)
 		}
 
-		err := write2PlanMD(ctx, r.op)
+		err := write2PlanMD(ctx, r.op, finished)
 		if err != nil {
 			log.Print("write plan failed", err)
 			return
@@ -88,7 +94,7 @@ func (r *write2PlanMDWrapper) Run(ctx context.Context, input *adk.AgentInput, op
 	return nIter
 }
 
-func write2PlanMD(ctx context.Context, op commandline.Operator) error {
+func write2PlanMD(ctx context.Context, op commandline.Operator, finished bool) error {
 	var executedSteps []planexecute.ExecutedStep
 	var plan *generic.Plan
 	p, ok := utils.GetSessionValue[*generic.Plan](ctx, planexecute.PlanSessionKey)
@@ -99,6 +105,11 @@ func write2PlanMD(ctx context.Context, op commandline.Operator) error {
 	if ok {
 		executedSteps = es
 	}
+	manifests, _ := utils.GetSessionValue[[]*generic.Manifest](ctx, generic.StepManifestsSessionKey)
+	// 只保留与已完成步骤对应的文件变更
+	if len(manifests) > len(executedSteps) {
+		manifests = manifests[:len(executedSteps)]
+	}
 	wd, ok := params.GetTypedContextParams[string](ctx, params.WorkDirSessionKey)
 	if !ok {
 		return fmt.Errorf("work dir not found")
@@ -111,6 +122,10 @@ func write2PlanMD(ctx context.Context, op commandline.Operator) error {
 		if err == nil {
 			desc = s.Desc
 		}
+		var artifacts *generic.Manifest
+		if i < len(manifests) {
+			artifacts = manifests[i]
+		}
 		plans = append(plans, &generic.FullPlan{
 			TaskID: i + 1,
 			Status: generic.PlanStatusDone,
@@ -118,7 +133,7 @@ func write2PlanMD(ctx context.Context, op commandline.Operator) error {
 			ExecResult: &generic.SubmitResult{
 				IsSuccess: utils.PtrOf(true),
 				Result:    step.Result,
-				Files:     nil, // todo
+				Artifacts: artifacts,
 			},
 		})
 	}
@@ -136,5 +151,17 @@ func write2PlanMD(ctx context.Context, op commandline.Operator) error {
 		return err
 	}
 
+	// 通知进度, 服务端据此保存检查点并推送计划
+	if progress, ok := params.GetTypedContextParams[generic.ProgressFunc](ctx, params.PlanProgressKey); ok && progress != nil {
+		userInput, _ := utils.GetSessionValue[[]adk.Message](ctx, planexecute.UserInputSessionKey)
+		baseline, _ := utils.GetSessionValue[*generic.Snapshot](ctx, generic.BaselineSnapshotSessionKey)
+		state := &generic.RunState{UserInput: userInput, ExecutedSteps: executedSteps, Manifests: slices.Clone(manifests), Baseline: baseline, Finished: finished}
+		if plan != nil {
+			// 复制一份, 重规划时会修改会话中的计划
+			state.Plan = &generic.Plan{Steps: slices.Clone(plan.Steps)}
+		}
+		progress(ctx, state, plans)
+	}
+
 	return nil
 }
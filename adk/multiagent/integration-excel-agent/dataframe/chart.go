@@ -0,0 +1,122 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dataframe
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var chartTypes = map[string]excelize.ChartType{
+	"bar":     excelize.Bar,
+	"col":     excelize.Col,
+	"column":  excelize.Col,
+	"line":    excelize.Line,
+	"pie":     excelize.Pie,
+	"area":    excelize.Area,
+	"scatter": excelize.Scatter,
+}
+
+// ChartSpec 基于工作表中已有数据的图表. 数据区域的第一行是表头, 按列名引用
+type ChartSpec struct {
+	Sheet    string   `json:"sheet,omitempty" jsonschema_description:"Sheet holding the data, defaults to the first sheet"`
+	Type     string   `json:"type" jsonschema_description:"One of bar, col, line, pie, area, scatter"`
+	Title    string   `json:"title,omitempty" jsonschema_description:"Chart title"`
+	Category string   `json:"category" jsonschema_description:"Column used as the category (x) axis"`
+	Values   []string `json:"values" jsonschema_description:"Columns plotted as series"`
+	Cell     string   `json:"cell,omitempty" jsonschema_description:"Top-left cell to anchor the chart, defaults to two columns right of the data"`
+}
+
+// AddChart 在工作簿中插入图表并返回新的文件内容
+func AddChart(workbook []byte, spec *ChartSpec) ([]byte, error) {
+	typ, ok := chartTypes[strings.ToLower(spec.Type)]
+	if !ok {
+		return nil, fmt.Errorf("unknown chart type %q", spec.Type)
+	}
+	if len(spec.Values) == 0 {
+		return nil, fmt.Errorf("chart needs at least one value column")
+	}
+	f, err := excelize.OpenReader(bytes.NewReader(workbook))
+	if err != nil {
+		return nil, fmt.Errorf("open workbook: %w", err)
+	}
+	defer f.Close()
+
+	sheet := spec.Sheet
+	if sheet == "" {
+		sheet = f.GetSheetName(0)
+	} else if idx, _ := f.GetSheetIndex(sheet); idx < 0 {
+		return nil, fmt.Errorf("%w: %q", ErrSheetNotFound, sheet)
+	}
+	rows, err := f.GetRows(sheet)
+	if err != nil {
+		return nil, err
+	}
+	t := FromGrid(rows)
+	if len(t.Rows) == 0 {
+		return nil, fmt.Errorf("sheet %q has no data rows", sheet)
+	}
+	catIdx, err := t.Index(spec.Category)
+	if err != nil {
+		return nil, err
+	}
+	valIdx, err := t.indexes(spec.Values)
+	if err != nil {
+		return nil, err
+	}
+
+	last := len(t.Rows) + 1
+	quoted := strings.ReplaceAll(sheet, "'", "''")
+	ref := func(col, from, to int) string {
+		name, _ := excelize.ColumnNumberToName(col + 1)
+		if from == to {
+			return fmt.Sprintf("'%s'!$%s$%d", quoted, name, from)
+		}
+		return fmt.Sprintf("'%s'!$%s$%d:$%s$%d", quoted, name, from, name, to)
+	}
+	chart := &excelize.Chart{
+		Type:   typ,
+		Legend: excelize.ChartLegend{Position: "bottom"},
+		Format: excelize.GraphicOptions{ScaleX: 1.5, ScaleY: 1.2},
+	}
+	if spec.Title != "" {
+		chart.Title = []excelize.RichTextRun{{Text: spec.Title}}
+	}
+	for _, i := range valIdx {
+		chart.Series = append(chart.Series, excelize.ChartSeries{
+			Name:       ref(i, 1, 1),
+			Categories: ref(catIdx, 2, last),
+			Values:     ref(i, 2, last),
+		})
+	}
+
+	cell := spec.Cell
+	if cell == "" {
+		cell, _ = excelize.CoordinatesToCellName(len(t.Columns)+2, 1)
+	}
+	if err = f.AddChart(sheet, cell, chart); err != nil {
+		return nil, fmt.Errorf("add chart: %w", err)
+	}
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,203 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dataframe
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func loadQuestions(t *testing.T) *Table {
+	data, err := os.ReadFile("../playground/test_data/questions.csv")
+	require.NoError(t, err)
+	table, err := Read("questions.csv", data, "", "")
+	require.NoError(t, err)
+	return table
+}
+
+func sales() *Table {
+	return NewTable([]string{"region", "product", "amount"}, [][]string{
+		{"east", "apple", "10"},
+		{"west", "apple", "5"},
+		{"east", "pear", "7.5"},
+		{"east", "apple", "2"},
+		{"north", "pear", "n/a"},
+	})
+}
+
+func TestReadCSV(t *testing.T) {
+	table := loadQuestions(t)
+	// BOM 被去掉, 较短的行补齐到表头宽度
+	assert.Equal(t, []string{"type", "question", "options", "answer", "explanation"}, table.Columns)
+	require.Len(t, table.Rows, 24)
+	assert.Equal(t, "multiple-choice", table.Rows[0][0])
+	assert.Equal(t, "", table.Rows[1][4])
+
+	data, err := os.ReadFile("../playground/test_data/questions.csv")
+	require.NoError(t, err)
+	ranged, err := Read("questions.csv", data, "", "A1:A3")
+	require.NoError(t, err)
+	assert.Equal(t, &Table{Columns: []string{"type"}, Rows: [][]string{{"multiple-choice"}, {"multiple-choice"}}}, ranged)
+
+	// 区域的第一行作为表头, 空表头按在区域中的位置用列字母命名
+	ranged, err = Read("questions.csv", data, "", "D3:F")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A. 管理硬件资源", "B", "C"}, ranged.Columns)
+
+	_, err = Read("questions.txt", data, "", "")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+	_, err = table.Index("score")
+	assert.ErrorIs(t, err, ErrColumnNotFound)
+}
+
+func TestFilterAndGroup(t *testing.T) {
+	table := loadQuestions(t)
+
+	short, err := table.Filter([]Condition{{Column: "type", Op: "eq", Value: "short-answer"}}, false)
+	require.NoError(t, err)
+	assert.Len(t, short.Rows, 4)
+
+	counts, err := table.GroupBy([]string{"type"}, []Aggregation{{Func: "count", As: "题目数"}})
+	require.NoError(t, err)
+	assert.Equal(t, &Table{
+		Columns: []string{"type", "题目数"},
+		Rows:    [][]string{{"multiple-choice", "20"}, {"short-answer", "4"}},
+	}, counts)
+
+	s := sales()
+	rows, err := s.Filter([]Condition{{Column: "amount", Op: "gt", Value: "6"}, {Column: "region", Op: "in", Values: []string{"west"}}}, true)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"east", "apple", "10"}, {"west", "apple", "5"}, {"east", "pear", "7.5"}}, rows.Rows)
+
+	_, err = s.Filter([]Condition{{Column: "amount", Op: "like"}}, false)
+	assert.Error(t, err)
+
+	grouped, err := s.GroupBy([]string{"region"}, []Aggregation{
+		{Column: "amount", Func: "sum"},
+		{Column: "amount", Func: "avg"},
+		{Column: "product", Func: "count_distinct", As: "products"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"region", "amount_sum", "amount_avg", "products"}, grouped.Columns)
+	assert.Equal(t, [][]string{
+		{"east", "19.5", "6.5", "2"},
+		{"west", "5", "5", "1"},
+		{"north", "0", "", "1"},
+	}, grouped.Rows)
+
+	sorted, err := grouped.Sort("amount_sum", true)
+	require.NoError(t, err)
+	assert.Equal(t, "east", sorted.Rows[0][0])
+	assert.Equal(t, "north", sorted.Rows[2][0])
+}
+
+func TestPivotJoinDedupe(t *testing.T) {
+	s := sales()
+	pivot, err := s.Pivot("region", "product", "amount", "")
+	require.NoError(t, err)
+	assert.Equal(t, &Table{
+		Columns: []string{"region", "apple", "pear"},
+		Rows:    [][]string{{"east", "12", "7.5"}, {"west", "5", ""}, {"north", "", "0"}},
+	}, pivot)
+
+	managers := NewTable([]string{"region", "manager", "amount"}, [][]string{
+		{"east", "Alice", "1"},
+		{"south", "Bob", "2"},
+	})
+	joined, err := Join(s, managers, []string{"region"}, []string{"region"}, "outer")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"region", "product", "amount", "manager", "amount_right"}, joined.Columns)
+	assert.Equal(t, [][]string{
+		{"east", "apple", "10", "Alice", "1"},
+		{"west", "apple", "5", "", ""},
+		{"east", "pear", "7.5", "Alice", "1"},
+		{"east", "apple", "2", "Alice", "1"},
+		{"north", "pear", "n/a", "", ""},
+		{"south", "", "", "Bob", "2"},
+	}, joined.Rows)
+
+	inner, err := Join(s, managers, []string{"region"}, []string{"region"}, "")
+	require.NoError(t, err)
+	assert.Len(t, inner.Rows, 3)
+	_, err = Join(s, managers, []string{"region"}, []string{"manager", "amount"}, "")
+	assert.Error(t, err)
+
+	first, err := s.Dedupe([]string{"region", "product"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"east", "apple", "10"}, {"west", "apple", "5"}, {"east", "pear", "7.5"}, {"north", "pear", "n/a"}}, first.Rows)
+	last, err := s.Dedupe([]string{"region", "product"}, "last")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"west", "apple", "5"}, {"east", "pear", "7.5"}, {"east", "apple", "2"}, {"north", "pear", "n/a"}}, last.Rows)
+}
+
+func TestWriteSheetAndChart(t *testing.T) {
+	s := sales()
+	book, err := Write("out.xlsx", nil, "销售", s)
+	require.NoError(t, err)
+
+	grouped, err := s.GroupBy([]string{"region"}, []Aggregation{{Column: "amount", Func: "sum", As: "total"}})
+	require.NoError(t, err)
+	book, err = WriteSheet(book, "汇总", grouped)
+	require.NoError(t, err)
+	// 覆盖已有工作表时保持工作表顺序
+	book, err = WriteSheet(book, "销售", s.Head(2))
+	require.NoError(t, err)
+
+	book, err = AddChart(book, &ChartSpec{Sheet: "汇总", Type: "col", Title: "销售额", Category: "region", Values: []string{"total"}})
+	require.NoError(t, err)
+	_, err = AddChart(book, &ChartSpec{Sheet: "汇总", Type: "radar3d", Category: "region", Values: []string{"total"}})
+	assert.Error(t, err)
+
+	f, err := excelize.OpenReader(bytes.NewReader(book))
+	require.NoError(t, err)
+	defer f.Close()
+	assert.Equal(t, []string{"销售", "汇总"}, f.GetSheetList())
+	rows, err := f.GetRows("销售")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"region", "product", "amount"}, {"east", "apple", "10"}, {"west", "apple", "5"}}, rows)
+	// 数字按数值写入
+	typ, err := f.GetCellType("汇总", "B2")
+	require.NoError(t, err)
+	assert.NotEqual(t, excelize.CellTypeSharedString, typ)
+	assert.Contains(t, string(mustFile(t, book, "xl/charts/chart1.xml")), "!$B$2:$B$4")
+
+	read, err := Read("out.xlsx", book, "汇总", "")
+	require.NoError(t, err)
+	assert.Equal(t, grouped, read)
+	_, err = Read("out.xlsx", book, "missing", "")
+	assert.ErrorIs(t, err, ErrSheetNotFound)
+
+	csvData, err := Write("out.csv", nil, "", grouped)
+	require.NoError(t, err)
+	read, err = Read("out.csv", csvData, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, grouped, read)
+}
+
+func mustFile(t *testing.T, book []byte, name string) []byte {
+	f, err := excelize.OpenReader(bytes.NewReader(book))
+	require.NoError(t, err)
+	defer f.Close()
+	v, ok := f.Pkg.Load(name)
+	require.True(t, ok, name)
+	return v.([]byte)
+}
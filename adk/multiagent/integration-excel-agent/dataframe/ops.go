@@ -0,0 +1,493 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dataframe
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Condition 单列过滤条件. 比较运算在两边都是数字时按数值比较, 否则按字符串比较;
+// 与数字做大小比较时, 非数字的单元格不匹配
+type Condition struct {
+	Column string   `json:"column" jsonschema_description:"Column name"`
+	Op     string   `json:"op" jsonschema_description:"One of eq, ne, gt, ge, lt, le, contains, not_contains, starts_with, ends_with, in, not_in, regex, empty, not_empty"`
+	Value  string   `json:"value,omitempty" jsonschema_description:"Value to compare with, not needed by empty/not_empty/in/not_in"`
+	Values []string `json:"values,omitempty" jsonschema_description:"Values for in/not_in"`
+}
+
+type matcher func(v string) bool
+
+func (c *Condition) compile() (matcher, error) {
+	switch c.Op {
+	case "eq", "=", "==":
+		return func(v string) bool { return compare(v, c.Value) == 0 }, nil
+	case "ne", "!=":
+		return func(v string) bool { return compare(v, c.Value) != 0 }, nil
+	case "gt", ">":
+		return c.ordered(func(r int) bool { return r > 0 }), nil
+	case "ge", ">=":
+		return c.ordered(func(r int) bool { return r >= 0 }), nil
+	case "lt", "<":
+		return c.ordered(func(r int) bool { return r < 0 }), nil
+	case "le", "<=":
+		return c.ordered(func(r int) bool { return r <= 0 }), nil
+	case "contains":
+		return func(v string) bool { return strings.Contains(v, c.Value) }, nil
+	case "not_contains":
+		return func(v string) bool { return !strings.Contains(v, c.Value) }, nil
+	case "starts_with":
+		return func(v string) bool { return strings.HasPrefix(v, c.Value) }, nil
+	case "ends_with":
+		return func(v string) bool { return strings.HasSuffix(v, c.Value) }, nil
+	case "in":
+		return func(v string) bool { return slices.Contains(c.Values, strings.TrimSpace(v)) }, nil
+	case "not_in":
+		return func(v string) bool { return !slices.Contains(c.Values, strings.TrimSpace(v)) }, nil
+	case "empty":
+		return func(v string) bool { return strings.TrimSpace(v) == "" }, nil
+	case "not_empty":
+		return func(v string) bool { return strings.TrimSpace(v) != "" }, nil
+	case "regex":
+		re, err := regexp.Compile(c.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %w", c.Value, err)
+		}
+		return re.MatchString, nil
+	default:
+		return nil, fmt.Errorf("unknown operator %q", c.Op)
+	}
+}
+
+// ordered 大小比较. 与数字比较时, 无法解析为数字的单元格(空值、"n/a" 等)不匹配
+func (c *Condition) ordered(ok func(r int) bool) matcher {
+	_, numeric := number(c.Value)
+	return func(v string) bool {
+		if _, isNum := number(v); numeric && !isNum {
+			return false
+		}
+		return ok(compare(v, c.Value))
+	}
+}
+
+// Filter 保留满足条件的行. matchAny 为 false 时所有条件都要满足, 为 true 时满足任意一个即可
+func (t *Table) Filter(conds []Condition, matchAny bool) (*Table, error) {
+	idx := make([]int, len(conds))
+	matchers := make([]matcher, len(conds))
+	for i := range conds {
+		var err error
+		if idx[i], err = t.Index(conds[i].Column); err != nil {
+			return nil, err
+		}
+		if matchers[i], err = conds[i].compile(); err != nil {
+			return nil, err
+		}
+	}
+
+	out := &Table{Columns: t.Columns, Rows: [][]string{}}
+	for _, row := range t.Rows {
+		keep := !matchAny || len(conds) == 0
+		for i, m := range matchers {
+			ok := m(row[idx[i]])
+			if matchAny && ok {
+				keep = true
+				break
+			}
+			if !matchAny && !ok {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			out.Rows = append(out.Rows, row)
+		}
+	}
+	return out, nil
+}
+
+// Aggregation 一个聚合列
+type Aggregation struct {
+	Column string `json:"column,omitempty" jsonschema_description:"Column to aggregate, may be omitted for count"`
+	Func   string `json:"func" jsonschema_description:"One of count, count_distinct, sum, avg, min, max, first, last, concat"`
+	As     string `json:"as,omitempty" jsonschema_description:"Output column name, defaults to <column>_<func>"`
+}
+
+func (a *Aggregation) name() string {
+	if a.As != "" {
+		return a.As
+	}
+	if a.Column == "" {
+		return a.Func
+	}
+	return a.Column + "_" + a.Func
+}
+
+// aggregate 计算一组值的聚合结果. sum/avg/min/max 忽略无法解析为数字的值
+func aggregate(fn string, values []string) (string, error) {
+	switch fn {
+	case "count":
+		return strconv.Itoa(len(values)), nil
+	case "count_distinct":
+		seen := make(map[string]struct{}, len(values))
+		for _, v := range values {
+			seen[v] = struct{}{}
+		}
+		return strconv.Itoa(len(seen)), nil
+	case "first":
+		if len(values) == 0 {
+			return "", nil
+		}
+		return values[0], nil
+	case "last":
+		if len(values) == 0 {
+			return "", nil
+		}
+		return values[len(values)-1], nil
+	case "concat":
+		return strings.Join(values, ", "), nil
+	case "sum", "avg", "mean", "min", "max":
+	default:
+		return "", fmt.Errorf("unknown aggregate function %q", fn)
+	}
+
+	var nums []float64
+	for _, v := range values {
+		if f, ok := number(v); ok {
+			nums = append(nums, f)
+		}
+	}
+	if len(nums) == 0 {
+		if fn == "sum" {
+			return "0", nil
+		}
+		return "", nil
+	}
+	var r float64
+	switch fn {
+	case "sum", "avg", "mean":
+		for _, f := range nums {
+			r += f
+		}
+		if fn != "sum" {
+			r /= float64(len(nums))
+		}
+	case "min":
+		r = slices.Min(nums)
+	case "max":
+		r = slices.Max(nums)
+	}
+	return formatNumber(r), nil
+}
+
+// GroupBy 按 by 列分组并计算聚合, 分组按首次出现的顺序输出
+func (t *Table) GroupBy(by []string, aggs []Aggregation) (*Table, error) {
+	keyIdx, err := t.indexes(by)
+	if err != nil {
+		return nil, err
+	}
+	aggIdx := make([]int, len(aggs))
+	for i, a := range aggs {
+		aggIdx[i] = -1
+		if a.Column != "" {
+			if aggIdx[i], err = t.Index(a.Column); err != nil {
+				return nil, err
+			}
+		} else if a.Func != "count" {
+			return nil, fmt.Errorf("aggregate %q needs a column", a.Func)
+		}
+	}
+
+	type group struct {
+		key  []string
+		rows [][]string
+	}
+	var groups []*group
+	byKey := make(map[string]*group)
+	for _, row := range t.Rows {
+		key := pick(row, keyIdx)
+		k := strings.Join(key, "\x00")
+		g, ok := byKey[k]
+		if !ok {
+			g = &group{key: key}
+			byKey[k] = g
+			groups = append(groups, g)
+		}
+		g.rows = append(g.rows, row)
+	}
+
+	out := &Table{}
+	for _, i := range keyIdx {
+		out.Columns = append(out.Columns, t.Columns[i])
+	}
+	for i := range aggs {
+		out.Columns = append(out.Columns, aggs[i].name())
+	}
+	out.Rows = make([][]string, 0, len(groups))
+	for _, g := range groups {
+		row := append([]string{}, g.key...)
+		for i, a := range aggs {
+			values := make([]string, 0, len(g.rows))
+			for _, r := range g.rows {
+				if aggIdx[i] < 0 {
+					values = append(values, "")
+				} else {
+					values = append(values, r[aggIdx[i]])
+				}
+			}
+			v, err := aggregate(a.Func, values)
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, v)
+		}
+		out.Rows = append(out.Rows, row)
+	}
+	return out, nil
+}
+
+// Pivot 以 index 列的值为行、columns 列的值为列, 对 values 列做 fn 聚合.
+// fn 为 count 时 values 可以为空. 行和列都按首次出现的顺序排列, 没有数据的格子为空.
+func (t *Table) Pivot(index, columns, values, fn string) (*Table, error) {
+	if fn == "" {
+		fn = "sum"
+	}
+	ri, err := t.Index(index)
+	if err != nil {
+		return nil, err
+	}
+	ci, err := t.Index(columns)
+	if err != nil {
+		return nil, err
+	}
+	vi := -1
+	if values != "" {
+		if vi, err = t.Index(values); err != nil {
+			return nil, err
+		}
+	} else if fn != "count" {
+		return nil, fmt.Errorf("pivot with %q needs a values column", fn)
+	}
+
+	var rowKeys, colKeys []string
+	cells := make(map[[2]string][]string)
+	seenRow, seenCol := make(map[string]bool), make(map[string]bool)
+	for _, row := range t.Rows {
+		rk, ck := row[ri], row[ci]
+		if !seenRow[rk] {
+			seenRow[rk] = true
+			rowKeys = append(rowKeys, rk)
+		}
+		if !seenCol[ck] {
+			seenCol[ck] = true
+			colKeys = append(colKeys, ck)
+		}
+		v := ""
+		if vi >= 0 {
+			v = row[vi]
+		}
+		cells[[2]string{rk, ck}] = append(cells[[2]string{rk, ck}], v)
+	}
+
+	out := &Table{Columns: append([]string{t.Columns[ri]}, colKeys...), Rows: make([][]string, 0, len(rowKeys))}
+	for _, rk := range rowKeys {
+		row := []string{rk}
+		for _, ck := range colKeys {
+			vs, ok := cells[[2]string{rk, ck}]
+			if !ok {
+				row = append(row, "")
+				continue
+			}
+			v, err := aggregate(fn, vs)
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, v)
+		}
+		out.Rows = append(out.Rows, row)
+	}
+	return NewTable(out.Columns, out.Rows), nil
+}
+
+// Join 按键列连接两张表, how 为 inner、left、right 或 outer.
+// 结果包含左表所有列和右表的非键列, 与左表重名的右表列加 "_right" 后缀;
+// 只在右表出现的行, 键值填在左表的键列上.
+func Join(left, right *Table, leftOn, rightOn []string, how string) (*Table, error) {
+	if len(leftOn) == 0 || len(leftOn) != len(rightOn) {
+		return nil, fmt.Errorf("join needs the same number of key columns on both sides")
+	}
+	switch how {
+	case "":
+		how = "inner"
+	case "inner", "left", "right", "outer":
+	default:
+		return nil, fmt.Errorf("unknown join type %q", how)
+	}
+	li, err := left.indexes(leftOn)
+	if err != nil {
+		return nil, fmt.Errorf("left table: %w", err)
+	}
+	ri, err := right.indexes(rightOn)
+	if err != nil {
+		return nil, fmt.Errorf("right table: %w", err)
+	}
+
+	var rightCols []int
+	columns := append([]string{}, left.Columns...)
+	for i, c := range right.Columns {
+		if slices.Contains(ri, i) {
+			continue
+		}
+		rightCols = append(rightCols, i)
+		if slices.Contains(columns, c) {
+			c += "_right"
+		}
+		columns = append(columns, c)
+	}
+
+	index := make(map[string][]int)
+	for i, row := range right.Rows {
+		k := strings.Join(pick(row, ri), "\x00")
+		index[k] = append(index[k], i)
+	}
+	matched := make([]bool, len(right.Rows))
+	out := &Table{Columns: columns, Rows: [][]string{}}
+	combine := func(l, r []string) []string {
+		row := make([]string, 0, len(columns))
+		row = append(row, l...)
+		for _, i := range rightCols {
+			if r == nil {
+				row = append(row, "")
+			} else {
+				row = append(row, r[i])
+			}
+		}
+		return row
+	}
+
+	for _, l := range left.Rows {
+		hits := index[strings.Join(pick(l, li), "\x00")]
+		for _, i := range hits {
+			matched[i] = true
+			out.Rows = append(out.Rows, combine(l, right.Rows[i]))
+		}
+		if len(hits) == 0 && (how == "left" || how == "outer") {
+			out.Rows = append(out.Rows, combine(l, nil))
+		}
+	}
+	if how == "right" || how == "outer" {
+		for i, r := range right.Rows {
+			if matched[i] {
+				continue
+			}
+			l := make([]string, len(left.Columns))
+			for k, idx := range li {
+				l[idx] = r[ri[k]]
+			}
+			out.Rows = append(out.Rows, combine(l, r))
+		}
+	}
+	return out, nil
+}
+
+// Dedupe 按 columns 列(为空时比较整行)去重, keep 为 last 时保留最后一次出现的行, 否则保留第一次
+func (t *Table) Dedupe(columns []string, keep string) (*Table, error) {
+	idx := make([]int, len(t.Columns))
+	for i := range idx {
+		idx[i] = i
+	}
+	if len(columns) > 0 {
+		var err error
+		if idx, err = t.indexes(columns); err != nil {
+			return nil, err
+		}
+	}
+
+	rows := t.Rows
+	if keep == "last" {
+		rows = slices.Clone(rows)
+		slices.Reverse(rows)
+	}
+	seen := make(map[string]struct{}, len(rows))
+	out := &Table{Columns: t.Columns, Rows: [][]string{}}
+	for _, row := range rows {
+		k := strings.Join(pick(row, idx), "\x00")
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		out.Rows = append(out.Rows, row)
+	}
+	if keep == "last" {
+		slices.Reverse(out.Rows)
+	}
+	return out, nil
+}
+
+// Sort 按列排序, desc 为 true 时降序; 数字按数值比较, 排序是稳定的
+func (t *Table) Sort(column string, desc bool) (*Table, error) {
+	i, err := t.Index(column)
+	if err != nil {
+		return nil, err
+	}
+	rows := slices.Clone(t.Rows)
+	slices.SortStableFunc(rows, func(a, b []string) int {
+		if desc {
+			return compare(b[i], a[i])
+		}
+		return compare(a[i], b[i])
+	})
+	return &Table{Columns: t.Columns, Rows: rows}, nil
+}
+
+func pick(row []string, idx []int) []string {
+	out := make([]string, len(idx))
+	for i, j := range idx {
+		out[i] = row[j]
+	}
+	return out
+}
+
+func number(s string) (float64, bool) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+	if s == "" {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	return f, err == nil
+}
+
+func compare(a, b string) int {
+	fa, okA := number(a)
+	fb, okB := number(b)
+	if okA && okB {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(strings.TrimSpace(a), strings.TrimSpace(b))
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,323 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package dataframe 是基于 excelize 和 encoding/csv 的轻量表格处理,
+// 覆盖 excel agent 常见的读取、过滤、分组聚合、透视、连接、去重、写入和图表操作,
+// 不依赖 Python 环境.
+package dataframe
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var (
+	ErrColumnNotFound    = errors.New("column not found")
+	ErrSheetNotFound     = errors.New("sheet not found")
+	ErrUnsupportedFormat = errors.New("unsupported file format, only .csv and .xlsx are supported")
+)
+
+// Table 第一行作为表头的二维表, 单元格统一按字符串保存, 数值计算时再解析
+type Table struct {
+	Columns []string   `json:"columns"`
+	Rows    [][]string `json:"rows"`
+}
+
+// NewTable 用表头和数据行创建 Table, 空表头以列字母命名, 行长度补齐到列数
+func NewTable(columns []string, rows [][]string) *Table {
+	width := len(columns)
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	t := &Table{Columns: make([]string, width), Rows: make([][]string, len(rows))}
+	for i := range t.Columns {
+		if i < len(columns) {
+			t.Columns[i] = strings.TrimSpace(columns[i])
+		}
+		if t.Columns[i] == "" {
+			t.Columns[i], _ = excelize.ColumnNumberToName(i + 1)
+		}
+	}
+	for i, row := range rows {
+		r := make([]string, width)
+		copy(r, row)
+		t.Rows[i] = r
+	}
+	return t
+}
+
+// FromGrid 把二维数组的第一行作为表头
+func FromGrid(grid [][]string) *Table {
+	if len(grid) == 0 {
+		return NewTable(nil, nil)
+	}
+	return NewTable(grid[0], grid[1:])
+}
+
+// Index 返回列下标, 列名前后的空白会被忽略
+func (t *Table) Index(column string) (int, error) {
+	column = strings.TrimSpace(column)
+	for i, c := range t.Columns {
+		if c == column {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("%w: %q, available columns: %s", ErrColumnNotFound, column, strings.Join(t.Columns, ", "))
+}
+
+func (t *Table) indexes(columns []string) ([]int, error) {
+	idx := make([]int, len(columns))
+	for i, c := range columns {
+		var err error
+		if idx[i], err = t.Index(c); err != nil {
+			return nil, err
+		}
+	}
+	return idx, nil
+}
+
+// Head 返回前 n 行组成的新表
+func (t *Table) Head(n int) *Table {
+	if n < 0 || n > len(t.Rows) {
+		n = len(t.Rows)
+	}
+	return &Table{Columns: t.Columns, Rows: t.Rows[:n]}
+}
+
+// Read 按扩展名解析 .csv 或 .xlsx 文件内容.
+// sheet 为空时读取第一个工作表; cellRange 形如 "A1:D20"、"B:D" 或 "A3", 为空时读取全部,
+// 范围内的第一行作为表头.
+func Read(name string, data []byte, sheet, cellRange string) (*Table, error) {
+	var (
+		grid [][]string
+		err  error
+	)
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		grid, err = readCSV(data)
+	case ".xlsx", ".xlsm":
+		grid, err = readSheet(data, sheet)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cellRange != "" {
+		if grid, err = sliceRange(grid, cellRange); err != nil {
+			return nil, err
+		}
+	}
+	return FromGrid(grid), nil
+}
+
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	grid, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parse csv: %w", err)
+	}
+	return grid, nil
+}
+
+func readSheet(data []byte, sheet string) ([][]string, error) {
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("open workbook: %w", err)
+	}
+	defer f.Close()
+
+	if sheet == "" {
+		sheet = f.GetSheetName(0)
+	} else if idx, _ := f.GetSheetIndex(sheet); idx < 0 {
+		return nil, fmt.Errorf("%w: %q, available sheets: %s", ErrSheetNotFound, sheet, strings.Join(f.GetSheetList(), ", "))
+	}
+	return f.GetRows(sheet)
+}
+
+// sliceRange 截取 "A1:D20" 这样的区域, 两端都可以只写列("B:D")或只写一个单元格("A3" 表示从 A3 到末尾)
+func sliceRange(grid [][]string, cellRange string) ([][]string, error) {
+	from, to, _ := strings.Cut(strings.ToUpper(strings.ReplaceAll(cellRange, "$", "")), ":")
+	c1, r1, err := parseRef(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid range %q: %w", cellRange, err)
+	}
+	c2, r2 := 0, 0
+	if to != "" {
+		if c2, r2, err = parseRef(to); err != nil {
+			return nil, fmt.Errorf("invalid range %q: %w", cellRange, err)
+		}
+	}
+	r1, c1 = max(r1, 1), max(c1, 1)
+	if r2 == 0 {
+		r2 = len(grid)
+	}
+
+	var out [][]string
+	for r := r1; r <= r2 && r <= len(grid); r++ {
+		row := grid[r-1]
+		end := len(row)
+		if c2 > 0 {
+			end = min(end, c2)
+		}
+		var cells []string
+		if c1 <= end {
+			cells = append(cells, row[c1-1:end]...)
+		}
+		// 保持区域的宽度, 这样空的表头也能得到列名
+		if c2 > 0 {
+			for len(cells) < c2-c1+1 {
+				cells = append(cells, "")
+			}
+		}
+		out = append(out, cells)
+	}
+	return out, nil
+}
+
+// parseRef 解析 "B3"、"B" 或 "3", 缺省的部分返回 0
+func parseRef(ref string) (col, row int, err error) {
+	ref = strings.TrimSpace(ref)
+	letters := strings.TrimRight(ref, "0123456789")
+	if digits := ref[len(letters):]; digits != "" {
+		if row, err = strconv.Atoi(digits); err != nil {
+			return 0, 0, err
+		}
+	}
+	if letters != "" {
+		if col, err = excelize.ColumnNameToNumber(letters); err != nil {
+			return 0, 0, err
+		}
+	}
+	if col == 0 && row == 0 {
+		return 0, 0, fmt.Errorf("empty cell reference")
+	}
+	return col, row, nil
+}
+
+// Write 按扩展名把表格编码为 .csv 或写入 .xlsx 的工作表.
+// 写 xlsx 时 existing 为已有文件内容(可为空), 同名工作表会被整体替换, 其他工作表保留.
+func Write(name string, existing []byte, sheet string, t *Table) ([]byte, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return t.EncodeCSV()
+	case ".xlsx", ".xlsm":
+		return WriteSheet(existing, sheet, t)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, name)
+	}
+}
+
+// EncodeCSV 编码为带 BOM 的 UTF-8 CSV, Excel 打开中文不会乱码
+func (t *Table) EncodeCSV() ([]byte, error) {
+	buf := bytes.NewBufferString("\xef\xbb\xbf")
+	w := csv.NewWriter(buf)
+	if err := w.Write(t.Columns); err != nil {
+		return nil, err
+	}
+	if err := w.WriteAll(t.Rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteSheet 把表格写入工作簿的 sheet 工作表, 看起来是数字的单元格按数字写入, 便于公式和图表引用
+func WriteSheet(workbook []byte, sheet string, t *Table) ([]byte, error) {
+	var (
+		f   *excelize.File
+		err error
+	)
+	if len(workbook) == 0 {
+		f = excelize.NewFile()
+	} else if f, err = excelize.OpenReader(bytes.NewReader(workbook)); err != nil {
+		return nil, fmt.Errorf("open workbook: %w", err)
+	}
+	defer f.Close()
+
+	if err = resetSheet(f, sheet); err != nil {
+		return nil, err
+	}
+	write := func(r int, values []string) error {
+		cells := make([]any, len(values))
+		for i, v := range values {
+			cells[i] = cellValue(v)
+		}
+		cell, _ := excelize.CoordinatesToCellName(1, r)
+		return f.SetSheetRow(sheet, cell, &cells)
+	}
+	if err = write(1, t.Columns); err != nil {
+		return nil, err
+	}
+	for i, row := range t.Rows {
+		if err = write(i+2, row); err != nil {
+			return nil, err
+		}
+	}
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resetSheet 保证 sheet 存在且为空. 新建工作簿时重命名默认的 Sheet1;
+// 已有的工作表先在原位置插入一个新表再删除旧表, 保持工作表顺序不变.
+func resetSheet(f *excelize.File, sheet string) error {
+	list := f.GetSheetList()
+	if len(list) == 1 && list[0] == "Sheet1" && sheet != "Sheet1" {
+		if rows, _ := f.GetRows("Sheet1"); len(rows) == 0 {
+			return f.SetSheetName("Sheet1", sheet)
+		}
+	}
+	idx, err := f.GetSheetIndex(sheet)
+	if err != nil {
+		return err
+	}
+	if idx < 0 {
+		_, err = f.NewSheet(sheet)
+		return err
+	}
+	const tmp = "__dataframe_tmp__"
+	if _, err = f.NewSheet(tmp); err != nil {
+		return err
+	}
+	if err = f.MoveSheet(tmp, sheet); err != nil {
+		return err
+	}
+	if err = f.DeleteSheet(sheet); err != nil {
+		return err
+	}
+	return f.SetSheetName(tmp, sheet)
+}
+
+// cellValue 只有能原样还原的数字才转成数值, 避免 "007"、"1e3" 这类编号被改写
+func cellValue(s string) any {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || strconv.FormatFloat(v, 'f', -1, 64) != s {
+		return s
+	}
+	return v
+}
@@ -0,0 +1,531 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package generic
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// StepManifestsSessionKey 会话中每个已执行步骤的文件变更 []*Manifest, 与 planexecute.ExecutedStepsSessionKey 一一对应
+const StepManifestsSessionKey = "StepManifests"
+
+// BaselineSnapshotSessionKey 会话中第一个步骤执行前的工作目录快照 *Snapshot, 整体的文件变更相对它计算
+const BaselineSnapshotSessionKey = "BaselineSnapshot"
+
+type ChangeType string
+
+const (
+	ChangeCreated  ChangeType = "created"
+	ChangeModified ChangeType = "modified"
+	ChangeDeleted  ChangeType = "deleted"
+)
+
+const (
+	// maxSheetDiffBytes 超过该大小的表格只比较哈希, 不做单元格级别的比较
+	maxSheetDiffBytes = 10 << 20
+	// maxCellChanges 每个工作表最多列出的单元格变化, 其余只计数
+	maxCellChanges = 50
+)
+
+// Artifact 是工作目录中的一个文件, Path 为相对工作目录的路径
+type Artifact struct {
+	Path   string     `json:"path"`
+	Change ChangeType `json:"change,omitempty"`
+	Size   int64      `json:"size"`
+	SHA256 string     `json:"sha256"`
+	MIME   string     `json:"mime"`
+}
+
+// Manifest 是一个步骤前后工作目录的差异
+type Manifest struct {
+	Files      []*Artifact  `json:"files,omitempty"`
+	SheetDiffs []*SheetDiff `json:"sheet_diffs,omitempty"`
+}
+
+// SheetDiff 是表格文件中一个工作表的变化. 新增和删除的工作表只给出行列数, 修改的工作表列出变化的单元格
+type SheetDiff struct {
+	Path         string      `json:"path"`
+	Sheet        string      `json:"sheet,omitempty"` // csv/tsv 没有工作表名
+	Change       ChangeType  `json:"change"`
+	OldRows      int         `json:"old_rows"`
+	NewRows      int         `json:"new_rows"`
+	OldCols      int         `json:"old_cols"`
+	NewCols      int         `json:"new_cols"`
+	ChangedCells int         `json:"changed_cells"`
+	Cells        []*CellDiff `json:"cells,omitempty"`
+}
+
+type CellDiff struct {
+	Address string `json:"address"`
+	Old     string `json:"old,omitempty"`
+	New     string `json:"new,omitempty"`
+}
+
+// baselineDir 是工作目录中保存第一个步骤之前表格副本的隐藏目录, 见 Snapshot.Preserve
+const baselineDir = ".baseline"
+
+// Snapshot 是工作目录在某一时刻的文件列表, 表格文件同时保留单元格内容用于比较
+type Snapshot struct {
+	dir    string
+	files  map[string]*Artifact
+	sheets map[string][]*sheetData
+	// preserved 表示表格已复制到 baselineDir, 恢复的快照从副本读取单元格
+	preserved bool
+}
+
+type snapshotJSON struct {
+	Dir       string               `json:"dir"`
+	Files     map[string]*Artifact `json:"files"`
+	Preserved bool                 `json:"preserved,omitempty"`
+}
+
+// MarshalJSON 快照随 RunState 保存, 只保存文件的大小和哈希, 不保存单元格内容.
+// 恢复执行后比较单元格时从 Preserve 留下的副本重新读取
+func (s *Snapshot) MarshalJSON() ([]byte, error) {
+	return json.Marshal(snapshotJSON{Dir: s.dir, Files: s.files, Preserved: s.preserved})
+}
+
+func (s *Snapshot) UnmarshalJSON(b []byte) error {
+	var v snapshotJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	s.dir, s.files, s.sheets, s.preserved = v.Dir, v.Files, map[string][]*sheetData{}, v.Preserved
+	if s.files == nil {
+		s.files = map[string]*Artifact{}
+	}
+	return nil
+}
+
+// Preserve 把快照中的表格复制到工作目录的隐藏目录 .baseline 中并设为只读, 进程重启后恢复的快照
+// 仍能和原始内容比较单元格. 隐藏目录不计入快照、预览和任务的文件列表.
+func (s *Snapshot) Preserve() error {
+	root := filepath.Join(s.dir, baselineDir)
+	// 重新规划时会留下之前的副本
+	if err := os.RemoveAll(root); err != nil {
+		return err
+	}
+	for _, rel := range sortedKeys(s.sheets) {
+		dst := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return err
+		}
+		if err := copyReadOnly(filepath.Join(s.dir, filepath.FromSlash(rel)), dst); err != nil {
+			return err
+		}
+	}
+	s.preserved = true
+	return nil
+}
+
+func copyReadOnly(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o444)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// sheetsOf 返回快照中 rel 的工作表, ok 为 false 表示没有单元格内容(不是表格、无法解析、过大,
+// 或恢复的快照没有可用的副本), 只能比较哈希
+func (s *Snapshot) sheetsOf(rel string) ([]*sheetData, bool) {
+	if sheets, ok := s.sheets[rel]; ok {
+		return sheets, true
+	}
+	a, ok := s.files[rel]
+	if !s.preserved || !ok || !isSpreadsheet(rel) || a.Size > maxSheetDiffBytes {
+		return nil, false
+	}
+	// 副本可能被删除或修改, 哈希一致时才使用
+	path := filepath.Join(s.dir, baselineDir, filepath.FromSlash(rel))
+	if copied, err := fileArtifact(path, rel); err != nil || copied.SHA256 != a.SHA256 {
+		return nil, false
+	}
+	sheets, _, err := readSheets(&PreviewFile{FilePath: path})
+	if err != nil {
+		return nil, false
+	}
+	return sheets, true
+}
+
+// TakeSnapshot 记录 dir 下所有文件的大小、哈希和 MIME, 跳过隐藏文件和 Excel 的锁文件
+func TakeSnapshot(dir string) (*Snapshot, error) {
+	s := &Snapshot{dir: dir, files: map[string]*Artifact{}, sheets: map[string][]*sheetData{}}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && isHiddenFile(d.Name()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		a, err := fileArtifact(path, rel)
+		if err != nil {
+			return err
+		}
+		s.files[rel] = a
+		if isSpreadsheet(path) && a.Size <= maxSheetDiffBytes {
+			// 无法解析的表格只比较哈希
+			if sheets, _, err := readSheets(&PreviewFile{FilePath: path}); err == nil {
+				s.sheets[rel] = sheets
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func fileArtifact(path, rel string) (*Artifact, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	head = head[:n]
+	h.Write(head)
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return nil, err
+	}
+	return &Artifact{
+		Path:   rel,
+		Size:   size + int64(n),
+		SHA256: hex.EncodeToString(h.Sum(nil)),
+		MIME:   detectMIME(path, head),
+	}, nil
+}
+
+var mimeTypes = map[string]string{
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".xlsm": "application/vnd.ms-excel.sheet.macroEnabled.12",
+	".xls":  "application/vnd.ms-excel",
+	".csv":  "text/csv",
+	".tsv":  "text/tab-separated-values",
+	".md":   "text/markdown",
+	".py":   "text/x-python",
+	".json": "application/json",
+}
+
+func detectMIME(path string, head []byte) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if t, ok := mimeTypes[ext]; ok {
+		return t
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		return t
+	}
+	return http.DetectContentType(head)
+}
+
+func isSpreadsheet(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xlsx", ".xlsm", ".xls", ".csv", ".tsv":
+		return true
+	}
+	return false
+}
+
+// DiffSnapshots 比较同一目录的两个快照, 列出新建、修改和删除的文件, 以及表格文件各工作表的变化
+func DiffSnapshots(before, after *Snapshot) *Manifest {
+	m := &Manifest{}
+	for _, path := range sortedKeys(after.files) {
+		a := *after.files[path]
+		old, ok := before.files[path]
+		switch {
+		case !ok:
+			a.Change = ChangeCreated
+		case old.SHA256 != a.SHA256:
+			a.Change = ChangeModified
+		default:
+			continue
+		}
+		m.Files = append(m.Files, &a)
+		newSheets, ok := after.sheetsOf(path)
+		if !ok {
+			continue
+		}
+		var oldSheets []*sheetData
+		if a.Change == ChangeModified {
+			// 修改前的内容未知时无法比较单元格
+			if oldSheets, ok = before.sheetsOf(path); !ok {
+				continue
+			}
+		}
+		m.SheetDiffs = append(m.SheetDiffs, diffSheets(path, oldSheets, newSheets)...)
+	}
+	for _, path := range sortedKeys(before.files) {
+		if _, ok := after.files[path]; ok {
+			continue
+		}
+		a := *before.files[path]
+		a.Change = ChangeDeleted
+		m.Files = append(m.Files, &a)
+	}
+	return m
+}
+
+// diffSheets 按工作表名对应比较, csv/tsv 只有一个没有名字的工作表
+func diffSheets(path string, before, after []*sheetData) []*SheetDiff {
+	var diffs []*SheetDiff
+	find := func(sheets []*sheetData, name string) *sheetData {
+		for _, s := range sheets {
+			if s.name == name {
+				return s
+			}
+		}
+		return nil
+	}
+	for _, s := range after {
+		d := &SheetDiff{Path: path, Sheet: s.name, NewRows: len(s.grid), NewCols: gridWidth(s.grid)}
+		old := find(before, s.name)
+		if old == nil {
+			d.Change = ChangeCreated
+			diffs = append(diffs, d)
+			continue
+		}
+		d.Change = ChangeModified
+		d.OldRows, d.OldCols = len(old.grid), gridWidth(old.grid)
+		d.ChangedCells, d.Cells = diffGrid(old.grid, s.grid)
+		if d.ChangedCells > 0 {
+			diffs = append(diffs, d)
+		}
+	}
+	for _, s := range before {
+		if find(after, s.name) == nil {
+			diffs = append(diffs, &SheetDiff{Path: path, Sheet: s.name, Change: ChangeDeleted, OldRows: len(s.grid), OldCols: gridWidth(s.grid)})
+		}
+	}
+	return diffs
+}
+
+// diffGrid 按单元格位置比较, 返回变化的单元格总数和前 maxCellChanges 个变化
+func diffGrid(old, new [][]string) (int, []*CellDiff) {
+	total := 0
+	var cells []*CellDiff
+	for r := 0; r < max(len(old), len(new)); r++ {
+		oldRow, newRow := rowAt(old, r), rowAt(new, r)
+		for c := 0; c < max(len(oldRow), len(newRow)); c++ {
+			ov, nv := cellAt(oldRow, c), cellAt(newRow, c)
+			if ov == nv {
+				continue
+			}
+			total++
+			if len(cells) < maxCellChanges {
+				addr, _ := excelize.CoordinatesToCellName(c+1, r+1)
+				cells = append(cells, &CellDiff{Address: addr, Old: ov, New: nv})
+			}
+		}
+	}
+	return total, cells
+}
+
+func rowAt(grid [][]string, r int) []string {
+	if r < len(grid) {
+		return grid[r]
+	}
+	return nil
+}
+
+func cellAt(row []string, c int) string {
+	if c < len(row) {
+		return row[c]
+	}
+	return ""
+}
+
+func gridWidth(grid [][]string) int {
+	width := 0
+	for _, row := range grid {
+		width = max(width, len(row))
+	}
+	return width
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// String 给出便于阅读的变更摘要, 用于计划和报告
+func (m *Manifest) String() string {
+	if m == nil || len(m.Files) == 0 {
+		return ""
+	}
+	res := "#### 文件变更"
+	for _, f := range m.Files {
+		res += fmt.Sprintf("\n- [%s] %s (%s, %d 字节)", f.Change, f.Path, f.MIME, f.Size)
+	}
+	for _, d := range m.SheetDiffs {
+		name := d.Path
+		if d.Sheet != "" {
+			name += "#" + d.Sheet
+		}
+		switch d.Change {
+		case ChangeModified:
+			res += fmt.Sprintf("\n- 表格 %s: %d 个单元格变化, %dx%d -> %dx%d", name, d.ChangedCells, d.OldRows, d.OldCols, d.NewRows, d.NewCols)
+			for _, c := range d.Cells {
+				res += fmt.Sprintf("\n  - %s: %q -> %q", c.Address, c.Old, c.New)
+			}
+			if more := d.ChangedCells - len(d.Cells); more > 0 {
+				res += fmt.Sprintf("\n  - ... 另有 %d 个单元格变化", more)
+			}
+		case ChangeCreated:
+			res += fmt.Sprintf("\n- 表格 %s: 新增, %d 行 %d 列", name, d.NewRows, d.NewCols)
+		case ChangeDeleted:
+			res += fmt.Sprintf("\n- 表格 %s: 删除, 原有 %d 行 %d 列", name, d.OldRows, d.OldCols)
+		}
+	}
+	return res
+}
+
+// OverallManifest 整个执行过程的文件变更: 比较第一个步骤之前的快照 baseline 和当前的快照 final.
+// 缺少其中之一时(快照失败, 或从没有保存快照的进度恢复)退回到合并各步骤的变更.
+func OverallManifest(baseline, final *Snapshot, manifests []*Manifest) *Manifest {
+	if baseline != nil && final != nil {
+		return DiffSnapshots(baseline, final)
+	}
+	return MergeManifests(manifests)
+}
+
+// MergeManifests 合并多个步骤的文件变更, 得到相对于第一个步骤之前的整体变化.
+// 单元格差异只保留每个工作表最后一次的结果, 能拿到快照时应使用 OverallManifest.
+func MergeManifests(manifests []*Manifest) *Manifest {
+	files := map[string]*Artifact{}
+	var order []string
+	seen := map[string]bool{}
+	sheets := map[string]*SheetDiff{}
+	var sheetOrder []string
+	for _, m := range manifests {
+		if m == nil {
+			continue
+		}
+		for _, f := range m.Files {
+			cp := *f
+			prev, ok := files[f.Path]
+			if !ok {
+				// 新建后删除又再次新建的文件只列出一次
+				if !seen[f.Path] {
+					seen[f.Path] = true
+					order = append(order, f.Path)
+				}
+				files[f.Path] = &cp
+				continue
+			}
+			switch {
+			case prev.Change == ChangeCreated && f.Change == ChangeDeleted:
+				delete(files, f.Path)
+				continue
+			case prev.Change == ChangeCreated:
+				cp.Change = ChangeCreated
+			case prev.Change == ChangeDeleted && f.Change == ChangeCreated:
+				cp.Change = ChangeModified
+			}
+			files[f.Path] = &cp
+		}
+		for _, d := range m.SheetDiffs {
+			key := d.Path + "#" + d.Sheet
+			if _, ok := sheets[key]; !ok {
+				sheetOrder = append(sheetOrder, key)
+			}
+			sheets[key] = d
+		}
+	}
+	merged := &Manifest{}
+	for _, path := range order {
+		if f, ok := files[path]; ok {
+			merged.Files = append(merged.Files, f)
+		}
+	}
+	for _, key := range sheetOrder {
+		if _, ok := files[sheets[key].Path]; ok {
+			merged.SheetDiffs = append(merged.SheetDiffs, sheets[key])
+		}
+	}
+	return merged
+}
+
+// Artifact 返回快照中 path(绝对路径或相对工作目录的路径)对应的文件
+func (s *Snapshot) Artifact(path string) (*Artifact, bool) {
+	if filepath.IsAbs(path) {
+		rel, err := filepath.Rel(s.dir, path)
+		if err != nil {
+			return nil, false
+		}
+		path = rel
+	}
+	a, ok := s.files[filepath.ToSlash(filepath.Clean(path))]
+	return a, ok
+}
+
+// Change 返回相对路径 path 的变更类型, 未变化的文件返回空
+func (m *Manifest) Change(path string) ChangeType {
+	if m == nil {
+		return ""
+	}
+	for _, f := range m.Files {
+		if f.Path == path {
+			return f.Change
+		}
+	}
+	return ""
+}
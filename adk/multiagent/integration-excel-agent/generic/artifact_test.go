@@ -0,0 +1,171 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package generic
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	write("scores.csv", "name,score\nalice,1\nbob,2\n")
+	write("notes.txt", "draft")
+	write("plan.md", "- [ ] 1. read")
+	write(".hidden", "x")
+	before, err := TakeSnapshot(dir)
+	require.NoError(t, err)
+
+	write("scores.csv", "name,score\nalice,3\nbob,2\ncarol,4\n")
+	write("result.md", "# report")
+	write(".hidden", "y")
+	require.NoError(t, os.Remove(filepath.Join(dir, "notes.txt")))
+	after, err := TakeSnapshot(dir)
+	require.NoError(t, err)
+
+	m := DiffSnapshots(before, after)
+	require.Len(t, m.Files, 3)
+	assert.Equal(t, "result.md", m.Files[0].Path)
+	assert.Equal(t, ChangeCreated, m.Files[0].Change)
+	assert.Equal(t, "text/markdown", m.Files[0].MIME)
+	assert.Equal(t, int64(8), m.Files[0].Size)
+	assert.Len(t, m.Files[0].SHA256, 64)
+	assert.Equal(t, "scores.csv", m.Files[1].Path)
+	assert.Equal(t, ChangeModified, m.Files[1].Change)
+	assert.Equal(t, ChangeDeleted, m.Change("notes.txt"))
+
+	require.Len(t, m.SheetDiffs, 1)
+	d := m.SheetDiffs[0]
+	assert.Equal(t, ChangeModified, d.Change)
+	assert.Equal(t, 3, d.OldRows)
+	assert.Equal(t, 4, d.NewRows)
+	assert.Equal(t, 3, d.ChangedCells)
+	assert.Equal(t, []*CellDiff{
+		{Address: "B2", Old: "1", New: "3"},
+		{Address: "A4", New: "carol"},
+		{Address: "B4", New: "4"},
+	}, d.Cells)
+	assert.Contains(t, m.String(), `B2: "1" -> "3"`)
+
+	a, ok := after.Artifact(filepath.Join(dir, "result.md"))
+	require.True(t, ok)
+	assert.Equal(t, "result.md", a.Path)
+}
+
+func TestDiffXLSXSheets(t *testing.T) {
+	dir := t.TempDir()
+	fp := filepath.Join(dir, "sales.xlsx")
+	f := excelize.NewFile()
+	require.NoError(t, f.SetSheetRow("Sheet1", "A1", &[]any{"region", "amount"}))
+	require.NoError(t, f.SetSheetRow("Sheet1", "A2", &[]any{"east", 10}))
+	_, err := f.NewSheet("Old")
+	require.NoError(t, err)
+	require.NoError(t, f.SaveAs(fp))
+	before, err := TakeSnapshot(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, f.SetCellValue("Sheet1", "B2", 12))
+	require.NoError(t, f.DeleteSheet("Old"))
+	_, err = f.NewSheet("Summary")
+	require.NoError(t, err)
+	require.NoError(t, f.SetSheetRow("Summary", "A1", &[]any{"total", 12}))
+	require.NoError(t, f.Save())
+	require.NoError(t, f.Close())
+	after, err := TakeSnapshot(dir)
+	require.NoError(t, err)
+
+	step := DiffSnapshots(before, after)
+	require.Len(t, step.SheetDiffs, 3)
+	assert.Equal(t, &SheetDiff{Path: "sales.xlsx", Sheet: "Sheet1", Change: ChangeModified, OldRows: 2, NewRows: 2, OldCols: 2, NewCols: 2,
+		ChangedCells: 1, Cells: []*CellDiff{{Address: "B2", Old: "10", New: "12"}}}, step.SheetDiffs[0])
+	assert.Equal(t, ChangeCreated, step.SheetDiffs[1].Change)
+	assert.Equal(t, "Summary", step.SheetDiffs[1].Sheet)
+	assert.Equal(t, ChangeDeleted, step.SheetDiffs[2].Change)
+	assert.Equal(t, "Old", step.SheetDiffs[2].Sheet)
+
+	// 先新建再修改的文件在合并后仍是新建, 新建后又删除的文件不出现
+	created := &Manifest{Files: []*Artifact{{Path: "sales.xlsx", Change: ChangeCreated}, {Path: "tmp.py", Change: ChangeCreated}}}
+	deleted := &Manifest{Files: []*Artifact{{Path: "tmp.py", Change: ChangeDeleted}}}
+	merged := MergeManifests([]*Manifest{created, step, deleted})
+	require.Len(t, merged.Files, 1)
+	assert.Equal(t, ChangeCreated, merged.Files[0].Change)
+	assert.Len(t, merged.SheetDiffs, 3)
+}
+
+func TestOverallManifest(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	write("scores.csv", "name,score\nalice,1\nbob,2\n")
+	baseline, err := TakeSnapshot(dir)
+	require.NoError(t, err)
+	require.NoError(t, baseline.Preserve())
+
+	// 两个步骤分别修改不同的单元格, 中间生成又删除了临时文件, 最后重新生成
+	write("scores.csv", "name,score\nalice,3\nbob,2\n")
+	write("tmp.py", "print(1)")
+	mid, err := TakeSnapshot(dir)
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(filepath.Join(dir, "tmp.py")))
+	write("scores.csv", "name,score\nalice,3\nbob,5\n")
+	afterDelete, err := TakeSnapshot(dir)
+	require.NoError(t, err)
+	write("tmp.py", "print(2)")
+	final, err := TakeSnapshot(dir)
+	require.NoError(t, err)
+	steps := []*Manifest{DiffSnapshots(baseline, mid), DiffSnapshots(mid, afterDelete), DiffSnapshots(afterDelete, final)}
+
+	// 快照随 RunState 保存后再恢复, 只保存文件信息, 单元格从副本读取
+	b, err := json.Marshal(&RunState{Baseline: baseline})
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "alice")
+	var state RunState
+	require.NoError(t, json.Unmarshal(b, &state))
+	_, ok := final.Artifact(".baseline/scores.csv")
+	assert.False(t, ok)
+
+	m := OverallManifest(state.Baseline, final, steps)
+	require.Len(t, m.Files, 2)
+	assert.Equal(t, ChangeModified, m.Change("scores.csv"))
+	assert.Equal(t, ChangeCreated, m.Change("tmp.py"))
+	require.Len(t, m.SheetDiffs, 1)
+	assert.Equal(t, []*CellDiff{{Address: "B2", Old: "1", New: "3"}, {Address: "B3", Old: "2", New: "5"}}, m.SheetDiffs[0].Cells)
+
+	// 副本被修改后只比较哈希
+	require.NoError(t, os.Chmod(filepath.Join(dir, ".baseline", "scores.csv"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".baseline", "scores.csv"), []byte("name,score\n"), 0o644))
+	m = OverallManifest(state.Baseline, final, steps)
+	assert.Equal(t, ChangeModified, m.Change("scores.csv"))
+	assert.Empty(t, m.SheetDiffs)
+
+	// 没有快照时退回到合并各步骤, 重新生成的文件只列出一次
+	merged := OverallManifest(nil, final, steps)
+	require.Len(t, merged.Files, 2)
+	assert.Equal(t, "scores.csv", merged.Files[0].Path)
+	assert.Equal(t, "tmp.py", merged.Files[1].Path)
+	assert.Equal(t, ChangeCreated, merged.Files[1].Change)
+}
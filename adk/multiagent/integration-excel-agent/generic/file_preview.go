@@ -19,21 +19,28 @@ package generic
 import (
 	"os"
 	"path/filepath"
+	"strings"
 
-	"github.com/xuri/excelize/v2"
 	"golang.org/x/sync/errgroup"
 )
 
 type PreviewFile struct {
 	FilePath           string               `json:"file_path,omitempty" xml:"file_path"`
+	Format             string               `json:"format,omitempty" xml:"format"`
+	Encoding           string               `json:"encoding,omitempty" xml:"encoding"`   // 文本文件的编码, 如 utf-8、gbk
+	Delimiter          string               `json:"delimiter,omitempty" xml:"delimiter"` // csv/tsv 识别出的分隔符
 	SingleFilePreviews []*SingleFilePreview `json:"single_file_previews,omitempty" xml:"single_file_previews>single_file_preview"`
+	Error              string               `json:"error,omitempty" xml:"error"` // 解析失败的原因, 不影响其他文件的预览
 }
 
 type SingleFilePreview struct {
-	SheetName   string         `json:"sheet_name,omitempty" xml:"sheet_name"`
-	Header      []*ExcelCell   `json:"header" xml:"header"`
-	Content     [][]*ExcelCell `json:"content,omitempty" xml:"content"`
-	MergedCells []*ExcelCell   `json:"merged_cells,omitempty" xml:"merged_cells>merged_cell"`
+	SheetName   string           `json:"sheet_name,omitempty" xml:"sheet_name"`
+	RowCount    int              `json:"row_count" xml:"row_count"` // 不含表头的数据行数
+	Columns     []*ColumnProfile `json:"columns,omitempty" xml:"columns>column"`
+	Header      []*ExcelCell     `json:"header" xml:"header"`
+	Content     [][]*ExcelCell   `json:"content,omitempty" xml:"content"` // 抽样的数据行, 地址保留原始行号
+	MergedCells []*ExcelCell     `json:"merged_cells,omitempty" xml:"merged_cells>merged_cell"`
+	Sampling    string           `json:"sampling,omitempty" xml:"sampling"` // 抽样方式说明, 全部行都在 Content 中时为空
 }
 
 type ExcelCell struct {
@@ -41,7 +48,45 @@ type ExcelCell struct {
 	Value   string `json:"value,omitempty" xml:"value"`     // 单元格的值
 }
 
+// ColumnProfile 根据全部数据行(而不只是抽样行)统计的列信息
+type ColumnProfile struct {
+	Name      string  `json:"name" xml:"name"`
+	Type      string  `json:"type" xml:"type"` // integer、number、boolean、date、datetime、string 或 empty
+	NullRatio float64 `json:"null_ratio" xml:"null_ratio"`
+}
+
+// PreviewOptions 控制抽样行数和每个文件预览的大小
+type PreviewOptions struct {
+	HeadRows     int   // 开头保留的行数
+	TailRows     int   // 末尾保留的行数
+	RandomRows   int   // 中间随机抽取的行数
+	MaxCellChars int   // 单元格内容超出时截断
+	TokenBudget  int   // 所有文件预览合计的 token 预算, 按文件平均分配
+	Seed         int64 // 随机抽样的种子, 相同输入得到相同的预览
+}
+
+func DefaultPreviewOptions() *PreviewOptions {
+	return &PreviewOptions{
+		HeadRows:     10,
+		TailRows:     5,
+		RandomRows:   5,
+		MaxCellChars: 200,
+		TokenBudget:  12000,
+		Seed:         1,
+	}
+}
+
+// PreviewPath 使用默认选项预览 path 下的所有文件
 func PreviewPath(path string) ([]*PreviewFile, error) {
+	return PreviewPathWithOptions(path, DefaultPreviewOptions())
+}
+
+// PreviewPathWithOptions 预览 path(文件或目录)下的所有文件.
+// 支持 xlsx/xlsm、xls、csv、tsv、json/jsonl, 其他文件只给出路径; 单个文件解析失败记录在 Error 中.
+func PreviewPathWithOptions(path string, opts *PreviewOptions) ([]*PreviewFile, error) {
+	if opts == nil {
+		opts = DefaultPreviewOptions()
+	}
 	filePaths, err := getAllFiles(path)
 	if err != nil {
 		return nil, err
@@ -49,6 +94,7 @@ func PreviewPath(path string) ([]*PreviewFile, error) {
 	if len(filePaths) == 0 {
 		return nil, nil
 	}
+	budget := opts.TokenBudget / len(filePaths)
 	resp := make([]*PreviewFile, len(filePaths))
 	eg := errgroup.Group{}
 	eg.SetLimit(10)
@@ -56,19 +102,7 @@ func PreviewPath(path string) ([]*PreviewFile, error) {
 		idx := i
 		fp := filePaths[idx]
 		eg.Go(func() error {
-			var (
-				pf *PreviewFile
-				e  error
-			)
-			if ext := filepath.Ext(fp); ext != ".xlsx" { // .xls not support
-				pf = &PreviewFile{FilePath: fp}
-			} else {
-				pf, e = previewExcelDocument(path)
-			}
-			if e != nil {
-				return e
-			}
-			resp[idx] = pf
+			resp[idx] = previewFile(fp, opts, budget)
 			return nil
 		})
 	}
@@ -78,6 +112,48 @@ func PreviewPath(path string) ([]*PreviewFile, error) {
 	return resp, nil
 }
 
+func previewFile(fp string, opts *PreviewOptions, budget int) *PreviewFile {
+	pf := &PreviewFile{FilePath: fp}
+	sheets, ok, err := readSheets(pf)
+	if !ok {
+		return pf
+	}
+	if err != nil {
+		pf.Error = err.Error()
+		return pf
+	}
+
+	perSheet := budget
+	if len(sheets) > 0 {
+		perSheet = budget / len(sheets)
+	}
+	for _, s := range sheets {
+		pf.SingleFilePreviews = append(pf.SingleFilePreviews, previewSheet(s, opts, perSheet))
+	}
+	return pf
+}
+
+// readSheets 按扩展名读取 pf.FilePath 的全部工作表并记录格式, 不支持的格式 ok 为 false
+func readSheets(pf *PreviewFile) (sheets []*sheetData, ok bool, err error) {
+	switch strings.ToLower(filepath.Ext(pf.FilePath)) {
+	case ".xlsx", ".xlsm":
+		pf.Format = "xlsx"
+		sheets, err = readXLSX(pf.FilePath)
+	case ".xls":
+		pf.Format = "xls"
+		sheets, err = readXLS(pf.FilePath)
+	case ".csv", ".tsv":
+		pf.Format = strings.TrimPrefix(strings.ToLower(filepath.Ext(pf.FilePath)), ".")
+		sheets, err = readDelimited(pf)
+	case ".json", ".jsonl":
+		pf.Format = "json"
+		sheets, err = readJSON(pf)
+	default:
+		return nil, false, nil
+	}
+	return sheets, true, err
+}
+
 func getAllFiles(path string) ([]string, error) {
 	info, err := os.Stat(path)
 	if err != nil {
@@ -92,18 +168,18 @@ func getAllFiles(path string) ([]string, error) {
 	}
 
 	var files []string
-	if err = filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
+	if err = filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		if isHiddenFile(info.Name()) {
+		if p != path && isHiddenFile(info.Name()) {
 			if info.IsDir() {
 				return filepath.SkipDir
 			}
 			return nil
 		}
 		if !info.IsDir() {
-			files = append(files, path)
+			files = append(files, p)
 		}
 		return nil
 	}); err != nil {
@@ -113,106 +189,7 @@ func getAllFiles(path string) ([]string, error) {
 	return files, nil
 }
 
-func previewExcelDocument(filePath string) (*PreviewFile, error) {
-	f, err := excelize.OpenFile(filePath)
-	if err != nil {
-		return nil, err
-	}
-
-	defer f.Close()
-
-	pf := &PreviewFile{
-		FilePath:           filePath,
-		SingleFilePreviews: nil,
-	}
-	for _, sheetName := range f.GetSheetList() {
-		sfp, err := parseSheet(f, sheetName)
-		if err != nil {
-			return nil, err
-		}
-
-		pf.SingleFilePreviews = append(pf.SingleFilePreviews, sfp)
-	}
-
-	return pf, nil
-}
-
-func parseSheet(f *excelize.File, sheetName string) (*SingleFilePreview, error) {
-	preview := &SingleFilePreview{
-		SheetName:   sheetName,
-		Header:      make([]*ExcelCell, 0),
-		Content:     make([][]*ExcelCell, 0),
-		MergedCells: make([]*ExcelCell, 0),
-	}
-	mcs := make([][][]int, 20)
-	mergedCells, err := f.GetMergeCells(sheetName)
-	if err != nil {
-		return nil, err
-	}
-	for _, cell := range mergedCells {
-		lcol, lrow, err := excelize.CellNameToCoordinates(cell.GetStartAxis())
-		if err != nil {
-			return nil, err
-		}
-		if lrow >= 20 {
-			continue
-		}
-
-		rcol, rrow, err := excelize.CellNameToCoordinates(cell.GetEndAxis())
-		if err != nil {
-			return nil, err
-		}
-		preview.MergedCells = append(preview.MergedCells, &ExcelCell{
-			Address: cell[0],
-			Value:   cell.GetCellValue(),
-		})
-		r := mcs[lrow]
-		r = append(r, []int{lcol, lrow, rcol, rrow})
-	}
-
-	rowIter, err := f.Rows(sheetName)
-	if err != nil {
-		return nil, err
-	}
-
-	i := 0
-	for rowIter.Next() && i < 20 {
-		values, err := rowIter.Columns()
-		if err != nil {
-			return nil, err
-		}
-		var rowValues []*ExcelCell
-		for j, val := range values {
-			col, row := j+1, i+1
-			skip := false
-			if r := mcs[i]; len(r) > 0 {
-				for _, coverage := range r {
-					if col >= coverage[0] && row >= coverage[1] && col <= coverage[2] && row <= coverage[3] {
-						skip = true
-						break
-					}
-				}
-			}
-			if skip {
-				continue
-			}
-			addr, err := excelize.CoordinatesToCellName(col, row)
-			if err != nil {
-				return nil, err
-			}
-			rowValues = append(rowValues, &ExcelCell{Address: addr, Value: val})
-		}
-		if i == 0 {
-			preview.Header = rowValues
-		} else {
-			preview.Content = append(preview.Content, rowValues)
-		}
-		i++
-	}
-
-	return preview, nil
-}
-
+// isHiddenFile 隐藏文件以及 Excel 打开文件时生成的 ~$ 锁文件
 func isHiddenFile(name string) bool {
-	return len(name) > 0 && name[0] == '.'
+	return strings.HasPrefix(name, ".") || strings.HasPrefix(name, "~$")
 }
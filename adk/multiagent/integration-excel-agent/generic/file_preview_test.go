@@ -0,0 +1,156 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package generic
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func TestPreviewCSV(t *testing.T) {
+	previews, err := PreviewPath("../playground/test_data/questions.csv")
+	require.NoError(t, err)
+	require.Len(t, previews, 1)
+	pf := previews[0]
+	assert.Empty(t, pf.Error)
+	assert.Equal(t, "csv", pf.Format)
+	assert.Equal(t, "utf-8-sig", pf.Encoding)
+	assert.Equal(t, ",", pf.Delimiter)
+
+	require.Len(t, pf.SingleFilePreviews, 1)
+	sp := pf.SingleFilePreviews[0]
+	assert.Equal(t, 24, sp.RowCount)
+	assert.Equal(t, "type", sp.Header[0].Value)
+	assert.Equal(t, &ColumnProfile{Name: "type", Type: "string"}, sp.Columns[0])
+	assert.NotEmpty(t, sp.Sampling)
+	assert.Equal(t, "A2", sp.Content[0][0].Address)
+}
+
+func TestPreviewGBKAndDelimiter(t *testing.T) {
+	dir := t.TempDir()
+	gbk, err := simplifiedchinese.GBK.NewEncoder().String("题目;分数;日期\n加法;3;2025-01-02\n减法;;2025-01-03\n")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "scores.csv"), []byte(gbk), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "~$scores.xlsx"), []byte("lock"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.json"), []byte("[1, 2]"), 0o644))
+
+	previews, err := PreviewPath(dir)
+	require.NoError(t, err)
+	require.Len(t, previews, 2)
+
+	// 解析失败的文件不影响其他文件
+	assert.Equal(t, "json", previews[0].Format)
+	assert.NotEmpty(t, previews[0].Error)
+
+	pf := previews[1]
+	assert.Equal(t, "gbk", pf.Encoding)
+	assert.Equal(t, ";", pf.Delimiter)
+	sp := pf.SingleFilePreviews[0]
+	assert.Equal(t, 2, sp.RowCount)
+	assert.Equal(t, []*ColumnProfile{
+		{Name: "题目", Type: "string"},
+		{Name: "分数", Type: "integer", NullRatio: 0.5},
+		{Name: "日期", Type: "date"},
+	}, sp.Columns)
+	assert.Empty(t, sp.Sampling)
+}
+
+func TestPreviewJSONL(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "rows.jsonl")
+	require.NoError(t, os.WriteFile(fp, []byte(`{"b":1,"a":"x"}`+"\n"+`{"a":"y","c":true}`+"\n"), 0o644))
+
+	previews, err := PreviewPath(fp)
+	require.NoError(t, err)
+	sp := previews[0].SingleFilePreviews[0]
+	assert.Equal(t, []*ExcelCell{{Address: "A1", Value: "b"}, {Address: "B1", Value: "a"}, {Address: "C1", Value: "c"}}, sp.Header)
+	assert.Equal(t, []*ExcelCell{{Address: "B3", Value: "y"}, {Address: "C3", Value: "true"}}, sp.Content[1])
+	assert.Equal(t, "boolean", sp.Columns[2].Type)
+}
+
+func TestPreviewXLSXMergedAndSampling(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "sales.xlsx")
+	f := excelize.NewFile()
+	require.NoError(t, f.SetSheetRow("Sheet1", "A1", &[]any{"region", "", "amount"}))
+	require.NoError(t, f.MergeCell("Sheet1", "A1", "B1"))
+	for i := 0; i < 100; i++ {
+		row := i + 2
+		require.NoError(t, f.SetSheetRow("Sheet1", "A"+strconv.Itoa(row), &[]any{"east", "shop", i}))
+	}
+	// 合并单元格跨越第 50、51 行
+	require.NoError(t, f.MergeCell("Sheet1", "A50", "B51"))
+	require.NoError(t, f.SaveAs(fp))
+	require.NoError(t, f.Close())
+
+	opts := DefaultPreviewOptions()
+	opts.RandomRows = 0
+	previews, err := PreviewPathWithOptions(fp, opts)
+	require.NoError(t, err)
+	require.Empty(t, previews[0].Error)
+	sp := previews[0].SingleFilePreviews[0]
+	assert.Equal(t, 100, sp.RowCount)
+	assert.Equal(t, "head 10, tail 5 and 0 random rows of 100", sp.Sampling)
+	require.Len(t, sp.Content, 15)
+	assert.Equal(t, "A101", sp.Content[14][0].Address)
+	// 表头被合并的单元格只在 MergedCells 中出现, 未抽样到的合并区域不列出
+	assert.Equal(t, []*ExcelCell{{Address: "C1", Value: "amount"}}, sp.Header)
+	assert.Equal(t, []*ExcelCell{{Address: "A1:B1", Value: "region"}}, sp.MergedCells)
+	assert.Equal(t, "integer", sp.Columns[2].Type)
+
+	// 预算不足时减少抽样行数
+	opts.TokenBudget = 300
+	previews, err = PreviewPathWithOptions(fp, opts)
+	require.NoError(t, err)
+	assert.Less(t, len(previews[0].SingleFilePreviews[0].Content), 15)
+}
+
+func TestPreviewMalformedXLS(t *testing.T) {
+	// 合法的复合文档头, 后面的扇区是垃圾数据, extrame/xls 解析时会 panic
+	b := make([]byte, 512*4)
+	copy(b, []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1})
+	binary.LittleEndian.PutUint16(b[0x18:], 0x3E)
+	binary.LittleEndian.PutUint16(b[0x1A:], 3)
+	binary.LittleEndian.PutUint16(b[0x1C:], 0xFFFE)
+	binary.LittleEndian.PutUint16(b[0x1E:], 9)
+	binary.LittleEndian.PutUint16(b[0x20:], 6)
+	binary.LittleEndian.PutUint32(b[0x2C:], 1)
+	binary.LittleEndian.PutUint32(b[0x30:], 1)
+	binary.LittleEndian.PutUint32(b[0x38:], 4096)
+	binary.LittleEndian.PutUint32(b[0x3C:], 0xFFFFFFFE)
+	binary.LittleEndian.PutUint32(b[0x44:], 0xFFFFFFFE)
+	for i := 0x50; i < 512; i += 4 {
+		binary.LittleEndian.PutUint32(b[i:], 0xFFFFFFFF)
+	}
+	for i := 512; i < len(b); i++ {
+		b[i] = 1
+	}
+	fp := filepath.Join(t.TempDir(), "broken.xls")
+	require.NoError(t, os.WriteFile(fp, b, 0644))
+
+	previews, err := PreviewPath(fp)
+	require.NoError(t, err)
+	require.Len(t, previews, 1)
+	assert.Equal(t, "xls", previews[0].Format)
+	assert.Contains(t, previews[0].Error, "invalid xls")
+}
@@ -0,0 +1,293 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package generic
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/extrame/xls"
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// readXLSX 读取工作簿的全部工作表以及合并单元格
+func readXLSX(fp string) ([]*sheetData, error) {
+	f, err := excelize.OpenFile(fp)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var sheets []*sheetData
+	for _, name := range f.GetSheetList() {
+		rows, err := f.GetRows(name)
+		if err != nil {
+			return nil, fmt.Errorf("read sheet %s: %w", name, err)
+		}
+		mcs, err := f.GetMergeCells(name)
+		if err != nil {
+			return nil, fmt.Errorf("read merged cells of sheet %s: %w", name, err)
+		}
+		s := &sheetData{name: name, grid: rows}
+		for _, mc := range mcs {
+			c1, r1, err := excelize.CellNameToCoordinates(mc.GetStartAxis())
+			if err != nil {
+				return nil, err
+			}
+			c2, r2, err := excelize.CellNameToCoordinates(mc.GetEndAxis())
+			if err != nil {
+				return nil, err
+			}
+			s.merged = append(s.merged, mergedRange{c1: c1, r1: r1, c2: c2, r2: r2, value: mc.GetCellValue()})
+		}
+		sheets = append(sheets, s)
+	}
+	return sheets, nil
+}
+
+// readXLS 读取旧版 Excel 97-2003 工作簿, 不包含合并单元格信息
+func readXLS(fp string) (sheets []*sheetData, err error) {
+	// extrame/xls 遇到损坏的文件会 panic
+	defer func() {
+		if p := recover(); p != nil {
+			sheets, err = nil, fmt.Errorf("invalid xls: %v", p)
+		}
+	}()
+	wb, err := xls.Open(fp, "utf-8")
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < wb.NumSheets(); i++ {
+		ws := wb.GetSheet(i)
+		if ws == nil {
+			continue
+		}
+		s := &sheetData{name: ws.Name}
+		for r := 0; r <= int(ws.MaxRow); r++ {
+			row := ws.Row(r)
+			if row == nil {
+				s.grid = append(s.grid, nil)
+				continue
+			}
+			values := make([]string, row.LastCol())
+			for c := row.FirstCol(); c < row.LastCol(); c++ {
+				values[c] = row.Col(c)
+			}
+			s.grid = append(s.grid, values)
+		}
+		s.grid = trimTrailingEmptyRows(s.grid)
+		sheets = append(sheets, s)
+	}
+	return sheets, nil
+}
+
+// readDelimited 读取 csv/tsv 文件, 识别出的编码和分隔符记录在 pf 上
+func readDelimited(pf *PreviewFile) ([]*sheetData, error) {
+	data, err := os.ReadFile(pf.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	data, pf.Encoding, err = decodeText(data)
+	if err != nil {
+		return nil, err
+	}
+
+	comma := sniffDelimiter(data, pf.Format == "tsv")
+	pf.Delimiter = string(comma)
+	if comma == '\t' {
+		pf.Delimiter = `\t`
+	}
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	grid, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	return []*sheetData{{name: "", grid: trimTrailingEmptyRows(grid)}}, nil
+}
+
+// decodeText 去掉 BOM 并把内容转成 UTF-8. 不是合法 UTF-8 时按 GBK(GB18030) 解码
+func decodeText(data []byte) ([]byte, string, error) {
+	if b, ok := bytes.CutPrefix(data, []byte("\xef\xbb\xbf")); ok {
+		return b, "utf-8-sig", nil
+	}
+	if utf8.Valid(data) {
+		return data, "utf-8", nil
+	}
+	decoded, err := simplifiedchinese.GB18030.NewDecoder().Bytes(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("unknown text encoding: %w", err)
+	}
+	return decoded, "gbk", nil
+}
+
+var delimiterCandidates = []rune{',', '\t', ';', '|'}
+
+// sniffDelimiter 选出在前若干行中每行出现次数一致且最多的分隔符, 无法判断时 tsv 取制表符, 其他取逗号
+func sniffDelimiter(data []byte, tsv bool) rune {
+	best, bestCount := ',', 0
+	if tsv {
+		best = '\t'
+	}
+	var lines []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() && len(lines) < 20 {
+		if strings.TrimSpace(sc.Text()) != "" {
+			lines = append(lines, sc.Text())
+		}
+	}
+	if len(lines) == 0 {
+		return best
+	}
+	for _, d := range delimiterCandidates {
+		first := strings.Count(lines[0], string(d))
+		if first == 0 {
+			continue
+		}
+		consistent := 0
+		for _, l := range lines {
+			if strings.Count(l, string(d)) == first {
+				consistent++
+			}
+		}
+		// 允许少量行因为引号内换行等原因不一致
+		if consistent*10 < len(lines)*8 {
+			continue
+		}
+		if first > bestCount {
+			best, bestCount = d, first
+		}
+	}
+	return best
+}
+
+// readJSON 读取对象数组或 jsonl(每行一个对象), 列为所有对象键按首次出现的顺序合并
+func readJSON(pf *PreviewFile) ([]*sheetData, error) {
+	data, err := os.ReadFile(pf.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	data, pf.Encoding, err = decodeText(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []json.RawMessage
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("[")) {
+		if err = json.Unmarshal(trimmed, &records); err != nil {
+			return nil, err
+		}
+	} else {
+		dec := json.NewDecoder(bytes.NewReader(trimmed))
+		for dec.More() {
+			var rec json.RawMessage
+			if err = dec.Decode(&rec); err != nil {
+				return nil, err
+			}
+			records = append(records, rec)
+		}
+	}
+
+	var (
+		columns []string
+		index   = map[string]int{}
+		rows    [][]string
+	)
+	for i, raw := range records {
+		var obj map[string]json.RawMessage
+		if err = json.Unmarshal(raw, &obj); err != nil {
+			return nil, fmt.Errorf("record %d is not an object: %w", i+1, err)
+		}
+		keys, err := objectKeys(raw)
+		if err != nil {
+			return nil, err
+		}
+		row := make([]string, len(columns))
+		for _, k := range keys {
+			if _, ok := index[k]; !ok {
+				index[k] = len(columns)
+				columns = append(columns, k)
+			}
+			for len(row) <= index[k] {
+				row = append(row, "")
+			}
+			row[index[k]] = jsonScalar(obj[k])
+		}
+		rows = append(rows, row)
+	}
+	if len(columns) == 0 {
+		return []*sheetData{{}}, nil
+	}
+	return []*sheetData{{grid: append([][]string{columns}, rows...)}}, nil
+}
+
+// objectKeys 按出现顺序返回 JSON 对象的键, map 会丢失顺序
+func objectKeys(raw json.RawMessage) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	var keys []string
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, t.(string))
+		var skip json.RawMessage
+		if err = dec.Decode(&skip); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// jsonScalar 字符串去掉引号, null 视为空, 数组和对象保留原始 JSON
+func jsonScalar(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	v := string(bytes.TrimSpace(raw))
+	if v == "null" {
+		return ""
+	}
+	return v
+}
+
+func trimTrailingEmptyRows(grid [][]string) [][]string {
+	for len(grid) > 0 {
+		last := grid[len(grid)-1]
+		if strings.TrimSpace(strings.Join(last, "")) != "" {
+			break
+		}
+		grid = grid[:len(grid)-1]
+	}
+	return grid
+}
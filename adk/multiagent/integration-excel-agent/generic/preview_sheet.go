@@ -0,0 +1,281 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package generic
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// sheetData 各种格式解析后的统一表示, grid 的第一行是表头
+type sheetData struct {
+	name   string
+	grid   [][]string
+	merged []mergedRange
+}
+
+// mergedRange 合并单元格的范围, 行列从 1 开始且包含两端, value 为左上角单元格的值
+type mergedRange struct {
+	c1, r1, c2, r2 int
+	value          string
+}
+
+func (m *mergedRange) contains(col, row int) bool {
+	return col >= m.c1 && col <= m.c2 && row >= m.r1 && row <= m.r2
+}
+
+func (m *mergedRange) address() string {
+	from, _ := excelize.CoordinatesToCellName(m.c1, m.r1)
+	to, _ := excelize.CoordinatesToCellName(m.c2, m.r2)
+	return from + ":" + to
+}
+
+// previewSheet 生成工作表预览: 列信息基于全部行统计, 内容按 开头/末尾/随机 抽样,
+// 超出 budget(估算的 token 数)时依次减少随机行、末尾行和开头行.
+func previewSheet(s *sheetData, opts *PreviewOptions, budget int) *SingleFilePreview {
+	p := &SingleFilePreview{
+		SheetName:   s.name,
+		Header:      make([]*ExcelCell, 0),
+		Content:     make([][]*ExcelCell, 0),
+		MergedCells: make([]*ExcelCell, 0),
+	}
+	if len(s.grid) == 0 {
+		return p
+	}
+	p.RowCount = len(s.grid) - 1
+	p.Columns = profileColumns(s.grid)
+	p.Header = s.rowCells(1, opts.MaxCellChars)
+
+	head, tail, random := opts.HeadRows, opts.TailRows, opts.RandomRows
+	for {
+		rows := sampleRows(p.RowCount, head, tail, random, opts.Seed)
+		p.Content = p.Content[:0]
+		for _, r := range rows {
+			// 数据行 r 位于工作表的第 r+2 行
+			p.Content = append(p.Content, s.rowCells(r+2, opts.MaxCellChars))
+		}
+		p.MergedCells = s.mergedCells(rows, opts.MaxCellChars)
+		p.Sampling = ""
+		if len(rows) < p.RowCount {
+			h, t := min(head, len(rows)), min(tail, len(rows)-min(head, len(rows)))
+			p.Sampling = fmt.Sprintf("head %d, tail %d and %d random rows of %d", h, t, len(rows)-h-t, p.RowCount)
+		}
+		if budget <= 0 || head+tail+random == 0 || estimateTokens(p) <= budget {
+			return p
+		}
+		switch {
+		case random > 0:
+			random--
+		case tail > 0:
+			tail--
+		default:
+			head--
+		}
+	}
+}
+
+// rowCells 返回工作表第 row 行(从 1 开始)的非空单元格, 被合并的单元格不单独列出
+func (s *sheetData) rowCells(row, maxChars int) []*ExcelCell {
+	cells := make([]*ExcelCell, 0)
+	values := s.grid[row-1]
+	for i, v := range values {
+		col := i + 1
+		if strings.TrimSpace(v) == "" || s.inMerged(col, row) {
+			continue
+		}
+		addr, _ := excelize.CoordinatesToCellName(col, row)
+		cells = append(cells, &ExcelCell{Address: addr, Value: truncate(v, maxChars)})
+	}
+	return cells
+}
+
+func (s *sheetData) inMerged(col, row int) bool {
+	for i := range s.merged {
+		if s.merged[i].contains(col, row) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergedCells 返回与表头或抽样行相交的合并单元格
+func (s *sheetData) mergedCells(rows []int, maxChars int) []*ExcelCell {
+	cells := make([]*ExcelCell, 0)
+	for i := range s.merged {
+		m := &s.merged[i]
+		visible := m.r1 == 1
+		for _, r := range rows {
+			if visible {
+				break
+			}
+			visible = r+2 >= m.r1 && r+2 <= m.r2
+		}
+		if visible {
+			cells = append(cells, &ExcelCell{Address: m.address(), Value: truncate(m.value, maxChars)})
+		}
+	}
+	return cells
+}
+
+// sampleRows 从 n 个数据行中选出开头 head 行、末尾 tail 行和中间随机 random 行, 返回升序的下标
+func sampleRows(n, head, tail, random int, seed int64) []int {
+	if head+tail+random >= n {
+		rows := make([]int, n)
+		for i := range rows {
+			rows[i] = i
+		}
+		return rows
+	}
+	rows := make([]int, 0, head+tail+random)
+	for i := 0; i < head; i++ {
+		rows = append(rows, i)
+	}
+	if mid := n - head - tail; random > 0 && mid > 0 {
+		rnd := rand.New(rand.NewPCG(uint64(seed), uint64(n)))
+		for _, i := range rnd.Perm(mid)[:min(random, mid)] {
+			rows = append(rows, head+i)
+		}
+	}
+	for i := n - tail; i < n; i++ {
+		rows = append(rows, i)
+	}
+	slices.Sort(rows)
+	return rows
+}
+
+var (
+	dateLayouts     = []string{"2006-01-02", "2006/01/02", "2006/1/2", "2006.01.02", "2006年1月2日", "01-02-06", "1/2/06"}
+	datetimeLayouts = []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006/01/02 15:04:05", "2006/1/2 15:04", time.RFC3339, "1/2/06 15:04"}
+	nullValues      = []string{"", "null", "nan", "n/a", "na", "none", "#n/a"}
+)
+
+// profileColumns 推断每一列的类型和空值比例. 非空值全部符合某种类型时才取该类型, 否则为 string
+func profileColumns(grid [][]string) []*ColumnProfile {
+	width := 0
+	for _, row := range grid {
+		width = max(width, len(row))
+	}
+	rows := grid[1:]
+	profiles := make([]*ColumnProfile, width)
+	for c := 0; c < width; c++ {
+		name := ""
+		if c < len(grid[0]) {
+			name = strings.TrimSpace(grid[0][c])
+		}
+		if name == "" {
+			name, _ = excelize.ColumnNumberToName(c + 1)
+		}
+		nulls := 0
+		kinds := map[string]bool{"integer": true, "number": true, "boolean": true, "date": true, "datetime": true}
+		for _, row := range rows {
+			v := ""
+			if c < len(row) {
+				v = strings.TrimSpace(row[c])
+			}
+			if slices.Contains(nullValues, strings.ToLower(v)) {
+				nulls++
+				continue
+			}
+			for k := range kinds {
+				if !matchesKind(k, v) {
+					delete(kinds, k)
+				}
+			}
+		}
+		p := &ColumnProfile{Name: name, Type: "string"}
+		if len(rows) > 0 {
+			p.NullRatio = math.Round(float64(nulls)/float64(len(rows))*1000) / 1000
+		}
+		switch {
+		case nulls == len(rows):
+			p.Type = "empty"
+		case kinds["integer"]:
+			p.Type = "integer"
+		case kinds["number"]:
+			p.Type = "number"
+		case kinds["boolean"]:
+			p.Type = "boolean"
+		case kinds["date"]:
+			p.Type = "date"
+		case kinds["datetime"]:
+			p.Type = "datetime"
+		}
+		profiles[c] = p
+	}
+	return profiles
+}
+
+func matchesKind(kind, v string) bool {
+	switch kind {
+	case "integer":
+		_, err := strconv.ParseInt(strings.ReplaceAll(v, ",", ""), 10, 64)
+		return err == nil
+	case "number":
+		_, err := strconv.ParseFloat(strings.TrimSuffix(strings.ReplaceAll(v, ",", ""), "%"), 64)
+		return err == nil
+	case "boolean":
+		switch strings.ToLower(v) {
+		case "true", "false":
+			return true
+		}
+		return false
+	case "date":
+		return parsesAs(v, dateLayouts)
+	case "datetime":
+		return parsesAs(v, datetimeLayouts)
+	}
+	return false
+}
+
+func parsesAs(v string, layouts []string) bool {
+	for _, l := range layouts {
+		if _, err := time.Parse(l, v); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func truncate(s string, maxChars int) string {
+	if maxChars <= 0 || utf8.RuneCountInString(s) <= maxChars {
+		return s
+	}
+	return string([]rune(s)[:maxChars]) + "..."
+}
+
+// estimateTokens 粗略估算预览序列化后的 token 数: ASCII 约 4 个字符一个 token, 其他字符各算一个
+func estimateTokens(v any) int {
+	b, _ := json.Marshal(v)
+	ascii, other := 0, 0
+	for _, r := range string(b) {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return ascii/4 + other
+}
@@ -0,0 +1,38 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package generic
+
+import (
+	"context"
+
+	"github.com/cloudwego/eino/adk/prebuilt/planexecute"
+	"github.com/cloudwego/eino/schema"
+)
+
+// RunState 是规划或重规划完成时的执行进度: 已完成的步骤和剩余的计划.
+// 保存下来后, 中断的任务可以跳过规划, 从剩余计划的第一步继续执行.
+type RunState struct {
+	UserInput     []*schema.Message          `json:"user_input"`
+	Plan          *Plan                      `json:"plan,omitempty"`
+	ExecutedSteps []planexecute.ExecutedStep `json:"executed_steps,omitempty"`
+	Manifests     []*Manifest                `json:"manifests,omitempty"` // 与 ExecutedSteps 对应的文件变更
+	Baseline      *Snapshot                  `json:"baseline,omitempty"`  // 第一个步骤执行前的工作目录
+	Finished      bool                       `json:"finished,omitempty"`  // 计划已执行完毕, 恢复时只需生成报告
+}
+
+// ProgressFunc 在每次规划或重规划之后被调用, plans 为已完成(done)和待执行(todo)的全部步骤
+type ProgressFunc func(ctx context.Context, state *RunState, plans []*FullPlan)
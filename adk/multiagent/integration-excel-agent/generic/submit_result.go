@@ -27,11 +27,18 @@ type SubmitResult struct {
 	IsSuccess *bool               `json:"is_success,omitempty"`
 	Result    string              `json:"result,omitempty"`
 	Files     []*SubmitResultFile `json:"files,omitempty"`
+	Artifacts *Manifest           `json:"artifacts,omitempty"` // 执行前后工作目录的文件变更
 }
 
 type SubmitResultFile struct {
 	Path string `json:"path,omitempty"`
 	Desc string `json:"desc,omitempty"`
+
+	// 以下字段由 submit_result 工具根据工作目录快照填写
+	Change ChangeType `json:"change,omitempty"`
+	Size   int64      `json:"size,omitempty"`
+	SHA256 string     `json:"sha256,omitempty"`
+	MIME   string     `json:"mime,omitempty"`
 }
 
 func (s *SubmitResult) String() string {
@@ -42,6 +49,9 @@ func (s *SubmitResult) String() string {
 	for _, f := range s.Files {
 		res += fmt.Sprintf("\n- 描述：%s, 路径：%s", f.Desc, f.Path)
 	}
+	if changes := s.Artifacts.String(); changes != "" {
+		res += "\n" + changes
+	}
 	return res
 }
 
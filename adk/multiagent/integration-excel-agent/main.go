@@ -18,144 +18,139 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"github.com/joho/godotenv"
-	"likeeino/adk/common/prints"
+	"likeeino/adk/common/store"
 	"likeeino/adk/common/trace"
+	"likeeino/adk/multiagent/integration-excel-agent/agents"
 	"likeeino/adk/multiagent/integration-excel-agent/agents/executor"
 	"likeeino/adk/multiagent/integration-excel-agent/agents/planner"
 	"likeeino/adk/multiagent/integration-excel-agent/agents/replanner"
 	"likeeino/adk/multiagent/integration-excel-agent/agents/report"
-	"likeeino/adk/multiagent/integration-excel-agent/generic"
-	"likeeino/adk/multiagent/integration-excel-agent/params"
-	"likeeino/adk/multiagent/integration-excel-agent/utils"
+	"likeeino/adk/multiagent/integration-excel-agent/sandbox"
+	"likeeino/adk/multiagent/integration-excel-agent/service"
 	"log"
 	"os"
 	"path/filepath"
-	"time"
 
 	"github.com/cloudwego/eino/adk"
 	"github.com/cloudwego/eino/adk/prebuilt/planexecute"
-	"github.com/cloudwego/eino/schema"
-	"github.com/google/uuid"
+	"github.com/cloudwego/hertz/pkg/app/server"
 )
 
+// 默认启动 HTTP 服务: 上传文件、提交问题、查看计划进度并下载结果. 指定 -query 时只执行一次,
+// 输入文件来自 EXCEL_AGENT_INPUT_DIR, 例如:
+//
+//	go run . -query "请帮我将 questions.csv 表格中的第一列提取到一个新的 csv 中"
+//	go run . -query "读取 模拟出题.csv 中的表格内容，规范格式将题目、答案、解析、选项放在同一行，简答题只把答案写入解析即可"
 func main() {
-	// Set your own query here. e.g.
-	query := schema.UserMessage("统计附件文件中推荐的小说名称及推荐次数，并将结果写到文件中。凡是带有《》内容都是小说名称，形成表格，表头为小说名称和推荐次数，同名小说只列一行，推荐次数相加")
-	// query := schema.UserMessage("Count the recommended novel names and recommended times in the attachment file, and write the results into the file. The content with "" is the name of the novel, forming a table. The header is the name of the novel and the number of recommendations. The novels with the same name are listed in one row, and the number of recommendations is added")
-
-	// query := schema.UserMessage("读取 模拟出题.csv 中的表格内容，规范格式将题目、答案、解析、选项放在同一行，简答题只把答案写入解析即可")
-	// query := schema.UserMessage("Read the table content in the 模拟出题.csv, put the question, answer, resolution and options in the same line in a standardized format, and simply write the answer into the resolution")
-
-	//query := schema.UserMessage("请帮我将 questions.csv 表格中的第一列提取到一个新的 csv 中")
-	// query := schema.UserMessage("Please help me extract the first column in question.csv table into a new csv")
+	query := flag.String("query", "", "run the query once on the files of EXCEL_AGENT_INPUT_DIR instead of serving")
+	flag.Parse()
 
 	ctx := context.Background()
 	//链路
-	traceCloseFn, startSpanFn := trace.AppendCozeLoopCallbackIfConfigured(ctx)
+	traceCloseFn, _ := trace.AppendCozeLoopCallbackIfConfigured(ctx)
 	defer traceCloseFn(ctx)
 	//创建agent
 	agent, err := newExcelAgent(ctx)
 	if err != nil {
 		log.Fatal(err)
 	}
-
-	// uuid as task id
-	uuid := uuid.New().String()
-	fmt.Println("uuid:-----------", uuid)
-	//创建runner
-	runner := adk.NewRunner(ctx, adk.RunnerConfig{
-		Agent:           agent,
-		EnableStreaming: false, //todo 流式目前测试还有问题,待处理
-	})
 	//获取当前路径
 	wd, err := os.Getwd()
 	if err != nil {
 		log.Fatal(err)
 	}
-	//找到输入信息路径
-	var inputFileDir, workdir string
-	if env := os.Getenv("EXCEL_AGENT_INPUT_DIR"); env != "" {
-		inputFileDir = env
-	} else {
-		inputFileDir = filepath.Join(wd, "adk/multiagent/integration-excel-agent/playground/input")
-	}
-	//找到输出信息路径
+	//任务的工作目录都在 baseDir 下, 以任务 id 命名
+	baseDir := filepath.Join(wd, "adk/multiagent/integration-excel-agent/playground")
 	if env := os.Getenv("EXCEL_AGENT_WORK_DIR"); env != "" {
-		workdir = filepath.Join(env, uuid)
-	} else {
-		workdir = filepath.Join(wd, "adk/multiagent/integration-excel-agent/playground", uuid)
+		baseDir = env
 	}
-
-	if err = os.Mkdir(workdir, 0755); err != nil {
+	//任务和计划进度保存在检查点 store 中, CHECKPOINT_STORE=file|sqlite|redis 时进程重启后可以继续执行.
+	//任务记录和索引不应过期, 不使用 CHECKPOINT_TTL
+	storeCfg, err := store.ConfigFromEnv()
+	if err != nil {
 		log.Fatal(err)
 	}
-
-	if err = os.CopyFS(workdir, os.DirFS(inputFileDir)); err != nil {
+	storeCfg.TTL, storeCfg.GCInterval = 0, 0
+	taskStore, err := store.NewFromEnvConfig(storeCfg)
+	if err != nil {
 		log.Fatal(err)
 	}
+	defer taskStore.Close()
 
-	previews, err := generic.PreviewPath(workdir)
+	m, err := service.New(ctx, &service.Config{Agent: agent, Store: taskStore, BaseDir: baseDir})
 	if err != nil {
 		log.Fatal(err)
 	}
-	//将输入信息、所在路径等信息添加到上下文中,方面后续的使用
-	ctx = params.InitContextParams(ctx)
-	params.AppendContextParams(ctx, map[string]interface{}{
-		params.FilePathSessionKey:            inputFileDir,                 //输入信息路径
-		params.WorkDirSessionKey:             workdir,                      //工作空间
-		params.UserAllPreviewFilesSessionKey: utils.ToJSONString(previews), //文件转成对象-》转json
-		params.TaskIDKey:                     uuid,
-	})
-
-	ctx, endSpanFn := startSpanFn(ctx, "plan-execute-replan", query)
-	//执行-----agent---- >
-	iter := runner.Run(ctx, []*schema.Message{query})
 
-	var (
-		lastMessage       adk.Message
-		lastMessageStream *schema.StreamReader[adk.Message]
-	)
+	if *query != "" {
+		runOnce(ctx, m, wd, *query)
+		return
+	}
 
-	for {
-		event, ok := iter.Next()
-		if !ok {
-			break
-		}
-		if event.Output != nil && event.Output.MessageOutput != nil {
-			if lastMessageStream != nil {
-				lastMessageStream.Close()
-			}
-			if event.Output.MessageOutput.IsStreaming {
-				cpStream := event.Output.MessageOutput.MessageStream.Copy(2)
-				event.Output.MessageOutput.MessageStream = cpStream[0]
-				lastMessage = nil
-				lastMessageStream = cpStream[1]
-			} else {
-				lastMessage = event.Output.MessageOutput.Message
-				lastMessageStream = nil
-			}
+	if os.Getenv("EXCEL_AGENT_AUTO_RESUME") != "false" {
+		for _, id := range m.ResumeInterrupted(ctx) {
+			log.Printf("resumed interrupted task %s\n", id)
 		}
-		prints.Event(event)
 	}
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+	h := service.NewServer(m, server.WithHostPorts(":"+port))
+	log.Printf("excel agent on http://localhost:%s/\n", port)
+	h.Spin()
+}
 
-	if lastMessage != nil {
-		endSpanFn(ctx, lastMessage)
-	} else if lastMessageStream != nil {
-		msg, _ := schema.ConcatMessageStream(lastMessageStream)
-		endSpanFn(ctx, msg)
-	} else {
-		endSpanFn(ctx, "finished without output message")
+// runOnce 复制输入文件到新任务的工作目录, 执行并打印过程直到结束
+func runOnce(ctx context.Context, m *service.Manager, wd, query string) {
+	//找到输入信息路径
+	inputFileDir := filepath.Join(wd, "adk/multiagent/integration-excel-agent/playground/input")
+	if env := os.Getenv("EXCEL_AGENT_INPUT_DIR"); env != "" {
+		inputFileDir = env
+	}
+	t, err := m.Create(ctx, query, func(workdir string) error {
+		return os.CopyFS(workdir, os.DirFS(inputFileDir))
+	})
+	if err != nil {
+		log.Fatal(err)
 	}
+	fmt.Println("task id:", t.ID, "work dir:", t.WorkDir)
 
-	time.Sleep(time.Second * 30)
+	_, events, stop, err := m.Subscribe(t.ID, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer stop()
+	for e := range events {
+		switch e.Type {
+		case service.EventMessage:
+			fmt.Printf("[%s] %s\n", e.Agent, e.Content)
+		case service.EventPlan:
+			for _, p := range e.Plan {
+				fmt.Println(p.PlanString(p.TaskID))
+			}
+		}
+	}
+	if t, _ = m.Get(t.ID); t.Status != service.TaskCompleted {
+		log.Fatalf("task %s %s: %s", t.ID, t.Status, t.Error)
+	}
 }
 
 // 创建代理
 func newExcelAgent(ctx context.Context) (adk.Agent, error) {
-	//本地文件操作接口实现(官方还给出docker等环境的操作实现)
-	operator := &LocalOperator{}
+	//受限的文件操作接口实现: 文件访问限制在工作目录内, 命令在无网络、有资源限制的子进程中执行
+	//(LocalOperator 不做任何限制, 官方还给出docker等环境的操作实现)
+	conf := &sandbox.Config{
+		AllowNetwork: os.Getenv("EXCEL_AGENT_SANDBOX_NETWORK") == "true",
+	}
+	//指定的 python 不在系统目录下时(如 venv), 命令需要能读取它所在的环境
+	if py := os.Getenv("EXCEL_AGENT_PYTHON_EXECUTABLE_PATH"); filepath.IsAbs(py) {
+		conf.ReadPaths = append(conf.ReadPaths, filepath.Dir(filepath.Dir(py)))
+	}
+	operator := sandbox.NewOperator(conf)
 
 	//规划代理--
 	p, err := planner.NewPlanner(ctx, operator)
@@ -175,7 +170,7 @@ func newExcelAgent(ctx context.Context) (adk.Agent, error) {
 		return nil, err
 	}
 	//创建规划执行代理,分别为规划、执行、重规划
-	planExecuteAgent, err := planexecute.New(ctx, &planexecute.Config{
+	pe, err := planexecute.New(ctx, &planexecute.Config{
 		Planner:       p,
 		Executor:      e,
 		Replanner:     rp,
@@ -184,6 +179,8 @@ func newExcelAgent(ctx context.Context) (adk.Agent, error) {
 	if err != nil {
 		return nil, err
 	}
+	//任务恢复时计划已全部完成则跳过, 直接生成报告
+	planExecuteAgent := agents.NewResumablePlanExecute(pe)
 
 	reportAgent, err := report.NewReportAgent(ctx, operator)
 	if err != nil {
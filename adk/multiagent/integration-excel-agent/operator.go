@@ -19,6 +19,7 @@ package main
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"likeeino/adk/multiagent/integration-excel-agent/params"
 	"os"
@@ -30,6 +31,7 @@ import (
 )
 
 // LocalOperator 工具的文件操作,包括读、写、判断文件是否存在等
+// 直接在宿主机上执行, 不限制路径和资源, 受限的实现见 sandbox.Operator
 type LocalOperator struct{}
 
 func (l *LocalOperator) ReadFile(ctx context.Context, path string) (string, error) {
@@ -45,10 +47,23 @@ func (l *LocalOperator) WriteFile(ctx context.Context, path string, content stri
 }
 
 func (l *LocalOperator) IsDirectory(ctx context.Context, path string) (bool, error) {
-	return true, nil
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return info.IsDir(), nil
 }
 
 func (l *LocalOperator) Exists(ctx context.Context, path string) (bool, error) {
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
 	return true, nil
 }
 
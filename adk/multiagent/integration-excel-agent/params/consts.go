@@ -21,4 +21,6 @@ const (
 	UserAllPreviewFilesSessionKey = "user_all_preview_files_session_key"
 	WorkDirSessionKey             = "work_dir_session_key"
 	TaskIDKey                     = "task_id"
+	ResumeStateKey                = "resume_state_key"  // *generic.RunState, 存在时跳过规划, 从中断处继续执行
+	PlanProgressKey               = "plan_progress_key" // generic.ProgressFunc, 接收每次规划后的进度
 )
@@ -0,0 +1,52 @@
+//go:build linux
+
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sandbox
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// isolate 让子进程成为独立进程组的组长, 超时后连同其子进程一起杀掉.
+// 不允许网络时放进新的网络命名空间(只有一个未启用的 lo); 非 root 用户需要先创建
+// 用户命名空间, 并把当前 uid/gid 映射为自身.
+func isolate(cmd *exec.Cmd, allowNetwork bool) error {
+	attr := &syscall.SysProcAttr{Setpgid: true}
+	if !allowNetwork {
+		attr.Cloneflags = syscall.CLONE_NEWNET
+		if uid := os.Geteuid(); uid != 0 {
+			gid := os.Getegid()
+			attr.Cloneflags |= syscall.CLONE_NEWUSER
+			attr.UidMappings = []syscall.SysProcIDMap{{ContainerID: uid, HostID: uid, Size: 1}}
+			attr.GidMappings = []syscall.SysProcIDMap{{ContainerID: gid, HostID: gid, Size: 1}}
+		}
+	}
+	cmd.SysProcAttr = attr
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	return nil
+}
+
+func killGroup(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
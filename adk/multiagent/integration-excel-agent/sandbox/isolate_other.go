@@ -0,0 +1,39 @@
+//go:build !linux
+
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sandbox
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// isolate 网络隔离依赖 Linux 命名空间, 其他平台只能在显式允许网络时运行命令
+func isolate(cmd *exec.Cmd, allowNetwork bool) error {
+	if !allowNetwork {
+		return errors.New("network isolation is only supported on linux, set AllowNetwork to run commands anyway")
+	}
+	return nil
+}
+
+func killGroup(cmd *exec.Cmd) {}
+
+// start 文件访问限制依赖 Linux 的 Landlock, 其他平台的命令不受限制
+func start(cmd *exec.Cmd, wd string, readPaths []string) error {
+	return cmd.Start()
+}
@@ -0,0 +1,293 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package sandbox 提供受限的 commandline.Operator 实现:
+// 文件读写被限制在任务工作目录(params.WorkDirSessionKey)内, 命令在带有
+// CPU/内存/超时限制、无网络、环境变量白名单和输出上限的子进程中执行,
+// 子进程只能写工作目录, 只能读工作目录和系统目录(Linux 上通过 Landlock).
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino-ext/components/tool/commandline"
+
+	"likeeino/adk/multiagent/integration-excel-agent/params"
+)
+
+var (
+	// ErrOutsideWorkDir 路径解析后不在工作目录内(包括经由符号链接逃逸)
+	ErrOutsideWorkDir = errors.New("path is outside of the work dir")
+	// ErrNoWorkDir 上下文中没有工作目录
+	ErrNoWorkDir = errors.New("work dir not found")
+)
+
+// Config 命令执行的资源限制, 零值字段使用 DefaultConfig 中的值
+type Config struct {
+	// Timeout 单条命令的墙钟时间上限, 超时后整个进程组被杀掉
+	Timeout time.Duration
+	// MaxCPUTime 子进程的 CPU 时间上限(ulimit -t)
+	MaxCPUTime time.Duration
+	// MaxMemory 子进程的虚拟内存上限, 单位字节(ulimit -v)
+	MaxMemory int64
+	// MaxOutput stdout 和 stderr 各自保留的最大字节数, 超出部分被丢弃
+	MaxOutput int
+	// Env 允许传入子进程的环境变量名, HOME 和 TMPDIR 固定指向工作目录
+	Env []string
+	// AllowNetwork 为 true 时子进程共享宿主机网络, 默认在独立的网络命名空间中运行
+	AllowNetwork bool
+	// ReadPaths 除工作目录和 systemReadPaths 外子进程可以读取和执行的路径, 如不在系统目录下的 python 环境.
+	// Env 中 PATH、PYENV_ROOT、VIRTUAL_ENV、PYTHONPATH 列出的路径自动加入
+	ReadPaths []string
+}
+
+// systemReadPaths 命令运行所需的系统目录, /etc 下只开放动态链接、时区、证书和域名解析的配置
+var systemReadPaths = []string{
+	"/bin", "/sbin", "/usr", "/lib", "/lib32", "/lib64", "/libx32", "/opt", "/proc",
+	"/etc/ld.so.cache", "/etc/ld.so.conf", "/etc/ld.so.conf.d", "/etc/alternatives", "/etc/localtime",
+	"/etc/ssl", "/etc/ca-certificates", "/etc/pki", "/etc/resolv.conf", "/etc/hosts", "/etc/nsswitch.conf",
+	"/etc/fonts", "/etc/matplotlibrc",
+}
+
+// DefaultConfig 默认限制, pandas/openpyxl 处理常见表格足够
+func DefaultConfig() *Config {
+	return &Config{
+		Timeout:    2 * time.Minute,
+		MaxCPUTime: time.Minute,
+		MaxMemory:  4 << 30,
+		MaxOutput:  64 << 10,
+		Env: []string{
+			"PATH", "LANG", "LC_ALL", "LC_CTYPE", "TZ",
+			"PYENV_ROOT", "PYENV_VERSION", "VIRTUAL_ENV", "PYTHONPATH",
+		},
+	}
+}
+
+// Operator 受限的 commandline.Operator
+type Operator struct {
+	conf *Config
+}
+
+var _ commandline.Operator = (*Operator)(nil)
+
+// NewOperator 创建受限的 Operator, conf 为 nil 时使用 DefaultConfig
+func NewOperator(conf *Config) *Operator {
+	def := DefaultConfig()
+	if conf == nil {
+		return &Operator{conf: def}
+	}
+	c := *conf
+	if c.Timeout <= 0 {
+		c.Timeout = def.Timeout
+	}
+	if c.MaxCPUTime <= 0 {
+		c.MaxCPUTime = def.MaxCPUTime
+	}
+	if c.MaxMemory <= 0 {
+		c.MaxMemory = def.MaxMemory
+	}
+	if c.MaxOutput <= 0 {
+		c.MaxOutput = def.MaxOutput
+	}
+	if c.Env == nil {
+		c.Env = def.Env
+	}
+	return &Operator{conf: &c}
+}
+
+// ReadFile 与 LocalOperator 一致, 读取失败时把错误信息作为内容返回给模型
+func (o *Operator) ReadFile(ctx context.Context, path string) (string, error) {
+	p, err := o.resolve(ctx, path)
+	if err != nil {
+		return err.Error(), nil
+	}
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return err.Error(), nil
+	}
+	return string(b), nil
+}
+
+func (o *Operator) WriteFile(ctx context.Context, path string, content string) error {
+	p, err := o.resolve(ctx, path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, []byte(content), 0666)
+}
+
+func (o *Operator) IsDirectory(ctx context.Context, path string) (bool, error) {
+	p, err := o.resolve(ctx, path)
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(p)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return info.IsDir(), nil
+}
+
+func (o *Operator) Exists(ctx context.Context, path string) (bool, error) {
+	p, err := o.resolve(ctx, path)
+	if err != nil {
+		return false, err
+	}
+	if _, err = os.Stat(p); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// RunCommand 在工作目录中通过 /bin/sh 执行命令.
+// 单个元素按 shell 脚本执行(bash 工具), 多个元素逐个转义后拼接(python_runner 等).
+// 与 LocalOperator 一致, 执行失败返回以 "internal error" 开头的错误, 工具会把它交给模型.
+func (o *Operator) RunCommand(ctx context.Context, command []string) (*commandline.CommandOutput, error) {
+	wd, err := workDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(command) == 0 {
+		return nil, fmt.Errorf("command cannot be empty")
+	}
+	script := command[0]
+	if len(command) > 1 {
+		quoted := make([]string, len(command))
+		for i, arg := range command {
+			quoted[i] = shellQuote(arg)
+		}
+		script = strings.Join(quoted, " ")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, o.conf.Timeout)
+	defer cancel()
+
+	// 同时设置软硬限制, 命令内无法再调高
+	limits := fmt.Sprintf("ulimit -t %d || exit 126\nulimit -v %d || exit 126\n",
+		int64(math.Ceil(o.conf.MaxCPUTime.Seconds())), o.conf.MaxMemory>>10)
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", limits+script)
+	cmd.Dir = wd
+	cmd.Env = o.environ(wd)
+	// 后台进程持有输出管道时不再等待
+	cmd.WaitDelay = time.Second
+	if err = isolate(cmd, o.conf.AllowNetwork); err != nil {
+		return nil, err
+	}
+
+	stdout := &cappedBuffer{limit: o.conf.MaxOutput}
+	stderr := &cappedBuffer{limit: o.conf.MaxOutput}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err = start(cmd, wd, o.readPaths()); err != nil {
+		return nil, err
+	}
+	err = cmd.Wait()
+	// 命令结束后清理残留的后台进程
+	killGroup(cmd)
+	if err != nil {
+		if ctx.Err() != nil {
+			err = fmt.Errorf("killed after %v: %w", o.conf.Timeout, ctx.Err())
+		}
+		return nil, fmt.Errorf("internal error:\ncommand: %v\n\nerr: %v\n\nexec error: %v", script, err, stderr.String())
+	}
+	return &commandline.CommandOutput{
+		Stdout: stdout.String(),
+		Stderr: stderr.String(),
+	}, nil
+}
+
+func (o *Operator) environ(wd string) []string {
+	env := make([]string, 0, len(o.conf.Env)+2)
+	for _, name := range o.conf.Env {
+		if name == "HOME" || name == "TMPDIR" {
+			continue
+		}
+		if v, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+v)
+		}
+	}
+	return append(env, "HOME="+wd, "TMPDIR="+wd)
+}
+
+func (o *Operator) readPaths() []string {
+	paths := append(append([]string{}, systemReadPaths...), o.conf.ReadPaths...)
+	for _, name := range o.conf.Env {
+		switch name {
+		case "PATH", "PYENV_ROOT", "VIRTUAL_ENV", "PYTHONPATH":
+			for _, p := range filepath.SplitList(os.Getenv(name)) {
+				if filepath.IsAbs(p) {
+					paths = append(paths, p)
+				}
+			}
+		}
+	}
+	return paths
+}
+
+func workDir(ctx context.Context) (string, error) {
+	wd, ok := params.GetTypedContextParams[string](ctx, params.WorkDirSessionKey)
+	if !ok || wd == "" {
+		return "", ErrNoWorkDir
+	}
+	return wd, nil
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// cappedBuffer 只保留前 limit 个字节, 之后的写入被计数后丢弃.
+// Write 总是返回成功, 避免子进程因为管道错误提前退出.
+type cappedBuffer struct {
+	limit   int
+	buf     []byte
+	dropped int64
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if room := b.limit - len(b.buf); room > 0 {
+		if room > len(p) {
+			room = len(p)
+		}
+		b.buf = append(b.buf, p[:room]...)
+		p = p[room:]
+	}
+	b.dropped += int64(len(p))
+	return n, nil
+}
+
+func (b *cappedBuffer) String() string {
+	if b.dropped == 0 {
+		return string(b.buf)
+	}
+	return fmt.Sprintf("%s\n... [output truncated, %d bytes omitted]", b.buf, b.dropped)
+}
@@ -0,0 +1,217 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sandbox
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"likeeino/adk/multiagent/integration-excel-agent/params"
+)
+
+// newWorkDir 返回带工作目录的上下文, 以及工作目录旁边一个不应被访问到的目录
+func newWorkDir(t *testing.T) (ctx context.Context, wd, outside string) {
+	base := t.TempDir()
+	wd = filepath.Join(base, "task")
+	outside = filepath.Join(base, "outside")
+	require.NoError(t, os.Mkdir(wd, 0755))
+	require.NoError(t, os.Mkdir(outside, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(wd, "data.csv"), []byte("a,b\n1,2\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644))
+
+	ctx = params.InitContextParams(context.Background())
+	params.AppendContextParams(ctx, map[string]interface{}{params.WorkDirSessionKey: wd})
+	return ctx, wd, outside
+}
+
+func TestFileAccessConfinedToWorkDir(t *testing.T) {
+	ctx, wd, outside := newWorkDir(t)
+	op := NewOperator(nil)
+
+	content, err := op.ReadFile(ctx, "data.csv")
+	require.NoError(t, err)
+	assert.Equal(t, "a,b\n1,2\n", content)
+	require.NoError(t, op.WriteFile(ctx, filepath.Join(wd, "out.csv"), "x"))
+	require.NoError(t, op.WriteFile(ctx, "sub/../out2.csv", "y"))
+
+	ok, err := op.IsDirectory(ctx, wd)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = op.IsDirectory(ctx, "data.csv")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = op.Exists(ctx, "out2.csv")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = op.Exists(ctx, "missing.csv")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// 指向工作目录内部的符号链接可以使用
+	require.NoError(t, os.Symlink(filepath.Join(wd, "data.csv"), filepath.Join(wd, "alias.csv")))
+	content, err = op.ReadFile(ctx, "alias.csv")
+	require.NoError(t, err)
+	assert.Equal(t, "a,b\n1,2\n", content)
+
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(wd, "link.txt")))
+	require.NoError(t, os.Symlink(outside, filepath.Join(wd, "linkdir")))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "new.txt"), filepath.Join(wd, "dangling.txt")))
+
+	escapes := []string{
+		"../outside/secret.txt",
+		filepath.Join(wd, "..", "outside", "secret.txt"),
+		filepath.Join(outside, "secret.txt"),
+		"/etc/passwd",
+		"link.txt",
+		"linkdir/secret.txt",
+		"linkdir/nested/new.txt",
+		"..",
+	}
+	for _, path := range escapes {
+		content, err = op.ReadFile(ctx, path)
+		require.NoError(t, err)
+		assert.NotEqual(t, "secret", content, path)
+		assert.ErrorIs(t, op.WriteFile(ctx, path, "pwned"), ErrOutsideWorkDir, path)
+		_, err = op.Exists(ctx, path)
+		assert.ErrorIs(t, err, ErrOutsideWorkDir, path)
+		_, err = op.IsDirectory(ctx, path)
+		assert.ErrorIs(t, err, ErrOutsideWorkDir, path)
+	}
+
+	// 悬空链接不会被跟随着在外部创建文件
+	assert.Error(t, op.WriteFile(ctx, "dangling.txt", "pwned"))
+	_, err = os.Stat(filepath.Join(outside, "new.txt"))
+	assert.True(t, os.IsNotExist(err))
+	b, err := os.ReadFile(filepath.Join(outside, "secret.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(b))
+
+	_, err = op.Exists(params.InitContextParams(context.Background()), "data.csv")
+	assert.ErrorIs(t, err, ErrNoWorkDir)
+}
+
+func TestRunCommand(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("command isolation requires linux")
+	}
+	ctx, wd, _ := newWorkDir(t)
+	t.Setenv("EXCEL_AGENT_TEST_SECRET", "s3cret")
+	op := NewOperator(&Config{MaxOutput: 1024})
+
+	out, err := op.RunCommand(ctx, []string{`pwd; echo "home=$HOME secret=$EXCEL_AGENT_TEST_SECRET"; tail -n +3 /proc/net/dev | cut -d: -f1 | tr -d ' '`})
+	require.NoError(t, err)
+	realWD, _ := filepath.EvalSymlinks(wd)
+	assert.Equal(t, realWD+"\nhome="+wd+" secret=\nlo\n", out.Stdout)
+
+	// 多个参数逐个转义, 不会被 shell 再解释
+	out, err = op.RunCommand(ctx, []string{"echo", "$(id)", "a'b"})
+	require.NoError(t, err)
+	assert.Equal(t, "$(id) a'b\n", out.Stdout)
+
+	out, err = op.RunCommand(ctx, []string{"head -c 100000 /dev/zero | tr '\\0' x"})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out.Stdout, strings.Repeat("x", 1024)+"\n... [output truncated, 98976 bytes omitted]"))
+
+	_, err = op.RunCommand(ctx, []string{"exit 3"})
+	require.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "internal error"))
+
+	// 限制同时设置了硬限制, 命令内不能调高
+	_, err = op.RunCommand(ctx, []string{"ulimit -t unlimited"})
+	assert.Error(t, err)
+}
+
+func TestRunCommandConfinedToWorkDir(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("command isolation requires linux")
+	}
+	ctx, wd, outside := newWorkDir(t)
+	op := NewOperator(nil)
+
+	out, err := op.RunCommand(ctx, []string{"cat data.csv > copy.csv && mkdir sub && mv copy.csv sub/ && cat sub/copy.csv"})
+	require.NoError(t, err)
+	assert.Equal(t, "a,b\n1,2\n", out.Stdout)
+
+	// 工作目录以外的读写都被拒绝, 包括经由 .. 和符号链接
+	require.NoError(t, os.Symlink(outside, filepath.Join(wd, "link")))
+	for _, script := range []string{
+		"cat ../outside/secret.txt",
+		"cat link/secret.txt",
+		"cat /etc/passwd",
+		"ls ..",
+		"echo pwned > ../pwned.txt",
+		"echo pwned > link/pwned.txt",
+		"cp data.csv " + outside,
+		"rm ../outside/secret.txt",
+	} {
+		_, err = op.RunCommand(ctx, []string{script})
+		assert.Error(t, err, script)
+	}
+	entries, err := os.ReadDir(outside)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "secret.txt", entries[0].Name())
+	_, err = os.Stat(filepath.Join(filepath.Dir(wd), "pwned.txt"))
+	assert.True(t, os.IsNotExist(err))
+
+	// 只有执行命令的线程受限, 本进程仍能读取工作目录以外的文件
+	b, err := os.ReadFile(filepath.Join(outside, "secret.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(b))
+}
+
+func TestRunCommandLimits(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("command isolation requires linux")
+	}
+	ctx, wd, _ := newWorkDir(t)
+
+	op := NewOperator(&Config{Timeout: 300 * time.Millisecond})
+	start := time.Now()
+	// 后台子进程也随进程组一起被杀掉
+	_, err := op.RunCommand(ctx, []string{"(sleep 30; touch late) & sleep 30"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "killed after 300ms")
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	op = NewOperator(&Config{MaxCPUTime: time.Second, Timeout: 20 * time.Second})
+	start = time.Now()
+	_, err = op.RunCommand(ctx, []string{"while :; do :; done"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "signal")
+	assert.Less(t, time.Since(start), 10*time.Second)
+
+	if _, err = exec.LookPath("python3"); err != nil {
+		t.Skip("python3 not found")
+	}
+	op = NewOperator(&Config{MaxMemory: 128 << 20})
+	_, err = op.RunCommand(ctx, []string{"python3", "-c", "b = bytearray(512 << 20)"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "MemoryError")
+
+	_, err = os.Stat(filepath.Join(wd, "late"))
+	assert.True(t, os.IsNotExist(err))
+}
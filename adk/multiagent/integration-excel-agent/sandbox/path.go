@@ -0,0 +1,76 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// resolve 把 path 转换为工作目录内不含符号链接的绝对路径.
+// 相对路径基于工作目录; 已存在的部分通过 EvalSymlinks 展开, 不存在的部分(待创建的文件)
+// 直接拼接在后面, 最终结果必须仍位于展开后的工作目录内.
+func (o *Operator) resolve(ctx context.Context, path string) (string, error) {
+	wd, err := workDir(ctx)
+	if err != nil {
+		return "", err
+	}
+	if wd, err = filepath.Abs(wd); err != nil {
+		return "", err
+	}
+	root, err := filepath.EvalSymlinks(wd)
+	if err != nil {
+		return "", fmt.Errorf("resolve work dir: %w", err)
+	}
+
+	p := filepath.Clean(path)
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(wd, p)
+	}
+
+	// 找到最长的已存在前缀
+	existing, rest := p, ""
+	for {
+		if _, err = os.Lstat(existing); err == nil {
+			break
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = parent
+	}
+	// 悬空的符号链接在这里报错, 否则写入时会跟随链接在目标位置创建文件
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", path, err)
+	}
+	full := filepath.Join(resolved, rest)
+
+	rel, err := filepath.Rel(root, full)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s: %w", path, ErrOutsideWorkDir)
+	}
+	return full, nil
+}
@@ -0,0 +1,362 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"mime"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/hertz-contrib/sse"
+)
+
+//go:embed web
+var webContent embed.FS
+
+// maxUploadSize 单次提交上传文件的总大小上限
+const maxUploadSize = 100 << 20
+
+// MaxRequestBodySize 服务的请求体上限: 上传文件加上表单的其余部分, Hertz 默认只有 4MB
+const MaxRequestBodySize = maxUploadSize + 1<<20
+
+// NewServer 创建提供 BindRoutes 中接口的 Hertz 服务, 请求体上限为 MaxRequestBodySize
+func NewServer(m *Manager, opts ...config.Option) *server.Hertz {
+	h := server.Default(append([]config.Option{server.WithMaxRequestBodySize(MaxRequestBodySize)}, opts...)...)
+	m.BindRoutes(h.Group("/"))
+	return h
+}
+
+// BindRoutes 提供任务接口和页面:
+//
+//	GET  /                              页面
+//	POST /api/tasks                     multipart 表单: query 和多个 files, 创建任务
+//	GET  /api/tasks                     全部任务
+//	GET  /api/tasks/:id                 任务及其计划
+//	POST /api/tasks/:id/resume          继续执行中断或失败的任务
+//	GET  /api/tasks/:id/events          任务事件的 SSE, 支持 Last-Event-ID 重连
+//	GET  /api/tasks/:id/files           工作目录中的文件
+//	GET  /api/tasks/:id/files/*path     下载文件
+func (m *Manager) BindRoutes(r *route.RouterGroup) {
+	r.POST("/api/tasks", m.handleCreate)
+	r.GET("/api/tasks", m.handleList)
+	r.GET("/api/tasks/:id", m.handleGet)
+	r.POST("/api/tasks/:id/resume", m.handleResume)
+	r.GET("/api/tasks/:id/events", m.handleEvents)
+	r.GET("/api/tasks/:id/files", m.handleFiles)
+	r.GET("/api/tasks/:id/files/*path", m.handleDownload)
+
+	r.GET("/", func(ctx context.Context, c *app.RequestContext) {
+		serveWeb(c, "index.html")
+	})
+}
+
+func serveWeb(c *app.RequestContext, file string) {
+	content, err := webContent.ReadFile("web/" + file)
+	if err != nil {
+		c.String(consts.StatusNotFound, "File not found")
+		return
+	}
+	contentType := mime.TypeByExtension(filepath.Ext(file))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Type", contentType)
+	c.Write(content)
+}
+
+func (m *Manager) handleCreate(ctx context.Context, c *app.RequestContext) {
+	query := strings.TrimSpace(string(c.FormValue("query")))
+	if query == "" {
+		writeError(c, consts.StatusBadRequest, errors.New("missing query"))
+		return
+	}
+	// 不上传文件时也可以用普通表单提交
+	var form *multipart.Form
+	if strings.HasPrefix(string(c.ContentType()), "multipart/") {
+		f, err := c.MultipartForm()
+		if err != nil {
+			writeError(c, consts.StatusBadRequest, errors.New("invalid form: "+err.Error()))
+			return
+		}
+		form = f
+	}
+
+	populate := func(workdir string) error {
+		if form == nil {
+			return nil
+		}
+		var total int64
+		for _, fh := range form.File["files"] {
+			total += fh.Size
+			if total > maxUploadSize {
+				return fmt.Errorf("uploaded files exceed %d bytes", maxUploadSize)
+			}
+			name := filepath.Base(fh.Filename)
+			if name == "." || name == string(filepath.Separator) || strings.HasPrefix(name, ".") {
+				return fmt.Errorf("invalid file name %q", fh.Filename)
+			}
+			src, err := fh.Open()
+			if err != nil {
+				return err
+			}
+			dst, err := os.Create(filepath.Join(workdir, name))
+			if err == nil {
+				_, err = io.Copy(dst, src)
+				if cerr := dst.Close(); err == nil {
+					err = cerr
+				}
+			}
+			src.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	t, err := m.Create(ctx, query, populate)
+	if err != nil {
+		writeError(c, consts.StatusBadRequest, err)
+		return
+	}
+	c.JSON(consts.StatusAccepted, t)
+}
+
+func (m *Manager) handleList(ctx context.Context, c *app.RequestContext) {
+	c.JSON(consts.StatusOK, map[string]interface{}{
+		"tasks": m.List(),
+	})
+}
+
+func (m *Manager) handleGet(ctx context.Context, c *app.RequestContext) {
+	t, ok := m.Get(c.Param("id"))
+	if !ok {
+		writeError(c, consts.StatusNotFound, ErrTaskNotFound)
+		return
+	}
+	c.JSON(consts.StatusOK, t)
+}
+
+func (m *Manager) handleResume(ctx context.Context, c *app.RequestContext) {
+	t, err := m.Resume(ctx, c.Param("id"))
+	switch {
+	case errors.Is(err, ErrTaskNotFound):
+		writeError(c, consts.StatusNotFound, err)
+	case errors.Is(err, ErrTaskRunning), errors.Is(err, ErrTaskFinished):
+		writeError(c, consts.StatusConflict, err)
+	case err != nil:
+		writeError(c, consts.StatusInternalServerError, err)
+	default:
+		c.JSON(consts.StatusAccepted, t)
+	}
+}
+
+func (m *Manager) handleEvents(ctx context.Context, c *app.RequestContext) {
+	after, _ := strconv.ParseInt(string(c.GetHeader("Last-Event-ID")), 10, 64)
+	history, events, stop, err := m.Subscribe(c.Param("id"), after)
+	if err != nil {
+		writeError(c, consts.StatusNotFound, err)
+		return
+	}
+	defer stop()
+
+	s := sse.NewStream(c)
+	if err = relayEvents(ctx, history, events, s.Publish); err != nil {
+		log.Printf("[excel-agent] failed to publish event: %v\n", err)
+	}
+}
+
+// relayEvents 先发送历史事件, 再转发新事件直到任务结束或 ctx 结束, 空闲时发送 ping
+func relayEvents(ctx context.Context, history []Event, events <-chan Event, publish func(*sse.Event) error) error {
+	send := func(e Event) error {
+		data, err := json.Marshal(e)
+		if err != nil {
+			log.Printf("[excel-agent] failed to marshal event: %v\n", err)
+			return nil
+		}
+		return publish(&sse.Event{
+			ID:    strconv.FormatInt(e.Seq, 10),
+			Event: string(e.Type),
+			Data:  data,
+		})
+	}
+	for _, e := range history {
+		if err := send(e); err != nil {
+			return err
+		}
+	}
+
+	ping := time.NewTicker(30 * time.Second)
+	defer ping.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ping.C:
+			if err := publish(&sse.Event{Event: "ping", Data: []byte("{}")}); err != nil {
+				return err
+			}
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := send(e); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// OutputFile 是工作目录中的文件, Path 为相对工作目录的路径
+type OutputFile struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// Files 列出任务工作目录中的文件, 跳过隐藏文件
+func (m *Manager) Files(id string) ([]*OutputFile, error) {
+	t, ok := m.Get(id)
+	if !ok {
+		return nil, ErrTaskNotFound
+	}
+	files := make([]*OutputFile, 0)
+	err := filepath.WalkDir(t.WorkDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != t.WorkDir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(t.WorkDir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, &OutputFile{Path: filepath.ToSlash(rel), Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func (m *Manager) handleFiles(ctx context.Context, c *app.RequestContext) {
+	files, err := m.Files(c.Param("id"))
+	if errors.Is(err, ErrTaskNotFound) {
+		writeError(c, consts.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		writeError(c, consts.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(consts.StatusOK, map[string]interface{}{
+		"files": files,
+	})
+}
+
+func (m *Manager) handleDownload(ctx context.Context, c *app.RequestContext) {
+	t, ok := m.Get(c.Param("id"))
+	if !ok {
+		writeError(c, consts.StatusNotFound, ErrTaskNotFound)
+		return
+	}
+	rel := filepath.FromSlash(strings.TrimPrefix(c.Param("path"), "/"))
+	path := filepath.Join(t.WorkDir, rel)
+	// 只允许下载工作目录中的文件
+	r, err := filepath.Rel(t.WorkDir, path)
+	if err != nil || r == "." || outside(r) {
+		writeError(c, consts.StatusBadRequest, errors.New("invalid path"))
+		return
+	}
+	// 和 Files 一致, 隐藏文件(例如 Excel 的锁文件)不提供下载
+	if hidden(r) {
+		writeError(c, consts.StatusNotFound, errors.New("file not found"))
+		return
+	}
+	// 工作目录中的文件由代理生成, 可能是指向外部的符号链接: 按展开后的路径再检查一次
+	root, err := filepath.EvalSymlinks(t.WorkDir)
+	if err != nil {
+		writeError(c, consts.StatusNotFound, errors.New("file not found"))
+		return
+	}
+	name := filepath.Base(path)
+	path, err = filepath.EvalSymlinks(path)
+	if err != nil {
+		writeError(c, consts.StatusNotFound, errors.New("file not found"))
+		return
+	}
+	if r, err = filepath.Rel(root, path); err != nil || r == "." || outside(r) {
+		writeError(c, consts.StatusBadRequest, errors.New("invalid path"))
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || hidden(r) {
+		writeError(c, consts.StatusNotFound, errors.New("file not found"))
+		return
+	}
+	c.FileAttachment(path, name)
+}
+
+// outside 判断相对路径 rel 是否指向目录之外, 以 ".." 开头的文件名(例如 "..data.csv")仍在目录中
+func outside(rel string) bool {
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// hidden 判断相对路径 rel 中是否有以 "." 开头的部分
+func hidden(rel string) bool {
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		if strings.HasPrefix(part, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+func writeError(c *app.RequestContext, status int, err error) {
+	c.JSON(status, map[string]string{
+		"status": "error",
+		"error":  err.Error(),
+	})
+}
@@ -0,0 +1,575 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package service 把 excel agent 包装成任务服务: 每个任务有独立的工作目录, 计划进度在每次规划或重规划后
+// 保存到 store 中, 进程中断后任务可以从最后完成的步骤继续执行.
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"likeeino/adk/common/store"
+	"likeeino/adk/multiagent/integration-excel-agent/generic"
+	"likeeino/adk/multiagent/integration-excel-agent/params"
+	"likeeino/adk/multiagent/integration-excel-agent/utils"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/schema"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTaskNotFound = errors.New("task not found")
+	ErrTaskRunning  = errors.New("task is running")
+	ErrTaskFinished = errors.New("task already completed")
+)
+
+type TaskStatus string
+
+const (
+	TaskRunning     TaskStatus = "running"
+	TaskCompleted   TaskStatus = "completed"
+	TaskFailed      TaskStatus = "failed"
+	TaskInterrupted TaskStatus = "interrupted" // 进程在执行中退出, 可以继续执行
+)
+
+// Task 是一次提问及其执行情况
+type Task struct {
+	ID        string              `json:"id"`
+	Query     string              `json:"query"`
+	Status    TaskStatus          `json:"status"`
+	WorkDir   string              `json:"work_dir"`
+	Plan      []*generic.FullPlan `json:"plan,omitempty"`
+	Output    string              `json:"output,omitempty"` // 最后一条消息的内容
+	Error     string              `json:"error,omitempty"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+type EventType string
+
+const (
+	EventStatus  EventType = "status"  // 任务开始、完成、失败
+	EventPlan    EventType = "plan"    // 计划步骤的状态变化
+	EventMessage EventType = "message" // 代理输出的消息
+)
+
+// Event 是任务的事件, Seq 在任务内递增, 用于 SSE 断线重连
+type Event struct {
+	Seq     int64               `json:"seq"`
+	Type    EventType           `json:"type"`
+	TaskID  string              `json:"task_id"`
+	Status  TaskStatus          `json:"status,omitempty"`
+	Plan    []*generic.FullPlan `json:"plan,omitempty"`
+	Agent   string              `json:"agent,omitempty"`
+	Role    schema.RoleType     `json:"role,omitempty"`
+	Tool    string              `json:"tool,omitempty"`
+	Content string              `json:"content,omitempty"`
+	Error   string              `json:"error,omitempty"`
+	At      time.Time           `json:"at"`
+}
+
+const (
+	taskIndexKey  = "excel-agent:tasks"
+	taskKeyPrefix = "excel-agent:task:"
+	// maxEvents 每个任务在内存中保留的事件数, 更早的事件不再重放
+	maxEvents = 1000
+)
+
+// record 是保存到 store 中的任务
+type record struct {
+	Task     *Task             `json:"task"`
+	State    *generic.RunState `json:"state,omitempty"`
+	Previews string            `json:"previews,omitempty"`
+}
+
+type Config struct {
+	Agent adk.Agent
+	// Store 保存任务和计划进度, 为空时只保存在内存中
+	Store store.Store
+	// BaseDir 下为每个任务创建以任务 id 命名的工作目录
+	BaseDir string
+}
+
+type Manager struct {
+	runner  *adk.Runner
+	store   store.Store
+	baseDir string
+
+	mu    sync.Mutex
+	tasks map[string]*taskEntry
+}
+
+type taskEntry struct {
+	rec     record
+	running bool
+	seq     int64
+	events  []Event
+	subs    map[chan Event]struct{}
+}
+
+// New 创建任务管理器, 加载 store 中的任务. 上次进程退出时仍在执行的任务标记为 interrupted.
+func New(ctx context.Context, cfg *Config) (*Manager, error) {
+	if cfg.Agent == nil {
+		return nil, errors.New("agent is required")
+	}
+	if cfg.BaseDir == "" {
+		return nil, errors.New("base dir is required")
+	}
+	if err := os.MkdirAll(cfg.BaseDir, 0755); err != nil {
+		return nil, err
+	}
+	m := &Manager{
+		runner: adk.NewRunner(ctx, adk.RunnerConfig{
+			Agent:           cfg.Agent,
+			EnableStreaming: false, //todo 流式目前测试还有问题,待处理
+		}),
+		store:   cfg.Store,
+		baseDir: cfg.BaseDir,
+		tasks:   make(map[string]*taskEntry),
+	}
+	if m.store == nil {
+		return m, nil
+	}
+
+	data, ok, err := m.store.Get(ctx, taskIndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tasks: %w", err)
+	}
+	if !ok {
+		return m, nil
+	}
+	var ids []string
+	if err = json.Unmarshal(data, &ids); err != nil {
+		return nil, fmt.Errorf("invalid task index: %w", err)
+	}
+	for _, id := range ids {
+		data, ok, err := m.store.Get(ctx, taskKeyPrefix+id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load task %s: %w", id, err)
+		}
+		if !ok {
+			continue
+		}
+		e := &taskEntry{subs: make(map[chan Event]struct{})}
+		if err = json.Unmarshal(data, &e.rec); err != nil {
+			return nil, fmt.Errorf("invalid task %s: %w", id, err)
+		}
+		if e.rec.Task.Status == TaskRunning {
+			e.rec.Task.Status = TaskInterrupted
+			setPlanStatus(e.rec.Task.Plan, generic.PlanStatusDoing, generic.PlanStatusTodo)
+			m.save(ctx, e)
+		}
+		m.tasks[id] = e
+	}
+	return m, nil
+}
+
+// Create 创建任务并开始执行, populate 向任务的工作目录写入输入文件
+func (m *Manager) Create(ctx context.Context, query string, populate func(workdir string) error) (*Task, error) {
+	id := uuid.NewString()
+	workdir := filepath.Join(m.baseDir, id)
+	if err := os.Mkdir(workdir, 0755); err != nil {
+		return nil, err
+	}
+	if populate != nil {
+		if err := populate(workdir); err != nil {
+			_ = os.RemoveAll(workdir)
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	e := &taskEntry{
+		rec: record{
+			Task: &Task{
+				ID:        id,
+				Query:     query,
+				Status:    TaskRunning,
+				WorkDir:   workdir,
+				CreatedAt: now,
+				UpdatedAt: now,
+			},
+		},
+		running: true,
+		subs:    make(map[chan Event]struct{}),
+	}
+	m.mu.Lock()
+	m.tasks[id] = e
+	m.saveIndex(ctx)
+	m.save(ctx, e)
+	task := e.snapshot()
+	m.mu.Unlock()
+
+	m.publish(id, Event{Type: EventStatus, Status: TaskRunning})
+	go m.run(id, nil)
+	return task, nil
+}
+
+// Resume 继续执行中断或失败的任务, 有保存的进度时从最后完成的步骤开始, 否则重新规划
+func (m *Manager) Resume(ctx context.Context, id string) (*Task, error) {
+	m.mu.Lock()
+	e, ok := m.tasks[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil, ErrTaskNotFound
+	}
+	switch {
+	case e.running:
+		m.mu.Unlock()
+		return nil, ErrTaskRunning
+	case e.rec.Task.Status == TaskCompleted:
+		m.mu.Unlock()
+		return nil, ErrTaskFinished
+	}
+	e.running = true
+	e.rec.Task.Status = TaskRunning
+	e.rec.Task.Error = ""
+	e.rec.Task.UpdatedAt = time.Now()
+	setPlanStatus(e.rec.Task.Plan, generic.PlanStatusFailed, generic.PlanStatusTodo)
+	m.save(ctx, e)
+	state := e.rec.State
+	task := e.snapshot()
+	m.mu.Unlock()
+
+	m.publish(id, Event{Type: EventStatus, Status: TaskRunning})
+	go m.run(id, state)
+	return task, nil
+}
+
+// ResumeInterrupted 继续执行全部 interrupted 的任务, 返回它们的 id
+func (m *Manager) ResumeInterrupted(ctx context.Context) []string {
+	var ids []string
+	for _, t := range m.List() {
+		if t.Status != TaskInterrupted {
+			continue
+		}
+		if _, err := m.Resume(ctx, t.ID); err != nil {
+			log.Printf("[excel-agent] failed to resume task %s: %v\n", t.ID, err)
+			continue
+		}
+		ids = append(ids, t.ID)
+	}
+	return ids
+}
+
+func (m *Manager) Get(id string) (*Task, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.tasks[id]
+	if !ok {
+		return nil, false
+	}
+	return e.snapshot(), true
+}
+
+// List 按创建时间倒序返回全部任务
+func (m *Manager) List() []*Task {
+	m.mu.Lock()
+	tasks := make([]*Task, 0, len(m.tasks))
+	for _, e := range m.tasks {
+		tasks = append(tasks, e.snapshot())
+	}
+	m.mu.Unlock()
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].CreatedAt.After(tasks[j].CreatedAt)
+	})
+	return tasks
+}
+
+// Subscribe 返回任务中 Seq 大于 after 的历史事件和之后事件的 channel. 任务执行结束时 channel 被关闭,
+// 已结束的任务返回已关闭的 channel. stop 用于提前取消订阅.
+func (m *Manager) Subscribe(id string, after int64) ([]Event, <-chan Event, func(), error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.tasks[id]
+	if !ok {
+		return nil, nil, nil, ErrTaskNotFound
+	}
+	var history []Event
+	for _, ev := range e.events {
+		if ev.Seq > after {
+			history = append(history, ev)
+		}
+	}
+	ch := make(chan Event, 64)
+	if !e.running {
+		close(ch)
+		return history, ch, func() {}, nil
+	}
+	e.subs[ch] = struct{}{}
+
+	var once sync.Once
+	return history, ch, func() {
+		once.Do(func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			if _, ok := e.subs[ch]; ok {
+				delete(e.subs, ch)
+				close(ch)
+			}
+		})
+	}, nil
+}
+
+// Wait 阻塞到任务执行结束, 返回任务的最终状态
+func (m *Manager) Wait(ctx context.Context, id string) (*Task, error) {
+	_, events, stop, err := m.Subscribe(id, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case _, ok := <-events:
+			if !ok {
+				t, _ := m.Get(id)
+				return t, nil
+			}
+		}
+	}
+}
+
+func (m *Manager) run(id string, state *generic.RunState) {
+	var (
+		output string
+		runErr error
+	)
+	defer func() {
+		if e := recover(); e != nil {
+			log.Printf("[excel-agent] task %s panic recover:%+v, stack: %s", id, e, string(debug.Stack()))
+			runErr = utils.NewPanicErr(e, debug.Stack())
+		}
+		m.finish(id, output, runErr)
+	}()
+
+	m.mu.Lock()
+	e := m.tasks[id]
+	task := e.rec.Task
+	previews := e.rec.Previews
+	m.mu.Unlock()
+
+	// 预览大文件较慢, 在任务开始后而不是创建任务的请求中生成, 保存后重新执行时不再生成
+	if previews == "" {
+		files, err := generic.PreviewPath(task.WorkDir)
+		if err != nil {
+			runErr = fmt.Errorf("failed to preview input files: %w", err)
+			return
+		}
+		previews = utils.ToJSONString(files)
+		m.mu.Lock()
+		e.rec.Previews = previews
+		m.save(context.Background(), e)
+		m.mu.Unlock()
+	}
+
+	//将输入信息、所在路径等信息添加到上下文中,方面后续的使用
+	ctx := params.InitContextParams(context.Background())
+	values := map[string]interface{}{
+		params.FilePathSessionKey:            task.WorkDir,
+		params.WorkDirSessionKey:             task.WorkDir,
+		params.UserAllPreviewFilesSessionKey: previews,
+		params.TaskIDKey:                     id,
+		params.PlanProgressKey:               generic.ProgressFunc(m.progressFunc(id)),
+	}
+	if state != nil {
+		values[params.ResumeStateKey] = state
+	}
+	params.AppendContextParams(ctx, values)
+
+	iter := m.runner.Run(ctx, []*schema.Message{schema.UserMessage(task.Query)})
+	for {
+		event, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if event.Err != nil {
+			runErr = event.Err
+			continue
+		}
+		if event.Output == nil || event.Output.MessageOutput == nil {
+			continue
+		}
+		msg, err := event.Output.MessageOutput.GetMessage()
+		if err != nil {
+			runErr = err
+			continue
+		}
+		output = msg.Content
+		m.publish(id, Event{
+			Type:    EventMessage,
+			Agent:   event.AgentName,
+			Role:    msg.Role,
+			Tool:    msg.ToolName,
+			Content: msg.Content,
+		})
+	}
+}
+
+// progressFunc 保存规划后的进度, 计划未完成时剩余计划的第一步即将执行
+func (m *Manager) progressFunc(id string) generic.ProgressFunc {
+	return func(ctx context.Context, state *generic.RunState, plans []*generic.FullPlan) {
+		plans = clonePlans(plans)
+		if !state.Finished {
+			for _, p := range plans {
+				if p.Status == generic.PlanStatusTodo {
+					p.Status = generic.PlanStatusDoing
+					break
+				}
+			}
+		}
+		m.mu.Lock()
+		e, ok := m.tasks[id]
+		if !ok {
+			m.mu.Unlock()
+			return
+		}
+		e.rec.State = state
+		e.rec.Task.Plan = plans
+		e.rec.Task.UpdatedAt = time.Now()
+		m.save(ctx, e)
+		m.mu.Unlock()
+
+		m.publish(id, Event{Type: EventPlan, Plan: plans})
+	}
+}
+
+// finish 记录任务的结果: 成功时未执行的步骤标记为 skipped, 失败时正在执行的步骤标记为 failed
+func (m *Manager) finish(id, output string, err error) {
+	m.mu.Lock()
+	e := m.tasks[id]
+	t := e.rec.Task
+	t.UpdatedAt = time.Now()
+	if err != nil {
+		log.Printf("[excel-agent] task %s failed: %v\n", id, err)
+		t.Status = TaskFailed
+		t.Error = err.Error()
+		setPlanStatus(t.Plan, generic.PlanStatusDoing, generic.PlanStatusFailed)
+	} else {
+		t.Status = TaskCompleted
+		t.Output = output
+		setPlanStatus(t.Plan, generic.PlanStatusDoing, generic.PlanStatusDone)
+		setPlanStatus(t.Plan, generic.PlanStatusTodo, generic.PlanStatusSkipped)
+	}
+	m.save(context.Background(), e)
+	plans := clonePlans(t.Plan)
+	status, errMsg := t.Status, t.Error
+	m.mu.Unlock()
+
+	m.publish(id, Event{Type: EventPlan, Plan: plans})
+	m.publish(id, Event{Type: EventStatus, Status: status, Content: output, Error: errMsg})
+
+	m.mu.Lock()
+	e.running = false
+	for ch := range e.subs {
+		delete(e.subs, ch)
+		close(ch)
+	}
+	m.mu.Unlock()
+}
+
+func (m *Manager) publish(id string, ev Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.tasks[id]
+	if !ok {
+		return
+	}
+	e.seq++
+	ev.Seq = e.seq
+	ev.TaskID = id
+	ev.At = time.Now()
+	e.events = append(e.events, ev)
+	if len(e.events) > maxEvents {
+		e.events = slices.Clone(e.events[len(e.events)-maxEvents:])
+	}
+	for ch := range e.subs {
+		select {
+		case ch <- ev:
+		default:
+			log.Printf("[excel-agent] dropped event %d of task %s for a slow subscriber\n", ev.Seq, id)
+		}
+	}
+}
+
+// save 把任务写入 store, 调用方持有 m.mu
+func (m *Manager) save(ctx context.Context, e *taskEntry) {
+	if m.store == nil {
+		return
+	}
+	data, err := json.Marshal(&e.rec)
+	if err == nil {
+		err = m.store.Set(ctx, taskKeyPrefix+e.rec.Task.ID, data)
+	}
+	if err != nil {
+		log.Printf("[excel-agent] failed to save task %s: %v\n", e.rec.Task.ID, err)
+	}
+}
+
+// saveIndex 把任务 id 列表写入 store, 调用方持有 m.mu
+func (m *Manager) saveIndex(ctx context.Context) {
+	if m.store == nil {
+		return
+	}
+	ids := make([]string, 0, len(m.tasks))
+	for id := range m.tasks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	data, err := json.Marshal(ids)
+	if err == nil {
+		err = m.store.Set(ctx, taskIndexKey, data)
+	}
+	if err != nil {
+		log.Printf("[excel-agent] failed to save task index: %v\n", err)
+	}
+}
+
+// snapshot 返回任务的副本, 调用方持有 m.mu
+func (e *taskEntry) snapshot() *Task {
+	t := *e.rec.Task
+	t.Plan = clonePlans(t.Plan)
+	return &t
+}
+
+func clonePlans(plans []*generic.FullPlan) []*generic.FullPlan {
+	res := make([]*generic.FullPlan, 0, len(plans))
+	for _, p := range plans {
+		cp := *p
+		res = append(res, &cp)
+	}
+	return res
+}
+
+func setPlanStatus(plans []*generic.FullPlan, from, to generic.PlanStatus) {
+	for _, p := range plans {
+		if p.Status == from {
+			p.Status = to
+		}
+	}
+}
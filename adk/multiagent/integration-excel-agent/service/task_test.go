@@ -0,0 +1,277 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/adk/prebuilt/planexecute"
+	"github.com/cloudwego/eino/schema"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/hertz-contrib/sse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"likeeino/adk/common/store"
+	"likeeino/adk/multiagent/integration-excel-agent/generic"
+	"likeeino/adk/multiagent/integration-excel-agent/params"
+)
+
+// stepAgent 模拟规划-执行代理: 每次运行完成一步并报告进度, 剩余的步骤为 steps 减去已完成的步骤.
+// block 不为空时报告进度后阻塞, 模拟进程在执行中退出.
+type stepAgent struct {
+	steps   []string
+	block   chan struct{}
+	resumed chan *generic.RunState
+}
+
+func (a *stepAgent) Name(ctx context.Context) string        { return "step_agent" }
+func (a *stepAgent) Description(ctx context.Context) string { return "step agent" }
+
+func (a *stepAgent) Run(ctx context.Context, input *adk.AgentInput, options ...adk.AgentRunOption) *adk.AsyncIterator[*adk.AgentEvent] {
+	iter, gen := adk.NewAsyncIteratorPair[*adk.AgentEvent]()
+	go func() {
+		defer gen.Close()
+		state := &generic.RunState{UserInput: input.Messages}
+		if s, ok := params.GetTypedContextParams[*generic.RunState](ctx, params.ResumeStateKey); ok {
+			a.resumed <- s
+			state.ExecutedSteps = s.ExecutedSteps
+		}
+		progress := params.MustGetContextParams[generic.ProgressFunc](ctx, params.PlanProgressKey)
+
+		done := len(state.ExecutedSteps)
+		state.ExecutedSteps = append(state.ExecutedSteps, planexecute.ExecutedStep{Step: a.steps[done], Result: "ok"})
+		state.Plan = &generic.Plan{}
+		var plans []*generic.FullPlan
+		for i, s := range a.steps {
+			status := generic.PlanStatusDone
+			if i > done {
+				status = generic.PlanStatusTodo
+				state.Plan.Steps = append(state.Plan.Steps, generic.Step{Index: i + 1, Desc: s})
+			}
+			plans = append(plans, &generic.FullPlan{TaskID: i + 1, Status: status, Desc: s})
+		}
+		state.Finished = len(state.Plan.Steps) == 0
+		progress(ctx, state, plans)
+		gen.Send(adk.EventFromMessage(schema.AssistantMessage("finished "+a.steps[done], nil), nil, schema.Assistant, ""))
+		if a.block != nil {
+			<-a.block
+			gen.Send(&adk.AgentEvent{Err: context.Canceled})
+		}
+	}()
+	return iter
+}
+
+func TestResumeInterruptedTask(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	baseDir := t.TempDir()
+
+	block := make(chan struct{})
+	defer close(block)
+	m1, err := New(ctx, &Config{Agent: &stepAgent{steps: []string{"read", "write"}, block: block}, Store: s, BaseDir: baseDir})
+	require.NoError(t, err)
+	task, err := m1.Create(ctx, "count novels", func(workdir string) error {
+		return os.WriteFile(filepath.Join(workdir, "a.csv"), []byte("name\nx\n"), 0o644)
+	})
+	require.NoError(t, err)
+	_, events, stop, err := m1.Subscribe(task.ID, 0)
+	require.NoError(t, err)
+	defer stop()
+	for e := range events {
+		if e.Type == EventPlan {
+			assert.Equal(t, generic.PlanStatusDoing, e.Plan[1].Status)
+			break
+		}
+	}
+
+	// 新进程加载到仍在执行的任务
+	resumed := make(chan *generic.RunState, 1)
+	m2, err := New(ctx, &Config{Agent: &stepAgent{steps: []string{"read", "write"}, resumed: resumed}, Store: s, BaseDir: baseDir})
+	require.NoError(t, err)
+	got, ok := m2.Get(task.ID)
+	require.True(t, ok)
+	assert.Equal(t, TaskInterrupted, got.Status)
+	assert.Equal(t, generic.PlanStatusTodo, got.Plan[1].Status)
+
+	assert.Equal(t, []string{task.ID}, m2.ResumeInterrupted(ctx))
+	state := <-resumed
+	require.Len(t, state.ExecutedSteps, 1)
+	assert.Equal(t, "read", state.ExecutedSteps[0].Step)
+	assert.Equal(t, []generic.Step{{Index: 2, Desc: "write"}}, state.Plan.Steps)
+
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	got, err = m2.Wait(waitCtx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, TaskCompleted, got.Status)
+	assert.Equal(t, "finished write", got.Output)
+	assert.Equal(t, generic.PlanStatusDone, got.Plan[1].Status)
+
+	_, err = m2.Resume(ctx, task.ID)
+	assert.ErrorIs(t, err, ErrTaskFinished)
+	_, err = m2.Resume(ctx, "missing")
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+}
+
+func TestRoutes(t *testing.T) {
+	ctx := context.Background()
+	m, err := New(ctx, &Config{Agent: &stepAgent{steps: []string{"read"}}, BaseDir: t.TempDir()})
+	require.NoError(t, err)
+	engine := route.NewEngine(config.NewOptions(nil))
+	m.BindRoutes(engine.Group("/excel"))
+
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	require.NoError(t, mw.WriteField("query", "extract the first column"))
+	fw, err := mw.CreateFormFile("files", "questions.csv")
+	require.NoError(t, err)
+	_, err = fw.Write([]byte("type,question\nchoice,1+1\n"))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+	w := ut.PerformRequest(engine, "POST", "/excel/api/tasks", &ut.Body{Body: bytes.NewReader(body.Bytes()), Len: body.Len()},
+		ut.Header{Key: "Content-Type", Value: mw.FormDataContentType()})
+	require.Equal(t, 202, w.Code, w.Body.String())
+	var task Task
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &task))
+
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	_, err = m.Wait(waitCtx, task.ID)
+	require.NoError(t, err)
+
+	w = ut.PerformRequest(engine, "GET", "/excel/api/tasks/"+task.ID, nil)
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"completed"`)
+
+	w = ut.PerformRequest(engine, "GET", "/excel/api/tasks/"+task.ID+"/files", nil)
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), `"path":"questions.csv"`)
+	w = ut.PerformRequest(engine, "GET", "/excel/api/tasks/"+task.ID+"/files/questions.csv", nil)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "type,question\nchoice,1+1\n", w.Body.String())
+	w = ut.PerformRequest(engine, "GET", "/excel/api/tasks/"+task.ID+"/files/..%2F..%2Fsecret", nil)
+	assert.NotEqual(t, 200, w.Code)
+
+	// 指向工作目录以外的符号链接和非普通文件不能下载
+	secret := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(secret, []byte("secret"), 0644))
+	require.NoError(t, os.Symlink(secret, filepath.Join(task.WorkDir, "link.csv")))
+	require.NoError(t, os.Symlink(filepath.Dir(secret), filepath.Join(task.WorkDir, "linkdir")))
+	require.NoError(t, os.Symlink("questions.csv", filepath.Join(task.WorkDir, "inside.csv")))
+	for _, p := range []string{"link.csv", "linkdir/secret", "linkdir"} {
+		w = ut.PerformRequest(engine, "GET", "/excel/api/tasks/"+task.ID+"/files/"+p, nil)
+		assert.NotEqual(t, 200, w.Code, p)
+		assert.NotContains(t, w.Body.String(), "secret\"", p)
+	}
+	w = ut.PerformRequest(engine, "GET", "/excel/api/tasks/"+task.ID+"/files/inside.csv", nil)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "type,question\nchoice,1+1\n", w.Body.String())
+
+	// 和文件列表一致, 隐藏文件不能下载
+	require.NoError(t, os.WriteFile(filepath.Join(task.WorkDir, "..data.csv"), []byte("a"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(task.WorkDir, ".~lock.questions.csv#"), []byte("a"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(task.WorkDir, ".cache"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(task.WorkDir, ".cache", "data.csv"), []byte("a"), 0644))
+	for _, p := range []string{"..data.csv", ".~lock.questions.csv%23", ".cache/data.csv"} {
+		w = ut.PerformRequest(engine, "GET", "/excel/api/tasks/"+task.ID+"/files/"+p, nil)
+		assert.Equal(t, 404, w.Code, p)
+	}
+
+	// 已结束的任务重放 Last-Event-ID 之后的事件
+	history, events, stop, err := m.Subscribe(task.ID, 1)
+	require.NoError(t, err)
+	defer stop()
+	var got []*sse.Event
+	require.NoError(t, relayEvents(ctx, history, events, func(e *sse.Event) error {
+		got = append(got, e)
+		return nil
+	}))
+	require.NotEmpty(t, got)
+	assert.Equal(t, "2", got[0].ID)
+	assert.Equal(t, "status", got[len(got)-1].Event)
+	assert.Contains(t, string(got[len(got)-1].Data), `"status":"completed"`)
+
+	w = ut.PerformRequest(engine, "POST", "/excel/api/tasks/"+task.ID+"/resume", nil)
+	assert.Equal(t, 409, w.Code)
+	w = ut.PerformRequest(engine, "GET", "/excel/api/tasks/missing", nil)
+	assert.Equal(t, 404, w.Code)
+	w = ut.PerformRequest(engine, "GET", "/excel/", nil)
+	assert.Contains(t, w.Body.String(), "Excel Agent")
+}
+
+func TestServerAcceptsLargeUploads(t *testing.T) {
+	ctx := context.Background()
+	m, err := New(ctx, &Config{Agent: &stepAgent{steps: []string{"read"}}, BaseDir: t.TempDir()})
+	require.NoError(t, err)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+	h := NewServer(m, server.WithHostPorts(addr), server.WithExitWaitTime(0))
+	go h.Spin()
+	defer h.Shutdown(ctx)
+
+	// 超过 Hertz 默认的 4MB 请求体上限
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	require.NoError(t, mw.WriteField("query", "sum the amounts"))
+	fw, err := mw.CreateFormFile("files", "large.csv")
+	require.NoError(t, err)
+	_, err = fw.Write(bytes.Repeat([]byte("1,2\n"), 2<<20))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+		}
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+	// 开启 -race 时读取较慢, 只发送一次请求并给足时间
+	client := &http.Client{Timeout: time.Minute}
+	resp, err := client.Post("http://"+addr+"/api/tasks", mw.FormDataContentType(), bytes.NewReader(body.Bytes()))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	require.Equal(t, 202, resp.StatusCode, string(b))
+	var task Task
+	require.NoError(t, json.Unmarshal(b, &task))
+	info, err := os.Stat(filepath.Join(task.WorkDir, "large.csv"))
+	require.NoError(t, err)
+	assert.EqualValues(t, 8<<20, info.Size())
+
+	waitCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	_, err = m.Wait(waitCtx, task.ID)
+	require.NoError(t, err)
+}
@@ -0,0 +1,355 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudwego/eino-ext/components/tool/commandline"
+	"github.com/cloudwego/eino/components/tool"
+	toolutils "github.com/cloudwego/eino/components/tool/utils"
+
+	"likeeino/adk/multiagent/integration-excel-agent/dataframe"
+	"likeeino/adk/multiagent/integration-excel-agent/params"
+	"likeeino/adk/multiagent/integration-excel-agent/utils"
+)
+
+// 表格工具的结果里最多附带的预览行数
+const tablePreviewRows = 20
+
+type tableSource struct {
+	Path  string `json:"path" jsonschema_description:"A .csv or .xlsx file, relative to the working directory or absolute"`
+	Sheet string `json:"sheet,omitempty" jsonschema_description:"Sheet name for xlsx files, defaults to the first sheet"`
+	Range string `json:"range,omitempty" jsonschema_description:"Cell range such as A1:D20, B:D or A3 (from A3 to the end); its first row is the header. Defaults to the whole sheet"`
+}
+
+type tableTarget struct {
+	OutputPath  string `json:"output_path" jsonschema_description:"Where to write the result, .csv or .xlsx, relative to the working directory or absolute"`
+	OutputSheet string `json:"output_sheet,omitempty" jsonschema_description:"Sheet to (re)write when output_path is xlsx, other sheets are kept. Defaults to Sheet1"`
+}
+
+type tableResult struct {
+	Path     string     `json:"path,omitempty"`
+	Sheet    string     `json:"sheet,omitempty"`
+	RowCount int        `json:"row_count"`
+	Columns  []string   `json:"columns"`
+	Preview  [][]string `json:"preview"`
+}
+
+type readSheetInput struct {
+	tableSource
+	Limit int `json:"limit,omitempty" jsonschema_description:"Maximum rows to return, defaults to 50"`
+}
+
+type filterRowsInput struct {
+	tableSource
+	Conditions []dataframe.Condition `json:"conditions" jsonschema_description:"Row conditions"`
+	Match      string                `json:"match,omitempty" jsonschema_description:"all (default) keeps rows matching every condition, any keeps rows matching at least one"`
+	tableTarget
+}
+
+type groupAggregateInput struct {
+	tableSource
+	GroupBy      []string                `json:"group_by" jsonschema_description:"Columns to group by, empty aggregates the whole table"`
+	Aggregations []dataframe.Aggregation `json:"aggregations" jsonschema_description:"Aggregated columns"`
+	SortBy       string                  `json:"sort_by,omitempty" jsonschema_description:"Optional result column to sort by"`
+	Descending   bool                    `json:"descending,omitempty" jsonschema_description:"Sort descending"`
+	tableTarget
+}
+
+type pivotInput struct {
+	tableSource
+	Index   string `json:"index" jsonschema_description:"Column whose values become the rows"`
+	Columns string `json:"columns" jsonschema_description:"Column whose values become the columns"`
+	Values  string `json:"values,omitempty" jsonschema_description:"Column to aggregate, may be omitted for count"`
+	Func    string `json:"func,omitempty" jsonschema_description:"One of sum (default), count, count_distinct, avg, min, max, first, last, concat"`
+	tableTarget
+}
+
+type joinInput struct {
+	Left    tableSource `json:"left" jsonschema_description:"Left table"`
+	Right   tableSource `json:"right" jsonschema_description:"Right table"`
+	On      []string    `json:"on,omitempty" jsonschema_description:"Key columns with the same name in both tables"`
+	LeftOn  []string    `json:"left_on,omitempty" jsonschema_description:"Key columns of the left table when names differ"`
+	RightOn []string    `json:"right_on,omitempty" jsonschema_description:"Key columns of the right table when names differ"`
+	How     string      `json:"how,omitempty" jsonschema_description:"inner (default), left, right or outer"`
+	tableTarget
+}
+
+type dedupeInput struct {
+	tableSource
+	Columns []string `json:"columns,omitempty" jsonschema_description:"Columns identifying duplicates, defaults to whole rows"`
+	Keep    string   `json:"keep,omitempty" jsonschema_description:"first (default) or last"`
+	tableTarget
+}
+
+type writeSheetInput struct {
+	Columns []string   `json:"columns" jsonschema_description:"Header row"`
+	Rows    [][]string `json:"rows" jsonschema_description:"Data rows, every cell as a string"`
+	tableTarget
+}
+
+type addChartInput struct {
+	Path string `json:"path" jsonschema_description:"The xlsx file holding the data, relative to the working directory or absolute"`
+	dataframe.ChartSpec
+}
+
+// NewDataFrameTools 返回基于 dataframe 包的表格工具, 常见的表格处理不需要再生成 Python 代码.
+// 文件读写都经过 op, 相对路径基于工作目录.
+func NewDataFrameTools(op commandline.Operator) ([]tool.InvokableTool, error) {
+	d := &dataFrameTools{op: op}
+	var (
+		ts  []tool.InvokableTool
+		err error
+	)
+	add := func(t tool.InvokableTool, e error) {
+		if err == nil {
+			err = e
+		}
+		ts = append(ts, t)
+	}
+
+	add(toolutils.InferTool("read_sheet_range",
+		"读取 csv/xlsx 文件中一个工作表或单元格区域, 返回列名、总行数和前若干行. 处理数据前先用它确认列名.",
+		d.readSheet))
+	add(toolutils.InferTool("filter_rows",
+		"按条件过滤 csv/xlsx 表格的行并写入输出文件. 两边都是数字时按数值比较.",
+		d.filterRows))
+	add(toolutils.InferTool("group_aggregate",
+		"按列分组并计算 count/sum/avg/min/max 等聚合, 结果可排序, 写入输出文件.",
+		d.groupAggregate))
+	add(toolutils.InferTool("pivot_table",
+		"生成数据透视表: index 列的值作为行, columns 列的值作为列, 对 values 列聚合, 写入输出文件.",
+		d.pivot))
+	add(toolutils.InferTool("join_tables",
+		"按键列连接两张表(inner/left/right/outer)并写入输出文件.",
+		d.join))
+	add(toolutils.InferTool("deduplicate_rows",
+		"按指定列或整行去重并写入输出文件.",
+		d.dedupe))
+	add(toolutils.InferTool("write_sheet",
+		"把给定的表头和数据行写入 csv 或 xlsx 的工作表, 同名工作表会被覆盖, 其他工作表保留.",
+		d.writeSheet))
+	add(toolutils.InferTool("add_chart",
+		"基于 xlsx 工作表中的数据(第一行为表头)插入柱状图/条形图/折线图/饼图等图表.",
+		d.addChart))
+	if err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
+
+type dataFrameTools struct {
+	op commandline.Operator
+}
+
+// 与其他工具一致, 参数或数据上的错误作为结果返回给模型, 由模型修正后重试
+func (d *dataFrameTools) readSheet(ctx context.Context, in *readSheetInput) (string, error) {
+	t, err := d.load(ctx, &in.tableSource)
+	if err != nil {
+		return err.Error(), nil
+	}
+	limit := in.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	return utils.ToJSONString(&tableResult{
+		Path:     in.Path,
+		Sheet:    in.Sheet,
+		RowCount: len(t.Rows),
+		Columns:  t.Columns,
+		Preview:  t.Head(min(limit, 500)).Rows,
+	}), nil
+}
+
+func (d *dataFrameTools) filterRows(ctx context.Context, in *filterRowsInput) (string, error) {
+	return d.transform(ctx, &in.tableTarget, func() (*dataframe.Table, error) {
+		t, err := d.load(ctx, &in.tableSource)
+		if err != nil {
+			return nil, err
+		}
+		return t.Filter(in.Conditions, in.Match == "any")
+	}), nil
+}
+
+func (d *dataFrameTools) groupAggregate(ctx context.Context, in *groupAggregateInput) (string, error) {
+	return d.transform(ctx, &in.tableTarget, func() (*dataframe.Table, error) {
+		t, err := d.load(ctx, &in.tableSource)
+		if err != nil {
+			return nil, err
+		}
+		if t, err = t.GroupBy(in.GroupBy, in.Aggregations); err != nil {
+			return nil, err
+		}
+		if in.SortBy == "" {
+			return t, nil
+		}
+		return t.Sort(in.SortBy, in.Descending)
+	}), nil
+}
+
+func (d *dataFrameTools) pivot(ctx context.Context, in *pivotInput) (string, error) {
+	return d.transform(ctx, &in.tableTarget, func() (*dataframe.Table, error) {
+		t, err := d.load(ctx, &in.tableSource)
+		if err != nil {
+			return nil, err
+		}
+		return t.Pivot(in.Index, in.Columns, in.Values, in.Func)
+	}), nil
+}
+
+func (d *dataFrameTools) join(ctx context.Context, in *joinInput) (string, error) {
+	return d.transform(ctx, &in.tableTarget, func() (*dataframe.Table, error) {
+		left, err := d.load(ctx, &in.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := d.load(ctx, &in.Right)
+		if err != nil {
+			return nil, err
+		}
+		leftOn, rightOn := in.LeftOn, in.RightOn
+		if len(in.On) > 0 {
+			leftOn, rightOn = in.On, in.On
+		}
+		return dataframe.Join(left, right, leftOn, rightOn, in.How)
+	}), nil
+}
+
+func (d *dataFrameTools) dedupe(ctx context.Context, in *dedupeInput) (string, error) {
+	return d.transform(ctx, &in.tableTarget, func() (*dataframe.Table, error) {
+		t, err := d.load(ctx, &in.tableSource)
+		if err != nil {
+			return nil, err
+		}
+		return t.Dedupe(in.Columns, in.Keep)
+	}), nil
+}
+
+func (d *dataFrameTools) writeSheet(ctx context.Context, in *writeSheetInput) (string, error) {
+	return d.transform(ctx, &in.tableTarget, func() (*dataframe.Table, error) {
+		return dataframe.NewTable(in.Columns, in.Rows), nil
+	}), nil
+}
+
+func (d *dataFrameTools) addChart(ctx context.Context, in *addChartInput) (string, error) {
+	path, err := absPath(ctx, in.Path)
+	if err != nil {
+		return err.Error(), nil
+	}
+	content, err := d.readFile(ctx, path)
+	if err != nil {
+		return err.Error(), nil
+	}
+	data, err := dataframe.AddChart(content, &in.ChartSpec)
+	if err != nil {
+		return err.Error(), nil
+	}
+	if err = d.op.WriteFile(ctx, path, string(data)); err != nil {
+		return err.Error(), nil
+	}
+	return fmt.Sprintf("chart added to %s", path), nil
+}
+
+// transform 执行 fn 并把结果写入输出文件, 返回写入结果的摘要
+func (d *dataFrameTools) transform(ctx context.Context, target *tableTarget, fn func() (*dataframe.Table, error)) string {
+	if target.OutputPath == "" {
+		return "output_path can not be empty"
+	}
+	t, err := fn()
+	if err != nil {
+		return err.Error()
+	}
+	path, err := absPath(ctx, target.OutputPath)
+	if err != nil {
+		return err.Error()
+	}
+	sheet := target.OutputSheet
+	if sheet == "" {
+		sheet = "Sheet1"
+	}
+
+	var existing []byte
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".xlsx" || ext == ".xlsm" {
+		if ok, _ := d.op.Exists(ctx, path); ok {
+			if existing, err = d.readFile(ctx, path); err != nil {
+				return err.Error()
+			}
+		}
+	} else {
+		sheet = ""
+	}
+	data, err := dataframe.Write(path, existing, sheet, t)
+	if err != nil {
+		return err.Error()
+	}
+	if err = d.op.WriteFile(ctx, path, string(data)); err != nil {
+		return err.Error()
+	}
+	return utils.ToJSONString(&tableResult{
+		Path:     path,
+		Sheet:    sheet,
+		RowCount: len(t.Rows),
+		Columns:  t.Columns,
+		Preview:  t.Head(tablePreviewRows).Rows,
+	})
+}
+
+func (d *dataFrameTools) load(ctx context.Context, src *tableSource) (*dataframe.Table, error) {
+	path, err := absPath(ctx, src.Path)
+	if err != nil {
+		return nil, err
+	}
+	content, err := d.readFile(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return dataframe.Read(path, content, src.Sheet, src.Range)
+}
+
+// readFile LocalOperator 读取失败时把错误信息当作内容返回, 所以先确认文件存在
+func (d *dataFrameTools) readFile(ctx context.Context, path string) ([]byte, error) {
+	ok, err := d.op.Exists(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("file not found: %s", path)
+	}
+	content, err := d.op.ReadFile(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(content), nil
+}
+
+func absPath(ctx context.Context, path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("path can not be empty")
+	}
+	if filepath.IsAbs(path) {
+		return path, nil
+	}
+	wd, ok := params.GetTypedContextParams[string](ctx, params.WorkDirSessionKey)
+	if !ok {
+		return "", fmt.Errorf("work dir not found")
+	}
+	return filepath.Join(wd, path), nil
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"likeeino/adk/multiagent/integration-excel-agent/dataframe"
+	"likeeino/adk/multiagent/integration-excel-agent/params"
+	"likeeino/adk/multiagent/integration-excel-agent/sandbox"
+)
+
+func TestDataFrameTools(t *testing.T) {
+	wd := t.TempDir()
+	require.NoError(t, os.CopyFS(wd, os.DirFS("../playground/test_data")))
+	ctx := params.InitContextParams(context.Background())
+	params.AppendContextParams(ctx, map[string]interface{}{params.WorkDirSessionKey: wd})
+
+	ts, err := NewDataFrameTools(sandbox.NewOperator(nil))
+	require.NoError(t, err)
+	byName := make(map[string]tool.InvokableTool)
+	for _, it := range ts {
+		info, err := it.Info(ctx)
+		require.NoError(t, err)
+		byName[info.Name] = it
+	}
+	run := func(name, args string) string {
+		out, err := byName[name].InvokableRun(ctx, args)
+		require.NoError(t, err, name)
+		return out
+	}
+	result := func(out string) *tableResult {
+		r := &tableResult{}
+		require.NoError(t, json.Unmarshal([]byte(out), r), out)
+		return r
+	}
+
+	// 嵌入的结构体字段展开在参数的顶层
+	info, err := byName["filter_rows"].Info(ctx)
+	require.NoError(t, err)
+	js, err := info.ParamsOneOf.ToJSONSchema()
+	require.NoError(t, err)
+	for _, p := range []string{"path", "sheet", "conditions", "output_path"} {
+		_, ok := js.Properties.Get(p)
+		assert.True(t, ok, p)
+	}
+
+	r := result(run("read_sheet_range", `{"path":"questions.csv","limit":2}`))
+	assert.Equal(t, 24, r.RowCount)
+	assert.Len(t, r.Preview, 2)
+
+	assert.Contains(t, run("group_aggregate", `{"path":"questions.csv","group_by":["type"],"aggregations":[],"output_path":"out/x.csv"}`),
+		"no such file or directory")
+
+	require.NoError(t, os.Mkdir(filepath.Join(wd, "out"), 0755))
+	r = result(run("group_aggregate", `{"path":"questions.csv","group_by":["type"],"aggregations":[{"func":"count","as":"n"}],
+		"sort_by":"n","output_path":"out/summary.xlsx","output_sheet":"统计"}`))
+	assert.Equal(t, filepath.Join(wd, "out/summary.xlsx"), r.Path)
+	assert.Equal(t, [][]string{{"short-answer", "4"}, {"multiple-choice", "20"}}, r.Preview)
+
+	r = result(run("filter_rows", `{"path":"questions.csv","conditions":[{"column":"type","op":"eq","value":"short-answer"}],
+		"output_path":"out/summary.xlsx","output_sheet":"简答题"}`))
+	assert.Equal(t, 4, r.RowCount)
+
+	assert.Equal(t, "chart added to "+filepath.Join(wd, "out/summary.xlsx"),
+		run("add_chart", `{"path":"out/summary.xlsx","sheet":"统计","type":"pie","category":"type","values":["n"]}`))
+
+	book, err := os.ReadFile(filepath.Join(wd, "out/summary.xlsx"))
+	require.NoError(t, err)
+	stats, err := dataframe.Read("summary.xlsx", book, "统计", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"type", "n"}, stats.Columns)
+	short, err := dataframe.Read("summary.xlsx", book, "简答题", "")
+	require.NoError(t, err)
+	assert.Len(t, short.Rows, 4)
+
+	r = result(run("write_sheet", `{"columns":["type","label"],"rows":[["short-answer","简答"],["essay","论述"]],"output_path":"labels.csv"}`))
+	assert.Equal(t, 2, r.RowCount)
+	r = result(run("join_tables", `{"left":{"path":"out/summary.xlsx","sheet":"统计"},"right":{"path":"labels.csv"},"on":["type"],"how":"left","output_path":"out/joined.csv"}`))
+	assert.Equal(t, [][]string{{"short-answer", "4", "简答"}, {"multiple-choice", "20", ""}}, r.Preview)
+	r = result(run("pivot_table", `{"path":"questions.csv","index":"type","columns":"type","func":"count","output_path":"out/pivot.csv"}`))
+	assert.Equal(t, []string{"type", "multiple-choice", "short-answer"}, r.Columns)
+	r = result(run("deduplicate_rows", `{"path":"questions.csv","columns":["type"],"output_path":"out/types.csv"}`))
+	assert.Equal(t, 2, r.RowCount)
+
+	// 错误作为结果交给模型, 不会中断 agent
+	assert.Contains(t, run("filter_rows", `{"path":"questions.csv","conditions":[{"column":"score","op":"gt","value":"1"}],"output_path":"x.csv"}`),
+		"column not found")
+	assert.Contains(t, run("read_sheet_range", `{"path":"missing.xlsx"}`), "file not found")
+	assert.Contains(t, run("read_sheet_range", `{"path":"../../etc/passwd.csv"}`), "outside of the work dir")
+	assert.Equal(t, "output_path can not be empty", run("deduplicate_rows", `{"path":"questions.csv"}`))
+}
@@ -93,9 +93,14 @@ func (t *submitResultTool) InvokableRun(ctx context.Context, argumentsInJSON str
 
 	plan, _ := utils.GetSessionValue[*generic.Plan](ctx, planexecute.PlanSessionKey)
 	steps, _ := utils.GetSessionValue[[]planexecute.ExecutedStep](ctx, planexecute.ExecutedStepsSessionKey)
+	manifests, _ := utils.GetSessionValue[[]*generic.Manifest](ctx, generic.StepManifestsSessionKey)
 
 	var fullPlan []*generic.FullPlan
 	for i, step := range steps {
+		var artifacts *generic.Manifest
+		if i < len(manifests) {
+			artifacts = manifests[i]
+		}
 		fullPlan = append(fullPlan, &generic.FullPlan{
 			TaskID: i + 1,
 			Status: generic.PlanStatusDone,
@@ -103,6 +108,7 @@ func (t *submitResultTool) InvokableRun(ctx context.Context, argumentsInJSON str
 			ExecResult: &generic.SubmitResult{
 				IsSuccess: utils.PtrOf(true),
 				Result:    step.Result,
+				Artifacts: artifacts,
 			},
 		})
 	}
@@ -121,7 +127,20 @@ func (t *submitResultTool) InvokableRun(ctx context.Context, argumentsInJSON str
 		return "", fmt.Errorf("work dir not found")
 	}
 
-	_ = t.op.WriteFile(ctx, filepath.Join(wd, "final_report.json"), argumentsInJSON)
+	// 报告中附上整个执行过程相对原始输入的文件变更, 交付文件补充大小、哈希和类型
+	baseline, _ := utils.GetSessionValue[*generic.Snapshot](ctx, generic.BaselineSnapshotSessionKey)
+	// 快照失败时为 nil, 退回到合并各步骤的变更
+	snapshot, _ := generic.TakeSnapshot(wd)
+	args.Artifacts = generic.OverallManifest(baseline, snapshot, manifests)
+	if snapshot != nil {
+		for _, f := range args.Files {
+			if a, ok := snapshot.Artifact(f.Path); ok {
+				f.Change = args.Artifacts.Change(a.Path)
+				f.Size, f.SHA256, f.MIME = a.Size, a.SHA256, a.MIME
+			}
+		}
+	}
+	_ = t.op.WriteFile(ctx, filepath.Join(wd, "final_report.json"), utils.ToJSONString(args))
 	fmt.Println("submitResultTool开始将计划写入md文件---------")
 	_ = generic.Write2PlanMD(ctx, t.op, wd, fullPlan)
 	return utils.ToJSONString(&generic.FullPlan{AgentName: compose.END}), nil
@@ -21,9 +21,10 @@ import (
 	"context"
 	"fmt"
 	"github.com/joho/godotenv"
-	"likeeino/adk/common/model"
 	"likeeino/adk/common/prints"
+	"likeeino/adk/common/store"
 	"likeeino/adk/multiagent/integration-project-manager/agents"
+	"likeeino/pkg/model"
 	"likeeino/pkg/retriever"
 	"log"
 	"os"
@@ -31,13 +32,15 @@ import (
 	"github.com/cloudwego/eino/adk"
 	"github.com/cloudwego/eino/adk/prebuilt/supervisor"
 	"github.com/cloudwego/eino/components/tool"
-	"github.com/cloudwego/eino/compose"
 )
 
 func main() {
 	ctx := context.Background()
 
-	tcm := model.NewChatModel()
+	tcm, err := model.NewChatModel(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
 	// 代理的初始化聊天模型
 	//tcm, err := deepseek.NewChatModel(ctx, &deepseek.ChatModelConfig{
 	//	APIKey: os.Getenv("OPENAI_API_KEY"),
@@ -96,7 +99,7 @@ func main() {
 	runner := adk.NewRunner(ctx, adk.RunnerConfig{
 		Agent:           supervisorAgent,
 		EnableStreaming: true,
-		CheckPointStore: newInMemoryStore(),
+		CheckPointStore: store.NewInMemoryStore(),
 	})
 
 	// Replace it with your own query
@@ -155,26 +158,6 @@ func main() {
 	}
 }
 
-func newInMemoryStore() compose.CheckPointStore {
-	return &inMemoryStore{
-		mem: map[string][]byte{},
-	}
-}
-
-type inMemoryStore struct {
-	mem map[string][]byte
-}
-
-func (i *inMemoryStore) Set(ctx context.Context, key string, value []byte) error {
-	i.mem[key] = value
-	return nil
-}
-
-func (i *inMemoryStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
-	v, ok := i.mem[key]
-	return v, ok, nil
-}
-
 func init() {
 	// 加载 .env 文件
 	if err := godotenv.Load(); err != nil {
@@ -19,7 +19,7 @@ package main
 import (
 	"context"
 	"fmt"
-	"likeeino/adk/common/model"
+	"likeeino/pkg/model"
 	l2 "likeeino/pkg/tool/flow"
 
 	"github.com/cloudwego/eino/adk"
@@ -29,7 +29,10 @@ import (
 )
 
 func buildSearchAgent(ctx context.Context) (adk.Agent, error) {
-	m := model.NewChatModel()
+	m, err := model.NewChatModel(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	type searchReq struct {
 		Query string `json:"query"`
@@ -75,7 +78,10 @@ func buildSearchAgent(ctx context.Context) (adk.Agent, error) {
 }
 
 func buildSubtractAgent(ctx context.Context) (adk.Agent, error) {
-	m := model.NewChatModel()
+	m, err := model.NewChatModel(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	type subtractReq struct {
 		A float64 `json:"a"`
@@ -121,7 +127,10 @@ func buildSubtractAgent(ctx context.Context) (adk.Agent, error) {
 }
 
 func buildMultiplyAgent(ctx context.Context) (adk.Agent, error) {
-	m := model.NewChatModel()
+	m, err := model.NewChatModel(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	type multiplyReq struct {
 		A float64 `json:"a"`
@@ -167,7 +176,10 @@ func buildMultiplyAgent(ctx context.Context) (adk.Agent, error) {
 }
 
 func buildDivideAgent(ctx context.Context) (adk.Agent, error) {
-	m := model.NewChatModel()
+	m, err := model.NewChatModel(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	type divideReq struct {
 		A float64 `json:"a"`
@@ -213,7 +225,10 @@ func buildDivideAgent(ctx context.Context) (adk.Agent, error) {
 }
 
 func buildMathAgent(ctx context.Context) (adk.Agent, error) {
-	m := model.NewChatModel()
+	m, err := model.NewChatModel(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	sa, err := buildSubtractAgent(ctx)
 	if err != nil {
@@ -262,7 +277,10 @@ func buildMathAgent(ctx context.Context) (adk.Agent, error) {
 }
 
 func buildSupervisor(ctx context.Context) (adk.Agent, error) {
-	m := model.NewChatModel()
+	m, err := model.NewChatModel(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	sv, err := adk.NewChatModelAgent(ctx, &adk.ChatModelAgentConfig{
 		Name:        "supervisor",
@@ -19,8 +19,8 @@ package agent
 import (
 	"context"
 	"fmt"
-	"likeeino/adk/common/model"
 	"likeeino/adk/multiagent/plan-execute-replan/tools"
+	"likeeino/pkg/model"
 	"strings"
 
 	"github.com/cloudwego/eino/adk"
@@ -31,8 +31,12 @@ import (
 )
 
 func NewPlanner(ctx context.Context) (adk.Agent, error) {
+	m, err := model.NewChatModel(ctx)
+	if err != nil {
+		return nil, err
+	}
 	return planexecute.NewPlanner(ctx, &planexecute.PlannerConfig{
-		ToolCallingChatModel: model.NewChatModel(),
+		ToolCallingChatModel: m,
 	})
 }
 
@@ -73,9 +77,13 @@ func NewExecutor(ctx context.Context) (adk.Agent, error) {
 	if err != nil {
 		return nil, err
 	}
+	m, err := model.NewChatModel(ctx)
+	if err != nil {
+		return nil, err
+	}
 	// 创建执行器
 	return planexecute.NewExecutor(ctx, &planexecute.ExecutorConfig{
-		Model: model.NewChatModel(),
+		Model: m,
 		ToolsConfig: adk.ToolsConfig{
 			ToolsNodeConfig: compose.ToolsNodeConfig{
 				Tools: travelTools,
@@ -109,7 +117,36 @@ func NewExecutor(ctx context.Context) (adk.Agent, error) {
 }
 
 func NewRePlanAgent(ctx context.Context) (adk.Agent, error) {
+	m, err := model.NewChatModel(ctx)
+	if err != nil {
+		return nil, err
+	}
 	return planexecute.NewReplanner(ctx, &planexecute.ReplannerConfig{
-		ChatModel: model.NewChatModel(),
+		ChatModel: m,
+	})
+}
+
+// NewPlanExecuteAgent 组合规划器、执行器和重规划器,创建完整的 plan-execute-replan agent
+func NewPlanExecuteAgent(ctx context.Context) (adk.Agent, error) {
+	//创建规划器,暂时只需要一个个model chat即可
+	planAgent, err := NewPlanner(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("new planner failed: %w", err)
+	}
+	//创建执行器,需要工具集
+	executeAgent, err := NewExecutor(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("new executor failed: %w", err)
+	}
+	//创建重规划器,暂时只需要一个个model chat即可
+	rePlanAgent, err := NewRePlanAgent(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("new replanner failed: %w", err)
+	}
+	return planexecute.New(ctx, &planexecute.Config{
+		Planner:       planAgent,
+		Executor:      executeAgent,
+		Replanner:     rePlanAgent,
+		MaxIterations: 20,
 	})
 }
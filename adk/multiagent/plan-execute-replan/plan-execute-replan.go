@@ -26,7 +26,6 @@ import (
 	"time"
 
 	"github.com/cloudwego/eino/adk"
-	"github.com/cloudwego/eino/adk/prebuilt/planexecute"
 )
 
 func main() {
@@ -34,28 +33,8 @@ func main() {
 	//配置 trace 和metric,使用coze平台
 	traceCloseFn, startSpanFn := trace.AppendCozeLoopCallbackIfConfigured(ctx)
 	defer traceCloseFn(ctx)
-	//创建规划器,暂时只需要一个个model chat即可
-	planAgent, err := agent.NewPlanner(ctx)
-	if err != nil {
-		log.Fatalf("agent.NewPlanner failed, err: %v", err)
-	}
-	//创建执行器,需要工具集
-	executeAgent, err := agent.NewExecutor(ctx)
-	if err != nil {
-		log.Fatalf("agent.NewExecutor failed, err: %v", err)
-	}
-	//创建重规划器,暂时只需要一个个model chat即可
-	rePlanAgent, err := agent.NewRePlanAgent(ctx)
-	if err != nil {
-		log.Fatalf("agent.NewReplanAgent failed, err: %v", err)
-	}
-	//创建agent
-	entryAgent, err := planexecute.New(ctx, &planexecute.Config{
-		Planner:       planAgent,
-		Executor:      executeAgent,
-		Replanner:     rePlanAgent,
-		MaxIterations: 20,
-	})
+	//创建agent:规划器、执行器和重规划器
+	entryAgent, err := agent.NewPlanExecuteAgent(ctx)
 	if err != nil {
 		log.Fatalf("NewPlanExecuteAgent failed, err: %v", err)
 	}
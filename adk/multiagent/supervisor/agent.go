@@ -19,7 +19,7 @@ package main
 import (
 	"context"
 	"fmt"
-	"likeeino/adk/common/model"
+	"likeeino/pkg/model"
 	tool2 "likeeino/pkg/tool/flow"
 
 	"github.com/cloudwego/eino/adk"
@@ -30,7 +30,10 @@ import (
 
 // 查询智能体
 func buildSearchAgent(ctx context.Context) (adk.Agent, error) {
-	m := model.NewChatModel()
+	m, err := model.NewChatModel(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	type searchReq struct {
 		Query string `json:"query"`
@@ -76,7 +79,10 @@ func buildSearchAgent(ctx context.Context) (adk.Agent, error) {
 
 // 计算结果智能体
 func buildMathAgent(ctx context.Context) (adk.Agent, error) {
-	m := model.NewChatModel()
+	m, err := model.NewChatModel(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	type addReq struct {
 		A float64 `json:"a"`
@@ -161,7 +167,10 @@ func buildMathAgent(ctx context.Context) (adk.Agent, error) {
 }
 
 func buildSupervisor(ctx context.Context) (adk.Agent, error) {
-	m := model.NewChatModel()
+	m, err := model.NewChatModel(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	sv, err := adk.NewChatModelAgent(ctx, &adk.ChatModelAgentConfig{
 		Name:        "supervisor",
@@ -19,7 +19,10 @@ import (
 
 func buildSearchAgent(ctx context.Context) (adk.Agent, error) {
 	//1、创建chat model
-	m := model.NewChatModel()
+	m, err := model.NewChatModel(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	type searchReq struct {
 		Query string `json:"query"`
@@ -65,7 +68,10 @@ func buildSearchAgent(ctx context.Context) (adk.Agent, error) {
 
 // 创建一个agent,他绑定了加乘除三种工具
 func buildMathAgent(ctx context.Context) (adk.Agent, error) {
-	m := model.NewChatModel()
+	m, err := model.NewChatModel(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	type addReq struct {
 		A float64 `json:"a"`
@@ -149,9 +155,12 @@ func buildMathAgent(ctx context.Context) (adk.Agent, error) {
 	})
 }
 
-// 创建一个主agent,用来管理和协调其他两个子agent
-func buildSupervisor(ctx context.Context) (adk.Agent, error) {
-	m := model.NewChatModel()
+// NewSupervisor 创建一个主agent,用来管理和协调其他两个子agent
+func NewSupervisor(ctx context.Context) (adk.Agent, error) {
+	m, err := model.NewChatModel(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	sv, err := adk.NewChatModelAgent(ctx, &adk.ChatModelAgentConfig{
 		Name:        "supervisor",
@@ -193,7 +202,7 @@ func Agent() {
 	traceCloseFn, startSpanFn := trace.AppendCozeLoopCallbackIfConfigured(ctx)
 	defer traceCloseFn(ctx)
 	//构建agent
-	sv, err := buildSupervisor(ctx)
+	sv, err := NewSupervisor(ctx)
 	if err != nil {
 		log.Fatalf("build supervisor failed: %v", err)
 	}
@@ -128,71 +128,221 @@ func Init() error {
 	return err
 }
 
-func RunAgent(ctx context.Context, id string, msg string) (*schema.StreamReader[*schema.Message], error) {
+// RunAgent streams the answer to req and saves the exchange once the stream is over; saved is
+// closed when the exchange is stored or given up. opts are passed to the graph along with the
+// log callback.
+func RunAgent(ctx context.Context, req *ChatRequest, opts ...compose.Option) (sr *schema.StreamReader[*schema.Message], saved <-chan struct{}, err error) {
 	//创建graph的agent
 	runner, err := einoagent.BuildEinoAgent(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build agent graph: %w", err)
+		return nil, nil, fmt.Errorf("failed to build agent graph: %w", err)
 	}
+	id := req.ID
 	//数据缓存,存储每个会话的 多条记录(目前存储在项目data路径下的jsonl文件中)
 	conversation := memory.GetConversation(id, true)
 
+	// move the head to where the answer belongs before reading the history. A run that ends
+	// without storing an answer moves it back, so the conversation is not left on an empty branch.
+	prevHead := conversation.Metadata().Head
+	restoreHead := func() {
+		if err := conversation.Fork(prevHead); err != nil {
+			log.Printf("[Chat] failed to restore head of chat ID %s: %v\n", id, err)
+		}
+	}
+	msg := req.Message
+	regenerated := ""
+	if req.Regenerate {
+		target, err := regenerateTarget(conversation, req.ParentID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := conversation.Fork(target.ParentID); err != nil {
+			return nil, nil, err
+		}
+		msg = target.Message.Content
+		regenerated = target.ID
+	} else if req.ParentID != "" {
+		if err := conversation.Fork(req.ParentID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	//获取一定量的历史对话记录
+	history, err := conversation.GetWindowMessages(ctx)
+	if err != nil {
+		restoreHead()
+		return nil, nil, fmt.Errorf("failed to load history: %w", err)
+	}
+	history = completedMessages(history)
+	if regenerated != "" {
+		// the new answer becomes a sibling of the previous ones
+		if err := conversation.Fork(regenerated); err != nil {
+			restoreHead()
+			return nil, nil, err
+		}
+	}
+
 	userMessage := &einoagent.UserMessage{
-		ID:    id,
-		Query: msg,
-		//获取一定量的历史对话记录
-		History: conversation.GetMessages(),
+		ID:      id,
+		Query:   msg,
+		History: history,
 	}
 	if os.Getenv("APMPLUS_APP_KEY") != "" {
 		// set session info for apmplus callback
 		ctx = apmplus.SetSession(ctx, apmplus.WithSessionID(id), apmplus.WithUserID("eino-assistant-user"))
 	}
-	sr, err := runner.Stream(ctx, userMessage, compose.WithCallbacks(cbHandler))
+	out, err := runner.Stream(ctx, userMessage, append([]compose.Option{compose.WithCallbacks(cbHandler)}, opts...)...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to stream: %w", err)
+		restoreHead()
+		return nil, nil, fmt.Errorf("failed to stream: %w", err)
 	}
 
-	srs := sr.Copy(2)
+	srs := out.Copy(2)
+	done := make(chan struct{})
 
 	go func() {
+		defer close(done)
+		defer srs[1].Close()
+
 		// for save to memory
 		fullMsgs := make([]*schema.Message, 0)
+		var runErr error
+		for {
+			chunk, err := srs[1].Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				runErr = err
+				if ctx.Err() != nil {
+					runErr = context.Cause(ctx)
+				}
+				break
+			}
+			fullMsgs = append(fullMsgs, chunk)
+		}
+
+		if err := saveExchange(ctx, conversation, msg, regenerated == "", fullMsgs, runErr); err != nil {
+			log.Printf("[Chat] %v\n", err)
+			restoreHead()
+		}
+	}()
 
-		defer func() {
-			// close stream if you used it
-			srs[1].Close()
+	return srs[0], done, nil
+}
 
-			// add user input to history
-			conversation.Append(schema.UserMessage(msg))
+// saveExchange appends the user message, unless the answer is regenerated and reuses the stored
+// one, and the answer made of chunks. An answer cut short by runErr is kept with its status.
+func saveExchange(ctx context.Context, conversation *mem.Conversation, query string, withQuery bool, chunks []*schema.Message, runErr error) error {
+	// add user input to history
+	if withQuery {
+		if err := conversation.Append(schema.UserMessage(query)); err != nil {
+			return fmt.Errorf("failed to save user message: %w", err)
+		}
+	}
 
-			fullMsg, err := schema.ConcatMessages(fullMsgs)
-			if err != nil {
-				fmt.Println("error concatenating messages: ", err.Error())
-			}
-			// add agent response to history
-			conversation.Append(fullMsg)
-		}()
+	fullMsg := schema.AssistantMessage("", nil)
+	if len(chunks) > 0 {
+		concatenated, err := schema.ConcatMessages(chunks)
+		if err != nil {
+			return fmt.Errorf("failed to concatenate messages: %w", err)
+		}
+		fullMsg = concatenated
+	}
+	// an answer cut short is kept with its status, and left out of the next prompts
+	if status := runStatusOf(ctx, runErr); status != RunCompleted {
+		if fullMsg.Extra == nil {
+			fullMsg.Extra = map[string]any{}
+		}
+		fullMsg.Extra[ExtraRunStatus] = string(status)
+		fullMsg.Extra[ExtraRunError] = runErr.Error()
+	}
+	// add agent response to history
+	if err := conversation.Append(fullMsg); err != nil {
+		return fmt.Errorf("failed to save agent response: %w", err)
+	}
+	return nil
+}
+
+// completedMessages leaves out the answers of runs that did not complete.
+func completedMessages(msgs []*schema.Message) []*schema.Message {
+	res := make([]*schema.Message, 0, len(msgs))
+	for _, m := range msgs {
+		if _, ok := m.Extra[ExtraRunStatus]; !ok {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+// StartChat runs the agent in the background, recording the answer tokens and the events of
+// its callbacks on a stream registered in runs. The run is detached from ctx so that it
+// outlives the request that started it: a client that drops resumes the stream by the last
+// event id it received. It is bounded by req.Timeout and ends early when cancelled, see
+// HandleCancelRun, or when no client reads it any more.
+func StartChat(ctx context.Context, req *ChatRequest) (*ChatStream, error) {
+	ctx, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+	ctx, cancelTimeout := context.WithTimeoutCause(ctx, runTimeout(req.Timeout), ErrRunTimeout)
+	stream := newChatStream(req.ID)
+	stream.cancel = func(cause error) {
+		cancel(cause)
+		cancelTimeout()
+	}
+	if err := runs.start(stream); err != nil {
+		stream.cancel(nil)
+		return nil, err
+	}
+	sr, saved, err := RunAgent(ctx, req, compose.WithCallbacks(stream.Callbacks()...))
+	if err != nil {
+		stream.cancel(nil)
+		runs.remove(stream)
+		return nil, err
+	}
 
-	outer:
+	go func() {
+		defer sr.Close()
 		for {
-			select {
-			case <-ctx.Done():
-				fmt.Println("context done", ctx.Err())
+			msg, err := sr.Recv()
+			if errors.Is(err, io.EOF) {
+				// the run is over once its answer is stored, so a next one starts from it
+				<-saved
+				stream.finish(RunCompleted, nil)
 				return
-			default:
-				chunk, err := srs[1].Recv()
-				if err != nil {
-					if errors.Is(err, io.EOF) {
-						break outer
-					}
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					err = context.Cause(ctx)
 				}
-
-				fullMsgs = append(fullMsgs, chunk)
+				log.Printf("[Chat] Error receiving message: %v\n", err)
+				<-saved
+				stream.finish(runStatusOf(ctx, err), err)
+				return
+			}
+			if msg.Content != "" {
+				stream.emit(EventToken, &TokenData{Content: msg.Content})
 			}
 		}
 	}()
+	return stream, nil
+}
 
-	return srs[0], nil
+// regenerateTarget resolves the user message to answer again: the given message (an assistant
+// answer stands for the question it replied to) or the last user message of the active branch.
+func regenerateTarget(conversation *mem.Conversation, id string) (mem.MessageNode, error) {
+	if id == "" {
+		id = conversation.LastMessageID(schema.User)
+		if id == "" {
+			return mem.MessageNode{}, fmt.Errorf("nothing to regenerate: %w", mem.ErrMessageNotFound)
+		}
+	}
+	node, ok := conversation.Node(id)
+	if ok && node.Message.Role == schema.Assistant {
+		node, ok = conversation.Node(node.ParentID)
+	}
+	if !ok || node.Message.Role != schema.User {
+		return mem.MessageNode{}, mem.ErrMessageNotFound
+	}
+	return node, nil
 }
 
 type LogCallbackConfig struct {
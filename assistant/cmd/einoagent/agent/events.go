@@ -0,0 +1,291 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	einomodel "github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/retriever"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+	template "github.com/cloudwego/eino/utils/callbacks"
+	"github.com/google/uuid"
+
+	"likeeino/pkg/model"
+)
+
+// Event types of a chat stream. A stream ends with exactly one done or error event.
+const (
+	EventToken      = "token"
+	EventToolCall   = "tool_call"
+	EventToolResult = "tool_result"
+	EventRetrieval  = "retrieval"
+	EventUsage      = "usage"
+	EventError      = "error"
+	EventDone       = "done"
+)
+
+// Event is one event of a chat stream, sent as SSE with id "<stream id>:<seq>" and Data as
+// JSON.
+type Event struct {
+	Seq  int
+	Type string
+	Data any
+}
+
+type TokenData struct {
+	Content string `json:"content"`
+}
+
+type ToolCallData struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+type ToolResultData struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Result string `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type RetrievalData struct {
+	Documents []RetrievedDocument `json:"documents"`
+}
+
+type RetrievedDocument struct {
+	ID       string         `json:"id"`
+	Content  string         `json:"content"`
+	Score    float64        `json:"score"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// UsageData is the token usage of one model call, or of the whole run in DoneData.
+type UsageData struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// ErrorData ends a run that did not complete: Status tells a failure from a cancellation or a
+// timeout.
+type ErrorData struct {
+	Message string    `json:"message"`
+	Status  RunStatus `json:"status"`
+}
+
+type DoneData struct {
+	StreamID       string    `json:"stream_id"`
+	ConversationID string    `json:"conversation_id"`
+	Usage          UsageData `json:"usage"`
+}
+
+// ChatStream records the events of one agent run. The run does not depend on any client:
+// clients read the events from a sequence number on, so a dropped one resumes where it
+// stopped. See runs.go for its lifecycle.
+type ChatStream struct {
+	ID             string
+	ConversationID string
+
+	mu      sync.Mutex
+	events  []*Event
+	closed  bool
+	changed chan struct{}
+	usage   UsageData
+	usageCB model.UsageCallback
+	// pending counts the tool output streams still being read
+	pending sync.WaitGroup
+
+	status    RunStatus
+	errMsg    string
+	startedAt time.Time
+	endedAt   time.Time
+	cancel    context.CancelCauseFunc
+	// readers is the number of clients reading the stream, abandon the timer cancelling the run
+	// once none has been for a while
+	readers int
+	abandon *time.Timer
+}
+
+func newChatStream(conversationID string) *ChatStream {
+	s := &ChatStream{
+		ID:             uuid.New().String(),
+		ConversationID: conversationID,
+		changed:        make(chan struct{}),
+		status:         RunRunning,
+		startedAt:      time.Now(),
+		cancel:         func(error) {},
+	}
+	s.usageCB.Report = s.addUsage
+	return s
+}
+
+// EventID is the SSE id of the event seq.
+func (s *ChatStream) EventID(seq int) string {
+	return s.ID + ":" + strconv.Itoa(seq)
+}
+
+// Since returns the events after seq, whether the stream is over, and a channel closed on the
+// next event.
+func (s *ChatStream) Since(seq int) ([]*Event, bool, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if seq < 0 {
+		seq = 0
+	}
+	var events []*Event
+	if seq < len(s.events) {
+		events = s.events[seq:]
+	}
+	return events, s.closed, s.changed
+}
+
+func (s *ChatStream) emit(typ string, data any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.push(typ, data)
+}
+
+func (s *ChatStream) push(typ string, data any) {
+	if s.closed {
+		return
+	}
+	s.events = append(s.events, &Event{Seq: len(s.events) + 1, Type: typ, Data: data})
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// finish ends the stream with a done event, or an error event when the run did not complete,
+// and schedules its removal.
+func (s *ChatStream) finish(status RunStatus, err error) {
+	s.usageCB.Wait()
+	s.pending.Wait()
+	s.mu.Lock()
+	if status == RunCompleted {
+		s.push(EventDone, &DoneData{StreamID: s.ID, ConversationID: s.ConversationID, Usage: s.usage})
+	} else {
+		s.errMsg = err.Error()
+		s.push(EventError, &ErrorData{Message: s.errMsg, Status: status})
+	}
+	s.closed = true
+	s.status = status
+	s.endedAt = time.Now()
+	if s.abandon != nil {
+		s.abandon.Stop()
+	}
+	s.mu.Unlock()
+	s.cancel(nil)
+	time.AfterFunc(runRetention, func() { runs.remove(s) })
+}
+
+func (s *ChatStream) addUsage(u *einomodel.TokenUsage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.usage.PromptTokens += u.PromptTokens
+	s.usage.CompletionTokens += u.CompletionTokens
+	s.usage.TotalTokens += u.TotalTokens
+	s.push(EventUsage, &UsageData{
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+	})
+}
+
+// Callbacks turns the model, tool and retriever callbacks of a run, including those of the
+// nested ReAct agent, into events.
+func (s *ChatStream) Callbacks() []callbacks.Handler {
+	return []callbacks.Handler{s.usageCB.Handler(), s.eventHandler()}
+}
+
+func (s *ChatStream) eventHandler() callbacks.Handler {
+	return template.NewHandlerHelper().
+		Tool(&template.ToolCallbackHandler{
+			OnStart: func(ctx context.Context, info *callbacks.RunInfo, input *tool.CallbackInput) context.Context {
+				s.emit(EventToolCall, &ToolCallData{
+					ID:        compose.GetToolCallID(ctx),
+					Name:      info.Name,
+					Arguments: input.ArgumentsInJSON,
+				})
+				return ctx
+			},
+			OnEnd: func(ctx context.Context, info *callbacks.RunInfo, output *tool.CallbackOutput) context.Context {
+				s.emit(EventToolResult, &ToolResultData{ID: compose.GetToolCallID(ctx), Name: info.Name, Result: output.Response})
+				return ctx
+			},
+			OnEndWithStreamOutput: func(ctx context.Context, info *callbacks.RunInfo, output *schema.StreamReader[*tool.CallbackOutput]) context.Context {
+				id := compose.GetToolCallID(ctx)
+				s.pending.Add(1)
+				go func() {
+					defer s.pending.Done()
+					defer output.Close()
+					var b strings.Builder
+					for {
+						chunk, err := output.Recv()
+						if err != nil {
+							break
+						}
+						b.WriteString(chunk.Response)
+					}
+					s.emit(EventToolResult, &ToolResultData{ID: id, Name: info.Name, Result: b.String()})
+				}()
+				return ctx
+			},
+			OnError: func(ctx context.Context, info *callbacks.RunInfo, err error) context.Context {
+				s.emit(EventToolResult, &ToolResultData{ID: compose.GetToolCallID(ctx), Name: info.Name, Error: err.Error()})
+				return ctx
+			},
+		}).
+		Retriever(&template.RetrieverCallbackHandler{
+			OnEnd: func(ctx context.Context, info *callbacks.RunInfo, output *retriever.CallbackOutput) context.Context {
+				docs := make([]RetrievedDocument, 0, len(output.Docs))
+				for _, doc := range output.Docs {
+					docs = append(docs, RetrievedDocument{
+						ID:       doc.ID,
+						Content:  doc.Content,
+						Score:    doc.Score(),
+						Metadata: doc.MetaData,
+					})
+				}
+				s.emit(EventRetrieval, &RetrievalData{Documents: docs})
+				return ctx
+			},
+		}).
+		Handler()
+}
+
+// resolveEventID finds the stream of a Last-Event-ID and the sequence number to resume after.
+func resolveEventID(id string) (*ChatStream, int, error) {
+	streamID, seq, ok := strings.Cut(id, ":")
+	n, err := strconv.Atoi(seq)
+	if !ok || err != nil {
+		return nil, 0, fmt.Errorf("invalid event id %q", id)
+	}
+	s := runs.get(streamID)
+	if s == nil {
+		return nil, 0, fmt.Errorf("stream %s not found or expired", streamID)
+	}
+	return s, n, nil
+}
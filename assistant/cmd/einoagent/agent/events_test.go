@@ -0,0 +1,145 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/retriever"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/hertz-contrib/sse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func eventTypes(events []*Event) []string {
+	types := make([]string, len(events))
+	for i, e := range events {
+		types[i] = e.Type
+	}
+	return types
+}
+
+func TestChatStreamCallbacks(t *testing.T) {
+	s := newChatStream("conv")
+	run := func(component components.Component, name string) context.Context {
+		return callbacks.InitCallbacks(context.Background(), &callbacks.RunInfo{Component: component, Name: name}, s.Callbacks()...)
+	}
+
+	callbacks.OnEnd(run(components.ComponentOfRetriever, "Retriever"), &retriever.CallbackOutput{
+		Docs: []*schema.Document{(&schema.Document{ID: "d1", Content: "eino graph"}).WithScore(0.8)},
+	})
+	ctx := run(components.ComponentOfTool, "open_url")
+	callbacks.OnStart(ctx, &tool.CallbackInput{ArgumentsInJSON: `{"url":"x"}`})
+	callbacks.OnEnd(ctx, &tool.CallbackOutput{Response: "opened"})
+	callbacks.OnError(ctx, errors.New("boom"))
+
+	s.emit(EventToken, &TokenData{Content: "hi"})
+
+	// usage of a streamed answer is read off its last chunk
+	sr, sw := schema.Pipe[*model.CallbackOutput](2)
+	_, out := callbacks.OnEndWithStreamOutput(run(components.ComponentOfChatModel, "ChatModel"), sr)
+	out.Close()
+	sw.Send(&model.CallbackOutput{Message: schema.AssistantMessage("hi", nil)}, nil)
+	sw.Send(&model.CallbackOutput{TokenUsage: &model.TokenUsage{PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12}}, nil)
+	sw.Close()
+
+	s.finish(RunCompleted, nil)
+	s.emit(EventToken, &TokenData{Content: "late"})
+
+	events, closed, _ := s.Since(0)
+	assert.True(t, closed)
+	require.Equal(t, []string{EventRetrieval, EventToolCall, EventToolResult, EventToolResult, EventToken, EventUsage, EventDone}, eventTypes(events))
+
+	assert.Equal(t, 0.8, events[0].Data.(*RetrievalData).Documents[0].Score)
+	assert.Equal(t, &ToolCallData{Name: "open_url", Arguments: `{"url":"x"}`}, events[1].Data)
+	assert.Equal(t, "opened", events[2].Data.(*ToolResultData).Result)
+	assert.Equal(t, "boom", events[3].Data.(*ToolResultData).Error)
+	done := events[6].Data.(*DoneData)
+	assert.Equal(t, "conv", done.ConversationID)
+	assert.Equal(t, 12, done.Usage.TotalTokens)
+
+	events, _, _ = s.Since(5)
+	assert.Equal(t, []string{EventUsage, EventDone}, eventTypes(events))
+}
+
+func TestRelayChatStream(t *testing.T) {
+	s := newChatStream("conv")
+	s.emit(EventToken, &TokenData{Content: "Hello"})
+
+	var got []*sse.Event
+	done := make(chan error)
+	go func() {
+		done <- relayChatStream(context.Background(), s, 0, func(e *sse.Event) error {
+			got = append(got, e)
+			return nil
+		})
+	}()
+	// events emitted while relaying are sent too, up to the terminal one
+	s.emit(EventToken, &TokenData{Content: " world"})
+	s.finish(RunFailed, errors.New("model unavailable"))
+	require.NoError(t, <-done)
+
+	require.Len(t, got, 3)
+	assert.Equal(t, s.EventID(2), got[1].ID)
+	assert.Equal(t, EventToken, got[1].Event)
+	assert.JSONEq(t, `{"content":" world"}`, string(got[1].Data))
+	assert.Equal(t, EventError, got[2].Event)
+	assert.JSONEq(t, `{"message":"model unavailable","status":"failed"}`, string(got[2].Data))
+
+	// resuming after the first event replays the rest
+	got = nil
+	require.NoError(t, relayChatStream(context.Background(), s, 1, func(e *sse.Event) error {
+		got = append(got, e)
+		return nil
+	}))
+	assert.Len(t, got, 2)
+}
+
+func TestHandleChatErrors(t *testing.T) {
+	s := newChatStream("conv")
+	require.NoError(t, runs.start(s))
+	t.Cleanup(func() { runs.remove(s) })
+
+	found, seq, err := resolveEventID(s.EventID(4))
+	require.NoError(t, err)
+	assert.Same(t, s, found)
+	assert.Equal(t, 4, seq)
+
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.POST("/agent/api/chat", HandleChat)
+
+	for _, id := range []string{"gone:3", s.ID} {
+		w := ut.PerformRequest(engine, "POST", "/agent/api/chat", nil, ut.Header{Key: "Last-Event-ID", Value: id})
+		assert.Equal(t, 404, w.Code, id)
+	}
+
+	for _, body := range []string{`{"id":"conv"}`, `not json`} {
+		w := ut.PerformRequest(engine, "POST", "/agent/api/chat", &ut.Body{Body: strings.NewReader(body), Len: len(body)})
+		assert.Equal(t, 400, w.Code, body)
+	}
+}
@@ -0,0 +1,273 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// RunStatus is the state of an agent run. An answer that did not complete is saved with its
+// status, see ExtraRunStatus.
+type RunStatus string
+
+const (
+	RunRunning   RunStatus = "running"
+	RunCompleted RunStatus = "completed"
+	RunCancelled RunStatus = "cancelled"
+	RunTimedOut  RunStatus = "timed_out"
+	RunFailed    RunStatus = "failed"
+)
+
+var (
+	ErrRunInProgress = errors.New("a run is already in progress for this conversation")
+	ErrRunCancelled  = errors.New("run cancelled")
+	ErrRunAbandoned  = errors.New("run cancelled: no client is reading it")
+	ErrRunTimeout    = errors.New("run timed out")
+)
+
+// Keys of Message.Extra of an answer saved by a run that did not complete. Such answers are
+// left out of the history given to the model.
+const (
+	ExtraRunStatus = "run_status"
+	ExtraRunError  = "run_error"
+)
+
+const (
+	// runRetention is how long a finished run stays listed and its events available for replay.
+	runRetention = 10 * time.Minute
+	// defaultRunTimeout bounds a run unless AGENT_RUN_TIMEOUT says otherwise.
+	defaultRunTimeout = 10 * time.Minute
+	// defaultAbandonAfter is how long a run goes on with no client reading it, unless
+	// AGENT_RUN_ABANDON_AFTER says otherwise. A client that reconnects in time resumes it.
+	defaultAbandonAfter = time.Minute
+)
+
+func durationFromEnv(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Printf("[Chat] Invalid %s %q, using %v\n", key, v, def)
+		return def
+	}
+	return d
+}
+
+// runTimeout is the timeout of a run asking for seconds, at most AGENT_RUN_TIMEOUT.
+func runTimeout(seconds int) time.Duration {
+	max := durationFromEnv("AGENT_RUN_TIMEOUT", defaultRunTimeout)
+	if d := time.Duration(seconds) * time.Second; d > 0 && d < max {
+		return d
+	}
+	return max
+}
+
+// runStatusOf tells how a run that ended with err, io.EOF or nil on success, ended, from the
+// cause of the cancellation of its context.
+func runStatusOf(ctx context.Context, err error) RunStatus {
+	if err == nil || errors.Is(err, io.EOF) {
+		return RunCompleted
+	}
+	cause := context.Cause(ctx)
+	switch {
+	case errors.Is(cause, ErrRunTimeout):
+		return RunTimedOut
+	case errors.Is(cause, ErrRunCancelled), errors.Is(cause, ErrRunAbandoned):
+		return RunCancelled
+	default:
+		return RunFailed
+	}
+}
+
+// RunInfo describes a run for the runs API.
+type RunInfo struct {
+	ConversationID string     `json:"conversation_id"`
+	StreamID       string     `json:"stream_id"`
+	Status         RunStatus  `json:"status"`
+	Error          string     `json:"error,omitempty"`
+	StartedAt      time.Time  `json:"started_at"`
+	EndedAt        *time.Time `json:"ended_at,omitempty"`
+	Readers        int        `json:"readers"`
+	Usage          UsageData  `json:"usage"`
+}
+
+func (s *ChatStream) Info() RunInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	info := RunInfo{
+		ConversationID: s.ConversationID,
+		StreamID:       s.ID,
+		Status:         s.status,
+		Error:          s.errMsg,
+		StartedAt:      s.startedAt,
+		Readers:        s.readers,
+		Usage:          s.usage,
+	}
+	if !s.endedAt.IsZero() {
+		ended := s.endedAt
+		info.EndedAt = &ended
+	}
+	return info
+}
+
+// Cancel stops the run; it reports false when the run is already over.
+func (s *ChatStream) Cancel(cause error) bool {
+	s.mu.Lock()
+	running := s.status == RunRunning
+	s.mu.Unlock()
+	if running {
+		s.cancel(cause)
+	}
+	return running
+}
+
+// attach registers a client reading the stream.
+func (s *ChatStream) attach() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.readers++
+	if s.abandon != nil {
+		s.abandon.Stop()
+		s.abandon = nil
+	}
+}
+
+// detach unregisters a client. The run is cancelled if no client reads it for abandonAfter.
+func (s *ChatStream) detach(abandonAfter time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.readers--
+	if s.readers > 0 || s.status != RunRunning {
+		return
+	}
+	s.abandon = time.AfterFunc(abandonAfter, func() {
+		s.mu.Lock()
+		abandoned := s.readers == 0
+		s.mu.Unlock()
+		if abandoned && s.Cancel(ErrRunAbandoned) {
+			log.Printf("[Chat] Cancelled abandoned run of chat ID: %s\n", s.ConversationID)
+		}
+	})
+}
+
+// runRegistry keeps the latest run of every conversation, until runRetention after it ended,
+// and finds runs by stream id for resuming.
+type runRegistry struct {
+	mu             sync.Mutex
+	byConversation map[string]*ChatStream
+	byID           map[string]*ChatStream
+}
+
+var runs = &runRegistry{
+	byConversation: make(map[string]*ChatStream),
+	byID:           make(map[string]*ChatStream),
+}
+
+// start registers a new run, unless one is in progress for the conversation.
+func (r *runRegistry) start(s *ChatStream) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cur, ok := r.byConversation[s.ConversationID]; ok && cur.Info().Status == RunRunning {
+		return ErrRunInProgress
+	}
+	r.byConversation[s.ConversationID] = s
+	r.byID[s.ID] = s
+	return nil
+}
+
+func (r *runRegistry) get(streamID string) *ChatStream {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.byID[streamID]
+}
+
+func (r *runRegistry) conversation(id string) *ChatStream {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.byConversation[id]
+}
+
+func (r *runRegistry) remove(s *ChatStream) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.byID, s.ID)
+	if r.byConversation[s.ConversationID] == s {
+		delete(r.byConversation, s.ConversationID)
+	}
+}
+
+// list returns the runs, the most recent first.
+func (r *runRegistry) list() []RunInfo {
+	r.mu.Lock()
+	infos := make([]RunInfo, 0, len(r.byConversation))
+	for _, s := range r.byConversation {
+		infos = append(infos, s.Info())
+	}
+	r.mu.Unlock()
+	sort.Slice(infos, func(i, j int) bool { return infos[i].StartedAt.After(infos[j].StartedAt) })
+	return infos
+}
+
+// HandleRuns lists the latest run of every conversation, status=running keeping the active ones.
+func HandleRuns(ctx context.Context, c *app.RequestContext) {
+	status := RunStatus(c.Query("status"))
+	infos := runs.list()
+	res := make([]RunInfo, 0, len(infos))
+	for _, info := range infos {
+		if status == "" || info.Status == status {
+			res = append(res, info)
+		}
+	}
+	c.JSON(consts.StatusOK, map[string]interface{}{
+		"runs": res,
+	})
+}
+
+// HandleCancelRun cancels the run of conversation :id. What was answered so far is saved with
+// the cancelled status.
+func HandleCancelRun(ctx context.Context, c *app.RequestContext) {
+	s := runs.conversation(c.Param("id"))
+	if s == nil {
+		c.JSON(consts.StatusNotFound, map[string]string{
+			"error": "run not found",
+		})
+		return
+	}
+	if !s.Cancel(ErrRunCancelled) {
+		c.JSON(consts.StatusConflict, map[string]interface{}{
+			"error": "run is not running",
+			"run":   s.Info(),
+		})
+		return
+	}
+	log.Printf("[Chat] Cancelled run of chat ID: %s\n", s.ConversationID)
+	c.JSON(consts.StatusAccepted, map[string]interface{}{
+		"run": s.Info(),
+	})
+}
@@ -0,0 +1,156 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"likeeino/pkg/mem"
+)
+
+func TestRunStatusOf(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, RunCompleted, runStatusOf(ctx, nil))
+	assert.Equal(t, RunCompleted, runStatusOf(ctx, io.EOF))
+	assert.Equal(t, RunFailed, runStatusOf(ctx, errors.New("boom")))
+
+	cancelled, cancel := context.WithCancelCause(ctx)
+	cancel(ErrRunCancelled)
+	assert.Equal(t, RunCancelled, runStatusOf(cancelled, context.Canceled))
+
+	timedOut, cancelTimeout := context.WithTimeoutCause(ctx, time.Nanosecond, ErrRunTimeout)
+	defer cancelTimeout()
+	<-timedOut.Done()
+	assert.Equal(t, RunTimedOut, runStatusOf(timedOut, context.DeadlineExceeded))
+}
+
+func TestRunTimeout(t *testing.T) {
+	t.Setenv("AGENT_RUN_TIMEOUT", "2m")
+	assert.Equal(t, 2*time.Minute, runTimeout(0))
+	assert.Equal(t, 30*time.Second, runTimeout(30))
+	assert.Equal(t, 2*time.Minute, runTimeout(600))
+}
+
+func TestCompletedMessages(t *testing.T) {
+	cut := schema.AssistantMessage("partial", nil)
+	cut.Extra = map[string]any{ExtraRunStatus: string(RunCancelled)}
+	msgs := []*schema.Message{schema.UserMessage("hi"), cut, schema.UserMessage("again")}
+	assert.Equal(t, []*schema.Message{msgs[0], msgs[2]}, completedMessages(msgs))
+}
+
+func TestSaveExchange(t *testing.T) {
+	dir := t.TempDir()
+	conversation := mem.NewSimpleMemory(mem.SimpleMemoryConfig{Dir: dir}).GetConversation("conv-save", true)
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(ErrRunCancelled)
+
+	chunks := []*schema.Message{schema.AssistantMessage("par", nil), schema.AssistantMessage("tial", nil)}
+	require.NoError(t, saveExchange(ctx, conversation, "hi", true, chunks, context.Canceled))
+	msgs := conversation.GetMessages()
+	require.Len(t, msgs, 2)
+	assert.Equal(t, "hi", msgs[0].Content)
+	assert.Equal(t, "partial", msgs[1].Content)
+	assert.Equal(t, string(RunCancelled), msgs[1].Extra[ExtraRunStatus])
+
+	// a store that fails is reported, so that RunAgent can move the head back
+	require.NoError(t, os.Remove(filepath.Join(dir, "conv-save.jsonl")))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "conv-save.jsonl"), 0755))
+	assert.Error(t, saveExchange(context.Background(), conversation, "again", true, nil, nil))
+}
+
+func TestRunRegistry(t *testing.T) {
+	first := newChatStream("conv-runs")
+	require.NoError(t, runs.start(first))
+	t.Cleanup(func() { runs.remove(first) })
+	assert.ErrorIs(t, runs.start(newChatStream("conv-runs")), ErrRunInProgress)
+
+	// a finished run gives way to the next one, which replaces it in the listing
+	first.finish(RunCompleted, nil)
+	second := newChatStream("conv-runs")
+	require.NoError(t, runs.start(second))
+	t.Cleanup(func() { runs.remove(second) })
+	assert.Same(t, second, runs.conversation("conv-runs"))
+	assert.Same(t, first, runs.get(first.ID))
+
+	runs.remove(first)
+	assert.Nil(t, runs.get(first.ID))
+	assert.Same(t, second, runs.conversation("conv-runs"))
+}
+
+func TestHandleCancelRun(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	s := newChatStream("conv-cancel")
+	s.cancel = cancel
+	require.NoError(t, runs.start(s))
+	t.Cleanup(func() { runs.remove(s) })
+
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.GET("/agent/api/runs", HandleRuns)
+	engine.POST("/agent/api/runs/:id/cancel", HandleCancelRun)
+
+	w := ut.PerformRequest(engine, "GET", "/agent/api/runs?status=running", nil)
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), `"conversation_id":"conv-cancel"`)
+
+	w = ut.PerformRequest(engine, "POST", "/agent/api/runs/missing/cancel", nil)
+	assert.Equal(t, 404, w.Code)
+
+	w = ut.PerformRequest(engine, "POST", "/agent/api/runs/conv-cancel/cancel", nil)
+	assert.Equal(t, 202, w.Code)
+	assert.ErrorIs(t, context.Cause(ctx), ErrRunCancelled)
+
+	// the run records how it ended once it winds down
+	s.finish(runStatusOf(ctx, context.Canceled), context.Cause(ctx))
+	info := s.Info()
+	assert.Equal(t, RunCancelled, info.Status)
+	assert.Equal(t, ErrRunCancelled.Error(), info.Error)
+	assert.NotNil(t, info.EndedAt)
+
+	w = ut.PerformRequest(engine, "POST", "/agent/api/runs/conv-cancel/cancel", nil)
+	assert.Equal(t, 409, w.Code)
+}
+
+func TestAbandonedRun(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	s := newChatStream("conv-abandon")
+	s.cancel = cancel
+
+	// a client coming back in time keeps the run going
+	s.attach()
+	s.detach(20 * time.Millisecond)
+	s.attach()
+	time.Sleep(50 * time.Millisecond)
+	assert.NoError(t, ctx.Err())
+
+	s.detach(time.Millisecond)
+	<-ctx.Done()
+	assert.ErrorIs(t, context.Cause(ctx), ErrRunAbandoned)
+	assert.Equal(t, RunCancelled, runStatusOf(ctx, context.Canceled))
+}
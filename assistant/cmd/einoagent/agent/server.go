@@ -20,6 +20,7 @@ import (
 	"bufio"
 	"context"
 	"embed"
+	"encoding/json"
 	"errors"
 	"io"
 	"likeeino/pkg/mem"
@@ -27,6 +28,7 @@ import (
 	"mime"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/cloudwego/hertz/pkg/app"
@@ -41,6 +43,13 @@ var webContent embed.FS
 type ChatRequest struct {
 	ID      string `json:"id"`
 	Message string `json:"message"`
+	// ParentID continues the conversation after this message instead of the current head,
+	// e.g. the parent of an edited user message.
+	ParentID string `json:"parent_id"`
+	// Regenerate answers the user message ParentID (default: the last one) again, Message is ignored.
+	Regenerate bool `json:"regenerate"`
+	// Timeout bounds the run in seconds, at most AGENT_RUN_TIMEOUT (default 10m).
+	Timeout int `json:"timeout,omitempty"`
 }
 
 func BindRoutes(r *route.RouterGroup) error {
@@ -49,10 +58,18 @@ func BindRoutes(r *route.RouterGroup) error {
 	}
 
 	// API 路由
-	r.GET("/api/chat", HandleChat)
+	r.POST("/api/chat", HandleChat)
+	r.GET("/api/runs", HandleRuns)
+	r.POST("/api/runs/:id/cancel", HandleCancelRun)
 	r.GET("/api/log", HandleLog)
 	r.GET("/api/history", HandleHistory)
 	r.DELETE("/api/history", HandleDeleteHistory)
+	r.GET("/api/history/search", HandleSearchHistory)
+	r.GET("/api/history/meta", HandleGetHistoryMeta)
+	r.POST("/api/history/meta", HandleUpdateHistoryMeta)
+	r.GET("/api/history/tree", HandleHistoryTree)
+	r.POST("/api/history/checkout", HandleCheckoutHistory)
+	r.POST("/api/history/truncate", HandleTruncateHistory)
 
 	// 静态文件服务
 	r.GET("/", func(ctx context.Context, c *app.RequestContext) {
@@ -84,74 +101,152 @@ func BindRoutes(r *route.RouterGroup) error {
 	return nil
 }
 
+// HandleChat streams the answer to a ChatRequest body as typed events (see Event): token,
+// tool_call, tool_result, retrieval, usage, then a final done or error. parent_id answers
+// after that message instead of the current head (editing a message), regenerate=true answers
+// parent_id or the last user message again; both keep the previous branch reachable.
+// timeout bounds the run in seconds. A conversation runs one answer at a time: 409 while
+// another is in progress, see HandleRuns and HandleCancelRun.
+//
+// A request with a Last-Event-ID header resumes that stream after the event instead, replaying
+// what the client missed; the body is then ignored.
 func HandleChat(ctx context.Context, c *app.RequestContext) {
-	id := c.Query("id")
-	message := c.Query("message")
-	if id == "" || message == "" {
+	if lastID := sse.GetLastEventID(c); lastID != "" {
+		stream, seq, err := resolveEventID(lastID)
+		if err != nil {
+			c.JSON(consts.StatusNotFound, map[string]string{
+				"status": "error",
+				"error":  err.Error(),
+			})
+			return
+		}
+		log.Printf("[Chat] Resuming stream %s of chat ID: %s after event %d\n", stream.ID, stream.ConversationID, seq)
+		serveChatStream(ctx, c, stream, seq)
+		return
+	}
+
+	req := &ChatRequest{}
+	if err := json.Unmarshal(c.Request.Body(), req); err != nil {
+		c.JSON(consts.StatusBadRequest, map[string]string{
+			"status": "error",
+			"error":  "invalid request body: " + err.Error(),
+		})
+		return
+	}
+	id := req.ID
+	if id == "" || (req.Message == "" && !req.Regenerate) {
 		c.JSON(consts.StatusBadRequest, map[string]string{
 			"status": "error",
-			"error":  "missing id or message parameter",
+			"error":  "missing id or message",
 		})
 		return
 	}
 
-	log.Printf("[Chat] Starting chat with ID: %s, Message: %s\n", id, message)
+	log.Printf("[Chat] Starting chat with ID: %s, Message: %s, Parent: %s, Regenerate: %v\n",
+		id, req.Message, req.ParentID, req.Regenerate)
 
-	sr, err := RunAgent(ctx, id, message)
+	stream, err := StartChat(ctx, req)
 	if err != nil {
 		log.Printf("[Chat] Error running agent: %v\n", err)
-		c.JSON(consts.StatusInternalServerError, map[string]string{
+		status := consts.StatusInternalServerError
+		if errors.Is(err, mem.ErrMessageNotFound) {
+			status = consts.StatusNotFound
+		} else if errors.Is(err, ErrRunInProgress) {
+			status = consts.StatusConflict
+		}
+		c.JSON(status, map[string]string{
 			"status": "error",
 			"error":  err.Error(),
 		})
 		return
 	}
+	serveChatStream(ctx, c, stream, 0)
+}
+
+// serveChatStream sends the events of stream after seq until the stream is over or the client
+// goes away, which leaves the run going for a while for the client to resume it.
+func serveChatStream(ctx context.Context, c *app.RequestContext, stream *ChatStream, seq int) {
+	stream.attach()
+	defer stream.detach(durationFromEnv("AGENT_RUN_ABANDON_AFTER", defaultAbandonAfter))
 
 	s := sse.NewStream(c)
 	defer func() {
-		sr.Close()
 		c.Flush()
-
-		log.Printf("[Chat] Finished chat with ID: %s\n", id)
+		log.Printf("[Chat] Finished chat with ID: %s\n", stream.ConversationID)
 	}()
+	if err := relayChatStream(ctx, stream, seq, s.Publish); err != nil {
+		log.Printf("[Chat] Error publishing message: %v\n", err)
+	}
+}
+
+// relayChatStream publishes the events of stream after seq, pinging while the run is quiet.
+func relayChatStream(ctx context.Context, stream *ChatStream, seq int, publish func(*sse.Event) error) error {
+	ping := time.NewTicker(30 * time.Second)
+	defer ping.Stop()
 
-outer:
 	for {
-		select {
-		case <-ctx.Done():
-			log.Printf("[Chat] Context done for chat ID: %s\n", id)
-			return
-		default:
-			msg, err := sr.Recv()
-			if errors.Is(err, io.EOF) {
-				log.Printf("[Chat] EOF received for chat ID: %s\n", id)
-				break outer
-			}
+		events, closed, changed := stream.Since(seq)
+		for _, e := range events {
+			data, err := json.Marshal(e.Data)
 			if err != nil {
-				log.Printf("[Chat] Error receiving message: %v\n", err)
-				break outer
+				log.Printf("[Chat] Error marshaling %s event: %v\n", e.Type, err)
+				continue
+			}
+			if err := publish(&sse.Event{
+				ID:    stream.EventID(e.Seq),
+				Event: e.Type,
+				Data:  data,
+			}); err != nil {
+				return err
 			}
+			seq = e.Seq
+		}
+		if closed {
+			return nil
+		}
 
-			err = s.Publish(&sse.Event{
-				Data: []byte(msg.Content),
-			})
-			if err != nil {
-				log.Printf("[Chat] Error publishing message: %v\n", err)
-				break outer
+		select {
+		case <-ctx.Done():
+			log.Printf("[Chat] Context done for chat ID: %s\n", stream.ConversationID)
+			return nil
+		case <-ping.C:
+			if err := publish(&sse.Event{Event: "ping", Data: []byte("{}")}); err != nil {
+				return err
 			}
+		case <-changed:
 		}
 	}
 }
 
+// HandleHistory lists conversations, or returns one conversation when id is given.
+//
+//	list:  offset, limit, tag, sort=updated|created|title
+//	by id: offset, limit page through the messages
 func HandleHistory(ctx context.Context, c *app.RequestContext) {
-	// query: id => get history, none => list all
 	id := c.Query("id")
 
 	if id == "" {
-		ids := mem.GetDefaultMemory().ListConversations()
+		metas, total, err := mem.GetDefaultMemory().ListMetadata(mem.ListOptions{
+			Offset: queryInt(c, "offset", 0),
+			Limit:  queryInt(c, "limit", 0),
+			Tag:    c.Query("tag"),
+			Sort:   c.Query("sort"),
+		})
+		if err != nil {
+			c.JSON(consts.StatusInternalServerError, map[string]string{
+				"error": err.Error(),
+			})
+			return
+		}
 
+		ids := make([]string, 0, len(metas))
+		for _, meta := range metas {
+			ids = append(ids, meta.ID)
+		}
 		c.JSON(consts.StatusOK, map[string]interface{}{
-			"ids": ids,
+			"ids":           ids,
+			"conversations": metas,
+			"total":         total,
 		})
 		return
 	}
@@ -164,10 +259,184 @@ func HandleHistory(ctx context.Context, c *app.RequestContext) {
 		return
 	}
 
+	if c.Query("offset") == "" && c.Query("limit") == "" {
+		c.JSON(consts.StatusOK, map[string]interface{}{
+			"conversation": conversation,
+			"metadata":     conversation.Metadata(),
+		})
+		return
+	}
+
+	msgs, msgIDs, total := conversation.Page(queryInt(c, "offset", 0), queryInt(c, "limit", 0))
+	c.JSON(consts.StatusOK, map[string]interface{}{
+		"conversation": map[string]interface{}{
+			"id":          id,
+			"messages":    msgs,
+			"message_ids": msgIDs,
+			"head":        conversation.Head,
+		},
+		"metadata": conversation.Metadata(),
+		"total":    total,
+	})
+}
+
+// HandleSearchHistory runs a full-text search over all messages: q, offset, limit.
+func HandleSearchHistory(ctx context.Context, c *app.RequestContext) {
+	q := c.Query("q")
+	if q == "" {
+		c.JSON(consts.StatusBadRequest, map[string]string{
+			"error": "missing q parameter",
+		})
+		return
+	}
+
+	hits, total, err := mem.GetDefaultMemory().Search(q, queryInt(c, "offset", 0), queryInt(c, "limit", 20))
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(consts.StatusOK, map[string]interface{}{
+		"hits":  hits,
+		"total": total,
+	})
+}
+
+func HandleGetHistoryMeta(ctx context.Context, c *app.RequestContext) {
+	id := c.Query("id")
+	if id == "" {
+		c.JSON(consts.StatusBadRequest, map[string]string{
+			"error": "missing id parameter",
+		})
+		return
+	}
+
+	meta, err := mem.GetDefaultMemory().GetMetadata(id)
+	if err != nil {
+		writeMemError(c, err)
+		return
+	}
+	c.JSON(consts.StatusOK, map[string]interface{}{
+		"metadata": meta,
+	})
+}
+
+// HandleUpdateHistoryMeta sets the title and/or tags of a conversation,
+// body: {"title": "...", "tags": ["..."]}.
+func HandleUpdateHistoryMeta(ctx context.Context, c *app.RequestContext) {
+	id := c.Query("id")
+	if id == "" {
+		c.JSON(consts.StatusBadRequest, map[string]string{
+			"error": "missing id parameter",
+		})
+		return
+	}
+
+	var patch mem.MetadataPatch
+	if err := json.Unmarshal(c.Request.Body(), &patch); err != nil {
+		c.JSON(consts.StatusBadRequest, map[string]string{
+			"error": "invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	meta, err := mem.GetDefaultMemory().UpdateMetadata(id, patch)
+	if err != nil {
+		writeMemError(c, err)
+		return
+	}
+	c.JSON(consts.StatusOK, map[string]interface{}{
+		"metadata": meta,
+	})
+}
+
+// HandleHistoryTree returns every message of every branch with its parent, and the head.
+func HandleHistoryTree(ctx context.Context, c *app.RequestContext) {
+	conversation, ok := queryConversation(c)
+	if !ok {
+		return
+	}
+	c.JSON(consts.StatusOK, map[string]interface{}{
+		"nodes": conversation.Nodes(),
+		"head":  conversation.Metadata().Head,
+	})
+}
+
+// HandleCheckoutHistory switches to the branch containing message_id.
+func HandleCheckoutHistory(ctx context.Context, c *app.RequestContext) {
+	moveHead(c, (*mem.Conversation).Checkout)
+}
+
+// HandleTruncateHistory rolls the active branch back to just before message_id.
+func HandleTruncateHistory(ctx context.Context, c *app.RequestContext) {
+	moveHead(c, (*mem.Conversation).Truncate)
+}
+
+func moveHead(c *app.RequestContext, move func(*mem.Conversation, string) error) {
+	conversation, ok := queryConversation(c)
+	if !ok {
+		return
+	}
+	msgID := c.Query("message_id")
+	if msgID == "" {
+		c.JSON(consts.StatusBadRequest, map[string]string{
+			"error": "missing message_id parameter",
+		})
+		return
+	}
+	if err := move(conversation, msgID); err != nil {
+		writeMemError(c, err)
+		return
+	}
 	c.JSON(consts.StatusOK, map[string]interface{}{
 		"conversation": conversation,
+		"metadata":     conversation.Metadata(),
+	})
+}
+
+func queryConversation(c *app.RequestContext) (*mem.Conversation, bool) {
+	id := c.Query("id")
+	if id == "" {
+		c.JSON(consts.StatusBadRequest, map[string]string{
+			"error": "missing id parameter",
+		})
+		return nil, false
+	}
+	conversation := mem.GetDefaultMemory().GetConversation(id, false)
+	if conversation == nil {
+		c.JSON(consts.StatusNotFound, map[string]string{
+			"error": "conversation not found",
+		})
+		return nil, false
+	}
+	return conversation, true
+}
+
+func writeMemError(c *app.RequestContext, err error) {
+	if errors.Is(err, mem.ErrConversationNotFound) {
+		c.JSON(consts.StatusNotFound, map[string]string{
+			"error": "conversation not found",
+		})
+		return
+	}
+	if errors.Is(err, mem.ErrMessageNotFound) {
+		c.JSON(consts.StatusNotFound, map[string]string{
+			"error": "message not found",
+		})
+		return
+	}
+	c.JSON(consts.StatusInternalServerError, map[string]string{
+		"error": err.Error(),
 	})
+}
 
+func queryInt(c *app.RequestContext, key string, def int) int {
+	v, err := strconv.Atoi(c.Query(key))
+	if err != nil {
+		return def
+	}
+	return v
 }
 
 func HandleDeleteHistory(ctx context.Context, c *app.RequestContext) {
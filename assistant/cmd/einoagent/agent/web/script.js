@@ -22,6 +22,9 @@ document.addEventListener('DOMContentLoaded', () => {
 
     // 取消按钮点击事件
     cancelButton.addEventListener('click', () => {
+        // 先通知服务端停止本次运行，已生成的部分会带着 cancelled 状态保存
+        fetch(`/agent/api/runs/${encodeURIComponent(chatId)}/cancel`, { method: 'POST' })
+            .catch(error => console.error('Error cancelling run:', error));
         if (abortController) {
             abortController.abort();
             abortController = null;
@@ -265,7 +268,10 @@ document.addEventListener('DOMContentLoaded', () => {
                 chatMessages.innerHTML = '';
                 
                 data.conversation.messages.forEach(msg => {
-                    appendMessage(msg.content, msg.role === 'user', false);
+                    // 未正常完成的回答带有 run_status，标注出来
+                    const status = msg.extra && msg.extra.run_status;
+                    const content = status ? `${msg.content || ''}\n\n**${runStatusLabel(status)}**` : msg.content;
+                    appendMessage(content, msg.role === 'user', false);
                 });
                 
                 highlightCurrentChat();
@@ -276,6 +282,14 @@ document.addEventListener('DOMContentLoaded', () => {
     }
 
     // 添加消息到聊天区域
+    function runStatusLabel(status) {
+        switch (status) {
+            case 'cancelled': return 'Cancelled';
+            case 'timed_out': return 'Timed out';
+            default: return 'Failed';
+        }
+    }
+
     function appendMessage(content, isUser, animate = true) {
         const processedContent = processMessageContent(content);
         const messageDiv = document.createElement('div');
@@ -344,100 +358,175 @@ document.addEventListener('DOMContentLoaded', () => {
             // 创建新的 AbortController
             abortController = new AbortController();
 
-            // 使用 fetch 替代 EventSource，添加 signal
-            const response = await fetch(`/agent/api/chat?id=${chatId}&message=${encodeURIComponent(message)}`, {
-                signal: abortController.signal
-            });
+            let lastEventId = '';   // 断线后据此续传
+            let finished = false;   // 收到 done 或 error 事件
+            let activityDiv = null; // 工具调用、检索等过程信息
+
+            function ensureMessageDiv() {
+                if (!isFirstChunk) return;
+                const messageDiv = document.createElement('div');
+                messageDiv.className = 'flex items-start gap-3 mb-4';
+
+                // 添加头像
+                const avatar = document.createElement('div');
+                avatar.className = 'w-8 h-8 flex items-center justify-center rounded-full bg-gray-100 flex-shrink-0';
+                avatar.textContent = '🤖';
+                messageDiv.appendChild(avatar);
+
+                const bodyDiv = document.createElement('div');
+                bodyDiv.className = 'flex-1 min-w-0';
+                activityDiv = document.createElement('div');
+                activityDiv.className = 'tool-activity text-xs text-gray-500 mb-2';
+                bodyDiv.appendChild(activityDiv);
+
+                // 消息内容
+                contentDiv = document.createElement('div');
+                contentDiv.className = 'message markdown-body rounded-lg p-4 bg-gray-50';
+                bodyDiv.appendChild(contentDiv);
+                messageDiv.appendChild(bodyDiv);
+                chatMessages.appendChild(messageDiv);
+
+                currentMessageDiv = contentDiv;
+                isFirstChunk = false;
+            }
 
-            // 检查响应状态
-            if (!response.ok) {
-                throw new Error(`HTTP error! status: ${response.status}`);
+            function addActivity(text) {
+                ensureMessageDiv();
+                const line = document.createElement('div');
+                line.textContent = text;
+                activityDiv.appendChild(line);
+                chatMessages.scrollTop = chatMessages.scrollHeight;
             }
 
-            const reader = response.body.getReader();
-            const decoder = new TextDecoder();
-            let buffer = '';  // 用于存储不完整的 SSE 消息
-
-            try {
-                while (true) {
-                    const {value, done} = await reader.read();
-                    if (done) break;
-
-                    // 解码新的数据块并添加到缓冲区
-                    buffer += decoder.decode(value, {stream: true});
-                    
-                    // 按行分割并处理每一行
-                    const lines = buffer.split(/\r\n|\r|\n/);
-                    // 保留最后一个可能不完整的行
-                    buffer = lines.pop() || '';
-
-                    for (const line of lines) {
-                        // 解析 SSE 格式的行
-                        if (line.startsWith('data:')) {
-                            // 保留 data: 后的所有内容，包括前导空格
-                            const rawData = line.slice(5);  // 直接截取 'data:' 后的内容
-                            // console.log(`Raw SSE data: |${rawData}|`);
-                            hasReceivedMessage = true;
-
-                            // 如果是第一个 chunk，创建新的消息框
-                            if (isFirstChunk) {
-                                const messageDiv = document.createElement('div');
-                                messageDiv.className = 'flex items-start gap-3 mb-4';
-
-                                // 添加头像
-                                const avatar = document.createElement('div');
-                                avatar.className = 'w-8 h-8 flex items-center justify-center rounded-full bg-gray-100 flex-shrink-0';
-                                avatar.textContent = '🤖';
-                                messageDiv.appendChild(avatar);
-
-                                // 消息内容
-                                contentDiv = document.createElement('div');
-                                contentDiv.className = 'message markdown-body rounded-lg p-4 bg-gray-50';
-                                messageDiv.appendChild(contentDiv);
-                                chatMessages.appendChild(messageDiv);
-                                
-                                currentMessageDiv = contentDiv;
-                                isFirstChunk = false;
-                                accumulatedContent = rawData;
-                            } else {
-                                if (rawData === '') {
-                                    // 如果是空数据，添加换行符
-                                    accumulatedContent += '\n';
-                                } else {
-                                    // 否则直接拼接数据
-                                    accumulatedContent += rawData;
-                                }
-                            }
+            function renderContent() {
+                currentMessageDiv.innerHTML = marked.parse(accumulatedContent);
+                addCopyButtons();
+                chatMessages.scrollTop = chatMessages.scrollHeight;
+                lastRenderTime = Date.now();
+            }
+
+            function scheduleRender() {
+                // 限制渲染频率
+                const now = Date.now();
+                clearTimeout(window.renderTimeout);
+                if (now - lastRenderTime >= 100) {
+                    renderContent();
+                } else {
+                    window.renderTimeout = setTimeout(renderContent, 100 - (now - lastRenderTime));
+                }
+            }
+
+            function handleEvent(type, data) {
+                hasReceivedMessage = true;
+                switch (type) {
+                    case 'token':
+                        ensureMessageDiv();
+                        accumulatedContent += data.content;
+                        scheduleRender();
+                        break;
+                    case 'tool_call':
+                        addActivity(`🔧 ${data.name} ${data.arguments}`);
+                        break;
+                    case 'tool_result':
+                        addActivity(data.error ? `⚠️ ${data.name}: ${data.error}` : `✅ ${data.name}`);
+                        break;
+                    case 'retrieval': {
+                        const sources = [...new Set(data.documents
+                            .map(doc => doc.metadata && (doc.metadata._file_name || doc.metadata._source))
+                            .filter(Boolean))];
+                        addActivity(`📚 ${data.documents.length} documents${sources.length ? ': ' + sources.join(', ') : ''}`);
+                        break;
+                    }
+                    case 'error':
+                        finished = true;
+                        ensureMessageDiv();
+                        accumulatedContent += data.status && data.status !== 'failed'
+                            ? `\n\n**${runStatusLabel(data.status)}**`
+                            : `\n\n**Error:** ${data.message}`;
+                        renderContent();
+                        break;
+                    case 'done':
+                        finished = true;
+                        if (!isFirstChunk) renderContent();
+                        break;
+                }
+            }
 
-                            // 限制渲染频率
-                            const now = Date.now();
-                            if (now - lastRenderTime >= 100) {
-                                renderContent();
-                            } else {
-                                clearTimeout(window.renderTimeout);
-                                window.renderTimeout = setTimeout(renderContent, 100 - (now - lastRenderTime));
+            // 解析 SSE：事件之间以空行分隔，每行为 id/event/data 字段
+            async function readEvents(response) {
+                const reader = response.body.getReader();
+                const decoder = new TextDecoder();
+                let buffer = '';  // 用于存储不完整的 SSE 消息
+                let event = {type: 'message', id: '', data: []};
+                try {
+                    while (true) {
+                        const {value, done} = await reader.read();
+                        if (done) break;
+
+                        buffer += decoder.decode(value, {stream: true});
+                        const lines = buffer.split(/\r\n|\r|\n/);
+                        // 保留最后一个可能不完整的行
+                        buffer = lines.pop() || '';
+
+                        for (const line of lines) {
+                            if (line === '') {
+                                if (event.data.length > 0 && event.type !== 'ping') {
+                                    if (event.id) lastEventId = event.id;
+                                    handleEvent(event.type, JSON.parse(event.data.join('\n')));
+                                }
+                                event = {type: 'message', id: '', data: []};
+                                continue;
                             }
+                            const colon = line.indexOf(':');
+                            const field = colon < 0 ? line : line.slice(0, colon);
+                            let fieldValue = colon < 0 ? '' : line.slice(colon + 1);
+                            if (fieldValue.startsWith(' ')) fieldValue = fieldValue.slice(1);
+                            if (field === 'event') event.type = fieldValue;
+                            else if (field === 'id') event.id = fieldValue;
+                            else if (field === 'data') event.data.push(fieldValue);
                         }
                     }
+                } finally {
+                    // 确保读取器被正确关闭
+                    reader.cancel();
                 }
+            }
 
-                function renderContent() {
-                    currentMessageDiv.innerHTML = marked.parse(accumulatedContent);
-                    addCopyButtons();
-                    chatMessages.scrollTop = chatMessages.scrollHeight;
-                    lastRenderTime = Date.now();
+            // 连接中断时带上 Last-Event-ID 重新请求，从断点继续接收
+            let retries = 0;
+            while (!finished) {
+                const headers = {'Content-Type': 'application/json'};
+                if (lastEventId) headers['Last-Event-ID'] = lastEventId;
+                try {
+                    const response = await fetch('/agent/api/chat', {
+                        method: 'POST',
+                        headers,
+                        body: JSON.stringify({id: chatId, message}),
+                        signal: abortController.signal
+                    });
+
+                    // 检查响应状态
+                    if (!response.ok) {
+                        const error = new Error(`HTTP error! status: ${response.status}`);
+                        error.fatal = true;
+                        throw error;
+                    }
+                    await readEvents(response);
+                } catch (error) {
+                    if (error.name === 'AbortError' || error.fatal || !lastEventId) throw error;
+                    console.warn('Chat stream interrupted, resuming:', error);
+                }
+                if (!finished) {
+                    if (!lastEventId || ++retries > 3) throw new Error('chat stream ended unexpectedly');
+                    await new Promise(resolve => setTimeout(resolve, 1000 * retries));
                 }
-
-                // 请求完成后，隐藏取消按钮，显示发送按钮
-                cancelButton.classList.add('hidden');
-                sendButton.classList.remove('hidden');
-                abortController = null;
-
-            } finally {
-                // 确保读取器被正确关闭
-                reader.cancel();
             }
 
+            // 请求完成后，隐藏取消按钮，显示发送按钮
+            cancelButton.classList.add('hidden');
+            sendButton.classList.remove('hidden');
+            abortController = null;
+
         } catch (error) {
             console.error('Error sending message:', error);
             if (error.name === 'AbortError') {
@@ -467,57 +556,42 @@ document.addEventListener('DOMContentLoaded', () => {
     fetch('/agent/api/history')
         .then(response => response.json())
         .then(data => {
-            if (data.ids && data.ids.length > 0) {
+            if (data.conversations && data.conversations.length > 0) {
                 chatHistory.innerHTML = ''; // 清空现有历史
-                let firstConversationId = null;
-
-                const loadPromises = data.ids.map(id => 
-                    fetch(`/agent/api/history?id=${id}`)
-                        .then(response => response.json())
-                        .then(convData => {
-                            if (convData.conversation) {
-                                const firstMessage = convData.conversation.messages.length > 0 
-                                    ? convData.conversation.messages[0].content 
-                                    : 'Empty';
-
-                                const historyItem = document.createElement('div');
-                                historyItem.className = 'chat-item p-3 hover:bg-gray-100 cursor-pointer rounded-lg mb-2 transition-colors flex justify-between items-start';
-                                historyItem.dataset.chatId = id;
-                                historyItem.innerHTML = `
-                                    <div class="flex-1 min-w-0 mr-2" onclick="event.stopPropagation()">
-                                        <div class="font-medium text-gray-900 truncate">${firstMessage}</div>
-                                        <div class="text-sm text-gray-500">ID: ${id.substring(0, 8)}...</div>
-                                    </div>
-                                    <button class="delete-chat p-1 hover:bg-red-100 rounded-lg transition-colors" onclick="event.stopPropagation()">
-                                        <svg class="w-5 h-5 text-red-500" fill="none" stroke="currentColor" viewBox="0 0 24 24">
-                                            <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M19 7l-.867 12.142A2 2 0 0116.138 21H7.862a2 2 0 01-1.995-1.858L5 7m5 4v6m4-6v6m1-10V4a1 1 0 00-1-1h-4a1 1 0 00-1 1v3M4 7h16" />
-                                        </svg>
-                                    </button>
-                                `;
-                                
-                                const deleteButton = historyItem.querySelector('.delete-chat');
-                                deleteButton.addEventListener('click', (e) => {
-                                    e.stopPropagation();
-                                    deleteConversation(id, historyItem);
-                                });
-                                
-                                historyItem.querySelector('.flex-1').addEventListener('click', () => loadConversation(id));
-                                chatHistory.appendChild(historyItem);
-
-                                // 记录第一个对话的ID
-                                if (firstConversationId === null) {
-                                    firstConversationId = id;
-                                }
-                            }
-                        })
-                );
 
-                // 等待所有历史记录加载完成后，加载第一个对话
-                Promise.all(loadPromises).then(() => {
-                    if (firstConversationId) {
-                        loadConversation(firstConversationId);
-                    }
+                data.conversations.forEach(meta => {
+                    const id = meta.id;
+                    const title = meta.title || 'Empty';
+                    const updated = meta.updated_at ? new Date(meta.updated_at).toLocaleString() : '';
+
+                    const historyItem = document.createElement('div');
+                    historyItem.className = 'chat-item p-3 hover:bg-gray-100 cursor-pointer rounded-lg mb-2 transition-colors flex justify-between items-start';
+                    historyItem.dataset.chatId = id;
+                    historyItem.innerHTML = `
+                        <div class="flex-1 min-w-0 mr-2" onclick="event.stopPropagation()">
+                            <div class="font-medium text-gray-900 truncate"></div>
+                            <div class="text-sm text-gray-500">${meta.message_count} messages · ${updated}</div>
+                        </div>
+                        <button class="delete-chat p-1 hover:bg-red-100 rounded-lg transition-colors" onclick="event.stopPropagation()">
+                            <svg class="w-5 h-5 text-red-500" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+                                <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M19 7l-.867 12.142A2 2 0 0116.138 21H7.862a2 2 0 01-1.995-1.858L5 7m5 4v6m4-6v6m1-10V4a1 1 0 00-1-1h-4a1 1 0 00-1 1v3M4 7h16" />
+                            </svg>
+                        </button>
+                    `;
+                    historyItem.querySelector('.font-medium').textContent = title;
+
+                    const deleteButton = historyItem.querySelector('.delete-chat');
+                    deleteButton.addEventListener('click', (e) => {
+                        e.stopPropagation();
+                        deleteConversation(id, historyItem);
+                    });
+
+                    historyItem.querySelector('.flex-1').addEventListener('click', () => loadConversation(id));
+                    chatHistory.appendChild(historyItem);
                 });
+
+                // conversations are sorted by last update, open the most recent one
+                loadConversation(data.conversations[0].id);
             }
         })
         .catch(error => console.error('Error loading history:', error));
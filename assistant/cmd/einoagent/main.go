@@ -3,14 +3,21 @@ package main
 import (
 	"context"
 	"fmt"
+	planexecute "likeeino/adk/multiagent/plan-execute-replan/agent"
+	"likeeino/agent/multiagent/supervisor"
 	"likeeino/assistant/cmd/einoagent/agent"
+	"likeeino/assistant/cmd/einoagent/openai"
 	"likeeino/assistant/cmd/einoagent/task"
+	"likeeino/assistant/eino/einoagent"
 	"likeeino/pkg/env"
+	_ "likeeino/pkg/vectorstore/milvus"
 	"log"
 	"os"
 	"time"
 
 	"github.com/cloudwego/eino-ext/devops"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/app/server"
 	"github.com/hertz-contrib/obs-opentelemetry/provider"
@@ -37,8 +44,9 @@ func main() {
 		port = "8080"
 	}
 
-	// 创建 Hertz 服务器
-	h := server.Default(server.WithHostPorts(":" + port))
+	// 创建 Hertz 服务器; a run streamed over SSE notices through the request context that its
+	// client went away
+	h := server.Default(server.WithHostPorts(":"+port), server.WithSenseClientDisconnection(true))
 
 	h.Use(LogMiddleware())
 
@@ -55,7 +63,7 @@ func main() {
 			provider.WithResourceAttribute(attribute.String("apmplus.business_type", "llm")),
 		)
 		tracer, cfg := hertztracing.NewServerTracer()
-		h = server.Default(server.WithHostPorts(":"+port), tracer)
+		h = server.Default(server.WithHostPorts(":"+port), server.WithSenseClientDisconnection(true), tracer)
 		h.Use(LogMiddleware(), hertztracing.ServerMiddleware(cfg))
 	}
 
@@ -71,6 +79,15 @@ func main() {
 		log.Fatal("failed to bind agent routes:", err)
 	}
 
+	// 注册 OpenAI 兼容接口,每个 agent 作为一个模型
+	v1 := openai.NewServer()
+	v1.Register("eino-agent", openai.GraphAgent(func(ctx context.Context) (compose.Runnable[*einoagent.UserMessage, *schema.Message], error) {
+		return einoagent.BuildEinoAgent(ctx)
+	}))
+	v1.Register("supervisor", openai.ADKAgent(supervisor.NewSupervisor))
+	v1.Register("plan-execute-replan", openai.ADKAgent(planexecute.NewPlanExecuteAgent))
+	v1.BindRoutes(h.Group("/v1"))
+
 	// Redirect root path to /agent
 	h.GET("/", func(ctx context.Context, c *app.RequestContext) {
 		c.Redirect(302, []byte("/agent"))
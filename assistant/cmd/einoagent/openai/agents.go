@@ -0,0 +1,206 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openai
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"likeeino/assistant/eino/einoagent"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+// ErrNoUserMessage is returned by agents given a conversation without a user message to answer.
+var ErrNoUserMessage = errors.New("messages contain no user message")
+
+// Agent answers a conversation.
+type Agent interface {
+	// Stream answers the last user message of req.Messages.
+	Stream(ctx context.Context, req *Request) (*schema.StreamReader[*schema.Message], error)
+}
+
+// Request is a conversation to answer. ID identifies the completion.
+type Request struct {
+	ID       string
+	Messages []*schema.Message
+}
+
+// GraphAgent serves an agent graph of the einoagent kind: the last user message becomes the
+// query and the messages before it the history. The graph is built on first use.
+func GraphAgent(build func(ctx context.Context) (compose.Runnable[*einoagent.UserMessage, *schema.Message], error)) Agent {
+	return &graphAgent{build: build}
+}
+
+type graphAgent struct {
+	mu     sync.Mutex
+	build  func(ctx context.Context) (compose.Runnable[*einoagent.UserMessage, *schema.Message], error)
+	runner compose.Runnable[*einoagent.UserMessage, *schema.Message]
+}
+
+func (a *graphAgent) Stream(ctx context.Context, req *Request) (*schema.StreamReader[*schema.Message], error) {
+	last := -1
+	for i, msg := range req.Messages {
+		if msg.Role == schema.User {
+			last = i
+		}
+	}
+	if last < 0 {
+		return nil, ErrNoUserMessage
+	}
+	runner, err := a.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return runner.Stream(ctx, &einoagent.UserMessage{
+		ID:      req.ID,
+		Query:   req.Messages[last].Content,
+		History: req.Messages[:last],
+	})
+}
+
+func (a *graphAgent) get(ctx context.Context) (compose.Runnable[*einoagent.UserMessage, *schema.Message], error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.runner == nil {
+		runner, err := a.build(ctx)
+		if err != nil {
+			return nil, err
+		}
+		a.runner = runner
+	}
+	return a.runner, nil
+}
+
+// ADKAgent serves an ADK agent, built on first use, given the whole conversation. Its answer is
+// the text of every assistant message of the run, sub-agents included, separated by blank
+// lines; tool messages are left out.
+func ADKAgent(build func(ctx context.Context) (adk.Agent, error)) Agent {
+	return &adkAgent{build: build}
+}
+
+type adkAgent struct {
+	mu    sync.Mutex
+	build func(ctx context.Context) (adk.Agent, error)
+	agent adk.Agent
+}
+
+func (a *adkAgent) Stream(ctx context.Context, req *Request) (*schema.StreamReader[*schema.Message], error) {
+	hasUser := false
+	for _, msg := range req.Messages {
+		hasUser = hasUser || msg.Role == schema.User
+	}
+	if !hasUser {
+		return nil, ErrNoUserMessage
+	}
+	agent, err := a.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	runner := adk.NewRunner(ctx, adk.RunnerConfig{Agent: agent, EnableStreaming: true})
+	iter := runner.Run(ctx, req.Messages)
+
+	sr, sw := schema.Pipe[*schema.Message](8)
+	go func() {
+		defer sw.Close()
+		w := &textWriter{sw: sw}
+		for {
+			event, ok := iter.Next()
+			if !ok {
+				return
+			}
+			if event.Err != nil {
+				sw.Send(nil, event.Err)
+				return
+			}
+			if event.Output == nil || event.Output.MessageOutput == nil || event.Output.MessageOutput.Role != schema.Assistant {
+				continue
+			}
+			if err := w.forward(event.Output.MessageOutput); err != nil {
+				if !errors.Is(err, errReaderClosed) {
+					sw.Send(nil, err)
+				}
+				return
+			}
+			w.next = true
+		}
+	}()
+	return sr, nil
+}
+
+func (a *adkAgent) get(ctx context.Context) (adk.Agent, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.agent == nil {
+		agent, err := a.build(ctx)
+		if err != nil {
+			return nil, err
+		}
+		a.agent = agent
+	}
+	return a.agent, nil
+}
+
+var errReaderClosed = errors.New("reader closed")
+
+// textWriter sends the text of messages, separating those with text by blank lines.
+type textWriter struct {
+	sw *schema.StreamWriter[*schema.Message]
+	// sent is whether any text was sent, next whether a new message started since
+	sent, next bool
+}
+
+// forward sends the text of a message output, chunk by chunk when it is streamed.
+func (w *textWriter) forward(mv *adk.MessageVariant) error {
+	if !mv.IsStreaming {
+		if mv.Message == nil {
+			return nil
+		}
+		return w.write(mv.Message.Content)
+	}
+	defer mv.MessageStream.Close()
+	for {
+		chunk, err := mv.MessageStream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := w.write(chunk.Content); err != nil {
+			return err
+		}
+	}
+}
+
+func (w *textWriter) write(text string) error {
+	if text == "" {
+		return nil
+	}
+	if w.sent && w.next {
+		text = "\n\n" + text
+	}
+	w.sent, w.next = true, false
+	if w.sw.Send(schema.AssistantMessage(text, nil), nil) {
+		return errReaderClosed
+	}
+	return nil
+}
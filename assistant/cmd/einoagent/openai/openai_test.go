@@ -0,0 +1,177 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"likeeino/assistant/eino/einoagent"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoModel answers with the number of messages it got and the last one, and reports usage
+// on its last chunk.
+type echoModel struct{}
+
+func (echoModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	return schema.ConcatMessages(echoChunks(input))
+}
+
+func (echoModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return schema.StreamReaderFromArray(echoChunks(input)), nil
+}
+
+func echoChunks(input []*schema.Message) []*schema.Message {
+	last := schema.AssistantMessage("", nil)
+	last.ResponseMeta = &schema.ResponseMeta{Usage: &schema.TokenUsage{PromptTokens: 5, CompletionTokens: 2, TotalTokens: 7}}
+	return []*schema.Message{
+		schema.AssistantMessage(fmt.Sprintf("%d messages, ", len(input)), nil),
+		schema.AssistantMessage("last: "+input[len(input)-1].Content, nil),
+		last,
+	}
+}
+
+func buildEchoGraph(ctx context.Context) (compose.Runnable[*einoagent.UserMessage, *schema.Message], error) {
+	g := compose.NewGraph[*einoagent.UserMessage, *schema.Message]()
+	_ = g.AddLambdaNode("ToMessages", compose.InvokableLambda(func(ctx context.Context, in *einoagent.UserMessage) ([]*schema.Message, error) {
+		return append(append([]*schema.Message{}, in.History...), schema.UserMessage(in.Query)), nil
+	}))
+	_ = g.AddChatModelNode("ChatModel", echoModel{})
+	_ = g.AddEdge(compose.START, "ToMessages")
+	_ = g.AddEdge("ToMessages", "ChatModel")
+	_ = g.AddEdge("ChatModel", compose.END)
+	return g.Compile(ctx)
+}
+
+// scriptedAgent emits a fixed run: an answer, a tool round trip and a streamed answer.
+type scriptedAgent struct{}
+
+func (scriptedAgent) Name(ctx context.Context) string        { return "scripted" }
+func (scriptedAgent) Description(ctx context.Context) string { return "scripted" }
+
+func (scriptedAgent) Run(ctx context.Context, input *adk.AgentInput, opts ...adk.AgentRunOption) *adk.AsyncIterator[*adk.AgentEvent] {
+	iter, gen := adk.NewAsyncIteratorPair[*adk.AgentEvent]()
+	go func() {
+		defer gen.Close()
+		gen.Send(adk.EventFromMessage(schema.AssistantMessage("first", nil), nil, schema.Assistant, ""))
+		gen.Send(adk.EventFromMessage(schema.AssistantMessage("", []schema.ToolCall{{ID: "1"}}), nil, schema.Assistant, ""))
+		gen.Send(adk.EventFromMessage(schema.ToolMessage("tool output", "1"), nil, schema.Tool, "search"))
+		stream := schema.StreamReaderFromArray([]*schema.Message{
+			schema.AssistantMessage("se", nil),
+			schema.AssistantMessage("cond", nil),
+		})
+		gen.Send(adk.EventFromMessage(nil, stream, schema.Assistant, ""))
+	}()
+	return iter
+}
+
+func newTestServer() *route.Engine {
+	s := NewServer()
+	s.Register("echo", GraphAgent(buildEchoGraph))
+	s.Register("scripted", ADKAgent(func(ctx context.Context) (adk.Agent, error) { return scriptedAgent{}, nil }))
+	engine := route.NewEngine(config.NewOptions(nil))
+	s.BindRoutes(engine.Group("/v1"))
+	return engine
+}
+
+func post(engine *route.Engine, body string) (*ut.ResponseRecorder, map[string]interface{}) {
+	w := ut.PerformRequest(engine, "POST", "/v1/chat/completions", &ut.Body{Body: strings.NewReader(body), Len: len(body)},
+		ut.Header{Key: "Content-Type", Value: "application/json"})
+	var res map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &res)
+	return w, res
+}
+
+func TestModels(t *testing.T) {
+	w := ut.PerformRequest(newTestServer(), "GET", "/v1/models", nil)
+	require.Equal(t, 200, w.Code)
+	var res struct {
+		Data []Model `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Len(t, res.Data, 2)
+	assert.Equal(t, "echo", res.Data[0].ID)
+	assert.Equal(t, "scripted", res.Data[1].ID)
+}
+
+func TestChatCompletions(t *testing.T) {
+	engine := newTestServer()
+
+	// the history and the last user message reach the graph, tool messages are dropped
+	w, res := post(engine, `{"model":"echo","messages":[
+		{"role":"system","content":"be brief"},
+		{"role":"user","content":[{"type":"text","text":"hi"},{"type":"image_url","image_url":{"url":"x"}}]},
+		{"role":"assistant","content":"hello"},
+		{"role":"tool","content":"ignored","tool_call_id":"1"},
+		{"role":"user","content":"what?"}]}`)
+	require.Equal(t, 200, w.Code, w.Body.String())
+	var completion ChatCompletion
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &completion))
+	assert.Equal(t, "chat.completion", completion.Object)
+	assert.True(t, strings.HasPrefix(completion.ID, "chatcmpl-"))
+	require.Len(t, completion.Choices, 1)
+	assert.Equal(t, Content("4 messages, last: what?"), completion.Choices[0].Message.Content)
+	assert.Equal(t, "stop", *completion.Choices[0].FinishReason)
+	assert.Equal(t, &Usage{PromptTokens: 5, CompletionTokens: 2, TotalTokens: 7}, completion.Usage)
+
+	w, _ = post(engine, `{"model":"scripted","messages":[{"role":"user","content":"go"}]}`)
+	require.Equal(t, 200, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &completion))
+	assert.Equal(t, Content("first\n\nsecond"), completion.Choices[0].Message.Content)
+
+	w, res = post(engine, `{"model":"gpt-4","messages":[{"role":"user","content":"hi"}]}`)
+	assert.Equal(t, 404, w.Code)
+	assert.Equal(t, "model_not_found", res["error"].(map[string]interface{})["type"])
+	w, _ = post(engine, `{"model":"echo","messages":[{"role":"system","content":"hi"}]}`)
+	assert.Equal(t, 400, w.Code)
+	w, _ = post(engine, `{"model":"echo","messages":[{"role":"user","content":42}]}`)
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestStreamChunks(t *testing.T) {
+	usage := newUsageCounter()
+	ctx := callbacks.InitCallbacks(context.Background(), &callbacks.RunInfo{Name: "echo"}, usage.Handler())
+	sr, err := GraphAgent(buildEchoGraph).Stream(ctx, &Request{ID: "c1", Messages: []*schema.Message{schema.UserMessage("ping")}})
+	require.NoError(t, err)
+
+	var chunks []string
+	err = streamChunks(&ChatCompletion{ID: "c1", Created: 1, Model: "echo"}, sr, usage, true, func(data []byte) error {
+		chunks = append(chunks, string(data))
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, chunks, 6)
+	assert.JSONEq(t, `{"id":"c1","object":"chat.completion.chunk","created":1,"model":"echo","choices":[{"index":0,"delta":{"role":"assistant"},"finish_reason":null}]}`, chunks[0])
+	assert.JSONEq(t, `{"id":"c1","object":"chat.completion.chunk","created":1,"model":"echo","choices":[{"index":0,"delta":{"content":"last: ping"},"finish_reason":null}]}`, chunks[2])
+	assert.JSONEq(t, `{"id":"c1","object":"chat.completion.chunk","created":1,"model":"echo","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`, chunks[3])
+	assert.JSONEq(t, `{"id":"c1","object":"chat.completion.chunk","created":1,"model":"echo","choices":[],"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7}}`, chunks[4])
+	assert.Equal(t, "[DONE]", chunks[5])
+}
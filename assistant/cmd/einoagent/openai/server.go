@@ -0,0 +1,310 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package openai serves the agents of the assistant behind an OpenAI-compatible API, so that
+// OpenAI SDKs and IDE plugins can use them as models:
+//
+//	GET  /v1/models
+//	POST /v1/chat/completions   (stream=true answers with chat.completion.chunk events)
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/schema"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/google/uuid"
+	"github.com/hertz-contrib/sse"
+)
+
+// Server maps model names to agents.
+type Server struct {
+	mu      sync.RWMutex
+	agents  map[string]Agent
+	created int64
+}
+
+func NewServer() *Server {
+	return &Server{agents: make(map[string]Agent), created: time.Now().Unix()}
+}
+
+// Register serves agent as model, replacing an agent of the same name.
+func (s *Server) Register(model string, agent Agent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.agents[model] = agent
+}
+
+func (s *Server) agent(model string) (Agent, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	a, ok := s.agents[model]
+	return a, ok
+}
+
+func (s *Server) BindRoutes(r *route.RouterGroup) {
+	r.GET("/models", s.handleModels)
+	r.POST("/chat/completions", s.handleChatCompletions)
+}
+
+type Model struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+func (s *Server) handleModels(ctx context.Context, c *app.RequestContext) {
+	s.mu.RLock()
+	models := make([]Model, 0, len(s.agents))
+	for id := range s.agents {
+		models = append(models, Model{ID: id, Object: "model", Created: s.created, OwnedBy: "einoagent"})
+	}
+	s.mu.RUnlock()
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+	c.JSON(consts.StatusOK, map[string]interface{}{
+		"object": "list",
+		"data":   models,
+	})
+}
+
+type ChatCompletionRequest struct {
+	Model         string         `json:"model"`
+	Messages      []ChatMessage  `json:"messages"`
+	Stream        bool           `json:"stream"`
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
+}
+
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type ChatMessage struct {
+	Role    string  `json:"role"`
+	Content Content `json:"content"`
+	Name    string  `json:"name,omitempty"`
+}
+
+// Content is the text of a message, given either as a string or as an array of content parts
+// of which the text parts are kept.
+type Content string
+
+func (c *Content) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*c = Content(text)
+		return nil
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return errors.New("content must be a string or an array of content parts")
+	}
+	var texts []string
+	for _, p := range parts {
+		if p.Type == "text" {
+			texts = append(texts, p.Text)
+		}
+	}
+	*c = Content(strings.Join(texts, "\n"))
+	return nil
+}
+
+type ChatCompletion struct {
+	ID      string   `json:"id"`
+	Object  string   `json:"object"`
+	Created int64    `json:"created"`
+	Model   string   `json:"model"`
+	Choices []Choice `json:"choices"`
+	Usage   *Usage   `json:"usage,omitempty"`
+}
+
+// Choice is a choice of a completion, with Message set, or of a chunk, with Delta set.
+type Choice struct {
+	Index        int          `json:"index"`
+	Message      *ChatMessage `json:"message,omitempty"`
+	Delta        *Delta       `json:"delta,omitempty"`
+	FinishReason *string      `json:"finish_reason"`
+}
+
+type Delta struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+var roles = map[string]schema.RoleType{
+	"system":    schema.System,
+	"developer": schema.System,
+	"user":      schema.User,
+	"assistant": schema.Assistant,
+}
+
+func (s *Server) handleChatCompletions(ctx context.Context, c *app.RequestContext) {
+	var req ChatCompletionRequest
+	if err := json.Unmarshal(c.Request.Body(), &req); err != nil {
+		writeError(c, consts.StatusBadRequest, "invalid_request_error", "invalid request body: "+err.Error())
+		return
+	}
+	agent, ok := s.agent(req.Model)
+	if !ok {
+		writeError(c, consts.StatusNotFound, "model_not_found", fmt.Sprintf("model %q does not exist", req.Model))
+		return
+	}
+	// tool messages answer tool calls of the client, which the agents do not make
+	msgs := make([]*schema.Message, 0, len(req.Messages))
+	for _, m := range req.Messages {
+		role, ok := roles[m.Role]
+		if !ok {
+			continue
+		}
+		msgs = append(msgs, &schema.Message{Role: role, Content: string(m.Content), Name: m.Name})
+	}
+
+	id := "chatcmpl-" + strings.ReplaceAll(uuid.New().String(), "-", "")
+	usage := newUsageCounter()
+	ctx = callbacks.InitCallbacks(ctx, &callbacks.RunInfo{Name: req.Model}, usage.Handler())
+
+	sr, err := agent.Stream(ctx, &Request{ID: id, Messages: msgs})
+	if err != nil {
+		log.Printf("[OpenAI] Error running model %s: %v\n", req.Model, err)
+		if errors.Is(err, ErrNoUserMessage) {
+			writeError(c, consts.StatusBadRequest, "invalid_request_error", err.Error())
+		} else {
+			writeError(c, consts.StatusInternalServerError, "server_error", err.Error())
+		}
+		return
+	}
+	defer sr.Close()
+
+	completion := &ChatCompletion{ID: id, Created: time.Now().Unix(), Model: req.Model}
+	if !req.Stream {
+		var b strings.Builder
+		for {
+			msg, err := sr.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				writeError(c, consts.StatusInternalServerError, "server_error", err.Error())
+				return
+			}
+			b.WriteString(msg.Content)
+		}
+		completion.Object = "chat.completion"
+		completion.Choices = []Choice{{
+			Message:      &ChatMessage{Role: "assistant", Content: Content(b.String())},
+			FinishReason: ptr("stop"),
+		}}
+		completion.Usage = usage.Total()
+		c.JSON(consts.StatusOK, completion)
+		return
+	}
+
+	includeUsage := req.StreamOptions != nil && req.StreamOptions.IncludeUsage
+	stream := sse.NewStream(c)
+	defer c.Flush()
+	err = streamChunks(completion, sr, usage, includeUsage, func(data []byte) error {
+		return stream.Publish(&sse.Event{Data: data})
+	})
+	if err != nil {
+		log.Printf("[OpenAI] Error streaming model %s: %v\n", req.Model, err)
+	}
+}
+
+// streamChunks publishes the chunks of an answer, a chunk with the finish reason, the usage
+// chunk when asked for and [DONE]. An error of the agent is published as an error object,
+// since the status has already been sent.
+func streamChunks(completion *ChatCompletion, sr *schema.StreamReader[*schema.Message], usage *usageCounter, includeUsage bool, publish func([]byte) error) error {
+	completion.Object = "chat.completion.chunk"
+	send := func(choices []Choice, u *Usage) error {
+		chunk := *completion
+		chunk.Choices, chunk.Usage = choices, u
+		data, err := json.Marshal(&chunk)
+		if err != nil {
+			return err
+		}
+		return publish(data)
+	}
+
+	if err := send([]Choice{{Delta: &Delta{Role: "assistant"}}}, nil); err != nil {
+		return err
+	}
+	for {
+		msg, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			data, _ := json.Marshal(errorBody("server_error", err.Error()))
+			if perr := publish(data); perr != nil {
+				return perr
+			}
+			return err
+		}
+		if msg.Content == "" {
+			continue
+		}
+		if err := send([]Choice{{Delta: &Delta{Content: msg.Content}}}, nil); err != nil {
+			return err
+		}
+	}
+	if err := send([]Choice{{Delta: &Delta{}, FinishReason: ptr("stop")}}, nil); err != nil {
+		return err
+	}
+	if includeUsage {
+		if err := send([]Choice{}, usage.Total()); err != nil {
+			return err
+		}
+	}
+	return publish([]byte("[DONE]"))
+}
+
+func errorBody(typ, message string) map[string]interface{} {
+	return map[string]interface{}{
+		"error": map[string]string{
+			"message": message,
+			"type":    typ,
+		},
+	}
+}
+
+func writeError(c *app.RequestContext, status int, typ, message string) {
+	c.JSON(status, errorBody(typ, message))
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
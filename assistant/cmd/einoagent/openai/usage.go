@@ -0,0 +1,58 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openai
+
+import (
+	"sync"
+
+	"likeeino/pkg/model"
+
+	"github.com/cloudwego/eino/callbacks"
+	einomodel "github.com/cloudwego/eino/components/model"
+)
+
+// usageCounter sums the token usage of the chat model calls of a run.
+type usageCounter struct {
+	mu    sync.Mutex
+	usage Usage
+	cb    model.UsageCallback
+}
+
+func newUsageCounter() *usageCounter {
+	u := &usageCounter{}
+	u.cb.Report = func(t *einomodel.TokenUsage) {
+		u.mu.Lock()
+		defer u.mu.Unlock()
+		u.usage.PromptTokens += t.PromptTokens
+		u.usage.CompletionTokens += t.CompletionTokens
+		u.usage.TotalTokens += t.TotalTokens
+	}
+	return u
+}
+
+func (u *usageCounter) Handler() callbacks.Handler {
+	return u.cb.Handler()
+}
+
+// Total waits for the model streams of the run to be read and returns the usage.
+func (u *usageCounter) Total() *Usage {
+	u.cb.Wait()
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	res := u.usage
+	return &res
+}
@@ -0,0 +1,372 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"likeeino/pkg/tool/task"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/google/uuid"
+	"github.com/hertz-contrib/sse"
+)
+
+// restAPI serves tasks as resources:
+//
+//	GET    /api/tasks                 list: q, done, limit, due_before, due_after, tag, priority, sort_by
+//	POST   /api/tasks                 create
+//	POST   /api/tasks/batch/create    {"tasks": [...]}, all or nothing
+//	POST   /api/tasks/batch/complete  {"ids": [...]}, all or nothing
+//	GET    /api/tasks/export          format=ical|csv|markdown, plus the list filters
+//	POST   /api/tasks/import          format=ical|csv|markdown, the file as body, all or nothing
+//	GET    /api/tasks/:id             ETag, If-None-Match
+//	PATCH  /api/tasks/:id             If-Match
+//	DELETE /api/tasks/:id             If-Match
+//	GET    /api/changes               SSE feed of every task mutation
+type restAPI struct {
+	storage *task.Storage
+}
+
+func bindRESTRoutes(r *route.RouterGroup, storage *task.Storage) {
+	api := &restAPI{storage: storage}
+	r.GET("/api/tasks", api.list)
+	r.POST("/api/tasks", api.create)
+	r.POST("/api/tasks/batch/create", api.batchCreate)
+	r.POST("/api/tasks/batch/complete", api.batchComplete)
+	r.GET("/api/tasks/export", api.export)
+	r.POST("/api/tasks/import", api.importTasks)
+	r.GET("/api/tasks/:id", api.get)
+	r.PATCH("/api/tasks/:id", api.patch)
+	r.DELETE("/api/tasks/:id", api.delete)
+	r.GET("/api/changes", api.changes)
+}
+
+func (a *restAPI) list(ctx context.Context, c *app.RequestContext) {
+	params, ok := listParams(c)
+	if !ok {
+		return
+	}
+	tasks, err := a.storage.List(params)
+	if err != nil {
+		writeStorageError(c, err)
+		return
+	}
+	c.JSON(consts.StatusOK, map[string]interface{}{
+		"tasks": nonNil(tasks),
+		"total": len(tasks),
+	})
+}
+
+// listParams reads the list filters from the query, writing a 400 when one is invalid.
+func listParams(c *app.RequestContext) (*task.ListParams, bool) {
+	params := &task.ListParams{
+		Query:    c.Query("q"),
+		Tag:      c.Query("tag"),
+		Priority: task.Priority(c.Query("priority")),
+		SortBy:   c.Query("sort_by"),
+	}
+	if v := c.Query("done"); v != "" {
+		done, err := strconv.ParseBool(v)
+		if err != nil {
+			writeError(c, consts.StatusBadRequest, fmt.Errorf("invalid done: %s", v))
+			return nil, false
+		}
+		params.IsDone = &done
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			writeError(c, consts.StatusBadRequest, fmt.Errorf("invalid limit: %s", v))
+			return nil, false
+		}
+		params.Limit = &limit
+	}
+	for key, dst := range map[string]**time.Time{"due_before": &params.DueBefore, "due_after": &params.DueAfter} {
+		if v := c.Query(key); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				writeError(c, consts.StatusBadRequest, fmt.Errorf("invalid %s: %s", key, v))
+				return nil, false
+			}
+			*dst = &t
+		}
+	}
+	if err := params.Validate(); err != nil {
+		writeError(c, consts.StatusBadRequest, err)
+		return nil, false
+	}
+	return params, true
+}
+
+func (a *restAPI) create(ctx context.Context, c *app.RequestContext) {
+	var t task.Task
+	if err := json.Unmarshal(c.Request.Body(), &t); err != nil {
+		writeError(c, consts.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if err := t.ValidateNew(); err != nil {
+		writeError(c, consts.StatusBadRequest, err)
+		return
+	}
+	t.ID = uuid.New().String()
+	if err := a.storage.Add(&t); err != nil {
+		writeStorageError(c, err)
+		return
+	}
+	c.Header("ETag", t.ETag())
+	c.Header("Location", string(c.Request.URI().Path())+"/"+t.ID)
+	c.JSON(consts.StatusCreated, &t)
+}
+
+func (a *restAPI) batchCreate(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		Tasks []*task.Task `json:"tasks"`
+	}
+	if err := json.Unmarshal(c.Request.Body(), &req); err != nil {
+		writeError(c, consts.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if len(req.Tasks) == 0 {
+		writeError(c, consts.StatusBadRequest, errors.New("tasks is required"))
+		return
+	}
+	for i, t := range req.Tasks {
+		if t == nil {
+			writeError(c, consts.StatusBadRequest, fmt.Errorf("tasks[%d]: task is required", i))
+			return
+		}
+		if err := t.ValidateNew(); err != nil {
+			writeError(c, consts.StatusBadRequest, fmt.Errorf("tasks[%d]: %w", i, err))
+			return
+		}
+		t.ID = uuid.New().String()
+	}
+	if err := a.storage.AddAll(req.Tasks); err != nil {
+		writeStorageError(c, err)
+		return
+	}
+	c.JSON(consts.StatusCreated, map[string]interface{}{
+		"tasks": req.Tasks,
+	})
+}
+
+func (a *restAPI) batchComplete(ctx context.Context, c *app.RequestContext) {
+	var req struct {
+		IDs []string `json:"ids"`
+	}
+	if err := json.Unmarshal(c.Request.Body(), &req); err != nil {
+		writeError(c, consts.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if len(req.IDs) == 0 {
+		writeError(c, consts.StatusBadRequest, errors.New("ids is required"))
+		return
+	}
+	// the completed tasks, followed by the next occurrences of recurring ones
+	tasks, err := a.storage.CompleteAll(req.IDs)
+	if err != nil {
+		writeStorageError(c, err)
+		return
+	}
+	c.JSON(consts.StatusOK, map[string]interface{}{
+		"tasks": tasks,
+	})
+}
+
+func (a *restAPI) export(ctx context.Context, c *app.RequestContext) {
+	format, err := task.ParseFormat(c.DefaultQuery("format", "ical"))
+	if err != nil {
+		writeError(c, consts.StatusBadRequest, err)
+		return
+	}
+	params, ok := listParams(c)
+	if !ok {
+		return
+	}
+	tasks, err := a.storage.List(params)
+	if err != nil {
+		writeStorageError(c, err)
+		return
+	}
+	data, err := task.Export(tasks, format)
+	if err != nil {
+		writeError(c, consts.StatusInternalServerError, err)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="tasks.%s"`, format.Extension()))
+	c.Data(consts.StatusOK, format.ContentType(), []byte(data))
+}
+
+// importTasks creates the tasks of an uploaded file, or updates the ones imported before.
+func (a *restAPI) importTasks(ctx context.Context, c *app.RequestContext) {
+	format, err := task.ParseFormat(c.Query("format"))
+	if err != nil {
+		writeError(c, consts.StatusBadRequest, err)
+		return
+	}
+	tasks, err := task.Parse(string(c.Request.Body()), format)
+	if err != nil {
+		writeError(c, consts.StatusBadRequest, err)
+		return
+	}
+	if len(tasks) == 0 {
+		writeError(c, consts.StatusBadRequest, errors.New("no tasks found"))
+		return
+	}
+	for i, t := range tasks {
+		if err := t.ValidateNew(); err != nil {
+			writeError(c, consts.StatusBadRequest, fmt.Errorf("tasks[%d]: %w", i, err))
+			return
+		}
+	}
+	res, err := a.storage.Import(tasks)
+	if err != nil {
+		writeStorageError(c, err)
+		return
+	}
+	c.JSON(consts.StatusOK, res)
+}
+
+func (a *restAPI) get(ctx context.Context, c *app.RequestContext) {
+	t, err := a.storage.Get(c.Param("id"))
+	if err != nil {
+		writeStorageError(c, err)
+		return
+	}
+	c.Header("ETag", t.ETag())
+	if string(c.GetHeader("If-None-Match")) == t.ETag() {
+		c.Status(consts.StatusNotModified)
+		return
+	}
+	c.JSON(consts.StatusOK, t)
+}
+
+// patch updates the fields present in the body. Completing a recurring task creates its next
+// occurrence, which is announced on the change feed.
+func (a *restAPI) patch(ctx context.Context, c *app.RequestContext) {
+	var t task.Task
+	var fields map[string]json.RawMessage
+	body := c.Request.Body()
+	if err := json.Unmarshal(body, &t); err != nil {
+		writeError(c, consts.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	_ = json.Unmarshal(body, &fields)
+	if err := t.Validate(); err != nil {
+		writeError(c, consts.StatusBadRequest, err)
+		return
+	}
+	t.ID = c.Param("id")
+
+	var opts []task.WriteOption
+	if etag := string(c.GetHeader("If-Match")); etag != "" {
+		opts = append(opts, task.IfMatch(etag))
+	}
+	if _, ok := fields["completed"]; !ok {
+		opts = append(opts, task.KeepCompleted())
+	}
+	tasks, err := a.storage.Update(&t, opts...)
+	if err != nil {
+		writeStorageError(c, err)
+		return
+	}
+	c.Header("ETag", tasks[0].ETag())
+	c.JSON(consts.StatusOK, tasks[0])
+}
+
+func (a *restAPI) delete(ctx context.Context, c *app.RequestContext) {
+	var opts []task.WriteOption
+	if etag := string(c.GetHeader("If-Match")); etag != "" {
+		opts = append(opts, task.IfMatch(etag))
+	}
+	if err := a.storage.Delete(c.Param("id"), opts...); err != nil {
+		writeStorageError(c, err)
+		return
+	}
+	c.Status(consts.StatusNoContent)
+}
+
+// changes streams every task mutation, whether it came from this API, the task_manager tool
+// of the agent, or another process sharing the data directory.
+func (a *restAPI) changes(ctx context.Context, c *app.RequestContext) {
+	changes, stop := a.storage.Subscribe()
+	defer stop()
+
+	ping := time.NewTicker(30 * time.Second)
+	defer ping.Stop()
+
+	s := sse.NewStream(c)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ping.C:
+			if err := s.Publish(&sse.Event{Event: "ping", Data: []byte("{}")}); err != nil {
+				return
+			}
+		case change, ok := <-changes:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(change)
+			if err != nil {
+				log.Printf("[task] failed to marshal change: %v", err)
+				continue
+			}
+			if err := s.Publish(&sse.Event{
+				ID:    strconv.FormatInt(change.Seq, 10),
+				Event: string(change.Type),
+				Data:  data,
+			}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func writeStorageError(c *app.RequestContext, err error) {
+	switch {
+	case errors.Is(err, task.ErrTaskNotFound):
+		writeError(c, consts.StatusNotFound, err)
+	case errors.Is(err, task.ErrETagMismatch):
+		writeError(c, consts.StatusPreconditionFailed, err)
+	default:
+		writeError(c, consts.StatusInternalServerError, err)
+	}
+}
+
+func writeError(c *app.RequestContext, status int, err error) {
+	c.JSON(status, map[string]string{
+		"status": "error",
+		"error":  err.Error(),
+	})
+}
+
+func nonNil(tasks []*task.Task) []*task.Task {
+	if tasks == nil {
+		return []*task.Task{}
+	}
+	return tasks
+}
@@ -0,0 +1,201 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package task
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"likeeino/pkg/tool/task"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAPI(t *testing.T) (*route.Engine, *task.Storage) {
+	storage, err := task.NewStorage(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { storage.Close() })
+
+	engine := route.NewEngine(config.NewOptions(nil))
+	bindRESTRoutes(engine.Group("/task"), storage)
+	return engine, storage
+}
+
+func doJSON(engine *route.Engine, method, url, body string, headers ...ut.Header) (*ut.ResponseRecorder, map[string]interface{}) {
+	var b *ut.Body
+	if body != "" {
+		b = &ut.Body{Body: bytes.NewBufferString(body), Len: len(body)}
+	}
+	w := ut.PerformRequest(engine, method, url, b, append(headers, ut.Header{Key: "Content-Type", Value: "application/json"})...)
+	var res map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &res)
+	return w, res
+}
+
+func TestRESTLifecycle(t *testing.T) {
+	engine, _ := newTestAPI(t)
+
+	w, created := doJSON(engine, "POST", "/task/api/tasks", `{"title":"write report","priority":"high","deadline":"2025-03-03T09:00:00Z"}`)
+	require.Equal(t, 201, w.Code, w.Body.String())
+	id := created["id"].(string)
+	etag := string(w.Header().Peek("ETag"))
+	assert.Equal(t, `"1"`, etag)
+
+	w, got := doJSON(engine, "GET", "/task/api/tasks/"+id, "")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "write report", got["title"])
+	w, _ = doJSON(engine, "GET", "/task/api/tasks/"+id, "", ut.Header{Key: "If-None-Match", Value: etag})
+	assert.Equal(t, 304, w.Code)
+
+	// a patch without completed leaves it alone, a stale ETag is rejected
+	w, _ = doJSON(engine, "PATCH", "/task/api/tasks/"+id, `{"completed":true}`, ut.Header{Key: "If-Match", Value: etag})
+	require.Equal(t, 200, w.Code, w.Body.String())
+	w, _ = doJSON(engine, "PATCH", "/task/api/tasks/"+id, `{"title":"x"}`, ut.Header{Key: "If-Match", Value: etag})
+	assert.Equal(t, 412, w.Code)
+	w, patched := doJSON(engine, "PATCH", "/task/api/tasks/"+id, `{"title":"final report"}`)
+	require.Equal(t, 200, w.Code)
+	assert.Equal(t, true, patched["completed"])
+	assert.Equal(t, "final report", patched["title"])
+
+	w, list := doJSON(engine, "GET", "/task/api/tasks?done=true&priority=high", "")
+	assert.Equal(t, 200, w.Code)
+	assert.EqualValues(t, 1, list["total"])
+
+	w, _ = doJSON(engine, "DELETE", "/task/api/tasks/"+id, "")
+	assert.Equal(t, 204, w.Code)
+	w, _ = doJSON(engine, "GET", "/task/api/tasks/"+id, "")
+	assert.Equal(t, 404, w.Code)
+
+	w, _ = doJSON(engine, "POST", "/task/api/tasks", `{"content":"no title"}`)
+	assert.Equal(t, 400, w.Code)
+	w, _ = doJSON(engine, "GET", "/task/api/tasks?due_before=tomorrow", "")
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestRESTBatch(t *testing.T) {
+	engine, storage := newTestAPI(t)
+
+	w, res := doJSON(engine, "POST", "/task/api/tasks/batch/create", `{"tasks":[{"title":"a"},{"title":"b"}]}`)
+	require.Equal(t, 201, w.Code, w.Body.String())
+	tasks := res["tasks"].([]interface{})
+	require.Len(t, tasks, 2)
+	a := tasks[0].(map[string]interface{})["id"].(string)
+	b := tasks[1].(map[string]interface{})["id"].(string)
+
+	// one invalid task rejects the whole batch
+	w, _ = doJSON(engine, "POST", "/task/api/tasks/batch/create", `{"tasks":[{"title":"c"},{"title":""}]}`)
+	assert.Equal(t, 400, w.Code)
+
+	// so does one unknown id
+	w, _ = doJSON(engine, "POST", "/task/api/tasks/batch/complete", `{"ids":["`+a+`","missing"]}`)
+	assert.Equal(t, 404, w.Code)
+	open, err := storage.List(&task.ListParams{})
+	require.NoError(t, err)
+	assert.Len(t, open, 2)
+	for _, t2 := range open {
+		assert.False(t, t2.Completed)
+	}
+
+	w, _ = doJSON(engine, "POST", "/task/api/tasks/batch/complete", `{"ids":["`+a+`","`+b+`"]}`)
+	assert.Equal(t, 200, w.Code)
+	done := true
+	completed, err := storage.List(&task.ListParams{IsDone: &done})
+	require.NoError(t, err)
+	assert.Len(t, completed, 2)
+}
+
+func TestChangeFeed(t *testing.T) {
+	dir := t.TempDir()
+	storage, err := task.NewStorage(dir)
+	require.NoError(t, err)
+	changes, stop := storage.Subscribe()
+	defer stop()
+
+	// a change made through the agent tool
+	tool, err := task.NewTaskToolImpl(context.Background(), &task.TaskToolConfig{Storage: storage})
+	require.NoError(t, err)
+	res, err := tool.Invoke(context.Background(), &task.TaskRequest{Action: task.ActionAdd, Task: &task.Task{Title: "from agent"}})
+	require.NoError(t, err)
+	c := next(t, changes)
+	assert.Equal(t, task.ChangeCreated, c.Type)
+	assert.Equal(t, res.TaskList[0].ID, c.ID)
+
+	// changes from another process are picked up by polling
+	other, err := task.NewStorage(dir)
+	require.NoError(t, err)
+	_, err = other.Update(&task.Task{ID: c.ID, Title: "renamed"})
+	require.NoError(t, err)
+	require.NoError(t, other.Delete(c.ID))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	storage.Watch(ctx, 10*time.Millisecond)
+	c = next(t, changes)
+	assert.Equal(t, task.ChangeUpdated, c.Type)
+	assert.Equal(t, "renamed", c.Task.Title)
+	assert.Equal(t, task.ChangeDeleted, next(t, changes).Type)
+}
+
+func next(t *testing.T, changes <-chan task.Change) task.Change {
+	select {
+	case c := <-changes:
+		return c
+	case <-time.After(2 * time.Second):
+		t.Fatal("no change received")
+		return task.Change{}
+	}
+}
+
+func TestRESTImportExport(t *testing.T) {
+	engine, _ := newTestAPI(t)
+
+	checklist := "- [ ] write report !high #work\n- [x] buy milk\n"
+	w := ut.PerformRequest(engine, "POST", "/task/api/tasks/import?format=markdown",
+		&ut.Body{Body: bytes.NewBufferString(checklist), Len: len(checklist)})
+	require.Equal(t, 200, w.Code, w.Body.String())
+	var res task.ImportResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(t, 2, res.Created)
+
+	w = ut.PerformRequest(engine, "GET", "/task/api/tasks/export?format=ical&tag=work", nil)
+	require.Equal(t, 200, w.Code, w.Body.String())
+	assert.Contains(t, string(w.Header().ContentType()), "text/calendar")
+	assert.Contains(t, string(w.Header().Peek("Content-Disposition")), "tasks.ics")
+	ics := w.Body.String()
+	assert.Contains(t, ics, "SUMMARY:write report")
+	assert.NotContains(t, ics, "buy milk")
+
+	// importing the export again finds the same task
+	w = ut.PerformRequest(engine, "POST", "/task/api/tasks/import?format=ical",
+		&ut.Body{Body: bytes.NewBufferString(ics), Len: len(ics)})
+	require.Equal(t, 200, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(t, 0, res.Created)
+	assert.Equal(t, 1, res.Unchanged)
+
+	w = ut.PerformRequest(engine, "GET", "/task/api/tasks/export?format=pdf", nil)
+	assert.Equal(t, 400, w.Code)
+	w = ut.PerformRequest(engine, "POST", "/task/api/tasks/import?format=csv",
+		&ut.Body{Body: bytes.NewBufferString("title\n\n"), Len: 7})
+	assert.Equal(t, 400, w.Code)
+}
@@ -19,13 +19,17 @@ package task
 import (
 	"context"
 	"embed"
+	"encoding/json"
 	"likeeino/pkg/tool/task"
+	"log"
 	"mime"
 	"path/filepath"
+	"time"
 
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
 	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/hertz-contrib/sse"
 )
 
 //go:embed web
@@ -42,6 +46,14 @@ func BindRoutes(r *route.RouterGroup) error {
 		return err
 	}
 
+	// 提醒调度: reminder and due events are pushed to /api/events
+	scheduler := task.NewScheduler(task.GetDefaultStorage(), 30*time.Second)
+	scheduler.Start(ctx)
+
+	// pick up tasks written by other processes for the change feed
+	task.GetDefaultStorage().Watch(ctx, 2*time.Second)
+	bindRESTRoutes(r, task.GetDefaultStorage())
+
 	// API 处理
 	r.POST("/api", func(ctx context.Context, c *app.RequestContext) {
 		var req task.TaskRequest
@@ -65,6 +77,39 @@ func BindRoutes(r *route.RouterGroup) error {
 		c.JSON(consts.StatusOK, resp)
 	})
 
+	r.GET("/api/events", func(ctx context.Context, c *app.RequestContext) {
+		events, stop := scheduler.Subscribe()
+		defer stop()
+
+		// the ping lets a dead connection fail on write instead of lingering until the next event
+		ping := time.NewTicker(30 * time.Second)
+		defer ping.Stop()
+
+		s := sse.NewStream(c)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ping.C:
+				if err := s.Publish(&sse.Event{Event: "ping", Data: []byte("{}")}); err != nil {
+					return
+				}
+			case e := <-events:
+				data, err := json.Marshal(e)
+				if err != nil {
+					log.Printf("[task] failed to marshal event: %v", err)
+					continue
+				}
+				if err := s.Publish(&sse.Event{
+					Event: string(e.Type),
+					Data:  data,
+				}); err != nil {
+					return
+				}
+			}
+		}
+	})
+
 	// 静态文件服务
 	r.GET("/", func(ctx context.Context, c *app.RequestContext) {
 		content, err := webContent.ReadFile("web/index.html")
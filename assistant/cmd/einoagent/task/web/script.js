@@ -48,6 +48,19 @@ function formatDate(dateStr) {
     });
 }
 
+// datetime-local 输入与 RFC3339 之间转换
+function toRFC3339(localValue) {
+    if (!localValue) return undefined;
+    return new Date(localValue).toISOString();
+}
+
+function toLocalInput(dateStr) {
+    if (!dateStr) return '';
+    const date = new Date(dateStr);
+    const offsetMs = date.getTimezoneOffset() * 60 * 1000;
+    return new Date(date - offsetMs).toISOString().slice(0, 16);
+}
+
 function calculateUrgency(task) {
     if (!task.deadline || task.completed) return Infinity;
     const now = new Date();
@@ -214,7 +227,7 @@ function openEditDialog(task) {
     form.id.value = task.id;
     form.title.value = task.title;
     form.content.value = task.content;
-    form.deadline.value = task.deadline ? task.deadline.slice(0, 16) : '';
+    form.deadline.value = toLocalInput(task.deadline);
     
     dialog.classList.remove('hidden');
 }
@@ -256,7 +269,7 @@ document.addEventListener('DOMContentLoaded', () => {
         const task = {
             title: form.title.value,
             content: form.content.value,
-            deadline: form.deadline.value
+            deadline: toRFC3339(form.deadline.value)
         };
 
         try {
@@ -348,7 +361,7 @@ document.addEventListener('DOMContentLoaded', () => {
             id: form.id.value,
             title: form.title.value,
             content: form.content.value,
-            deadline: form.deadline.value
+            deadline: toRFC3339(form.deadline.value)
         };
 
         try {
@@ -395,6 +408,31 @@ document.addEventListener('DOMContentLoaded', () => {
         }
     });
 
+    // 任务变更推送（包括 agent 通过 task_manager 工具做的修改）
+    const changes = new EventSource('/task/api/changes');
+    const reloadOnChange = debounce(loadTasks, 200);
+    ['created', 'updated', 'deleted'].forEach(type => changes.addEventListener(type, reloadOnChange));
+
+    // 提醒与到期事件
+    const events = new EventSource('/task/api/events');
+    ['reminder', 'due'].forEach(type => {
+        events.addEventListener(type, (e) => {
+            const event = JSON.parse(e.data);
+            const text = type === 'due'
+                ? `任务已到期: ${event.task.title}`
+                : `任务即将到期: ${event.task.title} (${formatDate(event.task.deadline)})`;
+            if (window.Notification && Notification.permission === 'granted') {
+                new Notification(text);
+            } else {
+                console.info(text);
+            }
+            loadTasks();
+        });
+    });
+    if (window.Notification && Notification.permission === 'default') {
+        Notification.requestPermission();
+    }
+
     // 初始化
     initializeFormValues();
     loadTasks();
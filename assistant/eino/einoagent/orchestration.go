@@ -23,14 +23,18 @@ import (
 	"github.com/cloudwego/eino/schema"
 )
 
-func BuildEinoAgent(ctx context.Context) (r compose.Runnable[*UserMessage, *schema.Message], err error) {
+func BuildEinoAgent(ctx context.Context, opts ...BuildOption) (r compose.Runnable[*UserMessage, *schema.Message], err error) {
 	const (
 		InputToQuery   = "InputToQuery"
 		ChatTemplate   = "ChatTemplate"
 		ReactAgent     = "ReactAgent"
-		RedisRetriever = "RedisRetriever"
+		Retriever      = "Retriever"
 		InputToHistory = "InputToHistory"
 	)
+	o := &buildOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
 	//创建graph
 	g := compose.NewGraph[*UserMessage, *schema.Message]()
 	//添加自定义逻辑节点
@@ -48,22 +52,22 @@ func BuildEinoAgent(ctx context.Context) (r compose.Runnable[*UserMessage, *sche
 	}
 	//将自定义的react agent 逻辑节点加入graph
 	_ = g.AddLambdaNode(ReactAgent, reactAgentKeyOfLambda, compose.WithNodeName("ReAct Agent"))
-	//自定义redis向量库节点
-	redisRetrieverKeyOfRetriever, err := newRetriever(ctx)
+	//向量库检索节点,向量库由配置选择
+	retrieverKeyOfRetriever, err := newRetriever(ctx, o.store)
 	if err != nil {
 		return nil, err
 	}
-	//将redis向量库节点加入到graph
-	_ = g.AddRetrieverNode(RedisRetriever, redisRetrieverKeyOfRetriever, compose.WithOutputKey("documents"))
+	//将向量库检索节点加入到graph
+	_ = g.AddRetrieverNode(Retriever, retrieverKeyOfRetriever, compose.WithOutputKey("documents"))
 	_ = g.AddLambdaNode(InputToHistory, compose.InvokableLambdaWithOption(newLambda2), compose.WithNodeName("UserMessageToVariables"))
 	//两条边并行执行
-	//compose.STAR  -》  InputToQuery  -》 RedisRetriever -》 ChatTemplate -》 ReactAgent -》 compose.END
+	//compose.STAR  -》  InputToQuery  -》 Retriever -》 ChatTemplate -》 ReactAgent -》 compose.END
 	//compose.START -》 InputToHistory -》 ChatTemplate   -》 ReactAgent   -》 compose.END
 	_ = g.AddEdge(compose.START, InputToQuery)
 	_ = g.AddEdge(compose.START, InputToHistory)
 	_ = g.AddEdge(ReactAgent, compose.END)
-	_ = g.AddEdge(InputToQuery, RedisRetriever)
-	_ = g.AddEdge(RedisRetriever, ChatTemplate)
+	_ = g.AddEdge(InputToQuery, Retriever)
+	_ = g.AddEdge(Retriever, ChatTemplate)
 	_ = g.AddEdge(InputToHistory, ChatTemplate)
 	_ = g.AddEdge(ChatTemplate, ReactAgent)
 	//编译
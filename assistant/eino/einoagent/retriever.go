@@ -20,62 +20,78 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"strconv"
 
-	"github.com/cloudwego/eino/schema"
-	redisCli "github.com/redis/go-redis/v9"
-	redispkg "likeeino/pkg/redis"
+	chatmodel "likeeino/pkg/model"
+	"likeeino/pkg/retriever/hybrid"
+	"likeeino/pkg/vectorstore"
 
-	"github.com/cloudwego/eino-ext/components/retriever/redis"
 	"github.com/cloudwego/eino/components/retriever"
 )
 
-// newRetriever component initialization function of node 'RedisRetriever' in graph 'EinoAgent'
-func newRetriever(ctx context.Context) (rtr retriever.Retriever, err error) {
-	// TODO Modify component configuration here.
-	redisAddr := os.Getenv("REDIS_ADDR")
-	redisClient := redisCli.NewClient(&redisCli.Options{
-		Addr:     redisAddr,
-		Protocol: 2,
-	})
-	config := &redis.RetrieverConfig{
-		Client:       redisClient,
-		Index:        fmt.Sprintf("%s%s", redispkg.RedisPrefix, redispkg.IndexName),
-		Dialect:      2,
-		ReturnFields: []string{redispkg.ContentField, redispkg.MetadataField, redispkg.DistanceField},
-		TopK:         8,
-		VectorField:  redispkg.VectorField,
-		DocumentConverter: func(ctx context.Context, doc redisCli.Document) (*schema.Document, error) {
-			resp := &schema.Document{
-				ID:       doc.ID,
-				Content:  "",
-				MetaData: map[string]any{},
-			}
-			for field, val := range doc.Fields {
-				if field == redispkg.ContentField {
-					resp.Content = val
-				} else if field == redispkg.MetadataField {
-					resp.MetaData[field] = val
-				} else if field == redispkg.DistanceField {
-					distance, err := strconv.ParseFloat(val, 64)
-					if err != nil {
-						continue
-					}
-					resp.WithScore(1 - distance)
-				}
-			}
+// BuildOption configures BuildEinoAgent.
+type BuildOption func(*buildOptions)
 
-			return resp, nil
-		},
+type buildOptions struct {
+	store *vectorstore.Config
+}
+
+// WithVectorStore sets the store documents are retrieved from, instead of the one configured
+// by the environment (see vectorstore.ConfigFromEnv). Without an embedder the ark one is used.
+func WithVectorStore(conf *vectorstore.Config) BuildOption {
+	return func(o *buildOptions) {
+		o.store = conf
 	}
-	embeddingIns11, err := newEmbedding(ctx)
-	if err != nil {
-		return nil, err
+}
+
+// newRetriever component initialization function of node 'Retriever' in graph 'EinoAgent'.
+// With the redis backend, RERANKER selects an optional reranker: "llm" grades with the chat
+// model, "http" calls the cross-encoder at RERANKER_URL (with RERANKER_MODEL and
+// RERANKER_API_KEY).
+func newRetriever(ctx context.Context, conf *vectorstore.Config) (rtr retriever.Retriever, err error) {
+	if conf == nil {
+		if conf, err = vectorstore.ConfigFromEnv(); err != nil {
+			return nil, err
+		}
 	}
-	config.Embedding = embeddingIns11
-	rtr, err = redis.NewRetriever(ctx, config)
+	c := *conf
+	if c.Embedding == nil {
+		if c.Embedding, err = newEmbedding(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if c.Redis.Retriever.Reranker == nil {
+		if c.Redis.Retriever.Reranker, err = newReranker(ctx); err != nil {
+			return nil, err
+		}
+	}
+	store, err := vectorstore.New(ctx, &c)
 	if err != nil {
 		return nil, err
 	}
-	return rtr, nil
+	return store.Retriever, nil
+}
+
+func newReranker(ctx context.Context) (hybrid.Reranker, error) {
+	switch reranker := os.Getenv("RERANKER"); reranker {
+	case "":
+		return nil, nil
+	case "llm":
+		cm, err := chatmodel.NewChatModel(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &hybrid.LLMReranker{Model: cm}, nil
+	case "http":
+		url := os.Getenv("RERANKER_URL")
+		if url == "" {
+			return nil, fmt.Errorf("RERANKER_URL is required for the http reranker")
+		}
+		return &hybrid.HTTPReranker{
+			URL:    url,
+			Model:  os.Getenv("RERANKER_MODEL"),
+			APIKey: os.Getenv("RERANKER_API_KEY"),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown RERANKER %q, want llm or http", reranker)
+	}
 }
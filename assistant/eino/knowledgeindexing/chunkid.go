@@ -0,0 +1,65 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package knowledgeindexing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/cloudwego/eino-ext/components/document/loader/file"
+	"github.com/cloudwego/eino/schema"
+
+	"likeeino/pkg/loader"
+	"likeeino/pkg/splitter"
+)
+
+// assignChunkIDs gives every chunk an id derived from its source, its position (header
+// breadcrumb, sheet, row, page, line) and its content, so indexing a file again overwrites its
+// chunks instead of duplicating them. Chunks that still collide, such as identical paragraphs
+// under one header, are told apart by their ordinal among the duplicates.
+func assignChunkIDs(ctx context.Context, docs []*schema.Document) ([]*schema.Document, error) {
+	seen := make(map[string]int, len(docs))
+	for _, doc := range docs {
+		id := chunkID(doc, 0)
+		n := seen[id]
+		seen[id] = n + 1
+		if n > 0 {
+			id = chunkID(doc, n)
+		}
+		doc.ID = id
+	}
+	return docs, nil
+}
+
+func chunkID(doc *schema.Document, ordinal int) string {
+	content := sha256.Sum256([]byte(doc.Content))
+	breadcrumb, _ := doc.MetaData[splitter.MetaKeyBreadcrumb].(string)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%v\x00%s\x00%x", doc.MetaData[file.MetaKeySource], breadcrumb, content)
+	for _, key := range []string{loader.MetaKeySheet, loader.MetaKeyRow, loader.MetaKeyPage, loader.MetaKeyLine} {
+		if v, ok := doc.MetaData[key]; ok {
+			fmt.Fprintf(h, "\x00%s=%v", key, v)
+		}
+	}
+	if ordinal > 0 {
+		fmt.Fprintf(h, "\x00#%d", ordinal)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
@@ -18,70 +18,62 @@ package knowledgeindexing
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"log"
-	"os"
 
-	"github.com/cloudwego/eino-ext/components/indexer/redis"
 	"github.com/cloudwego/eino/components/indexer"
-	"github.com/cloudwego/eino/schema"
-	"github.com/google/uuid"
-	redisCli "github.com/redis/go-redis/v9"
 
-	redispkg "likeeino/pkg/redis"
+	"likeeino/pkg/vectorstore"
 )
 
-// 初始化时直接创建key为 eino:doc:vector_index 的索引
-func init() {
-	err := redispkg.Init()
-	if err != nil {
-		log.Fatalf("failed to init redis index: %v", err)
-	}
-}
+// BuildOption configures BuildKnowledgeIndexing.
+type BuildOption func(*buildOptions)
 
-// newIndexer component initialization function of node 'RedisIndexer' in graph 'KnowledgeIndexing'
-func newIndexer(ctx context.Context) (idr indexer.Indexer, err error) {
-	// TODO Modify component configuration here.
-	redisAddr := os.Getenv("REDIS_ADDR")
-	redisClient := redisCli.NewClient(&redisCli.Options{
-		Addr:     redisAddr,
-		Protocol: 2,
-	})
+type buildOptions struct {
+	storeConf *vectorstore.Config
+	store     *vectorstore.Store
+}
 
-	config := &redis.IndexerConfig{
-		Client:    redisClient,
-		KeyPrefix: redispkg.RedisPrefix,
-		BatchSize: 1,
-		DocumentToHashes: func(ctx context.Context, doc *schema.Document) (*redis.Hashes, error) {
-			if doc.ID == "" {
-				doc.ID = uuid.New().String()
-			}
-			key := doc.ID
+// WithVectorStore sets the store the chunks are written to, instead of the one configured by
+// the environment (see vectorstore.ConfigFromEnv). Without an embedder the ark one is used.
+func WithVectorStore(conf *vectorstore.Config) BuildOption {
+	return func(o *buildOptions) {
+		o.storeConf = conf
+	}
+}
 
-			metadataBytes, err := json.Marshal(doc.MetaData)
-			if err != nil {
-				return nil, fmt.Errorf("failed to marshal metadata: %w", err)
-			}
+// WithStore writes the chunks to an open store.
+func WithStore(store *vectorstore.Store) BuildOption {
+	return func(o *buildOptions) {
+		o.store = store
+	}
+}
 
-			return &redis.Hashes{
-				Key: key,
-				Field2Value: map[string]redis.FieldValue{
-					redispkg.ContentField:  {Value: doc.Content, EmbedKey: redispkg.VectorField},
-					redispkg.MetadataField: {Value: metadataBytes},
-				},
-			}, nil
-		},
+// OpenStore opens the store of conf, or of the environment when conf is nil. Without an
+// embedder the ark one is used.
+func OpenStore(ctx context.Context, conf *vectorstore.Config) (*vectorstore.Store, error) {
+	var err error
+	if conf == nil {
+		if conf, err = vectorstore.ConfigFromEnv(); err != nil {
+			return nil, err
+		}
 	}
-	//向量模型
-	embeddingIns11, err := newEmbedding(ctx)
-	if err != nil {
-		return nil, err
+	if conf.Embedding == nil {
+		c := *conf
+		//向量模型
+		if c.Embedding, err = newEmbedding(ctx); err != nil {
+			return nil, err
+		}
+		conf = &c
 	}
-	config.Embedding = embeddingIns11
-	idr, err = redis.NewIndexer(ctx, config)
-	if err != nil {
-		return nil, err
+	return vectorstore.New(ctx, conf)
+}
+
+// newIndexer component initialization function of node 'Indexer' in graph 'KnowledgeIndexing'
+func newIndexer(ctx context.Context, o *buildOptions) (idr indexer.Indexer, err error) {
+	store := o.store
+	if store == nil {
+		if store, err = OpenStore(ctx, o.storeConf); err != nil {
+			return nil, err
+		}
 	}
-	return idr, nil
+	return store.Indexer, nil
 }
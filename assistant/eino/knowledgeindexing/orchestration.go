@@ -7,13 +7,17 @@ import (
 	"github.com/cloudwego/eino/compose"
 )
 
-func BuildKnowledgeIndexing(ctx context.Context) (r compose.Runnable[document.Source, []string], err error) {
+func BuildKnowledgeIndexing(ctx context.Context, opts ...BuildOption) (r compose.Runnable[document.Source, []string], err error) {
 	const (
-		FileLoader       = "FileLoader"
-		MarkdownSplitter = "MarkdownSplitter"
-		//RedisIndexer     = "RedisIndexer"
-		MilvusIndexer = "MilvusIndexer"
+		FileLoader = "FileLoader"
+		Splitter   = "Splitter"
+		ChunkID    = "ChunkID"
+		Indexer    = "Indexer"
 	)
+	o := &buildOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
 	g := compose.NewGraph[document.Source, []string]()
 	//文件加载器
 	fileLoaderKeyOfLoader, err := newLoader(ctx)
@@ -22,20 +26,24 @@ func BuildKnowledgeIndexing(ctx context.Context) (r compose.Runnable[document.So
 	}
 	_ = g.AddLoaderNode(FileLoader, fileLoaderKeyOfLoader)
 	//文件切割器,将文件分割(为存入向量库做准备)
-	markdownSplitterKeyOfDocumentTransformer, err := newDocumentTransformer(ctx)
+	splitterKeyOfDocumentTransformer, err := newDocumentTransformer(ctx)
 	if err != nil {
 		return nil, err
 	}
-	_ = g.AddDocumentTransformerNode(MarkdownSplitter, markdownSplitterKeyOfDocumentTransformer)
-	milvusIndexerKeyOfIndexer, err := newMilvusIndexer(ctx)
+	_ = g.AddDocumentTransformerNode(Splitter, splitterKeyOfDocumentTransformer)
+	//为切片生成确定的 id,重复索引同一文件时覆盖而不是重复写入
+	_ = g.AddLambdaNode(ChunkID, compose.InvokableLambda(assignChunkIDs))
+	//向量库由配置选择: redis, milvus 或 memory
+	indexerKeyOfIndexer, err := newIndexer(ctx, o)
 	if err != nil {
 		return nil, err
 	}
-	_ = g.AddIndexerNode(MilvusIndexer, milvusIndexerKeyOfIndexer)
+	_ = g.AddIndexerNode(Indexer, indexerKeyOfIndexer)
 	_ = g.AddEdge(compose.START, FileLoader)
-	_ = g.AddEdge(FileLoader, MarkdownSplitter)
-	_ = g.AddEdge(MarkdownSplitter, MilvusIndexer)
-	_ = g.AddEdge(MilvusIndexer, compose.END)
+	_ = g.AddEdge(FileLoader, Splitter)
+	_ = g.AddEdge(Splitter, ChunkID)
+	_ = g.AddEdge(ChunkID, Indexer)
+	_ = g.AddEdge(Indexer, compose.END)
 
 	r, err = g.Compile(ctx, compose.WithGraphName("KnowledgeIndexing"), compose.WithNodeTriggerMode(compose.AnyPredecessor))
 	if err != nil {
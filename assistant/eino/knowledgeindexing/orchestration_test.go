@@ -0,0 +1,80 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package knowledgeindexing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"likeeino/pkg/vectorstore"
+)
+
+// keywordEmbedder has one dimension per keyword.
+type keywordEmbedder struct{}
+
+var keywords = []string{"graph", "chain", "agent", "tool"}
+
+func (keywordEmbedder) EmbedStrings(ctx context.Context, texts []string, opts ...embedding.Option) ([][]float64, error) {
+	res := make([][]float64, len(texts))
+	for i, text := range texts {
+		v := make([]float64, len(keywords))
+		for j, k := range keywords {
+			v[j] = float64(strings.Count(strings.ToLower(text), k))
+		}
+		res[i] = v
+	}
+	return res, nil
+}
+
+func TestIndexThenRetrieve(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "eino.md")
+	require.NoError(t, os.WriteFile(path, []byte(`# Graph
+A graph connects nodes with edges; a graph may branch.
+
+# Agent
+An agent calls a tool, then another tool.
+`), 0644))
+
+	conf := &vectorstore.Config{
+		Backend:   vectorstore.BackendMemory,
+		Embedding: keywordEmbedder{},
+		Memory:    vectorstore.NewMemory(),
+	}
+	runner, err := BuildKnowledgeIndexing(ctx, WithVectorStore(conf))
+	require.NoError(t, err)
+	ids, err := runner.Invoke(ctx, document.Source{URI: path})
+	require.NoError(t, err)
+	assert.Len(t, ids, 2)
+	assert.Equal(t, 2, conf.Memory.Len())
+
+	store, err := vectorstore.New(ctx, conf)
+	require.NoError(t, err)
+	docs, err := store.Retriever.Retrieve(ctx, "which tool does the agent call")
+	require.NoError(t, err)
+	require.NotEmpty(t, docs)
+	assert.Contains(t, docs[0].Content, "An agent calls a tool")
+	assert.Equal(t, "Agent", docs[0].MetaData["title"])
+}
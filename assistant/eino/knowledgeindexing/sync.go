@@ -0,0 +1,279 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package knowledgeindexing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/components/document"
+
+	"likeeino/pkg/loader"
+	"likeeino/pkg/vectorstore"
+)
+
+// DefaultManifestName is the manifest file Sync keeps in the indexed directory by default.
+const DefaultManifestName = ".index_manifest.json"
+
+// Manifest records what has been indexed from a directory: the hash of every file, by path
+// relative to the directory, and the ids of its chunks.
+type Manifest struct {
+	Files map[string]*ManifestFile `json:"files"`
+}
+
+type ManifestFile struct {
+	Hash      string    `json:"hash"`
+	Chunks    []string  `json:"chunks"`
+	IndexedAt time.Time `json:"indexed_at"`
+}
+
+// LoadManifest reads a manifest, or returns an empty one when the file does not exist.
+func LoadManifest(path string) (*Manifest, error) {
+	m := &Manifest{Files: map[string]*ManifestFile{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	if m.Files == nil {
+		m.Files = map[string]*ManifestFile{}
+	}
+	return m, nil
+}
+
+// Save writes the manifest atomically.
+func (m *Manifest) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+type SyncOptions struct {
+	Dir string
+	// Manifest is the manifest path, Dir/.index_manifest.json by default.
+	Manifest string
+	// Match selects the files to index, those of a format the loader parses by default.
+	Match func(path string) bool
+	// DryRun reports what would change without touching the store or the manifest.
+	DryRun bool
+}
+
+// SyncReport lists files by path relative to the directory.
+type SyncReport struct {
+	Added     []string
+	Changed   []string
+	Removed   []string
+	Unchanged int
+
+	ChunksIndexed int
+	// ChunksDeleted is the number of chunks of removed files and, unless dry running, the
+	// chunks edited files no longer have.
+	ChunksDeleted int
+}
+
+func (r *SyncReport) String() string {
+	var b strings.Builder
+	for _, group := range []struct {
+		mark  string
+		files []string
+	}{{"+", r.Added}, {"~", r.Changed}, {"-", r.Removed}} {
+		for _, f := range group.files {
+			fmt.Fprintf(&b, "%s %s\n", group.mark, f)
+		}
+	}
+	fmt.Fprintf(&b, "%d added, %d changed, %d removed, %d unchanged; %d chunks indexed, %d deleted",
+		len(r.Added), len(r.Changed), len(r.Removed), r.Unchanged, r.ChunksIndexed, r.ChunksDeleted)
+	return b.String()
+}
+
+// Sync brings store in line with the files of a directory: new and edited files are indexed,
+// chunks that edited files no longer have are deleted, and so are the chunks of deleted
+// files. Unchanged files are skipped. The manifest is saved after every file, so a failed
+// run resumes where it stopped. store may be nil when dry running.
+func Sync(ctx context.Context, store *vectorstore.Store, opts *SyncOptions) (*SyncReport, error) {
+	dir, err := filepath.Abs(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+	manifestPath := opts.Manifest
+	if manifestPath == "" {
+		manifestPath = filepath.Join(dir, DefaultManifestName)
+	}
+	if manifestPath, err = filepath.Abs(manifestPath); err != nil {
+		return nil, err
+	}
+	match := opts.Match
+	if match == nil {
+		match = loader.Supported
+	}
+	indexed := func(path string) bool {
+		return path != manifestPath && path != manifestPath+".tmp" && match(path)
+	}
+
+	manifest, err := LoadManifest(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	current, err := hashFiles(dir, indexed)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SyncReport{}
+	for rel, hash := range current {
+		old, ok := manifest.Files[rel]
+		switch {
+		case !ok:
+			report.Added = append(report.Added, rel)
+		case old.Hash != hash:
+			report.Changed = append(report.Changed, rel)
+		default:
+			report.Unchanged++
+		}
+	}
+	for rel, old := range manifest.Files {
+		if _, ok := current[rel]; !ok {
+			report.Removed = append(report.Removed, rel)
+			report.ChunksDeleted += len(old.Chunks)
+		}
+	}
+	sort.Strings(report.Added)
+	sort.Strings(report.Changed)
+	sort.Strings(report.Removed)
+	if opts.DryRun {
+		return report, nil
+	}
+	if store == nil {
+		return nil, errors.New("store is required")
+	}
+
+	runner, err := BuildKnowledgeIndexing(ctx, WithStore(store))
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range append(append([]string{}, report.Added...), report.Changed...) {
+		ids, err := runner.Invoke(ctx, document.Source{URI: filepath.Join(dir, filepath.FromSlash(rel))})
+		if err != nil {
+			return report, fmt.Errorf("failed to index %s: %w", rel, err)
+		}
+		ids = dedup(ids)
+		report.ChunksIndexed += len(ids)
+
+		if old, ok := manifest.Files[rel]; ok {
+			stale := subtract(old.Chunks, ids)
+			if err := store.Delete(ctx, stale...); err != nil {
+				return report, fmt.Errorf("failed to delete stale chunks of %s: %w", rel, err)
+			}
+			report.ChunksDeleted += len(stale)
+		}
+		manifest.Files[rel] = &ManifestFile{Hash: current[rel], Chunks: ids, IndexedAt: time.Now()}
+		if err := manifest.Save(manifestPath); err != nil {
+			return report, err
+		}
+	}
+	for _, rel := range report.Removed {
+		if err := store.Delete(ctx, manifest.Files[rel].Chunks...); err != nil {
+			return report, fmt.Errorf("failed to delete chunks of %s: %w", rel, err)
+		}
+		delete(manifest.Files, rel)
+		if err := manifest.Save(manifestPath); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// hashFiles hashes the matching files under dir by their slash-separated relative path.
+func hashFiles(dir string, match func(string) bool) (map[string]string, error) {
+	hashes := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !match(path) {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		hashes[filepath.ToSlash(rel)] = hex.EncodeToString(h.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk dir failed: %w", err)
+	}
+	return hashes, nil
+}
+
+func dedup(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	res := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			res = append(res, id)
+		}
+	}
+	return res
+}
+
+// subtract returns the ids of a that are not in b.
+func subtract(a, b []string) []string {
+	keep := make(map[string]bool, len(b))
+	for _, id := range b {
+		keep[id] = true
+	}
+	var res []string
+	for _, id := range a {
+		if !keep[id] {
+			res = append(res, id)
+		}
+	}
+	return res
+}
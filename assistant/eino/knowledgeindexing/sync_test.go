@@ -0,0 +1,128 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package knowledgeindexing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"likeeino/pkg/vectorstore"
+)
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	write("a.md", "# Graph\nA graph has nodes.\n\n# Chain\nA chain has no branches.\n")
+	write("b.md", "# Agent\nAn agent calls tools.\n")
+	write("image.png", "not indexed")
+
+	mem := vectorstore.NewMemory()
+	store, err := vectorstore.New(ctx, &vectorstore.Config{Backend: vectorstore.BackendMemory, Embedding: keywordEmbedder{}, Memory: mem})
+	require.NoError(t, err)
+	opts := &SyncOptions{Dir: dir}
+
+	report, err := Sync(ctx, store, opts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.md", "b.md"}, report.Added)
+	assert.Equal(t, 3, report.ChunksIndexed)
+	assert.Equal(t, 3, mem.Len())
+
+	manifest, err := LoadManifest(filepath.Join(dir, DefaultManifestName))
+	require.NoError(t, err)
+	require.Contains(t, manifest.Files, "a.md")
+	assert.Len(t, manifest.Files["a.md"].Chunks, 2)
+
+	// nothing changed
+	report, err = Sync(ctx, store, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Unchanged)
+	assert.Zero(t, report.ChunksIndexed)
+
+	// an edited section replaces its chunk, the untouched one keeps its id
+	write("a.md", "# Graph\nA graph has nodes.\n\n# Chain\nA chain is a straight graph.\n")
+	report, err = Sync(ctx, store, opts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.md"}, report.Changed)
+	assert.Equal(t, 2, report.ChunksIndexed)
+	assert.Equal(t, 1, report.ChunksDeleted)
+	assert.Equal(t, 3, mem.Len())
+
+	// a dry run reports the deletion without doing it
+	require.NoError(t, os.Remove(filepath.Join(dir, "b.md")))
+	report, err = Sync(ctx, nil, &SyncOptions{Dir: dir, DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b.md"}, report.Removed)
+	assert.Equal(t, 1, report.ChunksDeleted)
+	assert.Equal(t, 3, mem.Len())
+	assert.Contains(t, report.String(), "- b.md")
+
+	report, err = Sync(ctx, store, opts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b.md"}, report.Removed)
+	assert.Equal(t, 2, mem.Len())
+	manifest, err = LoadManifest(filepath.Join(dir, DefaultManifestName))
+	require.NoError(t, err)
+	assert.NotContains(t, manifest.Files, "b.md")
+}
+
+func TestReindexIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "a.md")
+	require.NoError(t, os.WriteFile(path, []byte("# Graph\nA graph.\n\n# Chain\nA chain.\n"), 0644))
+
+	conf := &vectorstore.Config{Backend: vectorstore.BackendMemory, Embedding: keywordEmbedder{}, Memory: vectorstore.NewMemory()}
+	runner, err := BuildKnowledgeIndexing(ctx, WithVectorStore(conf))
+	require.NoError(t, err)
+	first, err := runner.Invoke(ctx, document.Source{URI: path})
+	require.NoError(t, err)
+	second, err := runner.Invoke(ctx, document.Source{URI: path})
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Equal(t, 2, conf.Memory.Len())
+}
+
+func TestSyncKeepsIdenticalRows(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.csv"), []byte("name,kind\ngraph,node\ngraph,node\ngraph,node\n"), 0644))
+
+	mem := vectorstore.NewMemory()
+	store, err := vectorstore.New(ctx, &vectorstore.Config{Backend: vectorstore.BackendMemory, Embedding: keywordEmbedder{}, Memory: mem})
+	require.NoError(t, err)
+
+	report, err := Sync(ctx, store, &SyncOptions{Dir: dir})
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.ChunksIndexed)
+	assert.Equal(t, 3, mem.Len())
+
+	docs, err := assignChunkIDs(ctx, []*schema.Document{{Content: "same"}, {Content: "same"}, {Content: "other"}})
+	require.NoError(t, err)
+	assert.NotEqual(t, docs[0].ID, docs[1].ID)
+	again, err := assignChunkIDs(ctx, []*schema.Document{{Content: "same"}, {Content: "same"}, {Content: "other"}})
+	require.NoError(t, err)
+	assert.Equal(t, docs[1].ID, again[1].ID)
+}
@@ -19,21 +19,55 @@ package knowledgeindexing
 import (
 	"context"
 
-	"github.com/cloudwego/eino-ext/components/document/transformer/splitter/markdown"
 	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/schema"
+
+	"likeeino/pkg/loader"
+	"likeeino/pkg/splitter"
 )
 
-// newDocumentTransformer component initialization function of node 'MarkdownSplitter' in graph 'KnowledgeIndexing'
+// newDocumentTransformer component initialization function of node 'Splitter' in graph 'KnowledgeIndexing'
 func newDocumentTransformer(ctx context.Context) (tfr document.Transformer, err error) {
-	// TODO Modify component configuration here.
-	config := &markdown.HeaderConfig{
-		Headers: map[string]string{
-			"#": "title",
-		},
-		TrimHeaders: false}
-	tfr, err = markdown.NewHeaderSplitter(ctx, config)
+	recursive, err := splitter.NewRecursiveSplitter(&splitter.RecursiveConfig{
+		ChunkSize: 1000,
+		Overlap:   100,
+	})
 	if err != nil {
 		return nil, err
 	}
-	return tfr, nil
+	return &chunker{
+		headers:   splitter.NewHeaderSplitter(&splitter.HeaderConfig{MaxLevel: 3}),
+		recursive: recursive,
+	}, nil
+}
+
+// chunker splits markdown (including HTML and DOCX, which the loader converts to it) by
+// headers first, then every document down to the chunk size. Spreadsheet rows and Go
+// declarations arrive as documents of their own and are only split when too long.
+type chunker struct {
+	headers   *splitter.HeaderSplitter
+	recursive *splitter.RecursiveSplitter
+}
+
+func (c *chunker) Transform(ctx context.Context, docs []*schema.Document, opts ...document.TransformerOption) ([]*schema.Document, error) {
+	var res []*schema.Document
+	for _, doc := range docs {
+		parts := []*schema.Document{doc}
+		if doc.MetaData[loader.MetaKeyFormat] == loader.FormatMarkdown {
+			var err error
+			if parts, err = c.headers.Transform(ctx, parts, opts...); err != nil {
+				return nil, err
+			}
+		}
+		parts, err := c.recursive.Transform(ctx, parts, opts...)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, parts...)
+	}
+	return res, nil
+}
+
+func (c *chunker) GetType() string {
+	return "Chunker"
 }
@@ -2,19 +2,19 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	clc "github.com/cloudwego/eino-ext/callbacks/cozeloop"
 	"github.com/cloudwego/eino/callbacks"
 	"github.com/coze-dev/cozeloop-go"
 	"github.com/joho/godotenv"
-	"io/fs"
 	"likeeino/assistant/eino/knowledgeindexing"
+	_ "likeeino/pkg/vectorstore/milvus"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
 
-	"github.com/cloudwego/eino/components/document"
 	"github.com/cloudwego/eino/components/embedding"
 	"github.com/redis/go-redis/v9"
 )
@@ -24,7 +24,7 @@ import (
 //	env.MustHasEnvs("ARK_API_KEY", "ARK_EMBEDDING_MODEL")
 //}
 
-// 将markdown文件写入到向量数据库,即文件向量化
+// 将文档(markdown、html、pdf、docx、xlsx、csv、go 等)写入到向量数据库,即文件向量化
 
 func main() {
 	cozeloopApiToken := os.Getenv("COZELOOP_API_TOKEN")
@@ -50,50 +50,41 @@ func main() {
 		fmt.Println("获取工作目录失败:", err)
 		return
 	}
-	fileDir := filepath.Join(wd, "example/knowledgeindexing/eino-docs")
-
-	err = indexMarkdownFiles(ctx, fileDir)
-	//err := indexMarkdownFiles(ctx, "/Users/huangzehui/learn/learn/likeeino/example/knowledgeindexing/eino-docs")
+	fileDir := flag.String("dir", filepath.Join(wd, "example/knowledgeindexing/eino-docs"), "directory of the documents to index")
+	manifest := flag.String("manifest", "", "manifest of what has been indexed, <dir>/"+knowledgeindexing.DefaultManifestName+" by default")
+	dryRun := flag.Bool("dry-run", false, "report what would change without indexing")
+	flag.Parse()
+
+	report, err := indexMarkdownFiles(ctx, *fileDir, *manifest, *dryRun)
+	if report != nil {
+		fmt.Println(report)
+	}
 	if err != nil {
 		panic(err)
 	}
 
-	fmt.Println("index success")
+	if !*dryRun {
+		fmt.Println("index success")
+	}
 }
 
-func indexMarkdownFiles(ctx context.Context, dir string) error {
-	runner, err := knowledgeindexing.BuildKnowledgeIndexing(ctx)
-	if err != nil {
-		return fmt.Errorf("build index graph failed: %w", err)
+// indexMarkdownFiles 只索引新增或修改过的文件,并删除已删除或修改的文件留下的切片
+func indexMarkdownFiles(ctx context.Context, dir, manifest string, dryRun bool) (*knowledgeindexing.SyncReport, error) {
+	opts := &knowledgeindexing.SyncOptions{
+		Dir:      dir,
+		Manifest: manifest,
+		DryRun:   dryRun,
+	}
+	if dryRun {
+		return knowledgeindexing.Sync(ctx, nil, opts)
 	}
 
-	// 遍历 dir 下的所有 markdown 文件
-	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return fmt.Errorf("walk dir failed: %w", err)
-		}
-		if d.IsDir() {
-			return nil
-		}
-
-		if !strings.HasSuffix(path, ".md") {
-			fmt.Printf("[skip] not a markdown file: %s\n", path)
-			return nil
-		}
-
-		fmt.Printf("[start] indexing file: %s\n", path)
-
-		ids, err := runner.Invoke(ctx, document.Source{URI: path})
-		if err != nil {
-			return fmt.Errorf("invoke index graph failed: %w", err)
-		}
-
-		fmt.Printf("[done] indexing file: %s, len of parts: %d\n", path, len(ids))
-
-		return nil
-	})
-
-	return err
+	store, err := knowledgeindexing.OpenStore(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("open vector store failed: %w", err)
+	}
+	defer store.Close()
+	return knowledgeindexing.Sync(ctx, store, opts)
 }
 
 type RedisVectorStoreConfig struct {
@@ -0,0 +1,152 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package loader
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cloudwego/eino/components/document/parser"
+	"github.com/cloudwego/eino/schema"
+)
+
+// DOCXParser reads the body of a Word document as markdown: Title and Heading paragraphs
+// become # headers and table rows cells separated by |.
+type DOCXParser struct{}
+
+func (DOCXParser) Parse(ctx context.Context, reader io.Reader, opts ...parser.Option) ([]*schema.Document, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid docx: %w", err)
+	}
+	f, err := zr.Open("word/document.xml")
+	if err != nil {
+		return nil, fmt.Errorf("invalid docx: %w", err)
+	}
+	defer f.Close()
+
+	text, err := docxText(xml.NewDecoder(f))
+	if err != nil {
+		return nil, fmt.Errorf("invalid docx: %w", err)
+	}
+	return []*schema.Document{newDoc(text, map[string]any{MetaKeyFormat: FormatMarkdown}, opts...)}, nil
+}
+
+// docxText walks the WordprocessingML body. Elements are matched by local name, the w:
+// namespace being the only one carrying text.
+func docxText(dec *xml.Decoder) (string, error) {
+	var (
+		b          strings.Builder
+		para       strings.Builder
+		style      string
+		tableDepth int
+		cells      []string
+	)
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				para.Reset()
+				style = ""
+			case "pStyle":
+				style = attr(t, "val")
+			case "tab":
+				para.WriteString("\t")
+			case "br", "cr":
+				para.WriteString("\n")
+			case "tbl":
+				tableDepth++
+			case "tr":
+				cells = cells[:0]
+			case "tc":
+				cells = append(cells, "")
+			case "t":
+				var s string
+				if err := dec.DecodeElement(&s, &t); err != nil {
+					return "", err
+				}
+				para.WriteString(s)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "p":
+				line := strings.TrimSpace(para.String())
+				if tableDepth > 0 && len(cells) > 0 {
+					cells[len(cells)-1] = strings.TrimSpace(cells[len(cells)-1] + " " + line)
+					continue
+				}
+				if line == "" {
+					continue
+				}
+				if level := headingLevel(style); level > 0 {
+					line = strings.Repeat("#", level) + " " + line
+				}
+				b.WriteString(line + "\n\n")
+			case "tr":
+				if tableDepth == 1 {
+					b.WriteString(strings.Join(cells, " | ") + "\n")
+				}
+			case "tbl":
+				tableDepth--
+				if tableDepth == 0 {
+					b.WriteString("\n")
+				}
+			}
+		}
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// headingLevel maps the built-in paragraph styles Title and Heading1-6 to header levels.
+func headingLevel(style string) int {
+	switch {
+	case style == "Title":
+		return 1
+	case strings.HasPrefix(style, "Heading") && len(style) == len("Heading")+1:
+		if n := int(style[len(style)-1] - '0'); n >= 1 && n <= 6 {
+			return n
+		}
+	}
+	return 0
+}
+
+func attr(e xml.StartElement, local string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package loader
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"strings"
+
+	einoparser "github.com/cloudwego/eino/components/document/parser"
+	"github.com/cloudwego/eino/schema"
+)
+
+// GoParser makes every top-level declaration, with its doc comment, a document. Imports are
+// dropped. A file that does not parse is kept whole.
+type GoParser struct{}
+
+func (GoParser) Parse(ctx context.Context, reader io.Reader, opts ...einoparser.Option) ([]*schema.Document, error) {
+	src, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return []*schema.Document{newDoc(string(src), nil, opts...)}, nil
+	}
+
+	pkg := file.Name.Name
+	var docs []*schema.Document
+	for _, decl := range file.Decls {
+		symbol, kind := declName(decl)
+		if kind == "" {
+			continue
+		}
+		start := decl.Pos()
+		if doc := declDoc(decl); doc != nil {
+			start = doc.Pos()
+		}
+		content := "package " + pkg + "\n\n" + string(src[fset.Position(start).Offset:fset.Position(decl.End()).Offset])
+		docs = append(docs, newDoc(content, map[string]any{
+			MetaKeySymbol: symbol,
+			MetaKeyKind:   kind,
+			MetaKeyLine:   fset.Position(start).Line,
+		}, opts...))
+	}
+	return docs, nil
+}
+
+func declDoc(decl ast.Decl) *ast.CommentGroup {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		return d.Doc
+	case *ast.GenDecl:
+		return d.Doc
+	}
+	return nil
+}
+
+// declName names a declaration: Recv.Method for methods, the first name of a group
+// otherwise. kind is empty for imports.
+func declName(decl ast.Decl) (symbol, kind string) {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if d.Recv == nil || len(d.Recv.List) == 0 {
+			return d.Name.Name, "func"
+		}
+		return recvName(d.Recv.List[0].Type) + "." + d.Name.Name, "method"
+	case *ast.GenDecl:
+		if d.Tok == token.IMPORT || len(d.Specs) == 0 {
+			return "", ""
+		}
+		var names []string
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				names = append(names, s.Name.Name)
+			case *ast.ValueSpec:
+				for _, n := range s.Names {
+					names = append(names, n.Name)
+				}
+			}
+		}
+		return strings.Join(names, ", "), d.Tok.String()
+	}
+	return "", ""
+}
+
+func recvName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return recvName(e.X)
+	case *ast.IndexExpr:
+		return recvName(e.X)
+	case *ast.IndexListExpr:
+		return recvName(e.X)
+	case *ast.Ident:
+		return e.Name
+	}
+	return ""
+}
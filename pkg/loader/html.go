@@ -0,0 +1,155 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package loader
+
+import (
+	"context"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/cloudwego/eino/components/document/parser"
+	"github.com/cloudwego/eino/schema"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// MetaKeyDocTitle is the <title> of an HTML page.
+const MetaKeyDocTitle = "doc_title"
+
+// HTMLParser converts a page to markdown: headings become # headers, list items - items,
+// table rows cells separated by |, and pre blocks code fences. Scripts, styles and the head
+// are dropped.
+type HTMLParser struct{}
+
+func (HTMLParser) Parse(ctx context.Context, reader io.Reader, opts ...parser.Option) ([]*schema.Document, error) {
+	root, err := html.Parse(reader)
+	if err != nil {
+		return nil, err
+	}
+	c := &htmlConverter{}
+	c.walk(root)
+	meta := map[string]any{MetaKeyFormat: FormatMarkdown}
+	if c.title != "" {
+		meta[MetaKeyDocTitle] = c.title
+	}
+	return []*schema.Document{newDoc(tidyLines(c.b.String()), meta, opts...)}, nil
+}
+
+type htmlConverter struct {
+	b     strings.Builder
+	title string
+	pre   bool
+}
+
+var spaces = regexp.MustCompile(`\s+`)
+
+func (c *htmlConverter) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		if c.pre {
+			c.b.WriteString(n.Data)
+		} else {
+			c.b.WriteString(spaces.ReplaceAllString(n.Data, " "))
+		}
+		return
+	case html.ElementNode:
+	default:
+		c.children(n)
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Noscript, atom.Template, atom.Svg, atom.Iframe:
+		return
+	case atom.Head:
+		for t := range n.Descendants() {
+			if t.DataAtom == atom.Title && t.FirstChild != nil {
+				c.title = strings.TrimSpace(t.FirstChild.Data)
+				break
+			}
+		}
+		return
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(n.Data[1] - '0')
+		c.b.WriteString("\n\n" + strings.Repeat("#", level) + " ")
+		c.children(n)
+		c.b.WriteString("\n\n")
+	case atom.Pre:
+		c.b.WriteString("\n\n```\n")
+		c.pre = true
+		c.children(n)
+		c.pre = false
+		c.b.WriteString("\n```\n\n")
+	case atom.Li:
+		c.b.WriteString("\n- ")
+		c.children(n)
+	case atom.Br:
+		c.b.WriteString("\n")
+	case atom.Tr:
+		c.b.WriteString("\n")
+		first := true
+		for cell := n.FirstChild; cell != nil; cell = cell.NextSibling {
+			if cell.DataAtom != atom.Td && cell.DataAtom != atom.Th {
+				continue
+			}
+			if !first {
+				c.b.WriteString(" | ")
+			}
+			first = false
+			c.children(cell)
+		}
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Main, atom.Header, atom.Footer,
+		atom.Ul, atom.Ol, atom.Table, atom.Blockquote, atom.Dl, atom.Dt, atom.Dd, atom.Hr:
+		c.b.WriteString("\n\n")
+		c.children(n)
+		c.b.WriteString("\n\n")
+	default:
+		c.children(n)
+	}
+}
+
+func (c *htmlConverter) children(n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		c.walk(child)
+	}
+}
+
+// tidyLines trims every line and collapses runs of blank lines into one, leaving code fences
+// untouched.
+func tidyLines(text string) string {
+	var res []string
+	fenced, blank := false, true
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fenced = !fenced
+			line = strings.TrimSpace(line)
+		} else if !fenced {
+			line = strings.TrimSpace(line)
+		}
+		if line == "" && !fenced {
+			if !blank {
+				res = append(res, "")
+			}
+			blank = true
+			continue
+		}
+		res = append(res, line)
+		blank = false
+	}
+	return strings.TrimSpace(strings.Join(res, "\n"))
+}
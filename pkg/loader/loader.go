@@ -0,0 +1,119 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package loader parses the document formats of the knowledge base: HTML, PDF, DOCX, XLSX,
+// CSV and Go source, besides plain text and markdown. Each parser implements
+// parser.Parser; NewParser dispatches between them by file extension.
+package loader
+
+import (
+	"context"
+	"io"
+	"maps"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cloudwego/eino-ext/components/document/loader/file"
+	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/components/document/parser"
+	"github.com/cloudwego/eino/schema"
+)
+
+const (
+	// MetaKeyFormat is "markdown" on documents whose content is markdown, including HTML and
+	// DOCX converted to it, so a header splitter can split them.
+	MetaKeyFormat = "_format"
+	// MetaKeyPage is the 1-based page of a PDF document.
+	MetaKeyPage = "page"
+	// MetaKeySheet and MetaKeyRow locate a spreadsheet row; the row is 1-based and counts the
+	// header.
+	MetaKeySheet = "sheet"
+	MetaKeyRow   = "row"
+	// MetaKeySymbol, MetaKeyKind and MetaKeyLine describe a Go declaration.
+	MetaKeySymbol = "symbol"
+	MetaKeyKind   = "kind"
+	MetaKeyLine   = "line"
+
+	FormatMarkdown = "markdown"
+)
+
+var parsers = map[string]parser.Parser{
+	".md":       markdownParser{},
+	".markdown": markdownParser{},
+	".txt":      parser.TextParser{},
+	".html":     HTMLParser{},
+	".htm":      HTMLParser{},
+	".pdf":      PDFParser{},
+	".docx":     DOCXParser{},
+	".xlsx":     XLSXParser{},
+	".csv":      CSVParser{},
+	".go":       GoParser{},
+}
+
+// Extensions lists the extensions NewParser understands.
+func Extensions() []string {
+	exts := make([]string, 0, len(parsers))
+	for ext := range parsers {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	return exts
+}
+
+// Supported reports whether the extension of path, in any case, has a parser.
+func Supported(path string) bool {
+	_, ok := parsers[strings.ToLower(filepath.Ext(path))]
+	return ok
+}
+
+// NewParser returns a parser picking the parser of the extension of the URI, in any case.
+// Other files are read as plain text.
+func NewParser(ctx context.Context) (parser.Parser, error) {
+	return extParser{fallback: parser.TextParser{}}, nil
+}
+
+// extParser is parser.ExtParser matching extensions in any case, as Supported does.
+type extParser struct {
+	fallback parser.Parser
+}
+
+func (p extParser) Parse(ctx context.Context, reader io.Reader, opts ...parser.Option) ([]*schema.Document, error) {
+	options := parser.GetCommonOptions(&parser.Options{}, opts...)
+	ps, ok := parsers[strings.ToLower(filepath.Ext(options.URI))]
+	if !ok {
+		ps = p.fallback
+	}
+	return ps.Parse(ctx, reader, opts...)
+}
+
+// NewLoader returns a file loader parsing every supported format.
+func NewLoader(ctx context.Context) (document.Loader, error) {
+	p, err := NewParser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return file.NewFileLoader(ctx, &file.FileLoaderConfig{Parser: p})
+}
+
+// newDoc creates a document with the extra metadata of the parse options.
+func newDoc(content string, meta map[string]any, opts ...parser.Option) *schema.Document {
+	options := parser.GetCommonOptions(&parser.Options{}, opts...)
+	doc := &schema.Document{Content: content, MetaData: make(map[string]any, len(meta)+len(options.ExtraMeta))}
+	maps.Copy(doc.MetaData, options.ExtraMeta)
+	maps.Copy(doc.MetaData, meta)
+	return doc
+}
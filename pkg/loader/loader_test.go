@@ -0,0 +1,158 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package loader
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/components/document/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestHTMLParser(t *testing.T) {
+	page := `<html><head><title> Eino Guide </title><style>p{}</style></head><body>
+<h1>Graph</h1><p>Build   a
+graph.</p><script>alert(1)</script>
+<h2>Nodes</h2><ul><li>lambda</li><li>model</li></ul>
+<table><tr><th>name</th><th>kind</th></tr><tr><td>chat</td><td>model</td></tr></table>
+<pre>g := compose.NewGraph()
+  g.Compile()</pre></body></html>`
+	docs, err := HTMLParser{}.Parse(context.Background(), strings.NewReader(page), parser.WithExtraMeta(map[string]any{"_source": "a.html"}))
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "# Graph\n\nBuild a graph.\n\n## Nodes\n\n- lambda\n- model\n\nname | kind\nchat | model\n\n```\ng := compose.NewGraph()\n  g.Compile()\n```", docs[0].Content)
+	assert.Equal(t, "Eino Guide", docs[0].MetaData[MetaKeyDocTitle])
+	assert.Equal(t, FormatMarkdown, docs[0].MetaData[MetaKeyFormat])
+	assert.Equal(t, "a.html", docs[0].MetaData["_source"])
+}
+
+func TestDOCXParser(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("word/document.xml")
+	require.NoError(t, err)
+	_, err = w.Write([]byte(`<?xml version="1.0"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Setup</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Install </w:t></w:r><w:r><w:t>Go.</w:t></w:r></w:p>
+<w:tbl><w:tr><w:tc><w:p><w:r><w:t>os</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>linux</w:t></w:r></w:p></w:tc></w:tr></w:tbl>
+<w:p/></w:body></w:document>`))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	docs, err := DOCXParser{}.Parse(context.Background(), &buf)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "# Setup\n\nInstall Go.\n\nos | linux", docs[0].Content)
+
+	_, err = DOCXParser{}.Parse(context.Background(), strings.NewReader("not a zip"))
+	assert.Error(t, err)
+}
+
+func TestSheetParsers(t *testing.T) {
+	ctx := context.Background()
+	docs, err := CSVParser{}.Parse(ctx, strings.NewReader("\ufeffname,owner\n\ngraph,alice\nchain,\n"))
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "name: graph\nowner: alice", docs[0].Content)
+	assert.Equal(t, 3, docs[0].MetaData[MetaKeyRow])
+	assert.Equal(t, "name: chain", docs[1].Content)
+
+	f := excelize.NewFile()
+	require.NoError(t, f.SetSheetRow("Sheet1", "A1", &[]any{"task", "", "due"}))
+	require.NoError(t, f.SetSheetRow("Sheet1", "A2", &[]any{"index docs", "x", "2025-01-01"}))
+	var buf bytes.Buffer
+	require.NoError(t, f.Write(&buf))
+	docs, err = XLSXParser{}.Parse(ctx, &buf)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "task: index docs\ncolumn 2: x\ndue: 2025-01-01", docs[0].Content)
+	assert.Equal(t, "Sheet1", docs[0].MetaData[MetaKeySheet])
+}
+
+func TestGoParser(t *testing.T) {
+	src := `package demo
+
+import "fmt"
+
+// Greeter greets.
+type Greeter struct{}
+
+// Greet prints a greeting.
+func (g *Greeter) Greet() { fmt.Println("hi") }
+
+const (
+	A = 1
+	B = 2
+)
+`
+	docs, err := GoParser{}.Parse(context.Background(), strings.NewReader(src))
+	require.NoError(t, err)
+	require.Len(t, docs, 3)
+	assert.Equal(t, "Greeter", docs[0].MetaData[MetaKeySymbol])
+	assert.Equal(t, "type", docs[0].MetaData[MetaKeyKind])
+	assert.Equal(t, "package demo\n\n// Greeter greets.\ntype Greeter struct{}", docs[0].Content)
+	assert.Equal(t, "Greeter.Greet", docs[1].MetaData[MetaKeySymbol])
+	assert.Equal(t, "method", docs[1].MetaData[MetaKeyKind])
+	assert.Equal(t, 8, docs[1].MetaData[MetaKeyLine])
+	assert.Equal(t, "A, B", docs[2].MetaData[MetaKeySymbol])
+
+	docs, err = GoParser{}.Parse(context.Background(), strings.NewReader("package broken {"))
+	require.NoError(t, err)
+	assert.Len(t, docs, 1)
+}
+
+func TestLoader(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ROWS.CSV")
+	require.NoError(t, os.WriteFile(path, []byte("a,b\n1,2\n3,4\n"), 0644))
+
+	l, err := NewLoader(ctx)
+	require.NoError(t, err)
+	docs, err := l.Load(ctx, document.Source{URI: path})
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, path, docs[1].MetaData["_source"])
+
+	// an extension in mixed case picks its parser too, not the text fallback
+	mixed := filepath.Join(dir, "Rows.Csv")
+	require.NoError(t, os.WriteFile(mixed, []byte("a,b\n1,2\n"), 0644))
+	docs, err = l.Load(ctx, document.Source{URI: mixed})
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, 2, docs[0].MetaData[MetaKeyRow])
+
+	assert.True(t, Supported("x.DOCX"))
+	assert.False(t, Supported("x.png"))
+	assert.Contains(t, Extensions(), ".pdf")
+
+	_, err = PDFParser{}.Parse(ctx, strings.NewReader("%PDF-broken"))
+	assert.Error(t, err)
+	// the reader panics on this one while opening it
+	_, err = PDFParser{}.Parse(ctx, strings.NewReader("%PDF-1.4\n1 0 obj\n<< /+ype /\x13Ref /Size 1 /W [1 1 1] /Length 0 >>\nst2eam\n\nendstream\nendobj\nstartxref\n9\n%%EOF\n"))
+	assert.ErrorContains(t, err, "invalid pdf")
+}
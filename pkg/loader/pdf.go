@@ -0,0 +1,65 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package loader
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cloudwego/eino/components/document/parser"
+	"github.com/cloudwego/eino/schema"
+	"github.com/ledongthuc/pdf"
+)
+
+// PDFParser extracts the text of every page as one document. Pages without text, such as
+// scans, are skipped.
+type PDFParser struct{}
+
+func (PDFParser) Parse(ctx context.Context, reader io.Reader, opts ...parser.Option) (docs []*schema.Document, err error) {
+	// the pdf library panics on some malformed files, most often while reading the trailer
+	defer func() {
+		if p := recover(); p != nil {
+			docs, err = nil, fmt.Errorf("invalid pdf: %v", p)
+		}
+	}()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	r, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid pdf: %w", err)
+	}
+	for i := 1; i <= r.NumPage(); i++ {
+		page := r.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		text, err := page.GetPlainText(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read page %d: %w", i, err)
+		}
+		if text = strings.TrimSpace(text); text == "" {
+			continue
+		}
+		docs = append(docs, newDoc(text, map[string]any{MetaKeyPage: i}, opts...))
+	}
+	return docs, nil
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package loader
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cloudwego/eino/components/document/parser"
+	"github.com/cloudwego/eino/schema"
+	"github.com/xuri/excelize/v2"
+)
+
+// XLSXParser makes every row of every sheet a document of "header: value" lines, the first
+// non-empty row of a sheet being its header.
+type XLSXParser struct{}
+
+func (XLSXParser) Parse(ctx context.Context, reader io.Reader, opts ...parser.Option) ([]*schema.Document, error) {
+	f, err := excelize.OpenReader(reader)
+	if err != nil {
+		return nil, fmt.Errorf("invalid xlsx: %w", err)
+	}
+	defer f.Close()
+
+	var docs []*schema.Document
+	for _, sheet := range f.GetSheetList() {
+		rows, err := f.GetRows(sheet)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sheet %s: %w", sheet, err)
+		}
+		docs = append(docs, rowDocs(sheet, rows, nil, opts...)...)
+	}
+	return docs, nil
+}
+
+// CSVParser makes every row a document of "header: value" lines, the first row being the
+// header.
+type CSVParser struct{}
+
+func (CSVParser) Parse(ctx context.Context, reader io.Reader, opts ...parser.Option) ([]*schema.Document, error) {
+	r := csv.NewReader(reader)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	var (
+		rows  [][]string
+		lines []int
+	)
+	for {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv: %w", err)
+		}
+		// the reader skips blank lines, so number rows by the line they start on
+		line, _ := r.FieldPos(0)
+		rows = append(rows, row)
+		lines = append(lines, line)
+	}
+	if len(rows) > 0 && len(rows[0]) > 0 {
+		rows[0][0] = strings.TrimPrefix(rows[0][0], "\ufeff")
+	}
+	return rowDocs("", rows, lines, opts...), nil
+}
+
+// rowDocs numbers rows by lines, or by their index when lines is nil.
+func rowDocs(sheet string, rows [][]string, lines []int, opts ...parser.Option) []*schema.Document {
+	var (
+		docs   []*schema.Document
+		header []string
+	)
+	for i, row := range rows {
+		if isBlank(row) {
+			continue
+		}
+		if header == nil {
+			header = row
+			continue
+		}
+		var b strings.Builder
+		for j, v := range row {
+			if v = strings.TrimSpace(v); v == "" {
+				continue
+			}
+			name := fmt.Sprintf("column %d", j+1)
+			if j < len(header) && strings.TrimSpace(header[j]) != "" {
+				name = strings.TrimSpace(header[j])
+			}
+			fmt.Fprintf(&b, "%s: %s\n", name, v)
+		}
+		num := i + 1
+		if lines != nil {
+			num = lines[i]
+		}
+		meta := map[string]any{MetaKeyRow: num}
+		if sheet != "" {
+			meta[MetaKeySheet] = sheet
+		}
+		docs = append(docs, newDoc(strings.TrimSpace(b.String()), meta, opts...))
+	}
+	return docs
+}
+
+func isBlank(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
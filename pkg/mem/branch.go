@@ -0,0 +1,181 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// A conversation is a tree of messages. Every stored message carries its node id and the id
+// of its parent in Message.Extra, so the stores stay append-only: editing or regenerating a
+// message appends a sibling instead of rewriting history. The active branch is the path from
+// the root to the head node, which is kept in the conversation metadata.
+
+// RootID is the virtual parent of the first message; Fork(RootID) starts a new branch from scratch.
+const RootID = "root"
+
+const (
+	extraNodeID   = "_mem_id"
+	extraParentID = "_mem_parent"
+)
+
+// ErrMessageNotFound is returned by branch operations for unknown message ids.
+var ErrMessageNotFound = errors.New("message not found")
+
+// MessageNode is one message of the conversation tree.
+type MessageNode struct {
+	ID       string          `json:"id"`
+	ParentID string          `json:"parent_id"`
+	Message  *schema.Message `json:"message"`
+}
+
+type messageTree struct {
+	nodes    map[string]*MessageNode
+	order    []string
+	children map[string][]string
+}
+
+func newMessageTree() *messageTree {
+	return &messageTree{
+		nodes:    make(map[string]*MessageNode),
+		children: make(map[string][]string),
+	}
+}
+
+// buildTree rebuilds the tree from stored messages. Messages written before branching existed
+// have no ids; they form a single chain and get ids derived from their position.
+func buildTree(msgs []*schema.Message) *messageTree {
+	t := newMessageTree()
+	prev := RootID
+	for i, msg := range msgs {
+		id, _ := msg.Extra[extraNodeID].(string)
+		parent, _ := msg.Extra[extraParentID].(string)
+		if id == "" {
+			id = fmt.Sprintf("legacy-%d", i)
+			parent = prev
+		}
+		if parent == "" {
+			parent = RootID
+		}
+		if _, dup := t.nodes[id]; dup {
+			continue
+		}
+		t.add(&MessageNode{ID: id, ParentID: parent, Message: cleanMessage(msg)})
+		prev = id
+	}
+	return t
+}
+
+func (t *messageTree) add(n *MessageNode) {
+	t.nodes[n.ID] = n
+	t.order = append(t.order, n.ID)
+	t.children[n.ParentID] = append(t.children[n.ParentID], n.ID)
+}
+
+func (t *messageTree) has(id string) bool {
+	if id == RootID {
+		return true
+	}
+	_, ok := t.nodes[id]
+	return ok
+}
+
+// path returns the nodes from the root down to head.
+func (t *messageTree) path(head string) []*MessageNode {
+	var rev []*MessageNode
+	for id := head; id != RootID; {
+		n, ok := t.nodes[id]
+		if !ok {
+			break
+		}
+		rev = append(rev, n)
+		id = n.ParentID
+	}
+	res := make([]*MessageNode, len(rev))
+	for i, n := range rev {
+		res[len(rev)-1-i] = n
+	}
+	return res
+}
+
+// latestLeaf follows the most recently created child from id down to a leaf.
+func (t *messageTree) latestLeaf(id string) string {
+	for {
+		kids := t.children[id]
+		if len(kids) == 0 {
+			return id
+		}
+		id = kids[len(kids)-1]
+	}
+}
+
+// defaultHead is the head used when none is recorded: the last stored message.
+func (t *messageTree) defaultHead() string {
+	if len(t.order) == 0 {
+		return RootID
+	}
+	return t.order[len(t.order)-1]
+}
+
+// cleanMessage returns msg without the tree bookkeeping, which must not reach the model.
+func cleanMessage(msg *schema.Message) *schema.Message {
+	_, hasID := msg.Extra[extraNodeID]
+	_, hasParent := msg.Extra[extraParentID]
+	if !hasID && !hasParent {
+		return msg
+	}
+	cp := *msg
+	cp.Extra = maps.Clone(msg.Extra)
+	delete(cp.Extra, extraNodeID)
+	delete(cp.Extra, extraParentID)
+	if len(cp.Extra) == 0 {
+		cp.Extra = nil
+	}
+	return &cp
+}
+
+// taggedMessage returns a copy of msg carrying its node id and parent for storage.
+func taggedMessage(msg *schema.Message, id, parent string) *schema.Message {
+	cp := *msg
+	cp.Extra = maps.Clone(msg.Extra)
+	if cp.Extra == nil {
+		cp.Extra = make(map[string]any, 2)
+	}
+	cp.Extra[extraNodeID] = id
+	cp.Extra[extraParentID] = parent
+	return &cp
+}
+
+// nodeIDOf returns the node id of a stored message at position i of the journal.
+func nodeIDOf(msg *schema.Message, i int) string {
+	if id, ok := msg.Extra[extraNodeID].(string); ok && id != "" {
+		return id
+	}
+	return fmt.Sprintf("legacy-%d", i)
+}
+
+func nodeMessages(nodes []*MessageNode) []*schema.Message {
+	msgs := make([]*schema.Message, len(nodes))
+	for i, n := range nodes {
+		msgs[i] = n.Message
+	}
+	return msgs
+}
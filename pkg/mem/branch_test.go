@@ -0,0 +1,117 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConversationBranching(t *testing.T) {
+	dir := t.TempDir()
+	m := NewSimpleMemory(SimpleMemoryConfig{Dir: dir})
+	c := m.GetConversation("b", true)
+
+	assert.NoError(t, c.Append(schema.UserMessage("q1")))
+	assert.NoError(t, c.Append(schema.AssistantMessage("a1", nil)))
+	assert.NoError(t, c.Append(schema.UserMessage("q2")))
+	assert.NoError(t, c.Append(schema.AssistantMessage("a2", nil)))
+	q2 := c.MessageIDs[2]
+	a2 := c.MessageIDs[3]
+
+	// edit q2: continue from its parent
+	assert.NoError(t, c.Fork(c.MessageIDs[1]))
+	assert.NoError(t, c.Append(schema.UserMessage("q2 edited")))
+	assert.NoError(t, c.Append(schema.AssistantMessage("a2 edited", nil)))
+	assert.Equal(t, []string{"q1", "a1", "q2 edited", "a2 edited"}, contents(c.GetFullMessages()))
+	assert.Len(t, c.Nodes(), 6)
+	assert.Equal(t, 4, c.Metadata().MessageCount)
+	assert.Equal(t, 6, c.Metadata().NodeCount)
+
+	// regenerate a2: fork at q2 and append a sibling answer
+	assert.NoError(t, c.Checkout(q2))
+	assert.Equal(t, a2, c.Head)
+	assert.NoError(t, c.Fork(q2))
+	assert.NoError(t, c.Append(schema.AssistantMessage("a2 retry", nil)))
+	assert.Equal(t, []string{"q1", "a1", "q2", "a2 retry"}, contents(c.GetFullMessages()))
+	assert.Equal(t, c.MessageIDs[2], q2)
+
+	// the head and the tree survive a reload, the bookkeeping never reaches the messages
+	reloaded := NewSimpleMemory(SimpleMemoryConfig{Dir: dir}).GetConversation("b", false)
+	assert.Equal(t, []string{"q1", "a1", "q2", "a2 retry"}, contents(reloaded.GetFullMessages()))
+	assert.Len(t, reloaded.Nodes(), 7)
+	for _, msg := range reloaded.GetFullMessages() {
+		assert.Nil(t, msg.Extra)
+	}
+
+	// checkout an older branch, then roll back
+	assert.NoError(t, reloaded.Checkout(a2))
+	assert.Equal(t, []string{"q1", "a1", "q2", "a2"}, contents(reloaded.GetFullMessages()))
+	assert.NoError(t, reloaded.Truncate(q2))
+	assert.Equal(t, []string{"q1", "a1"}, contents(reloaded.GetFullMessages()))
+	assert.NoError(t, reloaded.Fork(RootID))
+	assert.Empty(t, reloaded.GetFullMessages())
+
+	assert.ErrorIs(t, reloaded.Fork("missing"), ErrMessageNotFound)
+	assert.ErrorIs(t, reloaded.Checkout("missing"), ErrMessageNotFound)
+	assert.ErrorIs(t, reloaded.Truncate(RootID), ErrMessageNotFound)
+}
+
+func TestLegacyConversationBranching(t *testing.T) {
+	dir := t.TempDir()
+	legacy := `{"role":"user","content":"q1"}` + "\n" + `{"role":"assistant","content":"a1"}` + "\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "old.jsonl"), []byte(legacy), 0644))
+
+	c := NewSimpleMemory(SimpleMemoryConfig{Dir: dir}).GetConversation("old", false)
+	assert.Equal(t, []string{"legacy-0", "legacy-1"}, c.MessageIDs)
+	assert.Equal(t, "legacy-0", c.LastMessageID(schema.User))
+
+	assert.NoError(t, c.Fork("legacy-0"))
+	assert.NoError(t, c.Append(schema.AssistantMessage("a1 retry", nil)))
+
+	c = NewSimpleMemory(SimpleMemoryConfig{Dir: dir}).GetConversation("old", false)
+	assert.Equal(t, []string{"q1", "a1 retry"}, contents(c.GetFullMessages()))
+	assert.Len(t, c.Nodes(), 3)
+}
+
+func TestSummaryFollowsBranch(t *testing.T) {
+	cm := &fakeChatModel{answer: func([]*schema.Message) string { return "summary" }}
+	w := NewSummaryWindow(cm, 20)
+	w.Counter = func(*schema.Message) int { return 5 }
+	m := NewSimpleMemory(SimpleMemoryConfig{Dir: t.TempDir(), Window: w})
+	c := m.GetConversation("s", true)
+	for _, s := range []string{"1", "2", "3", "4", "5", "6"} {
+		assert.NoError(t, c.Append(schema.UserMessage(s)))
+	}
+	_, err := c.GetWindowMessages(context.Background())
+	assert.NoError(t, err)
+	sum := c.Summary()
+	assert.NotZero(t, sum.Covered)
+	assert.Equal(t, c.MessageIDs[sum.Covered-1], sum.Anchor)
+
+	// a branch that diverges inside the summarized part must not reuse the summary
+	assert.NoError(t, c.Fork(RootID))
+	assert.NoError(t, c.Append(schema.UserMessage("x")))
+	msgs, err := c.GetWindowMessages(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"x"}, contents(msgs))
+}
@@ -0,0 +1,190 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// A journal record is one line: the CRC-32C of the payload as 8 hex digits, a tab, and the
+// JSON encoded message. Lines starting with '{' are records written before checksums were
+// introduced and are accepted as they are.
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// SyncPolicy controls when appended records are fsynced to disk.
+type SyncPolicy int
+
+const (
+	// SyncNone leaves flushing to the OS, a crash may lose the latest records.
+	SyncNone SyncPolicy = iota
+	// SyncAlways fsyncs after every append.
+	SyncAlways
+	// SyncInterval fsyncs on append when the last fsync of the file is older than the interval.
+	// Records written in between are flushed by the next append, or by FileStore.Sync which
+	// runs once the interval has passed and on FileStore.Close.
+	SyncInterval
+)
+
+var errBadChecksum = errors.New("checksum mismatch")
+
+func encodeRecord(msg *schema.Message) ([]byte, error) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal message: %w", err)
+	}
+	line := make([]byte, 0, len(payload)+10)
+	line = fmt.Appendf(line, "%08x\t", crc32.Checksum(payload, crcTable))
+	line = append(line, payload...)
+	return append(line, '\n'), nil
+}
+
+func decodeRecord(line []byte) (*schema.Message, error) {
+	payload := line
+	if len(line) > 0 && line[0] != '{' {
+		if len(line) < 9 || line[8] != '\t' {
+			return nil, errors.New("malformed record")
+		}
+		sum, err := hex.DecodeString(string(line[:8]))
+		if err != nil {
+			return nil, errors.New("malformed checksum")
+		}
+		payload = line[9:]
+		if crc32.Checksum(payload, crcTable) != binary.BigEndian.Uint32(sum) {
+			return nil, errBadChecksum
+		}
+	}
+	var msg schema.Message
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+	return &msg, nil
+}
+
+// badRecord is a journal line that could not be recovered.
+type badRecord struct {
+	Line   int       `json:"line"`
+	Reason string    `json:"reason"`
+	Raw    string    `json:"raw"`
+	Time   time.Time `json:"time"`
+}
+
+// readJournal returns every record that can be decoded, and the lines that cannot.
+func readJournal(r io.Reader) (msgs []*schema.Message, bad []badRecord, err error) {
+	msgs = make([]*schema.Message, 0)
+	reader := bufio.NewReader(r)
+	for lineNo := 1; ; lineNo++ {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return nil, nil, fmt.Errorf("failed to read journal: %w", readErr)
+		}
+		complete := bytes.HasSuffix(line, []byte("\n"))
+		line = bytes.TrimRight(line, "\r\n")
+		if len(line) > 0 {
+			msg, decErr := decodeRecord(line)
+			switch {
+			case !complete:
+				// a torn write at the tail is never trusted, even if it happens to parse
+				bad = append(bad, badRecord{Line: lineNo, Reason: "truncated record", Raw: string(line), Time: time.Now()})
+			case decErr != nil:
+				bad = append(bad, badRecord{Line: lineNo, Reason: decErr.Error(), Raw: string(line), Time: time.Now()})
+			default:
+				msgs = append(msgs, msg)
+			}
+		}
+		if readErr == io.EOF {
+			return msgs, bad, nil
+		}
+	}
+}
+
+// writeFileAtomic replaces path with data so that readers see either the old or the new
+// content, never a partial file.
+func writeFileAtomic(path string, data []byte, sync bool) (err error) {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if sync {
+		if err = tmp.Sync(); err != nil {
+			return fmt.Errorf("failed to sync temp file: %w", err)
+		}
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+	if err = os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to chmod temp file: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to rename temp file: %w", err)
+	}
+	if sync {
+		syncDir(dir)
+	}
+	return nil
+}
+
+// syncDir makes a rename durable, errors are ignored since not every platform supports it.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
+
+// endsWithNewline reports whether the file is empty or its last byte is '\n'.
+func endsWithNewline(f *os.File) (bool, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+	if info.Size() == 0 {
+		return true, nil
+	}
+	b := make([]byte, 1)
+	if _, err := f.ReadAt(b, info.Size()-1); err != nil {
+		return false, err
+	}
+	return b[0] == '\n', nil
+}
@@ -0,0 +1,216 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func newJournalStore(t *testing.T) (*FileStore, string) {
+	dir := t.TempDir()
+	s, err := NewFileStore(FileStoreConfig{Dir: dir, Sync: SyncAlways})
+	assert.NoError(t, err)
+	return s, dir
+}
+
+func quarantined(t *testing.T, path string) []badRecord {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	assert.NoError(t, err)
+	defer f.Close()
+
+	var recs []badRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec badRecord
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &rec))
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+func TestJournalRecord(t *testing.T) {
+	rec, err := encodeRecord(schema.UserMessage("hello\nworld"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, bytes.Count(rec, []byte("\n")))
+
+	msg, err := decodeRecord(bytes.TrimSuffix(rec, []byte("\n")))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello\nworld", msg.Content)
+
+	flipped := bytes.Replace(rec, []byte("hello"), []byte("jello"), 1)
+	_, err = decodeRecord(bytes.TrimSuffix(flipped, []byte("\n")))
+	assert.ErrorIs(t, err, errBadChecksum)
+
+	// records written before checksums existed are still readable
+	msg, err = decodeRecord([]byte(`{"role":"user","content":"legacy"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "legacy", msg.Content)
+}
+
+func TestJournalTornTail(t *testing.T) {
+	ctx := context.Background()
+	s, dir := newJournalStore(t)
+	path := filepath.Join(dir, "c.jsonl")
+
+	assert.NoError(t, s.Append(ctx, "c", schema.UserMessage("q1")))
+	assert.NoError(t, s.Append(ctx, "c", schema.AssistantMessage("a1", nil)))
+
+	// simulate a crash in the middle of writing the third record
+	rec, _ := encodeRecord(schema.UserMessage("q2"))
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	assert.NoError(t, err)
+	_, _ = f.Write(rec[:len(rec)/2])
+	f.Close()
+
+	// the next append must not be glued onto the torn record
+	assert.NoError(t, s.Append(ctx, "c", schema.UserMessage("q2 again")))
+
+	msgs, err := s.Load(ctx, "c")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"q1", "a1", "q2 again"}, contents(msgs))
+
+	bad := quarantined(t, s.quarantinePath("c"))
+	if assert.Len(t, bad, 1) {
+		assert.Equal(t, 3, bad[0].Line)
+		assert.Equal(t, string(rec[:len(rec)/2]), bad[0].Raw)
+	}
+
+	// the journal was rewritten, loading again quarantines nothing new
+	msgs, err = s.Load(ctx, "c")
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 3)
+	assert.Len(t, quarantined(t, s.quarantinePath("c")), 1)
+}
+
+func TestJournalCorruptedMiddle(t *testing.T) {
+	ctx := context.Background()
+	s, dir := newJournalStore(t)
+	path := filepath.Join(dir, "c.jsonl")
+
+	for _, c := range []string{"one", "two", "three"} {
+		assert.NoError(t, s.Append(ctx, "c", schema.UserMessage(c)))
+	}
+	b, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(path, bytes.Replace(b, []byte("two"), []byte("tw0"), 1), 0644))
+
+	m := NewSimpleMemory(SimpleMemoryConfig{Store: s})
+	c := m.GetConversation("c", false)
+	if assert.NotNil(t, c) {
+		assert.Equal(t, []string{"one", "three"}, contents(c.GetFullMessages()))
+	}
+	bad := quarantined(t, s.quarantinePath("c"))
+	if assert.Len(t, bad, 1) {
+		assert.Equal(t, errBadChecksum.Error(), bad[0].Reason)
+	}
+	assert.Equal(t, []string{"c"}, m.ListConversations())
+}
+
+func TestJournalCompact(t *testing.T) {
+	ctx := context.Background()
+	s, dir := newJournalStore(t)
+	path := filepath.Join(dir, "legacy.jsonl")
+
+	legacy := `{"role":"user","content":"old question"}` + "\n" +
+		`{"role":"assistant","content":"old answer"}` + "\n" +
+		"not json at all\n"
+	assert.NoError(t, os.WriteFile(path, []byte(legacy), 0644))
+
+	assert.NoError(t, s.Compact(ctx, "legacy"))
+
+	b, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	assert.Len(t, lines, 2)
+	for _, line := range lines {
+		assert.NotEqual(t, byte('{'), line[0], "record should carry a checksum after compaction")
+	}
+	assert.Len(t, quarantined(t, s.quarantinePath("legacy")), 1)
+
+	msgs, err := s.Load(ctx, "legacy")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"old question", "old answer"}, contents(msgs))
+
+	// no temp files are left behind
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	for _, e := range entries {
+		assert.NotContains(t, e.Name(), ".tmp-")
+	}
+
+	assert.ErrorIs(t, s.Compact(ctx, "missing"), ErrConversationNotFound)
+}
+
+func TestJournalSyncInterval(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewFileStore(FileStoreConfig{Dir: t.TempDir(), Sync: SyncInterval, SyncInterval: 1 << 62})
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.Append(ctx, "c", schema.UserMessage("first")))
+	assert.False(t, s.dirty["c"], "the first append of a file is always synced")
+	assert.NoError(t, s.Append(ctx, "c", schema.UserMessage("second")))
+	assert.True(t, s.dirty["c"])
+
+	assert.NoError(t, s.Sync())
+	assert.Empty(t, s.dirty)
+	assert.Nil(t, s.flush)
+}
+
+func TestJournalSyncIntervalTimer(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewFileStore(FileStoreConfig{Dir: t.TempDir(), Sync: SyncInterval, SyncInterval: 50 * time.Millisecond})
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.Append(ctx, "c", schema.UserMessage("first")))
+	assert.NoError(t, s.Append(ctx, "c", schema.UserMessage("second")))
+	// no further append comes, the pending record is flushed by the timer
+	assert.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return len(s.dirty) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, s.Append(ctx, "c", schema.UserMessage("third")))
+	assert.NoError(t, s.Close())
+	assert.Empty(t, s.dirty)
+}
+
+func TestConversationAppendError(t *testing.T) {
+	s, dir := newJournalStore(t)
+	c := NewSimpleMemory(SimpleMemoryConfig{Store: s}).GetConversation("c", true)
+
+	// make the journal unwritable by replacing it with a directory
+	assert.NoError(t, os.Remove(filepath.Join(dir, "c.jsonl")))
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "c.jsonl"), 0755))
+
+	assert.Error(t, c.Append(schema.UserMessage("lost")))
+	assert.Empty(t, c.GetFullMessages())
+}
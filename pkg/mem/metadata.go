@@ -0,0 +1,134 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+const maxTitleRunes = 40
+
+// Metadata describes a conversation without loading its messages.
+type Metadata struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// MessageCount is the length of the active branch, NodeCount counts every stored message.
+	MessageCount int `json:"message_count"`
+	NodeCount    int `json:"node_count"`
+	// Head is the last message of the active branch, see Conversation.Fork.
+	Head string `json:"head,omitempty"`
+	// TitleEdited is set once a user renames the conversation, auto titles never override it.
+	TitleEdited bool `json:"title_edited,omitempty"`
+}
+
+// MetadataPatch holds the user editable fields of Metadata, nil fields are left unchanged.
+type MetadataPatch struct {
+	Title *string   `json:"title"`
+	Tags  *[]string `json:"tags"`
+}
+
+// TitleFunc generates the title of a conversation from its first messages.
+type TitleFunc func(ctx context.Context, msgs []*schema.Message) (string, error)
+
+// DefaultTitle uses the first line of the first user message.
+func DefaultTitle(_ context.Context, msgs []*schema.Message) (string, error) {
+	for _, msg := range msgs {
+		if msg.Role != schema.User || strings.TrimSpace(msg.Content) == "" {
+			continue
+		}
+		title := strings.TrimSpace(msg.Content)
+		if i := strings.IndexByte(title, '\n'); i >= 0 {
+			title = strings.TrimSpace(title[:i])
+		}
+		if utf8.RuneCountInString(title) > maxTitleRunes {
+			title = string([]rune(title)[:maxTitleRunes]) + "..."
+		}
+		return title, nil
+	}
+	return "", nil
+}
+
+func (m *Metadata) applyPatch(p MetadataPatch) {
+	if p.Title != nil {
+		m.Title = strings.TrimSpace(*p.Title)
+		m.TitleEdited = m.Title != ""
+	}
+	if p.Tags != nil {
+		m.Tags = normalizeTags(*p.Tags)
+	}
+}
+
+func normalizeTags(tags []string) []string {
+	res := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !slices.Contains(res, tag) {
+			res = append(res, tag)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// ListOptions filters and pages SimpleMemory.ListMetadata.
+type ListOptions struct {
+	Offset int
+	// Limit <= 0 returns every conversation after Offset.
+	Limit int
+	// Tag keeps only conversations carrying the tag.
+	Tag string
+	// Sort is "updated" (default, newest first), "created" (newest first) or "title".
+	Sort string
+}
+
+func sortMetadata(metas []Metadata, by string) {
+	sort.SliceStable(metas, func(i, j int) bool {
+		switch by {
+		case "created":
+			return metas[i].CreatedAt.After(metas[j].CreatedAt)
+		case "title":
+			return strings.ToLower(metas[i].Title) < strings.ToLower(metas[j].Title)
+		default:
+			return metas[i].UpdatedAt.After(metas[j].UpdatedAt)
+		}
+	})
+}
+
+// paginate clamps offset and limit to a slice of length n and returns the bounds.
+func paginate(n, offset, limit int) (int, int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > n {
+		offset = n
+	}
+	end := n
+	if limit > 0 && offset+limit < n {
+		end = offset + limit
+	}
+	return offset, end
+}
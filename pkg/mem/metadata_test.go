@@ -0,0 +1,207 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadataLifecycle(t *testing.T) {
+	dir := t.TempDir()
+	m := NewSimpleMemory(SimpleMemoryConfig{Dir: dir})
+
+	c := m.GetConversation("a", true)
+	meta := c.Metadata()
+	assert.Equal(t, "a", meta.ID)
+	assert.Empty(t, meta.Title)
+	assert.False(t, meta.CreatedAt.IsZero())
+
+	assert.NoError(t, c.Append(schema.UserMessage("How do I build a graph with Eino?\nI tried chains first.")))
+	assert.NoError(t, c.Append(schema.AssistantMessage("Use compose.NewGraph.", nil)))
+
+	meta, err := m.GetMetadata("a")
+	assert.NoError(t, err)
+	assert.Equal(t, "How do I build a graph with Eino?", meta.Title)
+	assert.Equal(t, 2, meta.MessageCount)
+	assert.False(t, meta.UpdatedAt.Before(meta.CreatedAt))
+
+	title := "Graphs"
+	tags := []string{" Eino ", "graph", "eino"}
+	meta, err = m.UpdateMetadata("a", MetadataPatch{Title: &title, Tags: &tags})
+	assert.NoError(t, err)
+	assert.Equal(t, "Graphs", meta.Title)
+	assert.Equal(t, []string{"eino", "graph"}, meta.Tags)
+
+	// an edited title is kept across appends and reloads
+	assert.NoError(t, c.Append(schema.UserMessage("another question")))
+	meta, err = NewSimpleMemory(SimpleMemoryConfig{Dir: dir}).GetMetadata("a")
+	assert.NoError(t, err)
+	assert.Equal(t, "Graphs", meta.Title)
+	assert.Equal(t, 3, meta.MessageCount)
+
+	// clearing the title falls back to the generated one
+	empty := ""
+	meta, err = m.UpdateMetadata("a", MetadataPatch{Title: &empty})
+	assert.NoError(t, err)
+	assert.Equal(t, "How do I build a graph with Eino?", meta.Title)
+
+	_, err = m.GetMetadata("missing")
+	assert.ErrorIs(t, err, ErrConversationNotFound)
+}
+
+func TestMetadataForLegacyConversation(t *testing.T) {
+	dir := t.TempDir()
+	legacy := `{"role":"user","content":"legacy question"}` + "\n" + `{"role":"assistant","content":"answer"}` + "\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "old.jsonl"), []byte(legacy), 0644))
+
+	m := NewSimpleMemory(SimpleMemoryConfig{Dir: dir})
+	metas, total, err := m.ListMetadata(ListOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "legacy question", metas[0].Title)
+	assert.Equal(t, 2, metas[0].MessageCount)
+
+	// the derived metadata was persisted as a sidecar, and is not listed as a conversation
+	_, err = os.Stat(filepath.Join(dir, "old.meta.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"old"}, m.ListConversations())
+}
+
+func TestListMetadata(t *testing.T) {
+	m := NewSimpleMemory(SimpleMemoryConfig{Dir: t.TempDir()})
+	for i := 0; i < 5; i++ {
+		c := m.GetConversation(fmt.Sprintf("c%d", i), true)
+		assert.NoError(t, c.Append(schema.UserMessage(fmt.Sprintf("topic %c", 'e'-i))))
+		time.Sleep(2 * time.Millisecond)
+	}
+	tags := []string{"keep"}
+	for _, id := range []string{"c1", "c3"} {
+		_, err := m.UpdateMetadata(id, MetadataPatch{Tags: &tags})
+		assert.NoError(t, err)
+	}
+
+	metas, total, err := m.ListMetadata(ListOptions{Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, total)
+	assert.Equal(t, []string{"c4", "c3"}, metaIDs(metas))
+
+	metas, _, err = m.ListMetadata(ListOptions{Offset: 4, Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c0"}, metaIDs(metas))
+
+	metas, _, err = m.ListMetadata(ListOptions{Sort: "title"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c4", "c3", "c2", "c1", "c0"}, metaIDs(metas))
+
+	metas, total, err = m.ListMetadata(ListOptions{Tag: "KEEP", Sort: "created"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, []string{"c3", "c1"}, metaIDs(metas))
+}
+
+func TestSearch(t *testing.T) {
+	dir := t.TempDir()
+	m := NewSimpleMemory(SimpleMemoryConfig{Dir: dir})
+
+	a := m.GetConversation("a", true)
+	assert.NoError(t, a.Append(schema.UserMessage("How do I use compose.NewGraph?")))
+	assert.NoError(t, a.Append(schema.AssistantMessage("Call compose.NewGraph and add nodes with AddChatModelNode.", nil)))
+	b := m.GetConversation("b", true)
+	assert.NoError(t, b.Append(schema.UserMessage("如何使用工具调用")))
+	assert.NoError(t, b.Append(schema.AssistantMessage("Graph nodes can call tools. "+strings.Repeat("filler ", 40)+"the end", nil)))
+
+	hits, total, err := m.Search("NewGraph", 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	for _, hit := range hits {
+		assert.Equal(t, "a", hit.ConversationID)
+		assert.Equal(t, "How do I use compose.NewGraph?", hit.Title)
+	}
+
+	hits, _, err = m.Search("工具调用", 0, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, hits, 1) {
+		assert.Equal(t, "b", hits[0].ConversationID)
+		assert.Equal(t, 0, hits[0].MessageIndex)
+		assert.Equal(t, schema.User, hits[0].Role)
+	}
+
+	// every word must match
+	hits, _, err = m.Search("graph missingword", 0, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, hits)
+
+	// long messages are cut to a snippet around the match
+	hits, _, err = m.Search("the end", 0, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, hits, 1) {
+		assert.True(t, strings.HasPrefix(hits[0].Snippet, "..."))
+		assert.True(t, strings.HasSuffix(hits[0].Snippet, "the end"))
+	}
+
+	// pagination
+	hits, total, err = m.Search("compose", 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Len(t, hits, 1)
+
+	// messages written by another replica are picked up, deleted conversations dropped
+	other := NewSimpleMemory(SimpleMemoryConfig{Dir: dir})
+	assert.NoError(t, other.GetConversation("c", true).Append(schema.UserMessage("replica graph")))
+	assert.NoError(t, other.DeleteConversation("a"))
+	hits, total, err = m.Search("graph", 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	for _, hit := range hits {
+		assert.NotEqual(t, "a", hit.ConversationID)
+	}
+}
+
+func TestConversationPage(t *testing.T) {
+	c := NewSimpleMemory(SimpleMemoryConfig{Dir: t.TempDir()}).GetConversation("p", true)
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, c.Append(schema.UserMessage(fmt.Sprint(i))))
+	}
+
+	page, ids, total := c.Page(1, 2)
+	assert.Equal(t, 5, total)
+	assert.Equal(t, []string{"1", "2"}, contents(page))
+	assert.Equal(t, c.MessageIDs[1:3], ids)
+
+	page, _, _ = c.Page(4, 10)
+	assert.Equal(t, []string{"4"}, contents(page))
+	page, _, _ = c.Page(10, 2)
+	assert.Empty(t, page)
+	page, _, _ = c.Page(-1, 0)
+	assert.Len(t, page, 5)
+}
+
+func metaIDs(metas []Metadata) []string {
+	ids := make([]string, 0, len(metas))
+	for _, meta := range metas {
+		ids = append(ids, meta.ID)
+	}
+	return ids
+}
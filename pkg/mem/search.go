@@ -0,0 +1,264 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+const snippetRunes = 80
+
+// SearchHit is one message matching a full-text query.
+type SearchHit struct {
+	ConversationID string `json:"conversation_id"`
+	Title          string `json:"title"`
+	MessageID      string `json:"message_id"`
+	// MessageIndex is the position of the message in the conversation's journal.
+	MessageIndex int             `json:"message_index"`
+	Role         schema.RoleType `json:"role"`
+	Snippet      string          `json:"snippet"`
+	Score        float64         `json:"score"`
+}
+
+type msgRef struct {
+	conv string
+	idx  int
+}
+
+// searchIndex is an in-process inverted index over message contents. It is refreshed from
+// the store before each search, so messages written by other replicas are found as well.
+type searchIndex struct {
+	mu       sync.Mutex
+	postings map[string]map[msgRef]int
+	// per conversation: the indexed messages and the tokens they contributed
+	msgs   map[string][]*schema.Message
+	tokens map[string]map[string]struct{}
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		postings: make(map[string]map[msgRef]int),
+		msgs:     make(map[string][]*schema.Message),
+		tokens:   make(map[string]map[string]struct{}),
+	}
+}
+
+// tokenize lower-cases text and splits it into words; CJK characters, which are not
+// separated by spaces, become one token each.
+func tokenize(text string) []string {
+	var tokens []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+			unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r):
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			word.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+func (x *searchIndex) indexedCount(conv string) int {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if msgs, ok := x.msgs[conv]; ok {
+		return len(msgs)
+	}
+	return -1
+}
+
+func (x *searchIndex) put(conv string, msgs []*schema.Message) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.removeLocked(conv)
+	convTokens := make(map[string]struct{})
+	for i, msg := range msgs {
+		for _, tok := range tokenize(msg.Content) {
+			refs, ok := x.postings[tok]
+			if !ok {
+				refs = make(map[msgRef]int)
+				x.postings[tok] = refs
+			}
+			refs[msgRef{conv: conv, idx: i}]++
+			convTokens[tok] = struct{}{}
+		}
+	}
+	x.msgs[conv] = msgs
+	x.tokens[conv] = convTokens
+}
+
+func (x *searchIndex) remove(conv string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.removeLocked(conv)
+}
+
+func (x *searchIndex) removeLocked(conv string) {
+	for tok := range x.tokens[conv] {
+		refs := x.postings[tok]
+		for ref := range refs {
+			if ref.conv == conv {
+				delete(refs, ref)
+			}
+		}
+		if len(refs) == 0 {
+			delete(x.postings, tok)
+		}
+	}
+	delete(x.tokens, conv)
+	delete(x.msgs, conv)
+}
+
+func (x *searchIndex) conversations() []string {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	ids := make([]string, 0, len(x.msgs))
+	for id := range x.msgs {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// search returns messages containing every query token, ranked by TF-IDF with a bonus for
+// messages containing the query as an exact phrase.
+func (x *searchIndex) search(query string) []SearchHit {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	qTokens := tokenize(query)
+	if len(qTokens) == 0 {
+		return nil
+	}
+
+	total := 0
+	for _, msgs := range x.msgs {
+		total += len(msgs)
+	}
+
+	scores := make(map[msgRef]float64)
+	for i, tok := range qTokens {
+		refs := x.postings[tok]
+		if len(refs) == 0 {
+			return nil
+		}
+		idf := math.Log(1 + float64(total)/float64(len(refs)))
+		next := make(map[msgRef]float64, len(refs))
+		for ref, tf := range refs {
+			prev, ok := scores[ref]
+			if i > 0 && !ok {
+				continue
+			}
+			next[ref] = prev + float64(tf)*idf
+		}
+		scores = next
+	}
+
+	phrase := strings.ToLower(strings.TrimSpace(query))
+	hits := make([]SearchHit, 0, len(scores))
+	for ref, score := range scores {
+		msg := x.msgs[ref.conv][ref.idx]
+		lower := strings.ToLower(msg.Content)
+		if strings.Contains(lower, phrase) {
+			score *= 2
+		}
+		hits = append(hits, SearchHit{
+			ConversationID: ref.conv,
+			MessageID:      nodeIDOf(msg, ref.idx),
+			MessageIndex:   ref.idx,
+			Role:           msg.Role,
+			Snippet:        snippet(msg.Content, phrase, qTokens[0]),
+			Score:          score,
+		})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if hits[i].ConversationID != hits[j].ConversationID {
+			return hits[i].ConversationID < hits[j].ConversationID
+		}
+		return hits[i].MessageIndex < hits[j].MessageIndex
+	})
+	return hits
+}
+
+// snippet cuts a window of text around the phrase, or the first token if the phrase does
+// not occur verbatim.
+func snippet(content, phrase, token string) string {
+	runes := []rune(content)
+	if len(runes) <= snippetRunes {
+		return content
+	}
+	lower := []rune(strings.ToLower(content))
+	pos := runeIndex(lower, []rune(phrase))
+	if pos < 0 {
+		pos = runeIndex(lower, []rune(token))
+	}
+	start := max(pos-snippetRunes/4, 0)
+	end := min(start+snippetRunes, len(runes))
+	start = max(end-snippetRunes, 0)
+
+	s := string(runes[start:end])
+	if start > 0 {
+		s = "..." + s
+	}
+	if end < len(runes) {
+		s += "..."
+	}
+	return s
+}
+
+func runeIndex(s, sub []rune) int {
+	if len(sub) == 0 {
+		return -1
+	}
+	for i := 0; i+len(sub) <= len(s); i++ {
+		match := true
+		for j := range sub {
+			if s[i+j] != sub[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
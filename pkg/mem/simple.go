@@ -17,40 +17,107 @@
 package mem
 
 import (
-	"bufio"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
+	"log"
 	"os"
-	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/cloudwego/eino/schema"
+	"github.com/google/uuid"
+
+	redispkg "likeeino/pkg/redis"
 )
 
+const defaultFallbackWindowSize = 6
+
+var (
+	defaultMemory     *SimpleMemory
+	defaultMemoryOnce sync.Once
+)
+
+// GetDefaultMemory returns the process-wide memory. The backend is chosen by MEMORY_STORE:
+// "file" (default, data/memory), "sqlite" (MEMORY_SQLITE_PATH) or "redis" (REDIS_ADDR).
 func GetDefaultMemory() *SimpleMemory {
-	return NewSimpleMemory(SimpleMemoryConfig{
-		Dir:           "data/memory",
-		MaxWindowSize: 6,
+	defaultMemoryOnce.Do(func() {
+		cfg := SimpleMemoryConfig{
+			Dir:           "data/memory",
+			MaxWindowSize: 6,
+			Sync:          SyncInterval,
+		}
+		store, err := newStoreFromEnv()
+		if err != nil {
+			log.Printf("[memory] failed to init %s store, fall back to files: %v", os.Getenv("MEMORY_STORE"), err)
+		} else {
+			cfg.Store = store
+		}
+		defaultMemory = NewSimpleMemory(cfg)
 	})
+	return defaultMemory
+}
+
+func newStoreFromEnv() (ConversationStore, error) {
+	switch os.Getenv("MEMORY_STORE") {
+	case "sqlite":
+		return NewSQLiteStore(os.Getenv("MEMORY_SQLITE_PATH"))
+	case "redis":
+		addr := os.Getenv("REDIS_ADDR")
+		if addr == "" {
+			addr = "localhost:6379"
+		}
+		store := NewRedisStore(redispkg.NewClient(addr), os.Getenv("MEMORY_REDIS_PREFIX"))
+		if err := store.client.Ping(context.Background()).Err(); err != nil {
+			return nil, err
+		}
+		return store, nil
+	default:
+		return nil, nil
+	}
 }
 
 type SimpleMemoryConfig struct {
+	// Dir is used by the default file store when Store is nil.
 	Dir           string
 	MaxWindowSize int
+	// Window decides which messages GetMessages returns, defaults to a CountWindow of MaxWindowSize.
+	Window WindowStrategy
+	// Sync is the fsync policy of the default file store.
+	Sync SyncPolicy
+	// Store persists the conversations, defaults to a FileStore in Dir.
+	Store ConversationStore
+	// Title names new conversations, defaults to DefaultTitle.
+	Title TitleFunc
 }
 
 func NewSimpleMemory(cfg SimpleMemoryConfig) *SimpleMemory {
-	if cfg.Dir == "" {
-		cfg.Dir = "/tmp/eino/memory"
+	store := cfg.Store
+	if store == nil {
+		fs, err := NewFileStore(FileStoreConfig{Dir: cfg.Dir, Sync: cfg.Sync})
+		if err != nil {
+			return nil
+		}
+		store = fs
 	}
-	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
-		return nil
+
+	window := cfg.Window
+	if window == nil {
+		window = NewCountWindow(cfg.MaxWindowSize)
+	}
+
+	title := cfg.Title
+	if title == nil {
+		title = DefaultTitle
 	}
 
 	return &SimpleMemory{
-		dir:           cfg.Dir,
-		maxWindowSize: cfg.MaxWindowSize,
+		store:         store,
+		window:        window,
+		title:         title,
+		index:         newSearchIndex(),
 		conversations: make(map[string]*Conversation),
 	}
 }
@@ -58,63 +125,220 @@ func NewSimpleMemory(cfg SimpleMemoryConfig) *SimpleMemory {
 // simple memory can store messages of each conversation
 type SimpleMemory struct {
 	mu            sync.Mutex
-	dir           string
-	maxWindowSize int
+	store         ConversationStore
+	window        WindowStrategy
+	title         TitleFunc
+	index         *searchIndex
 	conversations map[string]*Conversation
 }
 
+// GetConversation returns the conversation, refreshed from the store so that messages written
+// by other replicas are visible. It returns nil if the conversation does not exist and
+// createIfNotExist is false, or if the store fails.
 func (m *SimpleMemory) GetConversation(id string, createIfNotExist bool) *Conversation {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	_, ok := m.conversations[id]
+	ctx := context.Background()
+	msgs, err := m.store.Load(ctx, id)
+	if errors.Is(err, ErrConversationNotFound) && createIfNotExist {
+		if err = m.store.Create(ctx, id); err == nil {
+			msgs = make([]*schema.Message, 0)
+		}
+	}
+	if err != nil {
+		if !errors.Is(err, ErrConversationNotFound) {
+			log.Printf("[memory] failed to load conversation %s: %v", id, err)
+		}
+		delete(m.conversations, id)
+		return nil
+	}
+	sum, err := m.store.LoadSummary(ctx, id)
+	if err != nil {
+		log.Printf("[memory] failed to load summary of %s: %v", id, err)
+	}
+	meta, tree, err := m.syncMetadata(ctx, id, msgs)
+	if err != nil {
+		log.Printf("[memory] failed to load metadata of %s: %v", id, err)
+	}
 
-	filePath := filepath.Join(m.dir, id+".jsonl")
+	con, ok := m.conversations[id]
 	if !ok {
-		if _, err := os.Stat(filePath); os.IsNotExist(err) {
-			if createIfNotExist {
-				if err := os.WriteFile(filePath, []byte(""), 0644); err != nil {
-					return nil
-				}
-				m.conversations[id] = &Conversation{
-					ID:            id,
-					Messages:      make([]*schema.Message, 0),
-					filePath:      filePath,
-					maxWindowSize: m.maxWindowSize,
-				}
-			}
+		con = &Conversation{
+			ID:     id,
+			store:  m.store,
+			window: m.window,
+			title:  m.title,
 		}
+		m.conversations[id] = con
+	}
+	con.mu.Lock()
+	con.tree = tree
+	con.summary = sum
+	con.meta = meta
+	con.refreshBranchLocked()
+	con.mu.Unlock()
+
+	return con
+}
 
-		con := &Conversation{
-			ID:            id,
-			Messages:      make([]*schema.Message, 0),
-			filePath:      filePath,
-			maxWindowSize: m.maxWindowSize,
+// syncMetadata loads the metadata of a conversation and brings it in line with its messages,
+// creating it for conversations stored before metadata existed.
+func (m *SimpleMemory) syncMetadata(ctx context.Context, id string, msgs []*schema.Message) (Metadata, *messageTree, error) {
+	tree := buildTree(msgs)
+	meta, err := m.store.LoadMetadata(ctx, id)
+	if err != nil {
+		meta = Metadata{ID: id, Head: tree.defaultHead()}
+		return meta, tree, err
+	}
+	changed := false
+	if meta.ID == "" {
+		now := time.Now()
+		meta = Metadata{ID: id, Tags: []string{}, CreatedAt: now, UpdatedAt: now}
+		changed = true
+	}
+	if meta.Head == "" || !tree.has(meta.Head) {
+		meta.Head = tree.defaultHead()
+		changed = true
+	}
+	branch := nodeMessages(tree.path(meta.Head))
+	if meta.MessageCount != len(branch) || meta.NodeCount != len(tree.order) {
+		meta.MessageCount = len(branch)
+		meta.NodeCount = len(tree.order)
+		changed = true
+	}
+	if meta.Title == "" && !meta.TitleEdited && len(branch) > 0 {
+		if title, err := m.title(ctx, branch); err == nil && title != "" {
+			meta.Title = title
+			changed = true
+		}
+	}
+	if changed {
+		if err := m.store.SaveMetadata(ctx, meta); err != nil {
+			return meta, tree, err
 		}
-		con.load()
-		m.conversations[id] = con
 	}
+	return meta, tree, nil
+}
 
-	return m.conversations[id]
+// GetMetadata returns the metadata of a conversation, or ErrConversationNotFound.
+func (m *SimpleMemory) GetMetadata(id string) (Metadata, error) {
+	ctx := context.Background()
+	meta, err := m.store.LoadMetadata(ctx, id)
+	if err != nil || meta.ID != "" {
+		return meta, err
+	}
+	msgs, err := m.store.Load(ctx, id)
+	if err != nil {
+		return meta, err
+	}
+	meta, _, err = m.syncMetadata(ctx, id, msgs)
+	return meta, err
 }
 
-func (m *SimpleMemory) ListConversations() []string {
+// UpdateMetadata changes the title and tags of a conversation.
+func (m *SimpleMemory) UpdateMetadata(id string, patch MetadataPatch) (Metadata, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	files, err := os.ReadDir(m.dir)
+	meta, err := m.GetMetadata(id)
 	if err != nil {
-		return nil
+		return meta, err
+	}
+	meta.applyPatch(patch)
+	if meta.Title == "" {
+		// clearing the title goes back to the generated one
+		msgs, err := m.store.Load(context.Background(), id)
+		if err != nil {
+			return meta, err
+		}
+		meta.Title, _ = m.title(context.Background(), nodeMessages(buildTree(msgs).path(meta.Head)))
+	}
+	if err := m.store.SaveMetadata(context.Background(), meta); err != nil {
+		return meta, err
+	}
+	if con, ok := m.conversations[id]; ok {
+		con.mu.Lock()
+		con.meta = meta
+		con.mu.Unlock()
+	}
+	return meta, nil
+}
+
+// ListMetadata returns one page of conversation metadata and the number of conversations
+// matching the options before paging.
+func (m *SimpleMemory) ListMetadata(opts ListOptions) ([]Metadata, int, error) {
+	ids, err := m.store.List(context.Background())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	tag := strings.ToLower(strings.TrimSpace(opts.Tag))
+	metas := make([]Metadata, 0, len(ids))
+	for _, id := range ids {
+		meta, err := m.GetMetadata(id)
+		if err != nil {
+			if errors.Is(err, ErrConversationNotFound) {
+				continue
+			}
+			return nil, 0, err
+		}
+		if tag != "" && !slices.Contains(meta.Tags, tag) {
+			continue
+		}
+		metas = append(metas, meta)
+	}
+	sortMetadata(metas, opts.Sort)
+
+	start, end := paginate(len(metas), opts.Offset, opts.Limit)
+	return metas[start:end], len(metas), nil
+}
+
+// Search finds messages across all conversations containing every word of the query.
+// It returns one page of hits and the total number of hits.
+func (m *SimpleMemory) Search(query string, offset, limit int) ([]SearchHit, int, error) {
+	metas, _, err := m.ListMetadata(ListOptions{})
+	if err != nil {
+		return nil, 0, err
 	}
 
-	ids := make([]string, 0, len(files))
-	for _, file := range files {
-		if file.IsDir() {
+	titles := make(map[string]string, len(metas))
+	for _, meta := range metas {
+		titles[meta.ID] = meta.Title
+		if m.index.indexedCount(meta.ID) == meta.NodeCount {
 			continue
 		}
-		ids = append(ids, strings.TrimSuffix(file.Name(), ".jsonl"))
+		msgs, err := m.store.Load(context.Background(), meta.ID)
+		if err != nil {
+			if errors.Is(err, ErrConversationNotFound) {
+				continue
+			}
+			return nil, 0, err
+		}
+		m.index.put(meta.ID, msgs)
+	}
+	for _, id := range m.index.conversations() {
+		if _, ok := titles[id]; !ok {
+			m.index.remove(id)
+		}
 	}
 
+	hits := m.index.search(query)
+	for i := range hits {
+		hits[i].Title = titles[hits[i].ConversationID]
+	}
+	start, end := paginate(len(hits), offset, limit)
+	return hits[start:end], len(hits), nil
+}
+
+func (m *SimpleMemory) ListConversations() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids, err := m.store.List(context.Background())
+	if err != nil {
+		return nil
+	}
 	return ids
 }
 
@@ -122,87 +346,247 @@ func (m *SimpleMemory) DeleteConversation(id string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	filePath := filepath.Join(m.dir, id+".jsonl")
-	if err := os.Remove(filePath); err != nil {
-		return fmt.Errorf("failed to delete file: %w", err)
+	if err := m.store.Delete(context.Background(), id); err != nil {
+		return err
 	}
 
 	delete(m.conversations, id)
+	m.index.remove(id)
 	return nil
 }
 
 type Conversation struct {
 	mu sync.Mutex
 
-	ID       string            `json:"id"`
-	Messages []*schema.Message `json:"messages"`
+	ID string `json:"id"`
+	// Messages is the active branch, MessageIDs holds the node id of each of them.
+	Messages   []*schema.Message `json:"messages"`
+	MessageIDs []string          `json:"message_ids"`
+	Head       string            `json:"head"`
 
-	filePath string
+	store ConversationStore
+	tree  *messageTree
 
-	maxWindowSize int
+	window  WindowStrategy
+	summary Summary
+	title   TitleFunc
+	meta    Metadata
 }
 
-func (c *Conversation) Append(msg *schema.Message) {
+// Append persists the message as a child of the head and moves the head to it. The message is
+// stored first, so a message that failed to be stored is never visible in memory either.
+func (c *Conversation) Append(msg *schema.Message) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.Messages = append(c.Messages, msg)
+	parent := c.meta.Head
+	if parent == "" {
+		parent = RootID
+	}
+	node := &MessageNode{ID: uuid.NewString(), ParentID: parent, Message: cleanMessage(msg)}
+	if err := c.store.Append(context.Background(), c.ID, taggedMessage(msg, node.ID, parent)); err != nil {
+		return fmt.Errorf("failed to append to conversation %s: %w", c.ID, err)
+	}
+	c.tree.add(node)
+
+	c.meta.ID = c.ID
+	c.meta.Head = node.ID
+	c.meta.NodeCount = len(c.tree.order)
+	c.meta.UpdatedAt = time.Now()
+	if c.meta.CreatedAt.IsZero() {
+		c.meta.CreatedAt = c.meta.UpdatedAt
+	}
+	c.refreshBranchLocked()
+	if c.meta.Title == "" && !c.meta.TitleEdited && c.title != nil {
+		if title, err := c.title(context.Background(), c.Messages); err == nil {
+			c.meta.Title = title
+		}
+	}
+	// the message itself is stored, a stale count is repaired on the next load
+	if err := c.store.SaveMetadata(context.Background(), c.meta); err != nil {
+		log.Printf("[memory] failed to save metadata of %s: %v", c.ID, err)
+	}
+	return nil
+}
 
-	c.save(msg)
+// Fork moves the head to the given message (or RootID) without discarding anything, so the next
+// Append starts a new branch there. Editing a user message is Fork(parent of it) + Append.
+func (c *Conversation) Fork(id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.tree.has(id) {
+		return ErrMessageNotFound
+	}
+	return c.setHeadLocked(id)
 }
 
-func (c *Conversation) GetFullMessages() []*schema.Message {
+// Checkout switches to the branch containing the given message, following the most recent
+// replies below it down to a leaf.
+func (c *Conversation) Checkout(id string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	return c.Messages
+	if !c.tree.has(id) {
+		return ErrMessageNotFound
+	}
+	return c.setHeadLocked(c.tree.latestLeaf(id))
 }
 
-// get messages with max window size
-func (c *Conversation) GetMessages() []*schema.Message {
+// Truncate rolls the active branch back to just before the given message. The message and its
+// replies stay reachable through Checkout.
+func (c *Conversation) Truncate(id string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	//上下文超过最大窗口,则进行自动截取
-	if len(c.Messages) > c.maxWindowSize {
-		return c.Messages[len(c.Messages)-c.maxWindowSize:]
+
+	node, ok := c.tree.nodes[id]
+	if !ok {
+		return ErrMessageNotFound
 	}
+	return c.setHeadLocked(node.ParentID)
+}
 
-	return c.Messages
+func (c *Conversation) setHeadLocked(head string) error {
+	prev := c.meta
+	c.meta.ID = c.ID
+	c.meta.Head = head
+	c.refreshBranchLocked()
+	if err := c.store.SaveMetadata(context.Background(), c.meta); err != nil {
+		c.meta = prev
+		c.refreshBranchLocked()
+		return fmt.Errorf("failed to save head of %s: %w", c.ID, err)
+	}
+	return nil
 }
 
-func (c *Conversation) load() error {
-	reader, err := os.Open(c.filePath)
-	if err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
+func (c *Conversation) refreshBranchLocked() {
+	if c.tree == nil {
+		c.tree = newMessageTree()
+	}
+	if c.meta.Head == "" {
+		c.meta.Head = c.tree.defaultHead()
 	}
-	defer reader.Close()
+	nodes := c.tree.path(c.meta.Head)
+	c.Messages = nodeMessages(nodes)
+	c.MessageIDs = make([]string, len(nodes))
+	for i, n := range nodes {
+		c.MessageIDs[i] = n.ID
+	}
+	c.Head = c.meta.Head
+	c.meta.MessageCount = len(nodes)
+}
 
-	scanner := bufio.NewScanner(reader)
-	for scanner.Scan() {
-		line := scanner.Text()
-		var msg schema.Message
-		if err := json.Unmarshal([]byte(line), &msg); err != nil {
-			return fmt.Errorf("failed to unmarshal message: %w", err)
-		}
-		c.Messages = append(c.Messages, &msg)
+// Node returns one message of the tree.
+func (c *Conversation) Node(id string) (MessageNode, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n, ok := c.tree.nodes[id]
+	if !ok {
+		return MessageNode{}, false
 	}
+	return *n, true
+}
 
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("scanner error: %w", err)
+// Nodes returns every message of every branch in the order they were stored.
+func (c *Conversation) Nodes() []MessageNode {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	nodes := make([]MessageNode, 0, len(c.tree.order))
+	for _, id := range c.tree.order {
+		nodes = append(nodes, *c.tree.nodes[id])
 	}
+	return nodes
+}
 
-	return nil
+// LastMessageID returns the id of the last message with the role on the active branch, or "".
+func (c *Conversation) LastMessageID(role schema.RoleType) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := len(c.Messages) - 1; i >= 0; i-- {
+		if c.Messages[i].Role == role {
+			return c.MessageIDs[i]
+		}
+	}
+	return ""
+}
+
+// Metadata returns the metadata of the conversation as of the last load or append.
+func (c *Conversation) Metadata() Metadata {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.meta
 }
 
-func (c *Conversation) save(msg *schema.Message) {
-	str, _ := json.Marshal(msg)
+// Page returns up to limit messages of the active branch starting at offset, their ids, and
+// the length of the branch. limit <= 0 returns every message after offset.
+func (c *Conversation) Page(offset, limit int) ([]*schema.Message, []string, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	start, end := paginate(len(c.Messages), offset, limit)
+	page := make([]*schema.Message, end-start)
+	copy(page, c.Messages[start:end])
+	ids := make([]string, end-start)
+	copy(ids, c.MessageIDs[start:end])
+	return page, ids, len(c.Messages)
+}
+
+// GetFullMessages returns every message of the active branch.
+func (c *Conversation) GetFullMessages() []*schema.Message {
+	msgs, _ := c.branch()
+	return msgs
+}
+
+func (c *Conversation) branch() ([]*schema.Message, []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	msgs := make([]*schema.Message, len(c.Messages))
+	copy(msgs, c.Messages)
+	ids := make([]string, len(c.MessageIDs))
+	copy(ids, c.MessageIDs)
+	return msgs, ids
+}
 
-	// Append to file
-	f, err := os.OpenFile(c.filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+// get messages with max window size
+func (c *Conversation) GetMessages() []*schema.Message {
+	msgs, err := c.GetWindowMessages(context.Background())
 	if err != nil {
-		return
+		// fall back to plain truncation rather than losing the history entirely
+		msgs, _ = NewCountWindow(defaultFallbackWindowSize).Window(context.Background(), c)
+	}
+	return msgs
+}
+
+// GetWindowMessages returns the history selected by the conversation's window strategy.
+// 上下文超过窗口时按策略截取或摘要
+func (c *Conversation) GetWindowMessages(ctx context.Context) ([]*schema.Message, error) {
+	if c.window == nil {
+		return c.GetFullMessages(), nil
 	}
-	defer f.Close()
-	f.Write(str)
-	f.WriteString("\n")
+	return c.window.Window(ctx, c)
+}
+
+// Summary returns the rolling summary of the messages evicted from the window, if any.
+func (c *Conversation) Summary() Summary {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.summary
+}
+
+// SetSummary replaces the rolling summary and persists it in the store.
+func (c *Conversation) SetSummary(sum Summary) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.store.SaveSummary(context.Background(), c.ID, sum); err != nil {
+		return err
+	}
+	c.summary = sum
+	return nil
 }
@@ -0,0 +1,400 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// ErrConversationNotFound is returned by a ConversationStore for unknown conversation IDs.
+var ErrConversationNotFound = errors.New("conversation not found")
+
+// ConversationStore persists conversations for SimpleMemory.
+// Implementations must be safe for concurrent use and, except for the file store,
+// may be shared by several processes.
+type ConversationStore interface {
+	// Create registers an empty conversation, it is a no-op if the conversation exists.
+	Create(ctx context.Context, id string) error
+	// Load returns all messages of a conversation in append order.
+	Load(ctx context.Context, id string) ([]*schema.Message, error)
+	Append(ctx context.Context, id string, msg *schema.Message) error
+	List(ctx context.Context) ([]string, error)
+	Delete(ctx context.Context, id string) error
+
+	// LoadSummary returns the zero Summary if none has been saved.
+	LoadSummary(ctx context.Context, id string) (Summary, error)
+	SaveSummary(ctx context.Context, id string, sum Summary) error
+
+	// LoadMetadata returns the zero Metadata if none has been saved.
+	LoadMetadata(ctx context.Context, id string) (Metadata, error)
+	SaveMetadata(ctx context.Context, meta Metadata) error
+}
+
+// FileStoreConfig configures the journal files of a FileStore.
+type FileStoreConfig struct {
+	Dir  string
+	Sync SyncPolicy
+	// SyncInterval is used by SyncInterval, defaults to one second.
+	SyncInterval time.Duration
+}
+
+// FileStore keeps one checksummed journal file (.jsonl) per conversation in a directory,
+// plus a .summary.json sidecar. Records that fail verification on load are moved to a
+// .jsonl.quarantine file and the journal is compacted without them.
+type FileStore struct {
+	mu  sync.Mutex
+	dir string
+
+	sync         SyncPolicy
+	syncInterval time.Duration
+	lastSync     map[string]time.Time
+	dirty        map[string]bool
+	// flush runs Sync once the interval has passed since the first unsynced append
+	flush *time.Timer
+}
+
+func NewFileStore(cfg FileStoreConfig) (*FileStore, error) {
+	if cfg.Dir == "" {
+		cfg.Dir = "/tmp/eino/memory"
+	}
+	if cfg.SyncInterval <= 0 {
+		cfg.SyncInterval = time.Second
+	}
+	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create memory dir: %w", err)
+	}
+	return &FileStore{
+		dir:          cfg.Dir,
+		sync:         cfg.Sync,
+		syncInterval: cfg.SyncInterval,
+		lastSync:     make(map[string]time.Time),
+		dirty:        make(map[string]bool),
+	}, nil
+}
+
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.dir, id+".jsonl")
+}
+
+func (s *FileStore) summaryPath(id string) string {
+	return filepath.Join(s.dir, id+".summary.json")
+}
+
+func (s *FileStore) metaPath(id string) string {
+	return filepath.Join(s.dir, id+".meta.json")
+}
+
+func (s *FileStore) quarantinePath(id string) string {
+	return s.path(id) + ".quarantine"
+}
+
+func (s *FileStore) Create(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path(id), os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create conversation file: %w", err)
+	}
+	return f.Close()
+}
+
+func (s *FileStore) Load(_ context.Context, id string) ([]*schema.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msgs, bad, err := s.read(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(bad) > 0 {
+		if err := s.quarantine(id, bad); err != nil {
+			return nil, err
+		}
+		// rewrite the journal so that the same records are not quarantined again
+		if err := s.rewrite(id, msgs); err != nil {
+			return nil, err
+		}
+		log.Printf("[memory] quarantined %d corrupted records of conversation %s", len(bad), id)
+	}
+	return msgs, nil
+}
+
+func (s *FileStore) read(id string) ([]*schema.Message, []badRecord, error) {
+	reader, err := os.Open(s.path(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, ErrConversationNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer reader.Close()
+
+	return readJournal(reader)
+}
+
+func (s *FileStore) quarantine(id string, bad []badRecord) error {
+	f, err := os.OpenFile(s.quarantinePath(id), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open quarantine file: %w", err)
+	}
+	defer f.Close()
+
+	for _, rec := range bad {
+		b, err := json.Marshal(rec)
+		if err != nil {
+			return fmt.Errorf("failed to marshal quarantined record: %w", err)
+		}
+		if _, err := f.Write(append(b, '\n')); err != nil {
+			return fmt.Errorf("failed to write quarantine file: %w", err)
+		}
+	}
+	return f.Sync()
+}
+
+func (s *FileStore) rewrite(id string, msgs []*schema.Message) error {
+	var buf bytes.Buffer
+	for _, msg := range msgs {
+		rec, err := encodeRecord(msg)
+		if err != nil {
+			return err
+		}
+		buf.Write(rec)
+	}
+	if err := writeFileAtomic(s.path(id), buf.Bytes(), true); err != nil {
+		return err
+	}
+	delete(s.dirty, id)
+	s.lastSync[id] = time.Now()
+	return nil
+}
+
+// Compact atomically rewrites the journal of a conversation with only its valid records,
+// upgrading records written without a checksum. Unreadable records are quarantined.
+func (s *FileStore) Compact(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msgs, bad, err := s.read(id)
+	if err != nil {
+		return err
+	}
+	if len(bad) > 0 {
+		if err := s.quarantine(id, bad); err != nil {
+			return err
+		}
+	}
+	return s.rewrite(id, msgs)
+}
+
+func (s *FileStore) Append(_ context.Context, id string, msg *schema.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, err := encodeRecord(msg)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.path(id), os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	// a torn record left by a crash must not swallow the record written after it
+	ok, err := endsWithNewline(f)
+	if err != nil {
+		return fmt.Errorf("failed to inspect file: %w", err)
+	}
+	if !ok {
+		rec = append([]byte{'\n'}, rec...)
+	}
+	if _, err := f.Write(rec); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+
+	switch s.sync {
+	case SyncAlways:
+		return s.syncFile(id, f)
+	case SyncInterval:
+		if time.Since(s.lastSync[id]) >= s.syncInterval {
+			return s.syncFile(id, f)
+		}
+		s.dirty[id] = true
+		if s.flush == nil {
+			s.flush = time.AfterFunc(s.syncInterval-time.Since(s.lastSync[id]), func() {
+				if err := s.Sync(); err != nil {
+					log.Printf("[memory] failed to sync journals: %v", err)
+				}
+			})
+		}
+	}
+	return nil
+}
+
+func (s *FileStore) syncFile(id string, f *os.File) error {
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("failed to sync file: %w", err)
+	}
+	s.lastSync[id] = time.Now()
+	delete(s.dirty, id)
+	return nil
+}
+
+// Sync flushes every journal with records that SyncInterval has not flushed yet.
+// It runs on a timer after such appends, and can be called to flush earlier.
+func (s *FileStore) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.flush != nil {
+		s.flush.Stop()
+		s.flush = nil
+	}
+
+	var errs []error
+	for id := range s.dirty {
+		f, err := os.OpenFile(s.path(id), os.O_WRONLY, 0644)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+			delete(s.dirty, id)
+			continue
+		}
+		if err := s.syncFile(id, f); err != nil {
+			errs = append(errs, err)
+		}
+		f.Close()
+	}
+	return errors.Join(errs...)
+}
+
+// Close flushes the pending records, the store must not be used afterwards.
+func (s *FileStore) Close() error {
+	return s.Sync()
+}
+
+func (s *FileStore) List(_ context.Context) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memory dir: %w", err)
+	}
+
+	ids := make([]string, 0, len(files))
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".jsonl") {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(file.Name(), ".jsonl"))
+	}
+	return ids, nil
+}
+
+func (s *FileStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path(id)); err != nil {
+		if os.IsNotExist(err) {
+			return ErrConversationNotFound
+		}
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	delete(s.dirty, id)
+	delete(s.lastSync, id)
+	for _, sidecar := range []string{s.summaryPath(id), s.metaPath(id)} {
+		if err := os.Remove(sidecar); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete sidecar: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *FileStore) LoadSummary(_ context.Context, id string) (Summary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var sum Summary
+	b, err := os.ReadFile(s.summaryPath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return sum, nil
+		}
+		return sum, fmt.Errorf("failed to read summary: %w", err)
+	}
+	if err := json.Unmarshal(b, &sum); err != nil {
+		return sum, fmt.Errorf("failed to unmarshal summary: %w", err)
+	}
+	return sum, nil
+}
+
+func (s *FileStore) SaveSummary(_ context.Context, id string, sum Summary) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := json.Marshal(sum)
+	if err != nil {
+		return fmt.Errorf("failed to marshal summary: %w", err)
+	}
+	return writeFileAtomic(s.summaryPath(id), b, s.sync != SyncNone)
+}
+
+func (s *FileStore) LoadMetadata(_ context.Context, id string) (Metadata, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var meta Metadata
+	b, err := os.ReadFile(s.metaPath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return meta, nil
+		}
+		return meta, fmt.Errorf("failed to read metadata: %w", err)
+	}
+	if err := json.Unmarshal(b, &meta); err != nil {
+		return meta, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+	return meta, nil
+}
+
+func (s *FileStore) SaveMetadata(_ context.Context, meta Metadata) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	return writeFileAtomic(s.metaPath(meta.ID), b, s.sync != SyncNone)
+}
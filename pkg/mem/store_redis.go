@@ -0,0 +1,179 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/redis/go-redis/v9"
+)
+
+const defaultRedisMemoryPrefix = "eino:memory:"
+
+// RedisStore keeps each conversation as a Redis list of JSON messages:
+//
+//	<prefix>ids            set of conversation ids
+//	<prefix>msgs:<id>      list of messages
+//	<prefix>summary:<id>   rolling summary
+//	<prefix>meta:<id>      metadata
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore wraps a client, usually created with likeeino/pkg/redis.NewClient.
+// An empty prefix defaults to "eino:memory:".
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	if prefix == "" {
+		prefix = defaultRedisMemoryPrefix
+	}
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func (s *RedisStore) idsKey() string              { return s.prefix + "ids" }
+func (s *RedisStore) msgsKey(id string) string    { return s.prefix + "msgs:" + id }
+func (s *RedisStore) summaryKey(id string) string { return s.prefix + "summary:" + id }
+func (s *RedisStore) metaKey(id string) string    { return s.prefix + "meta:" + id }
+
+func (s *RedisStore) Create(ctx context.Context, id string) error {
+	if err := s.client.SAdd(ctx, s.idsKey(), id).Err(); err != nil {
+		return fmt.Errorf("failed to create conversation: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisStore) Load(ctx context.Context, id string) ([]*schema.Message, error) {
+	ok, err := s.client.SIsMember(ctx, s.idsKey(), id).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conversation: %w", err)
+	}
+	if !ok {
+		return nil, ErrConversationNotFound
+	}
+
+	vals, err := s.client.LRange(ctx, s.msgsKey(id), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load messages: %w", err)
+	}
+	msgs := make([]*schema.Message, 0, len(vals))
+	for _, val := range vals {
+		var msg schema.Message
+		if err := json.Unmarshal([]byte(val), &msg); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+		}
+		msgs = append(msgs, &msg)
+	}
+	return msgs, nil
+}
+
+func (s *RedisStore) Append(ctx context.Context, id string, msg *schema.Message) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, s.idsKey(), id)
+		pipe.RPush(ctx, s.msgsKey(id), b)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to append message: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisStore) List(ctx context.Context) ([]string, error) {
+	ids, err := s.client.SMembers(ctx, s.idsKey()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conversations: %w", err)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (s *RedisStore) Delete(ctx context.Context, id string) error {
+	var removed *redis.IntCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		removed = pipe.SRem(ctx, s.idsKey(), id)
+		pipe.Del(ctx, s.msgsKey(id), s.summaryKey(id), s.metaKey(id))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete conversation: %w", err)
+	}
+	if removed.Val() == 0 {
+		return ErrConversationNotFound
+	}
+	return nil
+}
+
+func (s *RedisStore) LoadSummary(ctx context.Context, id string) (Summary, error) {
+	var sum Summary
+	val, err := s.client.Get(ctx, s.summaryKey(id)).Result()
+	if errors.Is(err, redis.Nil) {
+		return sum, nil
+	}
+	if err != nil {
+		return sum, fmt.Errorf("failed to load summary: %w", err)
+	}
+	if err := json.Unmarshal([]byte(val), &sum); err != nil {
+		return sum, fmt.Errorf("failed to unmarshal summary: %w", err)
+	}
+	return sum, nil
+}
+
+func (s *RedisStore) SaveSummary(ctx context.Context, id string, sum Summary) error {
+	b, err := json.Marshal(sum)
+	if err != nil {
+		return fmt.Errorf("failed to marshal summary: %w", err)
+	}
+	if err := s.client.Set(ctx, s.summaryKey(id), b, 0).Err(); err != nil {
+		return fmt.Errorf("failed to save summary: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisStore) LoadMetadata(ctx context.Context, id string) (Metadata, error) {
+	var meta Metadata
+	val, err := s.client.Get(ctx, s.metaKey(id)).Result()
+	if errors.Is(err, redis.Nil) {
+		return meta, nil
+	}
+	if err != nil {
+		return meta, fmt.Errorf("failed to load metadata: %w", err)
+	}
+	if err := json.Unmarshal([]byte(val), &meta); err != nil {
+		return meta, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+	return meta, nil
+}
+
+func (s *RedisStore) SaveMetadata(ctx context.Context, meta Metadata) error {
+	b, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	if err := s.client.Set(ctx, s.metaKey(meta.ID), b, 0).Err(); err != nil {
+		return fmt.Errorf("failed to save metadata: %w", err)
+	}
+	return nil
+}
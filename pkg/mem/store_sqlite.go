@@ -0,0 +1,244 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudwego/eino/schema"
+	_ "modernc.org/sqlite"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS conversations (
+	id         TEXT PRIMARY KEY,
+	summary    TEXT NOT NULL DEFAULT '',
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE IF NOT EXISTS messages (
+	conversation_id TEXT NOT NULL,
+	seq             INTEGER NOT NULL,
+	body            TEXT NOT NULL,
+	PRIMARY KEY (conversation_id, seq)
+);
+CREATE TABLE IF NOT EXISTS conversation_meta (
+	conversation_id TEXT PRIMARY KEY,
+	body            TEXT NOT NULL
+);`
+
+// SQLiteStore keeps all conversations in one embedded SQLite database file,
+// which can be shared by several processes on the same host.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	if path == "" {
+		path = "data/memory/memory.db"
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create sqlite dir: %w", err)
+	}
+
+	// _txlock=immediate takes the write lock at BEGIN, so a read-then-insert transaction waits
+	// on busy_timeout instead of failing with SQLITE_BUSY when upgrading its lock
+	db, err := sql.Open("sqlite", path+"?_txlock=immediate&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite: %w", err)
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to init sqlite schema: %w", err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) Create(ctx context.Context, id string) error {
+	if _, err := s.db.ExecContext(ctx, `INSERT OR IGNORE INTO conversations (id) VALUES (?)`, id); err != nil {
+		return fmt.Errorf("failed to create conversation: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) exists(ctx context.Context, id string) (bool, error) {
+	var n int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(1) FROM conversations WHERE id = ?`, id).Scan(&n); err != nil {
+		return false, fmt.Errorf("failed to query conversation: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (s *SQLiteStore) Load(ctx context.Context, id string) ([]*schema.Message, error) {
+	ok, err := s.exists(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrConversationNotFound
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT body FROM messages WHERE conversation_id = ? ORDER BY seq`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query messages: %w", err)
+	}
+	defer rows.Close()
+
+	msgs := make([]*schema.Message, 0)
+	for rows.Next() {
+		var body string
+		if err := rows.Scan(&body); err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		var msg schema.Message
+		if err := json.Unmarshal([]byte(body), &msg); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+		}
+		msgs = append(msgs, &msg)
+	}
+	return msgs, rows.Err()
+}
+
+func (s *SQLiteStore) Append(ctx context.Context, id string, msg *schema.Message) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO conversations (id) VALUES (?)`, id); err != nil {
+		return fmt.Errorf("failed to create conversation: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+INSERT INTO messages (conversation_id, seq, body)
+SELECT ?, COALESCE(MAX(seq), 0) + 1, ? FROM messages WHERE conversation_id = ?`, id, string(b), id); err != nil {
+		return fmt.Errorf("failed to insert message: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) List(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id FROM conversations ORDER BY created_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conversations: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan conversation id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (s *SQLiteStore) Delete(ctx context.Context, id string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM conversations WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete conversation: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrConversationNotFound
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM messages WHERE conversation_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete messages: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM conversation_meta WHERE conversation_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete metadata: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) LoadSummary(ctx context.Context, id string) (Summary, error) {
+	var sum Summary
+	var raw string
+	err := s.db.QueryRowContext(ctx, `SELECT summary FROM conversations WHERE id = ?`, id).Scan(&raw)
+	if err == sql.ErrNoRows || (err == nil && raw == "") {
+		return sum, nil
+	}
+	if err != nil {
+		return sum, fmt.Errorf("failed to query summary: %w", err)
+	}
+	if err := json.Unmarshal([]byte(raw), &sum); err != nil {
+		return sum, fmt.Errorf("failed to unmarshal summary: %w", err)
+	}
+	return sum, nil
+}
+
+func (s *SQLiteStore) SaveSummary(ctx context.Context, id string, sum Summary) error {
+	b, err := json.Marshal(sum)
+	if err != nil {
+		return fmt.Errorf("failed to marshal summary: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `
+INSERT INTO conversations (id, summary) VALUES (?, ?)
+ON CONFLICT(id) DO UPDATE SET summary = excluded.summary`, id, string(b)); err != nil {
+		return fmt.Errorf("failed to save summary: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) LoadMetadata(ctx context.Context, id string) (Metadata, error) {
+	var meta Metadata
+	var raw string
+	err := s.db.QueryRowContext(ctx, `SELECT body FROM conversation_meta WHERE conversation_id = ?`, id).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return meta, nil
+	}
+	if err != nil {
+		return meta, fmt.Errorf("failed to query metadata: %w", err)
+	}
+	if err := json.Unmarshal([]byte(raw), &meta); err != nil {
+		return meta, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+	return meta, nil
+}
+
+func (s *SQLiteStore) SaveMetadata(ctx context.Context, meta Metadata) error {
+	b, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `
+INSERT INTO conversation_meta (conversation_id, body) VALUES (?, ?)
+ON CONFLICT(conversation_id) DO UPDATE SET body = excluded.body`, meta.ID, string(b)); err != nil {
+		return fmt.Errorf("failed to save metadata: %w", err)
+	}
+	return nil
+}
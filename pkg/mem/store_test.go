@@ -0,0 +1,221 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+
+	redispkg "likeeino/pkg/redis"
+)
+
+var storeBackends = map[string]func(t *testing.T) ConversationStore{
+	"file": func(t *testing.T) ConversationStore {
+		s, err := NewFileStore(FileStoreConfig{Dir: t.TempDir(), Sync: SyncAlways})
+		assert.NoError(t, err)
+		return s
+	},
+	"sqlite": func(t *testing.T) ConversationStore {
+		s, err := NewSQLiteStore(filepath.Join(t.TempDir(), "memory.db"))
+		assert.NoError(t, err)
+		t.Cleanup(func() { s.Close() })
+		return s
+	},
+	"redis": func(t *testing.T) ConversationStore {
+		mr := miniredis.RunT(t)
+		client := redispkg.NewClient(mr.Addr())
+		t.Cleanup(func() { client.Close() })
+		return NewRedisStore(client, "")
+	},
+}
+
+func TestConversationStores(t *testing.T) {
+	for name, newStore := range storeBackends {
+		t.Run(name, func(t *testing.T) {
+			t.Run("lifecycle", func(t *testing.T) { testStoreLifecycle(t, newStore(t)) })
+			t.Run("summary", func(t *testing.T) { testStoreSummary(t, newStore(t)) })
+			t.Run("metadata", func(t *testing.T) { testStoreMetadata(t, newStore(t)) })
+			t.Run("concurrent append", func(t *testing.T) { testStoreConcurrentAppend(t, newStore(t)) })
+			t.Run("memory", func(t *testing.T) { testStoreMemory(t, newStore(t)) })
+		})
+	}
+}
+
+func testStoreLifecycle(t *testing.T, s ConversationStore) {
+	ctx := context.Background()
+
+	_, err := s.Load(ctx, "missing")
+	assert.ErrorIs(t, err, ErrConversationNotFound)
+	assert.ErrorIs(t, s.Delete(ctx, "missing"), ErrConversationNotFound)
+
+	assert.NoError(t, s.Create(ctx, "a"))
+	assert.NoError(t, s.Create(ctx, "a"))
+	msgs, err := s.Load(ctx, "a")
+	assert.NoError(t, err)
+	assert.Empty(t, msgs)
+
+	assert.NoError(t, s.Append(ctx, "a", schema.UserMessage("hi")))
+	assert.NoError(t, s.Append(ctx, "a", schema.AssistantMessage("", []schema.ToolCall{{
+		ID:       "call_1",
+		Function: schema.FunctionCall{Name: "search", Arguments: `{"q":"eino"}`},
+	}})))
+	assert.NoError(t, s.Append(ctx, "a", schema.ToolMessage("found", "call_1")))
+	// Append implicitly creates the conversation
+	assert.NoError(t, s.Append(ctx, "b", schema.UserMessage("other")))
+
+	msgs, err = s.Load(ctx, "a")
+	assert.NoError(t, err)
+	if assert.Len(t, msgs, 3) {
+		assert.Equal(t, "hi", msgs[0].Content)
+		assert.Equal(t, "search", msgs[1].ToolCalls[0].Function.Name)
+		assert.Equal(t, "call_1", msgs[2].ToolCallID)
+	}
+
+	ids, err := s.List(ctx)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a", "b"}, ids)
+
+	assert.NoError(t, s.Delete(ctx, "a"))
+	_, err = s.Load(ctx, "a")
+	assert.ErrorIs(t, err, ErrConversationNotFound)
+	ids, err = s.List(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b"}, ids)
+}
+
+func testStoreSummary(t *testing.T, s ConversationStore) {
+	ctx := context.Background()
+	assert.NoError(t, s.Create(ctx, "a"))
+
+	sum, err := s.LoadSummary(ctx, "a")
+	assert.NoError(t, err)
+	assert.Zero(t, sum)
+
+	want := Summary{Content: "talked about eino", Covered: 4, UpdatedAt: time.Unix(1700000000, 0).UTC()}
+	assert.NoError(t, s.SaveSummary(ctx, "a", want))
+	sum, err = s.LoadSummary(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, want.Content, sum.Content)
+	assert.Equal(t, want.Covered, sum.Covered)
+	assert.True(t, want.UpdatedAt.Equal(sum.UpdatedAt))
+
+	assert.NoError(t, s.Delete(ctx, "a"))
+	sum, err = s.LoadSummary(ctx, "a")
+	assert.NoError(t, err)
+	assert.Zero(t, sum)
+}
+
+func testStoreMetadata(t *testing.T, s ConversationStore) {
+	ctx := context.Background()
+	assert.NoError(t, s.Create(ctx, "a"))
+
+	meta, err := s.LoadMetadata(ctx, "a")
+	assert.NoError(t, err)
+	assert.Zero(t, meta)
+
+	want := Metadata{ID: "a", Title: "hello", Tags: []string{"x"}, MessageCount: 2,
+		CreatedAt: time.Unix(1700000000, 0).UTC(), UpdatedAt: time.Unix(1700000100, 0).UTC()}
+	assert.NoError(t, s.SaveMetadata(ctx, want))
+	meta, err = s.LoadMetadata(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, want, meta)
+
+	assert.NoError(t, s.Delete(ctx, "a"))
+	meta, err = s.LoadMetadata(ctx, "a")
+	assert.NoError(t, err)
+	assert.Zero(t, meta)
+}
+
+func testStoreConcurrentAppend(t *testing.T, s ConversationStore) {
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				assert.NoError(t, s.Append(ctx, "c", schema.UserMessage(fmt.Sprintf("%d-%d", w, i))))
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	msgs, err := s.Load(ctx, "c")
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 100)
+}
+
+func testStoreMemory(t *testing.T, s ConversationStore) {
+	// two memories over one store behave like two replicas
+	m1 := NewSimpleMemory(SimpleMemoryConfig{Store: s, MaxWindowSize: 10})
+	m2 := NewSimpleMemory(SimpleMemoryConfig{Store: s, MaxWindowSize: 10})
+
+	assert.Nil(t, m1.GetConversation("x", false))
+	c1 := m1.GetConversation("x", true)
+	c1.Append(schema.UserMessage("hello"))
+	c1.Append(schema.AssistantMessage("hi there", nil))
+
+	c2 := m2.GetConversation("x", false)
+	if assert.NotNil(t, c2) {
+		assert.Len(t, c2.GetFullMessages(), 2)
+		c2.Append(schema.UserMessage("again"))
+	}
+	assert.Len(t, m1.GetConversation("x", false).GetFullMessages(), 3)
+	assert.Equal(t, []string{"x"}, m2.ListConversations())
+
+	assert.NoError(t, m2.DeleteConversation("x"))
+	assert.Nil(t, m1.GetConversation("x", false))
+}
+
+func TestSQLiteStoreConcurrentProcesses(t *testing.T) {
+	// two stores on one file stand in for two processes; appends must wait for each other instead of failing with SQLITE_BUSY
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "memory.db")
+	var stores []*SQLiteStore
+	for i := 0; i < 2; i++ {
+		s, err := NewSQLiteStore(path)
+		if !assert.NoError(t, err) {
+			return
+		}
+		t.Cleanup(func() { s.Close() })
+		stores = append(stores, s)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				assert.NoError(t, stores[w%2].Append(ctx, "c", schema.UserMessage(fmt.Sprintf("%d-%d", w, i))))
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	msgs, err := stores[0].Load(ctx, "c")
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 160)
+}
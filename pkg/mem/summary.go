@@ -0,0 +1,147 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+const defaultSummaryPrompt = `You maintain a running summary of a conversation between a user and an assistant.
+Merge the previous summary with the new messages into one concise summary.
+Keep facts, decisions, names, identifiers, open questions and the results of tool calls.
+Reply with the summary text only.`
+
+// Summary is the rolling summary of the messages that have left the window.
+type Summary struct {
+	Content string `json:"content"`
+	// Covered is the number of leading messages of the conversation folded into Content.
+	Covered int `json:"covered"`
+	// Anchor is the id of the last covered message, it ties the summary to one branch.
+	Anchor    string    `json:"anchor,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SummaryWindow keeps recent messages within a token budget like TokenWindow, and compresses
+// whatever falls out of the budget into a persisted summary that is prepended to the window.
+type SummaryWindow struct {
+	Model     model.ToolCallingChatModel
+	MaxTokens int
+	// Counter defaults to ApproxTokenCounter.
+	Counter TokenCounter
+	// SummaryTokens is the part of MaxTokens reserved for the summary, defaults to a quarter.
+	SummaryTokens int
+	// Prompt is the system prompt of the summarizer, defaults to defaultSummaryPrompt.
+	Prompt string
+}
+
+func NewSummaryWindow(cm model.ToolCallingChatModel, maxTokens int) *SummaryWindow {
+	return &SummaryWindow{Model: cm, MaxTokens: maxTokens}
+}
+
+func (w *SummaryWindow) Window(ctx context.Context, c *Conversation) ([]*schema.Message, error) {
+	msgs, ids := c.branch()
+	if w.MaxTokens <= 0 {
+		return msgs, nil
+	}
+	sum := c.Summary()
+	if sum.Covered > len(msgs) || (sum.Covered > 0 && sum.Anchor != "" && ids[sum.Covered-1] != sum.Anchor) {
+		// the active branch no longer starts with the summarized messages, start over
+		sum = Summary{}
+	}
+
+	counter := w.Counter
+	if counter == nil {
+		counter = ApproxTokenCounter
+	}
+
+	rest := msgs[sum.Covered:]
+	start := 0
+	if sum.Content == "" {
+		start = tailStart(rest, w.MaxTokens, counter)
+	}
+	if sum.Content != "" || start > 0 {
+		// once a summary is in play it owns part of the budget
+		start = tailStart(rest, max(w.MaxTokens-w.summaryTokens(), 0), counter)
+	}
+	if start > 0 {
+		content, err := w.summarize(ctx, sum.Content, rest[:start])
+		if err != nil {
+			return nil, fmt.Errorf("failed to summarize conversation: %w", err)
+		}
+		covered := sum.Covered + start
+		sum = Summary{
+			Content:   content,
+			Covered:   covered,
+			Anchor:    ids[covered-1],
+			UpdatedAt: time.Now(),
+		}
+		if err := c.SetSummary(sum); err != nil {
+			return nil, err
+		}
+	}
+
+	window := make([]*schema.Message, 0, len(rest)-start+1)
+	if sum.Content != "" {
+		window = append(window, summaryMessage(sum.Content))
+	}
+	return append(window, rest[start:]...), nil
+}
+
+func (w *SummaryWindow) summaryTokens() int {
+	if w.SummaryTokens > 0 {
+		return w.SummaryTokens
+	}
+	return w.MaxTokens / 4
+}
+
+func (w *SummaryWindow) summarize(ctx context.Context, previous string, evicted []*schema.Message) (string, error) {
+	if w.Model == nil {
+		return "", fmt.Errorf("summary window has no chat model")
+	}
+	prompt := w.Prompt
+	if prompt == "" {
+		prompt = defaultSummaryPrompt
+	}
+
+	var sb strings.Builder
+	if previous != "" {
+		sb.WriteString("Previous summary:\n")
+		sb.WriteString(previous)
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString("New messages:\n")
+	sb.WriteString(formatTranscript(evicted))
+
+	out, err := w.Model.Generate(ctx, []*schema.Message{
+		schema.SystemMessage(prompt),
+		schema.UserMessage(sb.String()),
+	})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out.Content), nil
+}
+
+func summaryMessage(content string) *schema.Message {
+	return schema.SystemMessage("Summary of the earlier conversation:\n" + content)
+}
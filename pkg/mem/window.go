@@ -0,0 +1,172 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// WindowStrategy selects the part of a conversation that is fed back to the model as history.
+type WindowStrategy interface {
+	Window(ctx context.Context, c *Conversation) ([]*schema.Message, error)
+}
+
+// TokenCounter estimates how many tokens a message costs in the prompt.
+type TokenCounter func(msg *schema.Message) int
+
+// ApproxTokenCounter is a model-agnostic estimate: one token per CJK rune,
+// one token per four bytes of other text, plus a small per-message overhead.
+func ApproxTokenCounter(msg *schema.Message) int {
+	if msg == nil {
+		return 0
+	}
+	n := 4 + approxTextTokens(msg.Content) + approxTextTokens(msg.ReasoningContent)
+	for _, tc := range msg.ToolCalls {
+		n += 4 + approxTextTokens(tc.Function.Name) + approxTextTokens(tc.Function.Arguments)
+	}
+	return n
+}
+
+func approxTextTokens(s string) int {
+	cjk, other := 0, 0
+	for _, r := range s {
+		if unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+			unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r) {
+			cjk++
+			continue
+		}
+		other += utf8.RuneLen(r)
+	}
+	return cjk + (other+3)/4
+}
+
+// CountWindow keeps the last Size messages. It never starts the window in the middle of a
+// tool call exchange, so the window may hold slightly fewer messages than Size.
+// Size <= 0 keeps the whole conversation.
+type CountWindow struct {
+	Size int
+}
+
+func NewCountWindow(size int) *CountWindow {
+	return &CountWindow{Size: size}
+}
+
+func (w *CountWindow) Window(_ context.Context, c *Conversation) ([]*schema.Message, error) {
+	msgs := c.GetFullMessages()
+	if w.Size <= 0 {
+		return msgs, nil
+	}
+	start := tailStart(msgs, w.Size, func(*schema.Message) int { return 1 })
+	return msgs[start:], nil
+}
+
+// TokenWindow keeps as many recent messages as fit in MaxTokens. An assistant message carrying
+// tool calls and the tool results answering it are kept or dropped together.
+type TokenWindow struct {
+	MaxTokens int
+	// Counter defaults to ApproxTokenCounter.
+	Counter TokenCounter
+}
+
+func NewTokenWindow(maxTokens int) *TokenWindow {
+	return &TokenWindow{MaxTokens: maxTokens}
+}
+
+func (w *TokenWindow) Window(_ context.Context, c *Conversation) ([]*schema.Message, error) {
+	msgs := c.GetFullMessages()
+	if w.MaxTokens <= 0 {
+		return msgs, nil
+	}
+	start := tailStart(msgs, w.MaxTokens, w.counter())
+	return msgs[start:], nil
+}
+
+func (w *TokenWindow) counter() TokenCounter {
+	if w.Counter != nil {
+		return w.Counter
+	}
+	return ApproxTokenCounter
+}
+
+// groupTurns splits messages into units that must not be separated: an assistant message
+// with tool calls together with the tool messages that follow it. Every other message is
+// a unit of its own.
+func groupTurns(msgs []*schema.Message) [][]*schema.Message {
+	groups := make([][]*schema.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		if msg.Role == schema.Tool && len(groups) > 0 {
+			last := groups[len(groups)-1]
+			if last[0].Role == schema.Assistant && len(last[0].ToolCalls) > 0 {
+				groups[len(groups)-1] = append(last, msg)
+				continue
+			}
+		}
+		groups = append(groups, []*schema.Message{msg})
+	}
+	return groups
+}
+
+// tailStart returns the index of the first message of the longest suffix made of whole
+// turns whose cost fits in budget. The last turn is always kept, even if it alone exceeds
+// the budget, so the model never loses the latest exchange.
+func tailStart(msgs []*schema.Message, budget int, cost TokenCounter) int {
+	groups := groupTurns(msgs)
+	start, used := len(msgs), 0
+	for i := len(groups) - 1; i >= 0; i-- {
+		gc := 0
+		for _, msg := range groups[i] {
+			gc += cost(msg)
+		}
+		if used+gc > budget && start < len(msgs) {
+			break
+		}
+		used += gc
+		start -= len(groups[i])
+	}
+	// a tool message whose call was evicted earlier cannot be understood on its own
+	for start < len(msgs) && msgs[start].Role == schema.Tool {
+		start++
+	}
+	return start
+}
+
+// formatTranscript renders messages as plain text for the summarizer.
+func formatTranscript(msgs []*schema.Message) string {
+	var sb strings.Builder
+	for _, msg := range msgs {
+		switch {
+		case msg.Role == schema.Assistant && len(msg.ToolCalls) > 0:
+			if msg.Content != "" {
+				fmt.Fprintf(&sb, "assistant: %s\n", msg.Content)
+			}
+			for _, tc := range msg.ToolCalls {
+				fmt.Fprintf(&sb, "assistant called tool %s with %s\n", tc.Function.Name, tc.Function.Arguments)
+			}
+		case msg.Role == schema.Tool:
+			fmt.Fprintf(&sb, "tool %s returned: %s\n", msg.ToolName, msg.Content)
+		default:
+			fmt.Fprintf(&sb, "%s: %s\n", msg.Role, msg.Content)
+		}
+	}
+	return sb.String()
+}
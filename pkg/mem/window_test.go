@@ -0,0 +1,192 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeChatModel records the prompts it receives and answers with a canned summary.
+type fakeChatModel struct {
+	calls  [][]*schema.Message
+	answer func(input []*schema.Message) string
+	err    error
+}
+
+func (m *fakeChatModel) Generate(_ context.Context, input []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	m.calls = append(m.calls, input)
+	if m.err != nil {
+		return nil, m.err
+	}
+	return schema.AssistantMessage(m.answer(input), nil), nil
+}
+
+func (m *fakeChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, err := m.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+}
+
+func (m *fakeChatModel) WithTools(_ []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	return m, nil
+}
+
+func newTestConversation(t *testing.T, window WindowStrategy) *Conversation {
+	m := NewSimpleMemory(SimpleMemoryConfig{Dir: t.TempDir(), Window: window})
+	return m.GetConversation("test", true)
+}
+
+func toolExchange(i int) []*schema.Message {
+	callID := fmt.Sprintf("call_%d", i)
+	return []*schema.Message{
+		schema.UserMessage(fmt.Sprintf("question %d", i)),
+		schema.AssistantMessage("", []schema.ToolCall{{
+			ID:       callID,
+			Function: schema.FunctionCall{Name: "search", Arguments: `{"q":"x"}`},
+		}}),
+		schema.ToolMessage(fmt.Sprintf("result %d", i), callID, schema.WithToolName("search")),
+		schema.AssistantMessage(fmt.Sprintf("answer %d", i), nil),
+	}
+}
+
+func assertNoOrphanTools(t *testing.T, msgs []*schema.Message) {
+	calls := map[string]bool{}
+	for _, msg := range msgs {
+		for _, tc := range msg.ToolCalls {
+			calls[tc.ID] = true
+		}
+		if msg.Role == schema.Tool {
+			assert.True(t, calls[msg.ToolCallID], "tool result %s without its call", msg.ToolCallID)
+		}
+	}
+}
+
+func TestCountWindowKeepsToolPairs(t *testing.T) {
+	c := newTestConversation(t, NewCountWindow(6))
+	for i := 0; i < 3; i++ {
+		for _, msg := range toolExchange(i) {
+			c.Append(msg)
+		}
+	}
+
+	msgs := c.GetMessages()
+	assert.LessOrEqual(t, len(msgs), 6)
+	assert.NotEqual(t, schema.Tool, msgs[0].Role)
+	assertNoOrphanTools(t, msgs)
+	assert.Equal(t, "answer 2", msgs[len(msgs)-1].Content)
+}
+
+func TestTokenWindow(t *testing.T) {
+	counter := func(*schema.Message) int { return 10 }
+	c := newTestConversation(t, &TokenWindow{MaxTokens: 50, Counter: counter})
+	for i := 0; i < 4; i++ {
+		for _, msg := range toolExchange(i) {
+			c.Append(msg)
+		}
+	}
+
+	msgs, err := c.GetWindowMessages(context.Background())
+	assert.NoError(t, err)
+	// the call/result pair of the last exchange costs 20 and must not be split
+	assert.Equal(t, []string{"answer 2", "question 3", "", "result 3", "answer 3"}, contents(msgs))
+	assertNoOrphanTools(t, msgs)
+
+	// a single oversized turn is still returned
+	c = newTestConversation(t, &TokenWindow{MaxTokens: 1})
+	c.Append(schema.UserMessage(strings.Repeat("long ", 100)))
+	msgs, err = c.GetWindowMessages(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 1)
+}
+
+func TestSummaryWindow(t *testing.T) {
+	ctx := context.Background()
+	cm := &fakeChatModel{answer: func(input []*schema.Message) string {
+		return fmt.Sprintf("summary#%d", strings.Count(input[1].Content, "\n"))
+	}}
+	counter := func(*schema.Message) int { return 10 }
+	dir := t.TempDir()
+	window := &SummaryWindow{Model: cm, MaxTokens: 40, Counter: counter}
+	c := NewSimpleMemory(SimpleMemoryConfig{Dir: dir, Window: window}).GetConversation("s", true)
+
+	for i := 0; i < 3; i++ {
+		c.Append(schema.UserMessage(fmt.Sprintf("q%d", i)))
+		c.Append(schema.AssistantMessage(fmt.Sprintf("a%d", i), nil))
+	}
+
+	msgs, err := c.GetWindowMessages(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, cm.calls, 1)
+	assert.Equal(t, schema.System, msgs[0].Role)
+	assert.Contains(t, msgs[0].Content, "summary#")
+	// summary costs 10, leaving room for three messages
+	assert.Equal(t, []string{"a1", "q2", "a2"}, contents(msgs[1:]))
+	assert.Equal(t, 3, c.Summary().Covered)
+
+	// nothing new was evicted, so the model is not called again
+	_, err = c.GetWindowMessages(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, cm.calls, 1)
+
+	// the summary survives a restart
+	reloaded := NewSimpleMemory(SimpleMemoryConfig{Dir: dir, Window: window}).GetConversation("s", false)
+	assert.Equal(t, c.Summary().Content, reloaded.Summary().Content)
+	assert.Equal(t, 3, reloaded.Summary().Covered)
+
+	// newly evicted turns are merged with the previous summary
+	reloaded.Append(schema.UserMessage("q3"))
+	reloaded.Append(schema.AssistantMessage("a3", nil))
+	msgs, err = reloaded.GetWindowMessages(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, cm.calls, 2)
+	assert.Contains(t, cm.calls[1][1].Content, "Previous summary:")
+	assert.Equal(t, []string{"a2", "q3", "a3"}, contents(msgs[1:]))
+	assert.Equal(t, 5, reloaded.Summary().Covered)
+
+	assert.NotContains(t, NewSimpleMemory(SimpleMemoryConfig{Dir: dir}).ListConversations(), "s.summary")
+}
+
+func TestSummaryWindowModelError(t *testing.T) {
+	cm := &fakeChatModel{err: errors.New("boom")}
+	c := newTestConversation(t, &SummaryWindow{Model: cm, MaxTokens: 10, Counter: func(*schema.Message) int { return 10 }})
+	c.Append(schema.UserMessage("q0"))
+	c.Append(schema.AssistantMessage("a0", nil))
+
+	_, err := c.GetWindowMessages(context.Background())
+	assert.Error(t, err)
+
+	// GetMessages degrades to plain truncation instead of failing
+	assert.Equal(t, []string{"q0", "a0"}, contents(c.GetMessages()))
+}
+
+func contents(msgs []*schema.Message) []string {
+	res := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		res = append(res, msg.Content)
+	}
+	return res
+}
@@ -18,58 +18,114 @@ package model
 
 import (
 	"context"
-	"github.com/cloudwego/eino-ext/components/model/deepseek"
-	"log"
-	"os"
-	"strings"
 
 	"github.com/cloudwego/eino-ext/components/model/ark"
+	"github.com/cloudwego/eino-ext/components/model/deepseek"
+	"github.com/cloudwego/eino-ext/components/model/ollama"
 	"github.com/cloudwego/eino-ext/components/model/openai"
 	"github.com/cloudwego/eino/components/model"
 	arkModel "github.com/volcengine/volcengine-go-sdk/service/arkruntime/model"
 )
 
-func NewChatModel() model.ToolCallingChatModel {
-	modelType := strings.ToLower(os.Getenv("MODEL_TYPE"))
+func init() {
+	Register("openai", newOpenAI)
+	Register("ark", newArk)
+	Register("deepseek", newDeepSeek)
+	Register("ollama", newOllama)
+}
 
-	if modelType == "deepseek" {
-		cm, err := deepseek.NewChatModel(context.Background(), &deepseek.ChatModelConfig{
-			APIKey: os.Getenv("OPENAI_API_KEY"),
-			Model:  os.Getenv("OPENAI_MODEL_NAME"),
-		})
-		if err != nil {
-			log.Fatalf("ark.NewChatModel failed: %v", err)
-		}
-		return cm
+type options struct {
+	config   *Config
+	provider string
+	registry *Registry
+}
+
+type Option func(*options)
+
+// WithConfig uses cfg instead of the environment.
+func WithConfig(cfg *Config) Option {
+	return func(o *options) {
+		o.config = cfg
+	}
+}
+
+// WithProvider replaces the primary provider of the environment config by this one,
+// configured from its environment variables. The fallbacks are kept.
+func WithProvider(provider string) Option {
+	return func(o *options) {
+		o.provider = provider
+	}
+}
+
+// WithRegistry builds the model from another registry than the default one.
+func WithRegistry(r *Registry) Option {
+	return func(o *options) {
+		o.registry = r
+	}
+}
+
+// NewChatModel creates the chat model configured by the environment, see ConfigFromEnv.
+func NewChatModel(ctx context.Context, opts ...Option) (model.ToolCallingChatModel, error) {
+	o := &options{registry: defaultRegistry}
+	for _, opt := range opts {
+		opt(o)
 	}
-	// Create Ark ChatModel when MODEL_TYPE is "ark"
-	if modelType == "ark" {
-		cm, err := ark.NewChatModel(context.Background(), &ark.ChatModelConfig{
-			// Add Ark-specific configuration from environment variables
-			APIKey: os.Getenv("ARK_API_KEY"),
-			Model:  os.Getenv("ARK_MODEL"),
-			//BaseURL: os.Getenv("ARK_BASE_URL"),
-			Thinking: &arkModel.Thinking{
-				Type: arkModel.ThinkingTypeDisabled,
-			},
-		})
+
+	cfg := o.config
+	if cfg == nil {
+		var err error
+		cfg, err = ConfigFromEnv()
 		if err != nil {
-			log.Fatalf("ark.NewChatModel failed: %v", err)
+			return nil, err
 		}
-		return cm
 	}
+	if o.provider != "" {
+		primary := ProviderFromEnv(o.provider)
+		primary.Timeout = cfg.Primary.Timeout
+		cfg = &Config{Primary: primary, Fallbacks: cfg.Fallbacks}
+	}
+	return o.registry.Build(ctx, cfg)
+}
 
-	// Create OpenAI ChatModel (default)
-	cm, err := openai.NewChatModel(context.Background(), &openai.ChatModelConfig{
-		APIKey:  os.Getenv("OPENAI_API_KEY"),
-		Model:   os.Getenv("OPENAI_MODEL"),
-		BaseURL: os.Getenv("OPENAI_BASE_URL"),
-		ByAzure: func() bool {
-			return os.Getenv("OPENAI_BY_AZURE") == "true"
-		}(),
+func newOpenAI(ctx context.Context, cfg *ProviderConfig) (model.ToolCallingChatModel, error) {
+	return openai.NewChatModel(ctx, &openai.ChatModelConfig{
+		APIKey:  cfg.APIKey,
+		Model:   cfg.Model,
+		BaseURL: cfg.BaseURL,
+		ByAzure: cfg.ByAzure,
 	})
-	if err != nil {
-		log.Fatalf("openai.NewChatModel failed: %v", err)
+}
+
+func newArk(ctx context.Context, cfg *ProviderConfig) (model.ToolCallingChatModel, error) {
+	thinking := arkModel.ThinkingTypeDisabled
+	if cfg.Thinking {
+		thinking = arkModel.ThinkingTypeEnabled
 	}
-	return cm
+	return ark.NewChatModel(ctx, &ark.ChatModelConfig{
+		APIKey:  cfg.APIKey,
+		Model:   cfg.Model,
+		BaseURL: cfg.BaseURL,
+		Thinking: &arkModel.Thinking{
+			Type: thinking,
+		},
+	})
+}
+
+func newDeepSeek(ctx context.Context, cfg *ProviderConfig) (model.ToolCallingChatModel, error) {
+	return deepseek.NewChatModel(ctx, &deepseek.ChatModelConfig{
+		APIKey:  cfg.APIKey,
+		Model:   cfg.Model,
+		BaseURL: cfg.BaseURL,
+	})
+}
+
+func newOllama(ctx context.Context, cfg *ProviderConfig) (model.ToolCallingChatModel, error) {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = "http://127.0.0.1:11434"
+	}
+	return ollama.NewChatModel(ctx, &ollama.ChatModelConfig{
+		BaseURL: baseURL,
+		Model:   cfg.Model,
+	})
 }
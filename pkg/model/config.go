@@ -0,0 +1,164 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProviderConfig configures one chat model of a provider registered in a Registry.
+type ProviderConfig struct {
+	// Provider is the name the factory was registered with, e.g. openai, ark, deepseek, ollama.
+	Provider string `yaml:"provider"`
+	Model    string `yaml:"model"`
+	APIKey   string `yaml:"api_key"`
+	BaseURL  string `yaml:"base_url"`
+	// ByAzure selects the Azure flavour of the openai provider.
+	ByAzure bool `yaml:"by_azure"`
+	// Thinking enables deep thinking on providers that support turning it off (ark).
+	Thinking bool `yaml:"thinking"`
+	// Timeout bounds one call, or the wait for the first chunk of a stream, before the next
+	// model of the chain is tried. Zero means no limit.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Config is a primary model and the models tried, in order, when it fails.
+//
+//	primary:
+//	  provider: ark
+//	  model: doubao-seed-1-6
+//	  api_key: ${ARK_API_KEY}
+//	  timeout: 60s
+//	fallbacks:
+//	  - provider: ollama
+//	    model: qwen3:8b
+//	    base_url: http://127.0.0.1:11434
+type Config struct {
+	Primary   ProviderConfig   `yaml:"primary"`
+	Fallbacks []ProviderConfig `yaml:"fallbacks"`
+}
+
+func (c *Config) Validate() error {
+	for i, p := range append([]ProviderConfig{c.Primary}, c.Fallbacks...) {
+		if p.Provider == "" {
+			if i == 0 {
+				return fmt.Errorf("primary: provider is required")
+			}
+			return fmt.Errorf("fallbacks[%d]: provider is required", i-1)
+		}
+		if p.Timeout < 0 {
+			return fmt.Errorf("%s: timeout must not be negative", p.Provider)
+		}
+	}
+	return nil
+}
+
+// LoadConfig reads a YAML config. ${VAR} references are replaced by environment variables, so
+// keys do not have to be written into the file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model config: %w", err)
+	}
+	return ParseConfig(data)
+}
+
+func ParseConfig(data []byte) (*Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(os.ExpandEnv(string(data))), &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse model config: %w", err)
+	}
+	cfg.Primary.Provider = strings.ToLower(cfg.Primary.Provider)
+	for i := range cfg.Fallbacks {
+		cfg.Fallbacks[i].Provider = strings.ToLower(cfg.Fallbacks[i].Provider)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// ConfigFromEnv reads the YAML file named by MODEL_CONFIG. Without it the primary provider is
+// MODEL_TYPE (openai by default) and MODEL_FALLBACKS lists the fallback providers, separated
+// by commas, each configured by its usual environment variables.
+func ConfigFromEnv() (*Config, error) {
+	if path := os.Getenv("MODEL_CONFIG"); path != "" {
+		return LoadConfig(path)
+	}
+	cfg := &Config{Primary: ProviderFromEnv(os.Getenv("MODEL_TYPE"))}
+	for _, name := range strings.Split(os.Getenv("MODEL_FALLBACKS"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			cfg.Fallbacks = append(cfg.Fallbacks, ProviderFromEnv(name))
+		}
+	}
+	if v := os.Getenv("MODEL_TIMEOUT"); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MODEL_TIMEOUT: %s", v)
+		}
+		cfg.Primary.Timeout = timeout
+		for i := range cfg.Fallbacks {
+			cfg.Fallbacks[i].Timeout = timeout
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ProviderFromEnv configures a built-in provider from the environment variables it has always
+// used, e.g. ARK_API_KEY and ARK_MODEL for ark.
+func ProviderFromEnv(provider string) ProviderConfig {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	switch provider {
+	case "deepseek":
+		return ProviderConfig{
+			Provider: provider,
+			APIKey:   os.Getenv("OPENAI_API_KEY"),
+			Model:    os.Getenv("OPENAI_MODEL_NAME"),
+		}
+	case "ark":
+		return ProviderConfig{
+			Provider: provider,
+			APIKey:   os.Getenv("ARK_API_KEY"),
+			Model:    os.Getenv("ARK_MODEL"),
+		}
+	case "ollama":
+		return ProviderConfig{
+			Provider: provider,
+			BaseURL:  os.Getenv("OLLAMA_BASE_URL"),
+			Model:    os.Getenv("OLLAMA_MODEL"),
+		}
+	case "", "openai":
+		return ProviderConfig{
+			Provider: "openai",
+			APIKey:   os.Getenv("OPENAI_API_KEY"),
+			Model:    os.Getenv("OPENAI_MODEL"),
+			BaseURL:  os.Getenv("OPENAI_BASE_URL"),
+			ByAzure:  os.Getenv("OPENAI_BY_AZURE") == "true",
+		}
+	default:
+		// a provider registered by the application, configured in code or YAML
+		return ProviderConfig{Provider: provider}
+	}
+}
@@ -0,0 +1,197 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// Candidate is one model of a fallback chain.
+type Candidate struct {
+	Name  string
+	Model model.ToolCallingChatModel
+	// Timeout bounds a call, or the wait for the first chunk of a stream. Zero means no limit.
+	Timeout time.Duration
+}
+
+// FallbackChatModel tries its candidates in order until one answers. A candidate is skipped
+// when it returns an error or runs out of time; once a stream has produced its first chunk it
+// is not abandoned any more, so later stream errors reach the caller.
+type FallbackChatModel struct {
+	candidates []Candidate
+}
+
+func NewFallbackChatModel(candidates ...Candidate) (*FallbackChatModel, error) {
+	if len(candidates) == 0 {
+		return nil, errors.New("at least one model is required")
+	}
+	for i, c := range candidates {
+		if c.Model == nil {
+			return nil, fmt.Errorf("model %d (%s) is nil", i, c.Name)
+		}
+	}
+	return &FallbackChatModel{candidates: candidates}, nil
+}
+
+func (f *FallbackChatModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	var errs []error
+	for i, c := range f.candidates {
+		callCtx, cancel := withTimeout(ctx, c.Timeout)
+		msg, err := c.Model.Generate(callCtx, input, opts...)
+		cancel()
+		if err == nil {
+			return msg, nil
+		}
+		if ctx.Err() != nil {
+			// the caller gave up, the next model would not be awaited either
+			return nil, err
+		}
+		errs = append(errs, f.failed(i, err))
+	}
+	return nil, fmt.Errorf("all models failed: %w", errors.Join(errs...))
+}
+
+func (f *FallbackChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	var errs []error
+	for i, c := range f.candidates {
+		sr, err := streamFirstChunk(ctx, c, input, opts)
+		if err == nil {
+			return sr, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		errs = append(errs, f.failed(i, err))
+	}
+	return nil, fmt.Errorf("all models failed: %w", errors.Join(errs...))
+}
+
+func (f *FallbackChatModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	candidates := make([]Candidate, len(f.candidates))
+	for i, c := range f.candidates {
+		cm, err := c.Model.WithTools(tools)
+		if err != nil {
+			return nil, fmt.Errorf("failed to bind tools to %s: %w", c.Name, err)
+		}
+		candidates[i] = Candidate{Name: c.Name, Model: cm, Timeout: c.Timeout}
+	}
+	return &FallbackChatModel{candidates: candidates}, nil
+}
+
+func (f *FallbackChatModel) GetType() string {
+	return "Fallback"
+}
+
+// IsCallbacksEnabled reports that callbacks are left to the candidates, which report their
+// own calls, so a fallback shows up as two model calls.
+func (f *FallbackChatModel) IsCallbacksEnabled() bool {
+	return true
+}
+
+func (f *FallbackChatModel) failed(i int, err error) error {
+	c := f.candidates[i]
+	if i+1 < len(f.candidates) {
+		log.Printf("[model] %s failed, falling back to %s: %v", c.Name, f.candidates[i+1].Name, err)
+	}
+	return fmt.Errorf("%s: %w", c.Name, err)
+}
+
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// streamFirstChunk starts a stream and waits for its first chunk within the timeout of the
+// candidate. The returned stream replays that chunk, then forwards the rest.
+func streamFirstChunk(ctx context.Context, c Candidate, input []*schema.Message, opts []model.Option) (*schema.StreamReader[*schema.Message], error) {
+	// the stream outlives this call, so only the wait for the first chunk is bounded
+	streamCtx, cancel := context.WithCancel(ctx)
+	sr, err := c.Model.Stream(streamCtx, input, opts...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	type chunk struct {
+		msg *schema.Message
+		err error
+	}
+	first := make(chan chunk, 1)
+	go func() {
+		msg, err := sr.Recv()
+		first <- chunk{msg, err}
+	}()
+
+	var timeout <-chan time.Time
+	if c.Timeout > 0 {
+		timer := time.NewTimer(c.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	var head chunk
+	select {
+	case head = <-first:
+	case <-timeout:
+		cancel()
+		sr.Close()
+		return nil, fmt.Errorf("no response within %s: %w", c.Timeout, context.DeadlineExceeded)
+	case <-ctx.Done():
+		cancel()
+		sr.Close()
+		return nil, ctx.Err()
+	}
+	if head.err != nil && !errors.Is(head.err, io.EOF) {
+		cancel()
+		sr.Close()
+		return nil, head.err
+	}
+
+	out, w := schema.Pipe[*schema.Message](1)
+	go func() {
+		defer cancel()
+		defer sr.Close()
+		defer w.Close()
+		if head.err != nil {
+			// an empty answer is still an answer
+			return
+		}
+		if closed := w.Send(head.msg, nil); closed {
+			return
+		}
+		for {
+			msg, err := sr.Recv()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if closed := w.Send(msg, err); closed || err != nil {
+				return
+			}
+		}
+	}()
+	return out, nil
+}
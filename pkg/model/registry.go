@@ -0,0 +1,120 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/cloudwego/eino/components/model"
+)
+
+var ErrUnknownProvider = errors.New("unknown model provider")
+
+// Factory creates a chat model from its config.
+type Factory func(ctx context.Context, cfg *ProviderConfig) (model.ToolCallingChatModel, error)
+
+// Registry maps provider names to factories.
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}
+
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]Factory)}
+}
+
+var defaultRegistry = NewRegistry()
+
+// DefaultRegistry holds the built-in providers and those added with Register.
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+// Register adds a provider to the default registry, replacing one of the same name.
+func Register(name string, factory Factory) {
+	defaultRegistry.Register(name, factory)
+}
+
+func (r *Registry) Register(name string, factory Factory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.factories[name] = factory
+}
+
+// Providers lists the registered provider names.
+func (r *Registry) Providers() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates one model of a registered provider.
+func (r *Registry) New(ctx context.Context, cfg *ProviderConfig) (model.ToolCallingChatModel, error) {
+	r.mu.RLock()
+	factory, ok := r.factories[cfg.Provider]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q, registered: %v", ErrUnknownProvider, cfg.Provider, r.Providers())
+	}
+	cm, err := factory(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s chat model: %w", cfg.Provider, err)
+	}
+	return cm, nil
+}
+
+// Build creates the model of a config. With fallbacks or a timeout it is a FallbackChatModel,
+// otherwise the primary model itself.
+func (r *Registry) Build(ctx context.Context, cfg *Config) (model.ToolCallingChatModel, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	primary, err := r.New(ctx, &cfg.Primary)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Fallbacks) == 0 && cfg.Primary.Timeout == 0 {
+		return primary, nil
+	}
+
+	chain := []Candidate{{Name: candidateName(&cfg.Primary), Model: primary, Timeout: cfg.Primary.Timeout}}
+	for i := range cfg.Fallbacks {
+		fc := &cfg.Fallbacks[i]
+		cm, err := r.New(ctx, fc)
+		if err != nil {
+			return nil, fmt.Errorf("fallbacks[%d]: %w", i, err)
+		}
+		chain = append(chain, Candidate{Name: candidateName(fc), Model: cm, Timeout: fc.Timeout})
+	}
+	return NewFallbackChatModel(chain...)
+}
+
+func candidateName(cfg *ProviderConfig) string {
+	if cfg.Model == "" {
+		return cfg.Provider
+	}
+	return cfg.Provider + "/" + cfg.Model
+}
@@ -0,0 +1,241 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeModel answers with its name, fails with err, or hangs until the context is done.
+type fakeModel struct {
+	name  string
+	err   error
+	hang  bool
+	tools int
+	calls *atomic.Int32
+}
+
+func (m *fakeModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	m.calls.Add(1)
+	if m.hang {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if m.err != nil {
+		return nil, m.err
+	}
+	return schema.AssistantMessage(m.name, nil), nil
+}
+
+func (m *fakeModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	m.calls.Add(1)
+	sr, w := schema.Pipe[*schema.Message](2)
+	go func() {
+		defer w.Close()
+		switch {
+		case m.hang:
+			<-ctx.Done()
+			w.Send(nil, ctx.Err())
+		case m.err != nil:
+			w.Send(nil, m.err)
+		default:
+			w.Send(schema.AssistantMessage(m.name, nil), nil)
+			w.Send(schema.AssistantMessage("!", nil), nil)
+		}
+	}()
+	return sr, nil
+}
+
+func (m *fakeModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	cp := *m
+	cp.tools = len(tools)
+	return &cp, nil
+}
+
+func fakeRegistry(models map[string]*fakeModel) *Registry {
+	r := NewRegistry()
+	r.Register("fake", func(ctx context.Context, cfg *ProviderConfig) (model.ToolCallingChatModel, error) {
+		m, ok := models[cfg.Model]
+		if !ok {
+			return nil, errors.New("no such model")
+		}
+		if m.calls == nil {
+			m.calls = &atomic.Int32{}
+		}
+		return m, nil
+	})
+	return r
+}
+
+func fake(name string, timeout time.Duration) ProviderConfig {
+	return ProviderConfig{Provider: "fake", Model: name, Timeout: timeout}
+}
+
+func TestRegistry(t *testing.T) {
+	ctx := context.Background()
+	r := fakeRegistry(map[string]*fakeModel{"a": {name: "a"}})
+
+	cm, err := r.Build(ctx, &Config{Primary: fake("a", 0)})
+	require.NoError(t, err)
+	_, isFallback := cm.(*FallbackChatModel)
+	assert.False(t, isFallback, "a single model without timeout is not wrapped")
+
+	_, err = r.Build(ctx, &Config{Primary: ProviderConfig{Provider: "missing"}})
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+	_, err = r.Build(ctx, &Config{Primary: fake("a", 0), Fallbacks: []ProviderConfig{fake("b", 0)}})
+	assert.ErrorContains(t, err, "fallbacks[0]")
+	_, err = r.Build(ctx, &Config{})
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{"ark", "deepseek", "ollama", "openai"}, DefaultRegistry().Providers())
+}
+
+func TestFallbackGenerate(t *testing.T) {
+	ctx := context.Background()
+	models := map[string]*fakeModel{
+		"broken": {name: "broken", err: errors.New("503")},
+		"slow":   {name: "slow", hang: true},
+		"ok":     {name: "ok"},
+	}
+	r := fakeRegistry(models)
+
+	cm, err := r.Build(ctx, &Config{
+		Primary:   fake("broken", 0),
+		Fallbacks: []ProviderConfig{fake("slow", 20*time.Millisecond), fake("ok", 0)},
+	})
+	require.NoError(t, err)
+	msg, err := cm.Generate(ctx, []*schema.Message{schema.UserMessage("hi")})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", msg.Content)
+	for _, m := range models {
+		assert.EqualValues(t, 1, m.calls.Load(), m.name)
+	}
+
+	// every model failing reports every error
+	cm, err = r.Build(ctx, &Config{Primary: fake("broken", 0), Fallbacks: []ProviderConfig{fake("slow", 10*time.Millisecond)}})
+	require.NoError(t, err)
+	_, err = cm.Generate(ctx, nil)
+	assert.ErrorContains(t, err, "503")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// a caller that gives up does not start the next model
+	cm, err = r.Build(ctx, &Config{Primary: fake("slow", 0), Fallbacks: []ProviderConfig{fake("ok", 0)}})
+	require.NoError(t, err)
+	calls := models["ok"].calls.Load()
+	cancelled, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = cm.Generate(cancelled, nil)
+	assert.Error(t, err)
+	assert.Equal(t, calls, models["ok"].calls.Load())
+}
+
+func TestFallbackStream(t *testing.T) {
+	ctx := context.Background()
+	r := fakeRegistry(map[string]*fakeModel{
+		"broken": {name: "broken", err: errors.New("503")},
+		"slow":   {name: "slow", hang: true},
+		"ok":     {name: "ok"},
+	})
+	cm, err := r.Build(ctx, &Config{
+		Primary:   fake("broken", 0),
+		Fallbacks: []ProviderConfig{fake("slow", 20*time.Millisecond), fake("ok", 0)},
+	})
+	require.NoError(t, err)
+
+	sr, err := cm.Stream(ctx, nil)
+	require.NoError(t, err)
+	defer sr.Close()
+	var content string
+	for {
+		msg, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		content += msg.Content
+	}
+	assert.Equal(t, "ok!", content)
+}
+
+func TestFallbackWithTools(t *testing.T) {
+	r := fakeRegistry(map[string]*fakeModel{"a": {name: "a"}, "b": {name: "b"}})
+	cm, err := r.Build(context.Background(), &Config{Primary: fake("a", 0), Fallbacks: []ProviderConfig{fake("b", 0)}})
+	require.NoError(t, err)
+
+	bound, err := cm.WithTools([]*schema.ToolInfo{{Name: "search"}})
+	require.NoError(t, err)
+	for _, c := range bound.(*FallbackChatModel).candidates {
+		assert.Equal(t, 1, c.Model.(*fakeModel).tools, c.Name)
+	}
+	for _, c := range cm.(*FallbackChatModel).candidates {
+		assert.Equal(t, 0, c.Model.(*fakeModel).tools, "the original chain is unchanged")
+	}
+}
+
+func TestConfig(t *testing.T) {
+	t.Setenv("FAKE_KEY", "secret")
+	path := filepath.Join(t.TempDir(), "model.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+primary:
+  provider: Ark
+  model: doubao
+  api_key: ${FAKE_KEY}
+  timeout: 30s
+fallbacks:
+  - provider: ollama
+    model: qwen3:8b
+`), 0644))
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "ark", cfg.Primary.Provider)
+	assert.Equal(t, "secret", cfg.Primary.APIKey)
+	assert.Equal(t, 30*time.Second, cfg.Primary.Timeout)
+	require.Len(t, cfg.Fallbacks, 1)
+	assert.Equal(t, "qwen3:8b", cfg.Fallbacks[0].Model)
+
+	_, err = ParseConfig([]byte("fallbacks:\n  - model: x\n"))
+	assert.Error(t, err)
+
+	t.Setenv("MODEL_CONFIG", "")
+	t.Setenv("MODEL_TYPE", "deepseek")
+	t.Setenv("MODEL_FALLBACKS", "ark, ollama")
+	t.Setenv("MODEL_TIMEOUT", "45s")
+	t.Setenv("ARK_MODEL", "doubao")
+	cfg, err = ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "deepseek", cfg.Primary.Provider)
+	require.Len(t, cfg.Fallbacks, 2)
+	assert.Equal(t, "doubao", cfg.Fallbacks[0].Model)
+	assert.Equal(t, 45*time.Second, cfg.Fallbacks[1].Timeout)
+
+	t.Setenv("MODEL_TIMEOUT", "soon")
+	_, err = ConfigFromEnv()
+	assert.Error(t, err)
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	template "github.com/cloudwego/eino/utils/callbacks"
+)
+
+// UsageCallback reports the token usage of every chat model call of a run that has one.
+type UsageCallback struct {
+	Report func(usage *model.TokenUsage)
+
+	// pending counts the streamed outputs still being read
+	pending sync.WaitGroup
+}
+
+// Handler returns the callback handler to run the chat models with.
+func (u *UsageCallback) Handler() callbacks.Handler {
+	return template.NewHandlerHelper().
+		ChatModel(&template.ModelCallbackHandler{
+			OnEnd: func(ctx context.Context, info *callbacks.RunInfo, output *model.CallbackOutput) context.Context {
+				u.report(UsageOf(output))
+				return ctx
+			},
+			OnEndWithStreamOutput: func(ctx context.Context, info *callbacks.RunInfo, output *schema.StreamReader[*model.CallbackOutput]) context.Context {
+				u.pending.Add(1)
+				go func() {
+					defer u.pending.Done()
+					defer output.Close()
+					// providers report the usage of a stream on its last chunks
+					var usage *model.TokenUsage
+					for {
+						chunk, err := output.Recv()
+						if err != nil {
+							break
+						}
+						if t := UsageOf(chunk); t != nil {
+							usage = t
+						}
+					}
+					u.report(usage)
+				}()
+				return ctx
+			},
+		}).
+		Handler()
+}
+
+// Wait blocks until the usage of the streamed outputs seen so far has been reported.
+func (u *UsageCallback) Wait() {
+	u.pending.Wait()
+}
+
+func (u *UsageCallback) report(usage *model.TokenUsage) {
+	if usage != nil && u.Report != nil {
+		u.Report(usage)
+	}
+}
+
+// UsageOf reads the token usage of a model output, from its message when the callback does not
+// carry it.
+func UsageOf(output *model.CallbackOutput) *model.TokenUsage {
+	if output == nil {
+		return nil
+	}
+	if output.TokenUsage != nil {
+		return output.TokenUsage
+	}
+	if output.Message != nil && output.Message.ResponseMeta != nil && output.Message.ResponseMeta.Usage != nil {
+		u := output.Message.ResponseMeta.Usage
+		return &model.TokenUsage{
+			PromptTokens:     u.PromptTokens,
+			CompletionTokens: u.CompletionTokens,
+			TotalTokens:      u.TotalTokens,
+		}
+	}
+	return nil
+}
@@ -53,21 +53,22 @@ type Config struct {
 	Dimension int
 }
 
+// NewClient creates a client speaking RESP2, which the FT.* search commands and the
+// eino redis indexer/retriever expect.
+func NewClient(addr string) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Protocol: 2,
+	})
+}
+
 func InitRedisIndex(ctx context.Context, config *Config) (err error) {
 	if config.Dimension <= 0 {
 		return fmt.Errorf("dimension must be positive")
 	}
 
-	client := redis.NewClient(&redis.Options{
-		Addr:     config.RedisAddr,
-		Protocol: 2,
-	})
-
-	defer func() {
-		if err != nil {
-			client.Close()
-		}
-	}()
+	client := NewClient(config.RedisAddr)
+	defer client.Close()
 
 	if err = client.Ping(ctx).Err(); err != nil {
 		return fmt.Errorf("failed to connect to Redis: %w", err)
@@ -0,0 +1,241 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package hybrid retrieves documents from a RediSearch index by both BM25 full-text search
+// and vector KNN, fuses the two rankings with reciprocal-rank fusion (RRF), and optionally
+// reranks the result.
+package hybrid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	redispkg "likeeino/pkg/redis"
+
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/retriever"
+	"github.com/cloudwego/eino/schema"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultTopK = 8
+	defaultRRFK = 60
+
+	// MetaVectorRank and MetaTextRank record the 1-based rank of a document in each search,
+	// absent when the search did not find it.
+	MetaVectorRank = "_vector_rank"
+	MetaTextRank   = "_text_rank"
+)
+
+type Config struct {
+	Client *redis.Client
+	// Index defaults to the index of pkg/redis.
+	Index     string
+	Embedding embedding.Embedder
+
+	// Field names default to those of pkg/redis.
+	ContentField  string
+	MetadataField string
+	VectorField   string
+
+	// TopK is the number of documents returned, 8 by default.
+	TopK int
+	// CandidateK is the number of documents each search contributes to the fusion, 4*TopK by
+	// default.
+	CandidateK int
+	// RRFK dampens the advantage of the first ranks, 60 by default.
+	RRFK int
+	// TextWeight and VectorWeight scale the RRF contribution of each search, 1 by default.
+	// A negative weight disables that search.
+	TextWeight   float64
+	VectorWeight float64
+
+	// MinSimilarity drops vector hits whose cosine similarity is below it before fusion, so
+	// unrelated neighbours do not make it in just because the index is small.
+	MinSimilarity float64
+	// ScoreThreshold drops documents whose final score is below it. Without a reranker the
+	// score is the fused RRF score scaled to [0, 1], 1 being first in both searches; with a
+	// reranker it is the reranker's score, and documents it did not score (see MetaUnranked)
+	// are kept.
+	ScoreThreshold float64
+
+	// Reranker reorders the fused candidates, optional.
+	Reranker Reranker
+}
+
+// Retriever implements retriever.Retriever.
+type Retriever struct {
+	conf *Config
+}
+
+func NewRetriever(ctx context.Context, conf *Config) (*Retriever, error) {
+	if conf.Client == nil {
+		return nil, errors.New("redis client is required")
+	}
+	c := *conf
+	if c.Index == "" {
+		c.Index = redispkg.RedisPrefix + redispkg.IndexName
+	}
+	if c.ContentField == "" {
+		c.ContentField = redispkg.ContentField
+	}
+	if c.MetadataField == "" {
+		c.MetadataField = redispkg.MetadataField
+	}
+	if c.VectorField == "" {
+		c.VectorField = redispkg.VectorField
+	}
+	if c.TopK <= 0 {
+		c.TopK = defaultTopK
+	}
+	if c.CandidateK <= 0 {
+		c.CandidateK = 4 * c.TopK
+	}
+	if c.RRFK <= 0 {
+		c.RRFK = defaultRRFK
+	}
+	if c.TextWeight == 0 {
+		c.TextWeight = 1
+	}
+	if c.VectorWeight == 0 {
+		c.VectorWeight = 1
+	}
+	if c.VectorWeight > 0 && c.Embedding == nil {
+		return nil, errors.New("embedding is required for vector search")
+	}
+	if c.TextWeight < 0 && c.VectorWeight < 0 {
+		return nil, errors.New("at least one of text and vector search must be enabled")
+	}
+	return &Retriever{conf: &c}, nil
+}
+
+// Retrieve honours the TopK, ScoreThreshold and Embedding options of retriever.
+func (r *Retriever) Retrieve(ctx context.Context, query string, opts ...retriever.Option) ([]*schema.Document, error) {
+	topK, threshold := r.conf.TopK, r.conf.ScoreThreshold
+	options := retriever.GetCommonOptions(&retriever.Options{
+		TopK:           &topK,
+		ScoreThreshold: &threshold,
+		Embedding:      r.conf.Embedding,
+	}, opts...)
+	if options.TopK != nil {
+		topK = *options.TopK
+	}
+	if options.ScoreThreshold != nil {
+		threshold = *options.ScoreThreshold
+	}
+	candidateK := r.conf.CandidateK
+	if candidateK < topK {
+		candidateK = topK
+	}
+
+	var textHits, vectorHits []*hit
+	var err error
+	if r.conf.TextWeight > 0 {
+		if textHits, err = r.searchText(ctx, query, candidateK); err != nil {
+			return nil, fmt.Errorf("failed to search text: %w", err)
+		}
+	}
+	if r.conf.VectorWeight > 0 {
+		if vectorHits, err = r.searchVector(ctx, options.Embedding, query, candidateK); err != nil {
+			return nil, fmt.Errorf("failed to search vectors: %w", err)
+		}
+	}
+
+	docs := r.fuse(textHits, vectorHits)
+	if r.conf.Reranker != nil && len(docs) > 0 {
+		if docs, err = r.conf.Reranker.Rerank(ctx, query, docs); err != nil {
+			return nil, fmt.Errorf("failed to rerank: %w", err)
+		}
+	}
+
+	res := make([]*schema.Document, 0, topK)
+	for _, doc := range docs {
+		if len(res) == topK {
+			break
+		}
+		if unranked, _ := doc.MetaData[MetaUnranked].(bool); doc.Score() < threshold && !unranked {
+			continue
+		}
+		res = append(res, doc)
+	}
+	return res, nil
+}
+
+func (r *Retriever) GetType() string {
+	return "Hybrid"
+}
+
+// hit is a document found by one search, in rank order.
+type hit struct {
+	doc *schema.Document
+	// similarity is the cosine similarity of a vector hit, or the BM25 score of a text hit
+	similarity float64
+}
+
+// fuse merges the two rankings by reciprocal-rank fusion: a document scores the sum of
+// weight/(k+rank) over the searches that found it.
+func (r *Retriever) fuse(textHits, vectorHits []*hit) []*schema.Document {
+	type fused struct {
+		doc   *schema.Document
+		score float64
+		// best single-search rank, to break ties
+		best int
+	}
+	byID := make(map[string]*fused)
+	var order []*fused
+	add := func(hits []*hit, weight float64, rankKey string) {
+		rank := 0
+		for _, h := range hits {
+			if rankKey == MetaVectorRank && h.similarity < r.conf.MinSimilarity {
+				continue
+			}
+			rank++
+			f, ok := byID[h.doc.ID]
+			if !ok {
+				f = &fused{doc: h.doc, best: rank}
+				byID[h.doc.ID] = f
+				order = append(order, f)
+			}
+			f.score += weight / float64(r.conf.RRFK+rank)
+			f.best = min(f.best, rank)
+			f.doc.MetaData[rankKey] = rank
+		}
+	}
+	maxScore := 0.0
+	if r.conf.TextWeight > 0 {
+		add(textHits, r.conf.TextWeight, MetaTextRank)
+		maxScore += r.conf.TextWeight / float64(r.conf.RRFK+1)
+	}
+	if r.conf.VectorWeight > 0 {
+		add(vectorHits, r.conf.VectorWeight, MetaVectorRank)
+		maxScore += r.conf.VectorWeight / float64(r.conf.RRFK+1)
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		if order[i].score != order[j].score {
+			return order[i].score > order[j].score
+		}
+		return order[i].best < order[j].best
+	})
+	docs := make([]*schema.Document, len(order))
+	for i, f := range order {
+		docs[i] = f.doc.WithScore(f.score / maxScore)
+	}
+	return docs
+}
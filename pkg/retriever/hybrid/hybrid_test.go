@@ -0,0 +1,354 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hybrid
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	redispkg "likeeino/pkg/redis"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alicebob/miniredis/v2/server"
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/retriever"
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEmbedder puts every text on a few topic axes, plus one for anything else.
+type fakeEmbedder struct{}
+
+var topics = []string{"graph", "chain", "model", "agent"}
+
+func (fakeEmbedder) EmbedStrings(ctx context.Context, texts []string, opts ...embedding.Option) ([][]float64, error) {
+	res := make([][]float64, len(texts))
+	for i, text := range texts {
+		v := make([]float64, len(topics)+1)
+		for _, w := range tokens(text) {
+			found := false
+			for j, topic := range topics {
+				if w == topic {
+					v[j]++
+					found = true
+				}
+			}
+			if !found {
+				v[len(topics)] += 0.01
+			}
+		}
+		res[i] = v
+	}
+	return res, nil
+}
+
+func tokens(text string) []string {
+	var res []string
+	for _, t := range queryTerms(text) {
+		res = append(res, strings.ToLower(strings.ReplaceAll(t, `\`, "")))
+	}
+	return res
+}
+
+// fakeSearch serves FT.SEARCH over the hashes in miniredis, with a term count standing in for
+// BM25 and exact cosine distance for KNN.
+func fakeSearch(t *testing.T, mr *miniredis.Miniredis) {
+	require.NoError(t, mr.Server().Register("FT.SEARCH", func(c *server.Peer, cmd string, args []string) {
+		query := args[1]
+		type result struct {
+			key, score string
+			value      float64
+		}
+		var results []result
+		for _, key := range mr.Keys() {
+			if !strings.HasPrefix(key, redispkg.RedisPrefix) {
+				continue
+			}
+			content := mr.HGet(key, redispkg.ContentField)
+			if strings.Contains(query, "KNN") {
+				var k int
+				fmt.Sscanf(query, "*=>[KNN %d", &k)
+				vec := args[indexOf(args, "PARAMS")+3]
+				d := 1 - cosine(bytesToVector(vec), bytesToVector(mr.HGet(key, redispkg.VectorField)))
+				results = append(results, result{key: key, value: d, score: strconv.FormatFloat(d, 'f', -1, 64)})
+				continue
+			}
+			terms := strings.Split(strings.TrimSuffix(strings.SplitN(query, ":(", 2)[1], ")"), "|")
+			n := 0
+			for _, term := range terms {
+				for _, w := range tokens(content) {
+					if w == strings.ToLower(strings.ReplaceAll(term, `\`, "")) {
+						n++
+					}
+				}
+			}
+			if n > 0 {
+				results = append(results, result{key: key, value: float64(n), score: strconv.Itoa(n)})
+			}
+		}
+		knn := strings.Contains(query, "KNN")
+		sort.Slice(results, func(i, j int) bool {
+			if knn {
+				return results[i].value < results[j].value
+			}
+			return results[i].value > results[j].value
+		})
+		if limit, _ := strconv.Atoi(args[indexOf(args, "LIMIT")+2]); len(results) > limit {
+			results = results[:limit]
+		}
+
+		step := 2
+		if !knn {
+			step = 3
+		}
+		c.WriteLen(1 + step*len(results))
+		c.WriteInt(len(results))
+		for _, r := range results {
+			c.WriteBulk(r.key)
+			fields := []string{redispkg.ContentField, mr.HGet(r.key, redispkg.ContentField), redispkg.MetadataField, mr.HGet(r.key, redispkg.MetadataField)}
+			if knn {
+				fields = append(fields, distanceAlias, r.score)
+			} else {
+				c.WriteBulk(r.score)
+			}
+			c.WriteStrings(fields)
+		}
+	}))
+}
+
+func indexOf(args []string, s string) int {
+	for i, a := range args {
+		if a == s {
+			return i
+		}
+	}
+	return -1
+}
+
+func bytesToVector(s string) []float64 {
+	v := make([]float64, len(s)/4)
+	for i := range v {
+		v[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32([]byte(s[i*4:]))))
+	}
+	return v
+}
+
+func cosine(a, b []float64) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
+
+var testDocs = map[string]string{
+	"graph": "Use compose NewGraph to build a graph of nodes and edges.",
+	"chain": "A chain is a graph without branches, made with compose NewChain.",
+	"model": "NewChatModel creates the model from MODEL_TYPE.",
+	"agent": "An agent calls tools in a loop until the agent is done.",
+}
+
+func newTestRetriever(t *testing.T, conf *Config) *Retriever {
+	mr := miniredis.RunT(t)
+	fakeSearch(t, mr)
+	client := redispkg.NewClient(mr.Addr())
+	t.Cleanup(func() { client.Close() })
+
+	ctx := context.Background()
+	for id, content := range testDocs {
+		vecs, err := fakeEmbedder{}.EmbedStrings(ctx, []string{content})
+		require.NoError(t, err)
+		meta, _ := json.Marshal(map[string]any{"source": id + ".md", "chunk": 1})
+		require.NoError(t, client.HSet(ctx, redispkg.RedisPrefix+id,
+			redispkg.ContentField, content,
+			redispkg.MetadataField, string(meta),
+			redispkg.VectorField, vectorToBytes(vecs[0]),
+		).Err())
+	}
+
+	conf.Client = client
+	conf.Embedding = fakeEmbedder{}
+	r, err := NewRetriever(ctx, conf)
+	require.NoError(t, err)
+	return r
+}
+
+func ids(docs []*schema.Document) []string {
+	res := make([]string, len(docs))
+	for i, doc := range docs {
+		res[i] = strings.TrimPrefix(doc.ID, redispkg.RedisPrefix)
+	}
+	return res
+}
+
+func TestHybridFusion(t *testing.T) {
+	r := newTestRetriever(t, &Config{MinSimilarity: 0.5})
+	ctx := context.Background()
+
+	// found by both searches, first in both
+	docs, err := r.Retrieve(ctx, "build graph")
+	require.NoError(t, err)
+	require.NotEmpty(t, docs)
+	assert.Equal(t, "graph", ids(docs)[0])
+	assert.InDelta(t, 1.0, docs[0].Score(), 1e-9)
+	assert.Equal(t, "graph.md", docs[0].MetaData["source"])
+	assert.EqualValues(t, 1, docs[0].MetaData["chunk"])
+	assert.Equal(t, 1, docs[0].MetaData[MetaTextRank])
+	assert.Equal(t, 1, docs[0].MetaData[MetaVectorRank])
+	assert.Contains(t, ids(docs), "chain")
+
+	// an exact API name the embedding knows nothing about is found by text
+	docs, err = r.Retrieve(ctx, "NewChatModel")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"model"}, ids(docs))
+	_, hasVectorRank := docs[0].MetaData[MetaVectorRank]
+	assert.False(t, hasVectorRank)
+
+	// options override the config
+	docs, err = r.Retrieve(ctx, "build graph", retriever.WithTopK(1))
+	require.NoError(t, err)
+	assert.Len(t, docs, 1)
+	docs, err = r.Retrieve(ctx, "build graph", retriever.WithScoreThreshold(0.99))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"graph"}, ids(docs))
+}
+
+func TestHybridSingleSearch(t *testing.T) {
+	r := newTestRetriever(t, &Config{TextWeight: -1})
+	docs, err := r.Retrieve(context.Background(), "NewChatModel agent")
+	require.NoError(t, err)
+	assert.Equal(t, "agent", ids(docs)[0])
+	for _, doc := range docs {
+		_, ok := doc.MetaData[MetaTextRank]
+		assert.False(t, ok)
+	}
+
+	_, err = NewRetriever(context.Background(), &Config{Client: r.conf.Client})
+	assert.Error(t, err, "vector search needs an embedder")
+}
+
+// gradingModel grades documents by how often they mention word. A positive limit grades only
+// that many documents, like a truncated reply.
+type gradingModel struct {
+	word  string
+	limit int
+}
+
+func (g *gradingModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	prompt := input[0].Content
+	var grades []grade
+	for i, part := range strings.Split(prompt, "\n[")[1:] {
+		if g.limit > 0 && i == g.limit {
+			break
+		}
+		grades = append(grades, grade{Index: i, Score: float64(5 * strings.Count(part, g.word))})
+	}
+	data, _ := json.Marshal(grades)
+	return schema.AssistantMessage("Here you go:\n```json\n"+string(data)+"\n```", nil), nil
+}
+
+func (g *gradingModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+func TestHybridLLMRerank(t *testing.T) {
+	r := newTestRetriever(t, &Config{
+		Reranker:       &LLMReranker{Model: &gradingModel{word: "agent"}},
+		ScoreThreshold: 0.5,
+	})
+	docs, err := r.Retrieve(context.Background(), "graph agent")
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "agent", ids(docs)[0])
+	assert.InDelta(t, 1.0, docs[0].Score(), 1e-9)
+	assert.Contains(t, docs[0].MetaData, MetaFusedScore)
+
+	grades, err := parseGrades(`[{"index": 0, "score": 3}, {"index": 1, "score": 9},]`)
+	require.NoError(t, err)
+	assert.Len(t, grades, 2)
+}
+
+func TestHybridPartialRerank(t *testing.T) {
+	full := newTestRetriever(t, &Config{})
+	all, err := full.Retrieve(context.Background(), "graph agent")
+	require.NoError(t, err)
+	require.Greater(t, len(all), 1)
+
+	// the reply grades only the first candidate, the others are kept with their fused scores
+	r := newTestRetriever(t, &Config{
+		Reranker:       &LLMReranker{Model: &gradingModel{word: "agent", limit: 1}},
+		ScoreThreshold: 0.5,
+	})
+	docs, err := r.Retrieve(context.Background(), "graph agent")
+	require.NoError(t, err)
+	require.Len(t, docs, len(all))
+	for _, doc := range docs[1:] {
+		assert.Equal(t, true, doc.MetaData[MetaUnranked])
+		assert.Equal(t, doc.MetaData[MetaFusedScore], doc.Score())
+	}
+	_, unranked := docs[0].MetaData[MetaUnranked]
+	assert.False(t, unranked)
+}
+
+func TestHTTPReranker(t *testing.T) {
+	for name, reply := range map[string]string{
+		"tei":    `[{"index": 1, "score": 0.9}, {"index": 0, "score": 0.2}]`,
+		"cohere": `{"results": [{"index": 1, "relevance_score": 0.9}, {"index": 0, "relevance_score": 0.2}]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				var body map[string]any
+				assert.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+				assert.Equal(t, "q", body["query"])
+				assert.Equal(t, "Bearer key", req.Header.Get("Authorization"))
+				w.Write([]byte(reply))
+			}))
+			defer srv.Close()
+
+			docs := []*schema.Document{
+				{ID: "a", Content: "a", MetaData: map[string]any{}},
+				{ID: "b", Content: "b", MetaData: map[string]any{}},
+				{ID: "c", Content: "c", MetaData: map[string]any{}},
+			}
+			res, err := (&HTTPReranker{URL: srv.URL, APIKey: "key"}).Rerank(context.Background(), "q", docs)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"b", "a", "c"}, ids(res))
+			assert.InDelta(t, 0.9, res[0].Score(), 1e-9)
+		})
+	}
+}
+
+func TestQueryTerms(t *testing.T) {
+	assert.Equal(t, []string{"compose", "NewGraph", "MODEL\\_TYPE"}, queryTerms("compose.NewGraph(MODEL_TYPE) compose"))
+	assert.Empty(t, queryTerms("?!"))
+}
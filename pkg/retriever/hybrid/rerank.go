@@ -0,0 +1,240 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hybrid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"github.com/kaptinlin/jsonrepair"
+)
+
+// Reranker scores documents against the query and returns them best first, with the new
+// score set.
+type Reranker interface {
+	Rerank(ctx context.Context, query string, docs []*schema.Document) ([]*schema.Document, error)
+}
+
+// MetaFusedScore keeps the RRF score of a reranked document.
+const MetaFusedScore = "_fused_score"
+
+// MetaUnranked is set to true on documents the reranker left out of its response. They keep
+// their fused score, which is not comparable to the reranker's, so ScoreThreshold skips them.
+const MetaUnranked = "_unranked"
+
+// rescore sets the scores, keeping the fused one in the metadata, and sorts best first.
+// Documents the reranker did not score keep their fused score and order after the scored ones.
+func rescore(docs []*schema.Document, scores map[int]float64) []*schema.Document {
+	type scored struct {
+		doc   *schema.Document
+		score float64
+		ok    bool
+	}
+	list := make([]scored, len(docs))
+	for i, doc := range docs {
+		s, ok := scores[i]
+		doc.MetaData[MetaFusedScore] = doc.Score()
+		if !ok {
+			s = doc.Score()
+			doc.MetaData[MetaUnranked] = true
+		}
+		list[i] = scored{doc: doc, score: s, ok: ok}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].ok != list[j].ok {
+			return list[i].ok
+		}
+		if !list[i].ok {
+			return false
+		}
+		return list[i].score > list[j].score
+	})
+	res := make([]*schema.Document, len(list))
+	for i, s := range list {
+		res[i] = s.doc.WithScore(s.score)
+	}
+	return res
+}
+
+// LLMReranker asks a chat model to grade the relevance of every document in one call.
+// Scores are scaled to [0, 1].
+type LLMReranker struct {
+	Model model.BaseChatModel
+	// MaxChars truncates each document in the prompt, 1000 by default.
+	MaxChars int
+}
+
+const llmRerankPrompt = `Grade how well each document answers the question, from 0 (irrelevant) to 10 (answers it directly). Exact matches of API names, functions and options in the question matter most.
+Reply with a JSON array only, one entry per document: [{"index": 0, "score": 7}, ...]
+
+Question: %s
+
+%s`
+
+func (l *LLMReranker) Rerank(ctx context.Context, query string, docs []*schema.Document) ([]*schema.Document, error) {
+	maxChars := l.MaxChars
+	if maxChars <= 0 {
+		maxChars = 1000
+	}
+	var b strings.Builder
+	for i, doc := range docs {
+		content := []rune(doc.Content)
+		if len(content) > maxChars {
+			content = content[:maxChars]
+		}
+		fmt.Fprintf(&b, "[%d]\n%s\n\n", i, string(content))
+	}
+
+	msg, err := l.Model.Generate(ctx, []*schema.Message{
+		schema.UserMessage(fmt.Sprintf(llmRerankPrompt, query, b.String())),
+	})
+	if err != nil {
+		return nil, err
+	}
+	grades, err := parseGrades(msg.Content)
+	if err != nil {
+		return nil, err
+	}
+	scores := make(map[int]float64, len(grades))
+	for _, g := range grades {
+		if g.Index >= 0 && g.Index < len(docs) {
+			scores[g.Index] = min(max(g.Score, 0), 10) / 10
+		}
+	}
+	return rescore(docs, scores), nil
+}
+
+type grade struct {
+	Index int     `json:"index"`
+	Score float64 `json:"score"`
+}
+
+// parseGrades reads the JSON array out of a model reply, which may wrap it in prose or a
+// code fence.
+func parseGrades(reply string) ([]grade, error) {
+	start, end := strings.Index(reply, "["), strings.LastIndex(reply, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no grades in reply: %q", reply)
+	}
+	data := reply[start : end+1]
+	var grades []grade
+	if err := json.Unmarshal([]byte(data), &grades); err == nil {
+		return grades, nil
+	}
+	repaired, err := jsonrepair.JSONRepair(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid grades: %w", err)
+	}
+	if err := json.Unmarshal([]byte(repaired), &grades); err != nil {
+		return nil, fmt.Errorf("invalid grades: %w", err)
+	}
+	return grades, nil
+}
+
+// HTTPReranker calls a cross-encoder rerank endpoint. It speaks both the text-embeddings-
+// inference API ({"query", "texts"} answered by [{"index", "score"}]) and the Jina/Cohere
+// one ({"model", "query", "documents"} answered by {"results": [{"index",
+// "relevance_score"}]}).
+type HTTPReranker struct {
+	URL    string
+	Model  string
+	APIKey string
+	Client *http.Client
+}
+
+func (h *HTTPReranker) Rerank(ctx context.Context, query string, docs []*schema.Document) ([]*schema.Document, error) {
+	texts := make([]string, len(docs))
+	for i, doc := range docs {
+		texts[i] = doc.Content
+	}
+	body, err := json.Marshal(map[string]any{
+		"model":     h.Model,
+		"query":     query,
+		"texts":     texts,
+		"documents": texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+h.APIKey)
+	}
+	client := h.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rerank endpoint returned %d: %s", resp.StatusCode, bytes.TrimSpace(data))
+	}
+
+	type result struct {
+		Index          int      `json:"index"`
+		Score          *float64 `json:"score"`
+		RelevanceScore *float64 `json:"relevance_score"`
+	}
+	var results []result
+	if err := json.Unmarshal(data, &results); err != nil {
+		var wrapped struct {
+			Results []result `json:"results"`
+		}
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return nil, fmt.Errorf("invalid rerank response: %w", err)
+		}
+		results = wrapped.Results
+	}
+
+	scores := make(map[int]float64, len(results))
+	for _, r := range results {
+		if r.Index < 0 || r.Index >= len(docs) {
+			continue
+		}
+		switch {
+		case r.Score != nil:
+			scores[r.Index] = *r.Score
+		case r.RelevanceScore != nil:
+			scores[r.Index] = *r.RelevanceScore
+		}
+	}
+	if len(scores) == 0 && len(docs) > 0 {
+		return nil, errors.New("rerank response has no scores")
+	}
+	return rescore(docs, scores), nil
+}
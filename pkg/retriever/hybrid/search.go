@@ -0,0 +1,174 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hybrid
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/schema"
+)
+
+const distanceAlias = "__distance"
+
+// searchText ranks documents by BM25 on the content field. Every word of the query is an
+// alternative, so a question mentioning an exact API name still finds the page defining it.
+func (r *Retriever) searchText(ctx context.Context, query string, k int) ([]*hit, error) {
+	terms := queryTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	args := []any{
+		"FT.SEARCH", r.conf.Index,
+		fmt.Sprintf("@%s:(%s)", r.conf.ContentField, strings.Join(terms, "|")),
+		"SCORER", "BM25", "WITHSCORES",
+		"RETURN", "2", r.conf.ContentField, r.conf.MetadataField,
+		"LIMIT", "0", strconv.Itoa(k),
+		"DIALECT", "2",
+	}
+	res, err := r.conf.Client.Do(ctx, args...).Result()
+	if err != nil {
+		return nil, err
+	}
+	return r.parseReply(res, true)
+}
+
+// searchVector ranks documents by cosine distance to the embedded query.
+func (r *Retriever) searchVector(ctx context.Context, emb embedding.Embedder, query string, k int) ([]*hit, error) {
+	vectors, err := emb.EmbedStrings(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("expected 1 query vector, got %d", len(vectors))
+	}
+	args := []any{
+		"FT.SEARCH", r.conf.Index,
+		fmt.Sprintf("*=>[KNN %d @%s $vec AS %s]", k, r.conf.VectorField, distanceAlias),
+		"PARAMS", "2", "vec", vectorToBytes(vectors[0]),
+		"SORTBY", distanceAlias,
+		"RETURN", "3", r.conf.ContentField, r.conf.MetadataField, distanceAlias,
+		"LIMIT", "0", strconv.Itoa(k),
+		"DIALECT", "2",
+	}
+	res, err := r.conf.Client.Do(ctx, args...).Result()
+	if err != nil {
+		return nil, err
+	}
+	return r.parseReply(res, false)
+}
+
+// parseReply reads a RESP2 FT.SEARCH reply: the total, then for every document its key, its
+// score when WITHSCORES was given, and its fields as a flat name/value list.
+func (r *Retriever) parseReply(res any, withScores bool) ([]*hit, error) {
+	items, ok := res.([]any)
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("unexpected FT.SEARCH reply: %T", res)
+	}
+	step := 2
+	if withScores {
+		step = 3
+	}
+	var hits []*hit
+	for i := 1; i+step-1 < len(items); i += step {
+		id := fmt.Sprint(items[i])
+		h := &hit{doc: &schema.Document{ID: id, MetaData: map[string]any{}}}
+		if withScores {
+			h.similarity, _ = strconv.ParseFloat(fmt.Sprint(items[i+1]), 64)
+		}
+		fields, _ := items[i+step-1].([]any)
+		for j := 0; j+1 < len(fields); j += 2 {
+			name, value := fmt.Sprint(fields[j]), fmt.Sprint(fields[j+1])
+			switch name {
+			case r.conf.ContentField:
+				h.doc.Content = value
+			case r.conf.MetadataField:
+				decodeMetadata(h.doc, value)
+			case distanceAlias:
+				if d, err := strconv.ParseFloat(value, 64); err == nil {
+					// COSINE distance is 1 - similarity
+					h.similarity = 1 - d
+				}
+			}
+		}
+		hits = append(hits, h)
+	}
+	return hits, nil
+}
+
+// decodeMetadata merges the JSON metadata written by the indexer into the document.
+func decodeMetadata(doc *schema.Document, value string) {
+	if value == "" {
+		return
+	}
+	var meta map[string]any
+	if err := json.Unmarshal([]byte(value), &meta); err != nil {
+		log.Printf("[retriever] invalid metadata of %s: %v", doc.ID, err)
+		return
+	}
+	for k, v := range meta {
+		doc.MetaData[k] = v
+	}
+}
+
+// queryTerms splits a query into words and escapes them for the RediSearch query syntax.
+// Words are split where the RediSearch tokenizer splits them, so "compose.NewGraph" becomes
+// "compose" and "NewGraph".
+func queryTerms(query string) []string {
+	words := strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+	seen := make(map[string]bool, len(words))
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		key := strings.ToLower(w)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		terms = append(terms, escapeTerm(w))
+	}
+	return terms
+}
+
+func escapeTerm(w string) string {
+	var b strings.Builder
+	for _, r := range w {
+		if r == '_' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// vectorToBytes encodes a vector as the FLOAT32 little-endian blob the index stores.
+func vectorToBytes(vector []float64) []byte {
+	buf := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(float32(v)))
+	}
+	return buf
+}
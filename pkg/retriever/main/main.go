@@ -7,14 +7,15 @@ import (
 	"github.com/joho/godotenv"
 	"likeeino/internal/logs"
 	"likeeino/pkg/retriever"
+	_ "likeeino/pkg/vectorstore/milvus"
 	"log"
 )
 
 func main() {
 	// 初始化检索器
 	ctx := context.Background()
-	//rtr, err := retriever.NewRetriever(ctx)
-	rtr, err := retriever.NewMilvusRetriever(ctx)
+	// VECTOR_STORE=milvus 使用 milvus
+	rtr, err := retriever.NewRetriever(ctx)
 	if err != nil {
 		fmt.Println(err)
 	} else {
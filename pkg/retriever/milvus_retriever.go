@@ -1,60 +0,0 @@
-package retriever
-
-import (
-	"context"
-	"github.com/cloudwego/eino-ext/components/embedding/ark"
-	"github.com/cloudwego/eino-ext/components/retriever/milvus"
-	"github.com/cloudwego/eino/components/retriever"
-	"github.com/milvus-io/milvus-sdk-go/v2/client"
-	"log"
-	"os"
-)
-
-func NewMilvusRetriever(ctx context.Context) (rtr retriever.Retriever, err error) {
-
-	addr := os.Getenv("MILVUS_ADDR")
-	username := os.Getenv("MILVUS_USERNAME")
-	password := os.Getenv("MILVUS_PASSWORD")
-	arkApiKey := os.Getenv("ARK_API_KEY")
-	arkModel := os.Getenv("ARK_MODEL")
-
-	cli, err := client.NewClient(ctx, client.Config{
-		Address:  addr,
-		Username: username,
-		Password: password,
-	})
-	if err != nil {
-		log.Fatalf("Failed to create client: %v", err)
-		return
-	}
-	defer cli.Close()
-
-	// Create an embedding model
-	emb, err := ark.NewEmbedder(ctx, &ark.EmbeddingConfig{
-		APIKey: arkApiKey,
-		Model:  arkModel,
-	})
-	// Create a retriever
-	rtr, err = milvus.NewRetriever(ctx, &milvus.RetrieverConfig{
-		Client:      cli,
-		Collection:  "",
-		Partition:   nil,
-		VectorField: "",
-		OutputFields: []string{
-			"id",
-			"content",
-			"metadata",
-		},
-		DocumentConverter: nil,
-		MetricType:        "",
-		TopK:              0,
-		ScoreThreshold:    5,
-		Sp:                nil,
-		Embedding:         emb,
-	})
-	if err != nil {
-		log.Fatalf("Failed to create retriever: %v", err)
-		return nil, err
-	}
-	return rtr, nil
-}
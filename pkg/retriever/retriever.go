@@ -2,62 +2,25 @@ package retriever
 
 import (
 	"context"
-	"fmt"
-	redispkg "likeeino/pkg/redis"
-	"os"
-	"strconv"
 
-	"github.com/cloudwego/eino/schema"
-	redisCli "github.com/redis/go-redis/v9"
+	"likeeino/pkg/vectorstore"
 
-	"github.com/cloudwego/eino-ext/components/retriever/redis"
 	"github.com/cloudwego/eino/components/retriever"
 )
 
+// NewRetriever 创建检索器,向量库由环境变量 VECTOR_STORE 选择(见 vectorstore.ConfigFromEnv)
 func NewRetriever(ctx context.Context) (rtr retriever.Retriever, err error) {
-	redisAddr := os.Getenv("REDIS_ADDR")
-	redisClient := redisCli.NewClient(&redisCli.Options{
-		Addr:     redisAddr,
-		Protocol: 2,
-	})
-	config := &redis.RetrieverConfig{
-		Client:       redisClient,
-		Index:        fmt.Sprintf("%s%s", redispkg.RedisPrefix, redispkg.IndexName),
-		Dialect:      2,
-		ReturnFields: []string{redispkg.ContentField, redispkg.MetadataField, redispkg.DistanceField},
-		TopK:         8,
-		VectorField:  redispkg.VectorField,
-		DocumentConverter: func(ctx context.Context, doc redisCli.Document) (*schema.Document, error) {
-			resp := &schema.Document{
-				ID:       doc.ID,
-				Content:  "",
-				MetaData: map[string]any{},
-			}
-			for field, val := range doc.Fields {
-				if field == redispkg.ContentField {
-					resp.Content = val
-				} else if field == redispkg.MetadataField {
-					resp.MetaData[field] = val
-				} else if field == redispkg.DistanceField {
-					distance, err := strconv.ParseFloat(val, 64)
-					if err != nil {
-						continue
-					}
-					resp.WithScore(1 - distance)
-				}
-			}
-
-			return resp, nil
-		},
+	config, err := vectorstore.ConfigFromEnv()
+	if err != nil {
+		return nil, err
 	}
-	embeddingIns11, err := NewEmbedding(ctx)
+	config.Embedding, err = NewEmbedding(ctx)
 	if err != nil {
 		return nil, err
 	}
-	config.Embedding = embeddingIns11
-	rtr, err = redis.NewRetriever(ctx, config)
+	store, err := vectorstore.New(ctx, config)
 	if err != nil {
 		return nil, err
 	}
-	return rtr, nil
+	return store.Retriever, nil
 }
@@ -0,0 +1,157 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package splitter
+
+import (
+	"context"
+	"maps"
+	"strings"
+
+	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/schema"
+)
+
+const (
+	// MetaKeyTitle is the innermost header of a section.
+	MetaKeyTitle = "title"
+	// MetaKeyBreadcrumb is the header path of a section, outermost first, joined by " > ".
+	MetaKeyBreadcrumb = "breadcrumb"
+
+	BreadcrumbSeparator = " > "
+)
+
+type HeaderConfig struct {
+	// MaxLevel is the deepest header level that starts a section, 3 by default. Deeper
+	// headers stay in the section of their parent.
+	MaxLevel int
+	// TrimHeaders drops the header line from the content of its section.
+	TrimHeaders bool
+}
+
+// HeaderSplitter splits markdown into one document per section. Headers inside code fences
+// are ignored, and sections holding nothing but their header are dropped.
+type HeaderSplitter struct {
+	maxLevel int
+	trim     bool
+}
+
+func NewHeaderSplitter(conf *HeaderConfig) *HeaderSplitter {
+	s := &HeaderSplitter{maxLevel: 3, trim: conf.TrimHeaders}
+	if conf.MaxLevel > 0 {
+		s.maxLevel = min(conf.MaxLevel, 6)
+	}
+	return s
+}
+
+func (s *HeaderSplitter) Transform(ctx context.Context, docs []*schema.Document, opts ...document.TransformerOption) ([]*schema.Document, error) {
+	var res []*schema.Document
+	for _, doc := range docs {
+		for _, sec := range s.sections(doc.Content) {
+			meta := maps.Clone(doc.MetaData)
+			if meta == nil {
+				meta = map[string]any{}
+			}
+			if len(sec.headers) > 0 {
+				meta[MetaKeyTitle] = sec.headers[len(sec.headers)-1]
+				meta[MetaKeyBreadcrumb] = strings.Join(sec.headers, BreadcrumbSeparator)
+			}
+			res = append(res, &schema.Document{ID: doc.ID, Content: sec.content, MetaData: meta})
+		}
+	}
+	return res, nil
+}
+
+func (s *HeaderSplitter) GetType() string {
+	return "HeaderSplitter"
+}
+
+type section struct {
+	headers []string
+	content string
+}
+
+func (s *HeaderSplitter) sections(text string) []section {
+	var (
+		res     []section
+		path    []string
+		levels  []int
+		lines   []string
+		hasBody bool
+		fence   string
+	)
+	flush := func() {
+		if hasBody {
+			res = append(res, section{
+				headers: append([]string(nil), path...),
+				content: strings.TrimSpace(strings.Join(lines, "\n")),
+			})
+		}
+		lines, hasBody = nil, false
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+		} else if f := fenceOf(trimmed); f != "" {
+			fence = f
+		} else if level, title := s.header(trimmed); level > 0 {
+			flush()
+			for len(levels) > 0 && levels[len(levels)-1] >= level {
+				levels, path = levels[:len(levels)-1], path[:len(path)-1]
+			}
+			levels, path = append(levels, level), append(path, title)
+			if !s.trim {
+				lines = append(lines, line)
+			}
+			continue
+		}
+		lines = append(lines, line)
+		if trimmed != "" {
+			hasBody = true
+		}
+	}
+	flush()
+	return res
+}
+
+// header returns the level and text of an ATX header up to maxLevel.
+func (s *HeaderSplitter) header(line string) (int, string) {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > s.maxLevel || (level < len(line) && line[level] != ' ' && line[level] != '\t') {
+		return 0, ""
+	}
+	title := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(line[level:]), "#"))
+	if title == "" {
+		return 0, ""
+	}
+	return level, title
+}
+
+func fenceOf(line string) string {
+	for _, f := range []string{"```", "~~~"} {
+		if strings.HasPrefix(line, f) {
+			return f
+		}
+	}
+	return ""
+}
@@ -0,0 +1,207 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package splitter splits documents into chunks for embedding: by markdown headers, keeping
+// the header breadcrumb, and recursively by paragraphs, lines, sentences and words down to a
+// size limit, with overlap.
+package splitter
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/schema"
+)
+
+// MetaKeyChunk is the 0-based index of a chunk within the document it was split from, set
+// when a document is split into several.
+const MetaKeyChunk = "chunk"
+
+// DefaultSeparators are tried in order: paragraphs, lines, sentences (Chinese and Latin),
+// words and finally characters.
+var DefaultSeparators = []string{"\n\n", "\n", "。", ". ", "！", "？", "; ", " ", ""}
+
+type RecursiveConfig struct {
+	// ChunkSize is the maximum length of a chunk, 1000 by default.
+	ChunkSize int
+	// Overlap is how much of the end of a chunk the next one repeats, 100 by default. A
+	// negative value disables it.
+	Overlap int
+	// Separators default to DefaultSeparators. "" splits between characters.
+	Separators []string
+	// Length measures text, in runes by default. ApproxTokens measures it in tokens.
+	Length func(string) int
+}
+
+// RecursiveSplitter splits text at the first separator it contains, and pieces still too long
+// at the next separators, then merges the pieces back into chunks of at most ChunkSize.
+type RecursiveSplitter struct {
+	size, overlap int
+	seps          []string
+	length        func(string) int
+}
+
+func NewRecursiveSplitter(conf *RecursiveConfig) (*RecursiveSplitter, error) {
+	s := &RecursiveSplitter{size: 1000, overlap: 100, seps: DefaultSeparators, length: utf8.RuneCountInString}
+	if conf.ChunkSize > 0 {
+		s.size = conf.ChunkSize
+	}
+	if conf.Overlap != 0 {
+		s.overlap = max(conf.Overlap, 0)
+	}
+	if len(conf.Separators) > 0 {
+		s.seps = conf.Separators
+	}
+	if conf.Length != nil {
+		s.length = conf.Length
+	}
+	if s.overlap >= s.size {
+		return nil, errors.New("overlap must be smaller than the chunk size")
+	}
+	return s, nil
+}
+
+// ApproxTokens estimates the tokens of text: a CJK character is one token, other words one
+// per four characters.
+func ApproxTokens(text string) int {
+	tokens, run := 0, 0
+	flush := func() {
+		tokens += (run + 3) / 4
+		run = 0
+	}
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			flush()
+			tokens++
+		case unicode.IsSpace(r) || unicode.IsPunct(r):
+			flush()
+			if unicode.IsPunct(r) {
+				tokens++
+			}
+		default:
+			run++
+		}
+	}
+	flush()
+	return tokens
+}
+
+// Transform keeps documents within ChunkSize as they are and splits the others, every chunk
+// copying the metadata of its document.
+func (s *RecursiveSplitter) Transform(ctx context.Context, docs []*schema.Document, opts ...document.TransformerOption) ([]*schema.Document, error) {
+	var res []*schema.Document
+	for _, doc := range docs {
+		if s.length(doc.Content) <= s.size {
+			res = append(res, doc)
+			continue
+		}
+		for i, chunk := range s.SplitText(doc.Content) {
+			meta := maps.Clone(doc.MetaData)
+			if meta == nil {
+				meta = map[string]any{}
+			}
+			meta[MetaKeyChunk] = i
+			res = append(res, &schema.Document{ID: doc.ID, Content: chunk, MetaData: meta})
+		}
+	}
+	return res, nil
+}
+
+func (s *RecursiveSplitter) GetType() string {
+	return "RecursiveSplitter"
+}
+
+// SplitText splits text into trimmed, non-empty chunks.
+func (s *RecursiveSplitter) SplitText(text string) []string {
+	var res []string
+	for _, chunk := range s.split(text, s.seps) {
+		if chunk = strings.TrimSpace(chunk); chunk != "" {
+			res = append(res, chunk)
+		}
+	}
+	return res
+}
+
+func (s *RecursiveSplitter) split(text string, seps []string) []string {
+	sep, rest := "", []string(nil)
+	for i, c := range seps {
+		if c == "" || strings.Contains(text, c) {
+			sep, rest = c, seps[i+1:]
+			break
+		}
+	}
+
+	var chunks, fits []string
+	for _, piece := range splitAfter(text, sep) {
+		if s.length(piece) <= s.size {
+			fits = append(fits, piece)
+			continue
+		}
+		chunks = append(chunks, s.merge(fits)...)
+		fits = nil
+		if len(rest) == 0 {
+			chunks = append(chunks, piece)
+		} else {
+			chunks = append(chunks, s.split(piece, rest)...)
+		}
+	}
+	return append(chunks, s.merge(fits)...)
+}
+
+// merge joins consecutive pieces into chunks of at most size. A new chunk starts with the
+// last pieces of the previous one, up to overlap.
+func (s *RecursiveSplitter) merge(pieces []string) []string {
+	var (
+		chunks []string
+		cur    []string
+		total  int
+	)
+	for _, piece := range pieces {
+		n := s.length(piece)
+		if len(cur) > 0 && total+n > s.size {
+			chunks = append(chunks, strings.Join(cur, ""))
+			for len(cur) > 0 && (total > s.overlap || total+n > s.size) {
+				total -= s.length(cur[0])
+				cur = cur[1:]
+			}
+		}
+		cur = append(cur, piece)
+		total += n
+	}
+	if len(cur) > 0 {
+		chunks = append(chunks, strings.Join(cur, ""))
+	}
+	return chunks
+}
+
+// splitAfter splits text after every sep, so joining the pieces gives text back. An empty sep
+// splits between characters.
+func splitAfter(text, sep string) []string {
+	if sep == "" {
+		pieces := make([]string, 0, len(text))
+		for _, r := range text {
+			pieces = append(pieces, string(r))
+		}
+		return pieces
+	}
+	return strings.SplitAfter(text, sep)
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package splitter
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecursiveSplitter(t *testing.T) {
+	s, err := NewRecursiveSplitter(&RecursiveConfig{ChunkSize: 30, Overlap: 10})
+	require.NoError(t, err)
+
+	text := "one two three four five six seven eight nine ten.\n\nshort paragraph."
+	chunks := s.SplitText(text)
+	require.Greater(t, len(chunks), 2)
+	for _, c := range chunks {
+		assert.LessOrEqual(t, utf8.RuneCountInString(c), 30, c)
+	}
+	assert.Equal(t, "short paragraph.", chunks[len(chunks)-1])
+	// consecutive chunks of the long paragraph share words
+	assert.Equal(t, "one two three four five six", chunks[0])
+	assert.True(t, strings.HasPrefix(chunks[1], "five six "), chunks[1])
+
+	docs, err := s.Transform(context.Background(), []*schema.Document{
+		{ID: "a", Content: "fits", MetaData: map[string]any{"k": "v"}},
+		{ID: "b", Content: text, MetaData: map[string]any{"k": "v"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, docs, 1+len(chunks))
+	assert.NotContains(t, docs[0].MetaData, MetaKeyChunk)
+	assert.Equal(t, 1, docs[2].MetaData[MetaKeyChunk])
+	assert.Equal(t, "v", docs[2].MetaData["k"])
+
+	_, err = NewRecursiveSplitter(&RecursiveConfig{ChunkSize: 10, Overlap: 10})
+	assert.Error(t, err)
+}
+
+func TestRecursiveSplitterCharacters(t *testing.T) {
+	s, err := NewRecursiveSplitter(&RecursiveConfig{ChunkSize: 4, Overlap: -1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"知识索引", "的切分"}, s.SplitText("知识索引的切分"))
+}
+
+func TestApproxTokens(t *testing.T) {
+	assert.Equal(t, 0, ApproxTokens(""))
+	assert.Equal(t, 4, ApproxTokens("知识索引"))
+	assert.Equal(t, 6, ApproxTokens("hello, world!"))
+}
+
+func TestHeaderSplitter(t *testing.T) {
+	md := `intro
+
+# Eino
+## Graph
+nodes and edges
+~~~
+# not a header
+~~~
+### Lambda
+#### Deep
+custom code
+## Chain
+`
+	docs, err := NewHeaderSplitter(&HeaderConfig{}).Transform(context.Background(), []*schema.Document{
+		{ID: "a", Content: md, MetaData: map[string]any{"_source": "a.md"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, docs, 3)
+
+	assert.Equal(t, "intro", docs[0].Content)
+	assert.NotContains(t, docs[0].MetaData, MetaKeyBreadcrumb)
+
+	assert.Equal(t, "## Graph\nnodes and edges\n~~~\n# not a header\n~~~", docs[1].Content)
+	assert.Equal(t, "Eino > Graph", docs[1].MetaData[MetaKeyBreadcrumb])
+	assert.Equal(t, "Graph", docs[1].MetaData[MetaKeyTitle])
+
+	// level 4 headers stay in the section of their level 3 parent
+	assert.Equal(t, "Eino > Graph > Lambda", docs[2].MetaData[MetaKeyBreadcrumb])
+	assert.Contains(t, docs[2].Content, "#### Deep\ncustom code")
+	assert.Equal(t, "a.md", docs[2].MetaData["_source"])
+}
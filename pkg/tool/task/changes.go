@@ -0,0 +1,140 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package task
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+type ChangeType string
+
+const (
+	ChangeCreated ChangeType = "created"
+	ChangeUpdated ChangeType = "updated"
+	ChangeDeleted ChangeType = "deleted"
+)
+
+// Change is one task mutation, made through this Storage or replayed from another process
+// sharing the data directory.
+type Change struct {
+	Seq  int64      `json:"seq"`
+	Type ChangeType `json:"type"`
+	ID   string     `json:"id"`
+	// Task is the task after the change, nil when it was deleted.
+	Task *Task     `json:"task,omitempty"`
+	At   time.Time `json:"at"`
+}
+
+type changeHub struct {
+	mu   sync.Mutex
+	seq  int64
+	subs map[chan Change]struct{}
+}
+
+// Subscribe returns a channel receiving every change from now on, and a function to stop.
+// A subscriber that does not keep up misses changes rather than blocking writers.
+func (s *Storage) Subscribe() (<-chan Change, func()) {
+	ch := make(chan Change, 64)
+	h := &s.hub
+	h.mu.Lock()
+	if h.subs == nil {
+		h.subs = make(map[chan Change]struct{})
+	}
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs, ch)
+			h.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Watch polls the log until ctx is done, so changes written by other processes reach
+// subscribers even while this process is idle.
+func (s *Storage) Watch(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.read(func() {}); err != nil {
+					log.Printf("[task] failed to poll %s: %v", s.filePath, err)
+				}
+			}
+		}
+	}()
+}
+
+// recordChange queues a change to publish once the current operation is done. The caller
+// holds s.mu.
+func (s *Storage) recordChange(typ ChangeType, id string, task *Task) {
+	s.pending = append(s.pending, Change{Type: typ, ID: id, Task: task, At: time.Now()})
+}
+
+// publishChanges hands the queued changes to the subscribers. The caller holds s.mu, so
+// changes are published in the order they were applied.
+func (s *Storage) publishChanges() {
+	if len(s.pending) == 0 {
+		return
+	}
+	h := &s.hub
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, c := range s.pending {
+		h.seq++
+		c.Seq = h.seq
+		for ch := range h.subs {
+			select {
+			case ch <- c:
+			default:
+				log.Printf("[task] dropped change %d for a slow subscriber", c.Seq)
+			}
+		}
+	}
+	s.pending = nil
+}
+
+// diffChanges queues the changes between two full states of the cache, used when the log
+// was replaced and replayed from scratch.
+func (s *Storage) diffChanges(old map[string]*Task) {
+	for id, task := range s.cache {
+		prev, ok := old[id]
+		switch {
+		case !ok:
+			s.recordChange(ChangeCreated, id, task)
+		case prev.Revision != task.Revision:
+			s.recordChange(ChangeUpdated, id, task)
+		}
+	}
+	for id := range old {
+		if _, ok := s.cache[id]; !ok {
+			s.recordChange(ChangeDeleted, id, nil)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package task
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvColumns is the header of an exported table. Imported tables are matched by column name,
+// in any order, and only need a title column; tags and reminders are separated by ';'.
+var csvColumns = []string{"external_id", "title", "content", "completed", "deadline", "priority", "tags", "recurrence", "reminders"}
+
+func exportCSV(tasks []*Task) (string, error) {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	if err := w.Write(csvColumns); err != nil {
+		return "", err
+	}
+	for _, t := range tasks {
+		deadline := ""
+		if t.Deadline != nil {
+			deadline = t.Deadline.Format(time.RFC3339)
+		}
+		reminders := make([]string, len(t.Reminders))
+		for i, rem := range t.Reminders {
+			reminders[i] = rem.Before
+		}
+		if err := w.Write([]string{
+			exportID(t),
+			t.Title,
+			t.Content,
+			strconv.FormatBool(t.Completed),
+			deadline,
+			string(t.Priority),
+			strings.Join(t.Tags, ";"),
+			t.Recurrence,
+			strings.Join(reminders, ";"),
+		}); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	return b.String(), w.Error()
+}
+
+func parseCSV(data string) ([]*Task, error) {
+	r := csv.NewReader(strings.NewReader(strings.TrimPrefix(data, "\ufeff")))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %v", err)
+	}
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "id" {
+			name = "external_id"
+		}
+		cols[name] = i
+	}
+	if _, ok := cols["title"]; !ok {
+		return nil, fmt.Errorf("missing title column")
+	}
+
+	var tasks []*Task
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := r.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := cols[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		t := &Task{
+			ExternalID: field("external_id"),
+			Title:      field("title"),
+			Content:    field("content"),
+			Priority:   Priority(strings.ToLower(field("priority"))),
+			Tags:       splitCSVList(field("tags")),
+			Recurrence: field("recurrence"),
+		}
+		if t.Title == "" && t.ExternalID == "" && t.Content == "" {
+			continue
+		}
+		if v := field("completed"); v != "" {
+			done, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid completed: %s", line, v)
+			}
+			t.Completed = done
+		}
+		if v := field("deadline"); v != "" {
+			due, err := parseDeadline(v)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid deadline: %s", line, v)
+			}
+			t.Deadline = &due
+		}
+		for _, before := range splitCSVList(field("reminders")) {
+			t.Reminders = append(t.Reminders, Reminder{Before: before})
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, nil
+}
+
+func splitCSVList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseDeadline accepts RFC3339 and, for hand-written files, "2006-01-02 15:04" and
+// "2006-01-02" in local time.
+func parseDeadline(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04", s, time.Local); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", s, time.Local)
+}
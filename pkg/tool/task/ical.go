@@ -0,0 +1,431 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package task
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	icalDateTime    = "20060102T150405"
+	icalDateTimeUTC = "20060102T150405Z"
+	icalDate        = "20060102"
+	// icalLineOctets is the longest content line before it has to be folded.
+	icalLineOctets = 75
+)
+
+func exportICal(tasks []*Task, now time.Time) string {
+	var b strings.Builder
+	w := func(line string) { writeICalLine(&b, line) }
+
+	w("BEGIN:VCALENDAR")
+	w("VERSION:2.0")
+	w("PRODID:-//likeeino//task manager//EN")
+	for _, t := range tasks {
+		w("BEGIN:VTODO")
+		w("UID:" + escapeICalText(exportID(t)))
+		w("DTSTAMP:" + now.UTC().Format(icalDateTimeUTC))
+		w("SUMMARY:" + escapeICalText(t.Title))
+		if t.Content != "" {
+			w("DESCRIPTION:" + escapeICalText(t.Content))
+		}
+		if t.Deadline != nil {
+			w("DUE:" + t.Deadline.UTC().Format(icalDateTimeUTC))
+		}
+		if t.Completed {
+			w("STATUS:COMPLETED")
+		} else {
+			w("STATUS:NEEDS-ACTION")
+		}
+		if p := icalPriority(t.Priority); p > 0 {
+			w("PRIORITY:" + strconv.Itoa(p))
+		}
+		if len(t.Tags) > 0 {
+			tags := make([]string, len(t.Tags))
+			for i, tag := range t.Tags {
+				tags[i] = escapeICalText(tag)
+			}
+			w("CATEGORIES:" + strings.Join(tags, ","))
+		}
+		if t.Recurrence != "" {
+			w("RRULE:" + t.Recurrence)
+		}
+		for _, rem := range t.Reminders {
+			d, err := time.ParseDuration(rem.Before)
+			if err != nil {
+				continue
+			}
+			w("BEGIN:VALARM")
+			w("ACTION:DISPLAY")
+			w("DESCRIPTION:" + escapeICalText(t.Title))
+			w("TRIGGER:-" + formatICalDuration(d))
+			w("END:VALARM")
+		}
+		w("END:VTODO")
+	}
+	w("END:VCALENDAR")
+	return b.String()
+}
+
+// writeICalLine writes a content line, folded so no line exceeds 75 octets (RFC 5545 3.1).
+func writeICalLine(b *strings.Builder, line string) {
+	limit := icalLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// the leading space of a continuation line counts towards its length
+		limit = icalLineOctets - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+// icalProperty is one unfolded content line.
+type icalProperty struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+func parseICal(data string) ([]*Task, error) {
+	var (
+		tasks []*Task
+		cur   *Task
+		stack []string
+	)
+	for n, line := range unfoldICal(data) {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		prop, err := parseICalLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n+1, err)
+		}
+
+		switch prop.Name {
+		case "BEGIN":
+			stack = append(stack, strings.ToUpper(prop.Value))
+			if len(stack) == 2 && stack[1] == "VTODO" {
+				cur = &Task{}
+			}
+			continue
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1] != strings.ToUpper(prop.Value) {
+				return nil, fmt.Errorf("line %d: unexpected END:%s", n+1, prop.Value)
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 1 && cur != nil {
+				tasks = append(tasks, cur)
+				cur = nil
+			}
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+
+		// properties of an alarm inside the task
+		if len(stack) == 3 && stack[2] == "VALARM" {
+			if prop.Name == "TRIGGER" {
+				if before, ok := parseICalTrigger(prop); ok {
+					cur.Reminders = append(cur.Reminders, Reminder{Before: before})
+				}
+			}
+			continue
+		}
+		if len(stack) != 2 {
+			continue
+		}
+
+		switch prop.Name {
+		case "UID":
+			cur.ExternalID = unescapeICalText(prop.Value)
+		case "SUMMARY":
+			cur.Title = unescapeICalText(prop.Value)
+		case "DESCRIPTION":
+			cur.Content = unescapeICalText(prop.Value)
+		case "DUE":
+			due, err := parseICalTime(prop)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid DUE: %v", n+1, err)
+			}
+			cur.Deadline = &due
+		case "STATUS":
+			cur.Completed = strings.EqualFold(prop.Value, "COMPLETED")
+		case "COMPLETED":
+			cur.Completed = true
+		case "PRIORITY":
+			p, err := strconv.Atoi(strings.TrimSpace(prop.Value))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid PRIORITY: %s", n+1, prop.Value)
+			}
+			cur.Priority = priorityFromICal(p)
+		case "CATEGORIES":
+			for _, tag := range splitICalList(prop.Value) {
+				if tag = strings.TrimSpace(unescapeICalText(tag)); tag != "" {
+					cur.Tags = append(cur.Tags, tag)
+				}
+			}
+		case "RRULE":
+			cur.Recurrence = prop.Value
+		}
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("missing END:%s", stack[len(stack)-1])
+	}
+	return tasks, nil
+}
+
+// unfoldICal joins folded lines: a line starting with a space or a tab continues the previous
+// one.
+func unfoldICal(data string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// parseICalLine splits "NAME;PARAM=VALUE;...:value". Parameter values may be quoted and
+// contain ':' or ';'.
+func parseICalLine(line string) (*icalProperty, error) {
+	prop := &icalProperty{Params: make(map[string]string)}
+	quoted := false
+	start := 0
+	var name string
+	var params []string
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == ';' || c == ':':
+			if name == "" {
+				name = line[start:i]
+			} else {
+				params = append(params, line[start:i])
+			}
+			start = i + 1
+			if c == ':' {
+				prop.Name = strings.ToUpper(strings.TrimSpace(name))
+				prop.Value = line[start:]
+				for _, p := range params {
+					k, v, _ := strings.Cut(p, "=")
+					prop.Params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+				}
+				return prop, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("invalid content line: %q", line)
+}
+
+// parseICalTime reads a DATE-TIME in UTC, in a TZID or floating (local), or a DATE, which
+// becomes midnight local time.
+func parseICalTime(prop *icalProperty) (time.Time, error) {
+	v := strings.TrimSpace(prop.Value)
+	if prop.Params["VALUE"] == "DATE" || len(v) == len(icalDate) {
+		return time.ParseInLocation(icalDate, v, time.Local)
+	}
+	if strings.HasSuffix(v, "Z") {
+		return time.Parse(icalDateTimeUTC, v)
+	}
+	loc := time.Local
+	if tzid := prop.Params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	return time.ParseInLocation(icalDateTime, v, loc)
+}
+
+// parseICalTrigger turns a trigger before the deadline, e.g. -PT15M, into the Before of a
+// reminder. Absolute triggers and triggers after the deadline are not supported.
+func parseICalTrigger(prop *icalProperty) (string, bool) {
+	if prop.Params["VALUE"] == "DATE-TIME" || prop.Params["RELATED"] == "START" {
+		return "", false
+	}
+	v := strings.TrimSpace(prop.Value)
+	if !strings.HasPrefix(v, "-") {
+		return "", false
+	}
+	d, err := parseICalDuration(v[1:])
+	if err != nil {
+		return "", false
+	}
+	return formatBefore(d), true
+}
+
+// parseICalDuration parses an unsigned duration such as P1D, PT1H30M or P2W.
+func parseICalDuration(s string) (time.Duration, error) {
+	s = strings.TrimPrefix(s, "+")
+	if !strings.HasPrefix(s, "P") || len(s) < 3 {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+	var d time.Duration
+	inTime := false
+	num := ""
+	for _, c := range s[1:] {
+		switch {
+		case c >= '0' && c <= '9':
+			num += string(c)
+			continue
+		case c == 'T':
+			inTime = true
+			continue
+		}
+		n, err := strconv.Atoi(num)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration: %s", s)
+		}
+		num = ""
+		switch {
+		case c == 'W' && !inTime:
+			d += time.Duration(n) * 7 * 24 * time.Hour
+		case c == 'D' && !inTime:
+			d += time.Duration(n) * 24 * time.Hour
+		case c == 'H' && inTime:
+			d += time.Duration(n) * time.Hour
+		case c == 'M' && inTime:
+			d += time.Duration(n) * time.Minute
+		case c == 'S' && inTime:
+			d += time.Duration(n) * time.Second
+		default:
+			return 0, fmt.Errorf("invalid duration: %s", s)
+		}
+	}
+	if num != "" {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+	return d, nil
+}
+
+func formatICalDuration(d time.Duration) string {
+	if d%(24*time.Hour) == 0 && d > 0 {
+		return fmt.Sprintf("P%dD", d/(24*time.Hour))
+	}
+	var b strings.Builder
+	b.WriteString("PT")
+	h, m, s := d/time.Hour, d%time.Hour/time.Minute, d%time.Minute/time.Second
+	if h > 0 {
+		fmt.Fprintf(&b, "%dH", h)
+	}
+	if m > 0 {
+		fmt.Fprintf(&b, "%dM", m)
+	}
+	if s > 0 || d < time.Minute {
+		fmt.Fprintf(&b, "%dS", s)
+	}
+	return b.String()
+}
+
+// formatBefore writes a duration the way reminders are usually written, e.g. 24h or 1h30m
+// rather than time.Duration's 24h0m0s.
+func formatBefore(d time.Duration) string {
+	var b strings.Builder
+	h, m, s := d/time.Hour, d%time.Hour/time.Minute, d%time.Minute/time.Second
+	if h > 0 {
+		fmt.Fprintf(&b, "%dh", h)
+	}
+	if m > 0 {
+		fmt.Fprintf(&b, "%dm", m)
+	}
+	if s > 0 || b.Len() == 0 {
+		fmt.Fprintf(&b, "%ds", s)
+	}
+	return b.String()
+}
+
+// icalPriority maps a priority to the 1 (highest) to 9 (lowest) scale, 0 is undefined.
+func icalPriority(p Priority) int {
+	switch p {
+	case PriorityHigh:
+		return 1
+	case PriorityMedium:
+		return 5
+	case PriorityLow:
+		return 9
+	default:
+		return 0
+	}
+}
+
+func priorityFromICal(p int) Priority {
+	switch {
+	case p >= 1 && p <= 4:
+		return PriorityHigh
+	case p == 5:
+		return PriorityMedium
+	case p >= 6 && p <= 9:
+		return PriorityLow
+	default:
+		return ""
+	}
+}
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeICalText(s string) string {
+	return icalEscaper.Replace(s)
+}
+
+func unescapeICalText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// splitICalList splits a list value on the commas that are not escaped.
+func splitICalList(s string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ',':
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
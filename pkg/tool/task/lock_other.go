@@ -0,0 +1,31 @@
+//go:build !unix
+
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package task
+
+// fileLock is a no-op where flock(2) is not available: a Storage is then only safe within
+// one process, where Storage.mu serializes access.
+type fileLock struct{}
+
+func openFileLock(string) (*fileLock, error) { return &fileLock{}, nil }
+
+func (l *fileLock) lock(bool) error { return nil }
+
+func (l *fileLock) unlock() error { return nil }
+
+func (l *fileLock) close() error { return nil }
@@ -0,0 +1,168 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package task
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// A checklist item is "- [x] Title !high #tag (due: 2025-03-03T09:00:00Z)", followed by a
+// comment like "<!-- id:abc rrule:FREQ=WEEKLY remind:15m,1h -->" and its content indented
+// below it. Only the trailing !priority and #tag words are markup, the rest is the title.
+var (
+	mdItem    = regexp.MustCompile(`^(\s*)[-*+] \[([ xX])\] (.*)$`)
+	mdComment = regexp.MustCompile(`\s*<!--(.*?)-->\s*$`)
+	mdDue     = regexp.MustCompile(`\s*\(due: ([^)]+)\)\s*$`)
+)
+
+func exportMarkdown(tasks []*Task) string {
+	var b strings.Builder
+	for _, t := range tasks {
+		check := " "
+		if t.Completed {
+			check = "x"
+		}
+		fmt.Fprintf(&b, "- [%s] %s", check, t.Title)
+		if t.Priority != "" {
+			fmt.Fprintf(&b, " !%s", t.Priority)
+		}
+		for _, tag := range t.Tags {
+			fmt.Fprintf(&b, " #%s", strings.ReplaceAll(tag, " ", "-"))
+		}
+		if t.Deadline != nil {
+			fmt.Fprintf(&b, " (due: %s)", t.Deadline.Format(time.RFC3339))
+		}
+
+		meta := []string{"id:" + exportID(t)}
+		if t.Recurrence != "" {
+			meta = append(meta, "rrule:"+t.Recurrence)
+		}
+		if len(t.Reminders) > 0 {
+			reminders := make([]string, len(t.Reminders))
+			for i, rem := range t.Reminders {
+				reminders[i] = rem.Before
+			}
+			meta = append(meta, "remind:"+strings.Join(reminders, ","))
+		}
+		fmt.Fprintf(&b, " <!-- %s -->\n", strings.Join(meta, " "))
+
+		if t.Content != "" {
+			for _, line := range strings.Split(t.Content, "\n") {
+				if line != "" {
+					b.WriteString("  ")
+				}
+				b.WriteString(line)
+				b.WriteString("\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+func parseMarkdown(data string) ([]*Task, error) {
+	var (
+		tasks   []*Task
+		cur     *Task
+		indent  string
+		content []string
+	)
+	flush := func() {
+		if cur != nil {
+			cur.Content = strings.TrimRight(strings.Join(content, "\n"), "\n")
+			tasks = append(tasks, cur)
+		}
+		cur, content = nil, nil
+	}
+
+	for n, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		if m := mdItem.FindStringSubmatch(line); m != nil {
+			flush()
+			t, err := parseMarkdownItem(m[3])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", n+1, err)
+			}
+			t.Completed = m[2] != " "
+			cur, indent = t, m[1]
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		switch {
+		case strings.TrimSpace(line) == "":
+			content = append(content, "")
+		case strings.HasPrefix(line, indent+"  "):
+			content = append(content, strings.TrimPrefix(line, indent+"  "))
+		case strings.HasPrefix(line, indent+"\t"):
+			content = append(content, strings.TrimPrefix(line, indent+"\t"))
+		default:
+			// any other paragraph or heading ends the item
+			flush()
+		}
+	}
+	flush()
+	return tasks, nil
+}
+
+func parseMarkdownItem(text string) (*Task, error) {
+	t := &Task{}
+	if m := mdComment.FindStringSubmatchIndex(text); m != nil {
+		for _, field := range strings.Fields(text[m[2]:m[3]]) {
+			key, value, _ := strings.Cut(field, ":")
+			switch key {
+			case "id":
+				t.ExternalID = value
+			case "rrule":
+				t.Recurrence = value
+			case "remind":
+				for _, before := range strings.Split(value, ",") {
+					if before != "" {
+						t.Reminders = append(t.Reminders, Reminder{Before: before})
+					}
+				}
+			}
+		}
+		text = text[:m[0]]
+	}
+	if m := mdDue.FindStringSubmatchIndex(text); m != nil {
+		v := text[m[2]:m[3]]
+		due, err := parseDeadline(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("invalid due: %s", v)
+		}
+		t.Deadline = &due
+		text = text[:m[0]]
+	}
+
+	words := strings.Fields(text)
+	for len(words) > 1 {
+		last := words[len(words)-1]
+		if strings.HasPrefix(last, "#") && len(last) > 1 {
+			t.Tags = append([]string{last[1:]}, t.Tags...)
+		} else if p := Priority(strings.ToLower(strings.TrimPrefix(last, "!"))); strings.HasPrefix(last, "!") && p.rank() < 3 {
+			t.Priority = p
+		} else {
+			break
+		}
+		words = words[:len(words)-1]
+	}
+	t.Title = strings.Join(words, " ")
+	return t, nil
+}
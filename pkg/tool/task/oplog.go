@@ -0,0 +1,325 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package task
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// tasks.jsonl is an operation log: every mutation appends one record, the current state is
+// the replay of all records. Compaction rewrites the log as one put per live task, dropping
+// deleted tasks and superseded versions. Lines written before the log existed are plain
+// tasks and are replayed as puts (or deletes when is_deleted is set).
+
+const (
+	opPut    = "put"
+	opDelete = "delete"
+	// opCompact heads a compacted log with a unique id. Inode numbers are reused, so the id is
+	// what tells a reader that the file it was tailing has been replaced.
+	opCompact = "compact"
+
+	// compact once the log holds this many records and more than twice the live tasks
+	defaultCompactMinOps = 256
+)
+
+type opRecord struct {
+	Op   string `json:"op"`
+	ID   string `json:"id,omitempty"`
+	Task *Task  `json:"task,omitempty"`
+
+	// created tells a new task from an updated one in the change feed
+	created bool
+}
+
+func putOp(t *Task) opRecord {
+	return opRecord{Op: opPut, ID: t.ID, Task: t}
+}
+
+func deleteOp(id string) opRecord {
+	return opRecord{Op: opDelete, ID: id}
+}
+
+func createOp(t *Task) opRecord {
+	return opRecord{Op: opPut, ID: t.ID, Task: t, created: true}
+}
+
+// applyLine replays one line of the log onto the cache, recording the change when track is
+// set.
+func (s *Storage) applyLine(line []byte, track bool) error {
+	var rec struct {
+		Op   string          `json:"op"`
+		ID   string          `json:"id"`
+		Task json.RawMessage `json:"task"`
+	}
+	if err := json.Unmarshal(line, &rec); err != nil {
+		return err
+	}
+
+	var task *Task
+	switch rec.Op {
+	case "":
+		legacy, err := decodeTask(line)
+		if err != nil {
+			return err
+		}
+		if legacy.IsDeleted {
+			rec.ID = legacy.ID
+		} else {
+			task = legacy
+		}
+	case opPut:
+		put, err := decodeTask(rec.Task)
+		if err != nil {
+			return err
+		}
+		task = put
+	case opDelete:
+	case opCompact:
+		return nil
+	default:
+		return fmt.Errorf("unknown op: %s", rec.Op)
+	}
+
+	if task == nil {
+		if _, ok := s.cache[rec.ID]; ok && track {
+			s.recordChange(ChangeDeleted, rec.ID, nil)
+		}
+		delete(s.cache, rec.ID)
+		return nil
+	}
+	if track {
+		typ := ChangeUpdated
+		if _, ok := s.cache[task.ID]; !ok {
+			typ = ChangeCreated
+		}
+		s.recordChange(typ, task.ID, task)
+	}
+	s.cache[task.ID] = task
+	return nil
+}
+
+// refreshLocked brings the cache up to date with the log, which another process may have
+// appended to or compacted since the last read. The caller holds s.mu and the file lock.
+func (s *Storage) refreshLocked() error {
+	file, err := os.Open(s.filePath)
+	if os.IsNotExist(err) {
+		s.cache = make(map[string]*Task)
+		s.info, s.offset, s.ops = nil, 0, 0
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	fi, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat file: %v", err)
+	}
+	// records appended by other processes are reported as they are replayed
+	track := s.info != nil
+	var old map[string]*Task
+	if s.info == nil || !os.SameFile(s.info, fi) || fi.Size() < s.offset || !s.sameHead(file) {
+		// first load, or the log was replaced by a compaction: replay it from scratch and
+		// report the difference to what we had
+		if s.info != nil {
+			old = s.cache
+		}
+		track = false
+		s.cache = make(map[string]*Task)
+		s.offset, s.ops = 0, 0
+	} else if fi.Size() == s.offset {
+		return nil
+	}
+
+	if _, err := file.Seek(s.offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek file: %v", err)
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %v", err)
+	}
+
+	// only complete lines are consumed, a partial last line is still being written or was torn
+	// by a crash; the next append terminates it
+	end := bytes.LastIndexByte(data, '\n') + 1
+	if s.offset == 0 {
+		s.head = firstLine(data[:end])
+	}
+	for _, line := range bytes.Split(data[:end], []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		if err := s.applyLine(line, track); err != nil {
+			log.Printf("[task] skipped bad record in %s: %v", s.filePath, err)
+		}
+		s.ops++
+	}
+	s.offset += int64(end)
+	s.info = fi
+	if old != nil {
+		s.diffChanges(old)
+	}
+	return nil
+}
+
+// appendLocked writes records to the log. The caller holds s.mu and the exclusive file lock,
+// has refreshed the cache and already applied the records to it.
+func (s *Storage) appendLocked(recs []opRecord) error {
+	var buf bytes.Buffer
+	file, err := os.OpenFile(s.filePath, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	fi, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat file: %v", err)
+	}
+	if fi.Size() == 0 {
+		defer func() { s.head = firstLine(buf.Bytes()) }()
+	} else {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, fi.Size()-1); err != nil {
+			return fmt.Errorf("failed to read file: %v", err)
+		}
+		if last[0] != '\n' {
+			buf.WriteByte('\n')
+		}
+	}
+	for _, rec := range recs {
+		data, err := json.Marshal(rec)
+		if err != nil {
+			return fmt.Errorf("failed to marshal task: %v", err)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write task: %v", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync file: %v", err)
+	}
+
+	if fi, err = file.Stat(); err != nil {
+		return fmt.Errorf("failed to stat file: %v", err)
+	}
+	s.info = fi
+	s.offset = fi.Size()
+	s.ops += len(recs)
+	return nil
+}
+
+// sameHead reports whether file still starts with the line the log started with when it was
+// last read.
+func (s *Storage) sameHead(file *os.File) bool {
+	head := make([]byte, len(s.head))
+	if _, err := file.ReadAt(head, 0); err != nil {
+		return false
+	}
+	return bytes.Equal(head, s.head)
+}
+
+func firstLine(data []byte) []byte {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		data = data[:i+1]
+	}
+	return bytes.Clone(data)
+}
+
+func (s *Storage) needsCompactionLocked() bool {
+	return s.ops >= s.compactMinOps && s.ops > 2*len(s.cache)
+}
+
+// compactLocked rewrites the log as one put per live task. The caller holds s.mu and the
+// exclusive file lock; other processes notice the replaced file and reload it.
+func (s *Storage) compactLocked() error {
+	tasks := make([]*Task, 0, len(s.cache))
+	for _, task := range s.cache {
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if tasks[i].CreatedAt != tasks[j].CreatedAt {
+			return tasks[i].CreatedAt < tasks[j].CreatedAt
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+
+	var buf bytes.Buffer
+	header, _ := json.Marshal(opRecord{Op: opCompact, ID: uuid.New().String()})
+	buf.Write(header)
+	buf.WriteByte('\n')
+	for _, task := range tasks {
+		data, err := json.Marshal(putOp(task))
+		if err != nil {
+			return fmt.Errorf("failed to marshal task: %v", err)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	// 先写临时文件，再原子替换
+	tmpFile := s.filePath + ".tmp"
+	file, err := os.Create(tmpFile)
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %v", err)
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		file.Close()
+		os.Remove(tmpFile)
+		return fmt.Errorf("failed to write temp file: %v", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		os.Remove(tmpFile)
+		return fmt.Errorf("failed to sync temp file: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmpFile)
+		return fmt.Errorf("failed to close temp file: %v", err)
+	}
+	if err := os.Rename(tmpFile, s.filePath); err != nil {
+		os.Remove(tmpFile)
+		return fmt.Errorf("failed to rename temp file: %v", err)
+	}
+	if dir, err := os.Open(filepath.Dir(s.filePath)); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+
+	fi, err := os.Stat(s.filePath)
+	if err != nil {
+		return fmt.Errorf("failed to stat file: %v", err)
+	}
+	s.info = fi
+	s.offset = fi.Size()
+	s.ops = len(tasks)
+	s.head = firstLine(buf.Bytes())
+	return nil
+}
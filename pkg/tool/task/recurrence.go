@@ -0,0 +1,242 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package task
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	FreqDaily   Frequency = "DAILY"
+	FreqWeekly  Frequency = "WEEKLY"
+	FreqMonthly Frequency = "MONTHLY"
+	FreqYearly  Frequency = "YEARLY"
+)
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// RRule is the subset of RFC 5545 recurrence rules supported by tasks:
+// FREQ, INTERVAL, BYDAY (weekly only), COUNT and UNTIL,
+// e.g. "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;COUNT=10".
+type RRule struct {
+	Freq     Frequency
+	Interval int
+	ByDay    []time.Weekday
+	// Count is the number of occurrences left including the current one, 0 means unlimited.
+	Count int
+	Until *time.Time
+}
+
+func ParseRRule(s string) (*RRule, error) {
+	r := &RRule{Interval: 1}
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rrule part: %q", part)
+		}
+		value = strings.ToUpper(strings.TrimSpace(value))
+		switch strings.ToUpper(strings.TrimSpace(key)) {
+		case "FREQ":
+			switch f := Frequency(value); f {
+			case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+				r.Freq = f
+			default:
+				return nil, fmt.Errorf("unsupported rrule freq: %s", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid rrule interval: %s", value)
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid rrule count: %s", value)
+			}
+			r.Count = n
+		case "UNTIL":
+			t, err := parseUntil(value)
+			if err != nil {
+				return nil, err
+			}
+			r.Until = &t
+		case "BYDAY":
+			for _, d := range strings.Split(value, ",") {
+				wd, ok := weekdays[d]
+				if !ok {
+					return nil, fmt.Errorf("invalid rrule byday: %s", d)
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported rrule part: %s", key)
+		}
+	}
+	if r.Freq == "" {
+		return nil, fmt.Errorf("rrule freq is required")
+	}
+	if len(r.ByDay) > 0 && r.Freq != FreqWeekly {
+		return nil, fmt.Errorf("rrule byday is only supported with FREQ=WEEKLY")
+	}
+	return r, nil
+}
+
+func parseUntil(v string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102", time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, v); err == nil {
+			if len(v) == 8 || len(v) == 10 {
+				// a date includes the whole day
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid rrule until: %s", v)
+}
+
+func (r *RRule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, wd := range r.ByDay {
+			days = append(days, strings.ToUpper(wd.String()[:2]))
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next returns the occurrence following cur, and false when the series has ended.
+func (r *RRule) Next(cur time.Time) (time.Time, bool) {
+	if r.Count == 1 {
+		return time.Time{}, false
+	}
+
+	var next time.Time
+	switch r.Freq {
+	case FreqDaily:
+		next = cur.AddDate(0, 0, r.Interval)
+	case FreqWeekly:
+		next = r.nextWeekly(cur)
+	case FreqMonthly:
+		next = addMonthsClamped(cur, r.Interval)
+	case FreqYearly:
+		next = addMonthsClamped(cur, 12*r.Interval)
+	}
+
+	if r.Until != nil && next.After(*r.Until) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+func (r *RRule) nextWeekly(cur time.Time) time.Time {
+	if len(r.ByDay) == 0 {
+		return cur.AddDate(0, 0, 7*r.Interval)
+	}
+	// weeks start on monday; only every Interval-th week counted from cur's week qualifies
+	curWeek := weekStart(cur)
+	for d := 1; d <= 7*r.Interval+7; d++ {
+		next := cur.AddDate(0, 0, d)
+		weeks := int(weekStart(next).Sub(curWeek).Hours()/24+0.5) / 7
+		if weeks%r.Interval != 0 {
+			continue
+		}
+		for _, wd := range r.ByDay {
+			if next.Weekday() == wd {
+				return next
+			}
+		}
+	}
+	return cur.AddDate(0, 0, 7*r.Interval)
+}
+
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	y, m, d := t.AddDate(0, 0, -offset).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// addMonthsClamped adds months keeping the day of month, clamped to the end of shorter
+// months (Jan 31 + 1 month is Feb 28/29, not Mar 3).
+func addMonthsClamped(t time.Time, months int) time.Time {
+	y, m, d := t.Date()
+	first := time.Date(y, m+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	if d > last {
+		d = last
+	}
+	return first.AddDate(0, 0, d-1)
+}
+
+// nextOccurrence builds the task instance following t in its series, or nil when the series
+// has ended or t does not recur.
+func nextOccurrence(t *Task) (*Task, error) {
+	if t.Recurrence == "" || t.Deadline == nil {
+		return nil, nil
+	}
+	rule, err := ParseRRule(t.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+	due, ok := rule.Next(*t.Deadline)
+	if !ok {
+		return nil, nil
+	}
+	if rule.Count > 0 {
+		rule.Count--
+	}
+
+	next := *t
+	next.Completed = false
+	next.Deadline = &due
+	next.Recurrence = rule.String()
+	next.Tags = append([]string(nil), t.Tags...)
+	next.Reminders = make([]Reminder, len(t.Reminders))
+	for i, rem := range t.Reminders {
+		next.Reminders[i] = Reminder{Before: rem.Before}
+	}
+	next.DueNotifiedAt = nil
+	// the source only knows the occurrence it exported
+	next.ExternalID = ""
+	if next.SeriesID == "" {
+		next.SeriesID = t.ID
+	}
+	return &next, nil
+}
@@ -0,0 +1,195 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package task
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestRRuleNext(t *testing.T) {
+	cases := []struct {
+		rule string
+		cur  string
+		next string
+		ok   bool
+	}{
+		{"FREQ=DAILY", "2025-03-01T09:00:00Z", "2025-03-02T09:00:00Z", true},
+		{"FREQ=DAILY;INTERVAL=3", "2025-03-01T09:00:00Z", "2025-03-04T09:00:00Z", true},
+		{"FREQ=WEEKLY", "2025-03-03T09:00:00Z", "2025-03-10T09:00:00Z", true},
+		// monday -> thursday of the same week, thursday -> monday two weeks later
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", "2025-03-03T09:00:00Z", "2025-03-06T09:00:00Z", true},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", "2025-03-06T09:00:00Z", "2025-03-17T09:00:00Z", true},
+		{"FREQ=MONTHLY", "2025-01-31T09:00:00Z", "2025-02-28T09:00:00Z", true},
+		{"FREQ=YEARLY", "2024-02-29T09:00:00Z", "2025-02-28T09:00:00Z", true},
+		{"FREQ=DAILY;COUNT=1", "2025-03-01T09:00:00Z", "", false},
+		{"FREQ=DAILY;UNTIL=20250301", "2025-03-01T09:00:00Z", "", false},
+		{"FREQ=DAILY;UNTIL=20250302", "2025-03-01T09:00:00Z", "2025-03-02T09:00:00Z", true},
+	}
+	for _, c := range cases {
+		r, err := ParseRRule(c.rule)
+		if !assert.NoError(t, err, c.rule) {
+			continue
+		}
+		next, ok := r.Next(date(c.cur))
+		assert.Equal(t, c.ok, ok, c.rule)
+		if c.ok {
+			assert.Equal(t, date(c.next), next, c.rule+" after "+c.cur)
+		}
+	}
+
+	for _, bad := range []string{"", "FREQ=HOURLY", "FREQ=DAILY;INTERVAL=0", "FREQ=DAILY;BYDAY=MO", "FREQ=WEEKLY;BYDAY=XX", "FREQ"} {
+		_, err := ParseRRule(bad)
+		assert.Error(t, err, bad)
+	}
+
+	r, err := ParseRRule("RRULE:freq=weekly;byday=mo,we;count=3")
+	assert.NoError(t, err)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=3", r.String())
+}
+
+func TestRecurringTask(t *testing.T) {
+	s, err := NewStorage(t.TempDir())
+	assert.NoError(t, err)
+	impl, err := NewTaskToolImpl(context.Background(), &TaskToolConfig{Storage: s})
+	assert.NoError(t, err)
+
+	due := date("2025-03-03T09:00:00Z")
+	res, err := impl.Invoke(context.Background(), &TaskRequest{Action: ActionAdd, Task: &Task{
+		Title:      "water plants",
+		Deadline:   &due,
+		Recurrence: "FREQ=WEEKLY;COUNT=2",
+		Tags:       []string{"Home"},
+		Reminders:  []Reminder{{Before: "1h"}},
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, "success", res.Status, res.Error)
+	id := res.TaskList[0].ID
+
+	res, err = impl.Invoke(context.Background(), &TaskRequest{Action: ActionUpdate, Task: &Task{ID: id, Completed: true}})
+	assert.NoError(t, err)
+	if assert.Len(t, res.TaskList, 2) {
+		next := res.TaskList[1]
+		assert.False(t, next.Completed)
+		assert.Equal(t, date("2025-03-10T09:00:00Z"), *next.Deadline)
+		assert.Equal(t, "FREQ=WEEKLY;COUNT=1", next.Recurrence)
+		assert.Equal(t, id, next.SeriesID)
+		assert.Equal(t, []string{"home"}, next.Tags)
+
+		// the last occurrence does not continue the series
+		res, err = impl.Invoke(context.Background(), &TaskRequest{Action: ActionUpdate, Task: &Task{ID: next.ID, Completed: true}})
+		assert.NoError(t, err)
+		assert.Len(t, res.TaskList, 1)
+	}
+
+	res, _ = impl.Invoke(context.Background(), &TaskRequest{Action: ActionAdd, Task: &Task{Title: "x", Recurrence: "FREQ=DAILY"}})
+	assert.Equal(t, "error", res.Status)
+	res, _ = impl.Invoke(context.Background(), &TaskRequest{Action: ActionAdd, Task: &Task{Title: "x", Priority: "urgent"}})
+	assert.Equal(t, "error", res.Status)
+}
+
+func TestListFilters(t *testing.T) {
+	s, err := NewStorage(t.TempDir())
+	assert.NoError(t, err)
+	add := func(title string, due string, p Priority, tags ...string) {
+		task := &Task{ID: title, Title: title, Priority: p, Tags: tags}
+		if due != "" {
+			d := date(due)
+			task.Deadline = &d
+		}
+		assert.NoError(t, s.Add(task))
+	}
+	add("a", "2025-03-05T09:00:00Z", PriorityLow, "work")
+	add("b", "2025-03-02T09:00:00Z", PriorityHigh)
+	add("c", "2025-03-12T09:00:00Z", PriorityMedium, "Work")
+	add("d", "", PriorityHigh)
+
+	titles := func(p *ListParams) []string {
+		tasks, err := s.List(p)
+		assert.NoError(t, err)
+		res := make([]string, 0, len(tasks))
+		for _, task := range tasks {
+			res = append(res, task.Title)
+		}
+		return res
+	}
+	after, before := date("2025-03-03T00:00:00Z"), date("2025-03-10T00:00:00Z")
+	assert.Equal(t, []string{"a"}, titles(&ListParams{DueAfter: &after, DueBefore: &before}))
+	assert.Equal(t, []string{"b", "a", "c", "d"}, titles(&ListParams{SortBy: "deadline"}))
+	assert.Equal(t, []string{"a", "c"}, titles(&ListParams{Tag: "WORK", SortBy: "deadline"}))
+	assert.Equal(t, []string{"b", "d"}, titles(&ListParams{Priority: PriorityHigh, SortBy: "deadline"}))
+	assert.Equal(t, "b", titles(&ListParams{SortBy: "priority"})[0])
+}
+
+func TestSchedulerEvents(t *testing.T) {
+	s, err := NewStorage(t.TempDir())
+	assert.NoError(t, err)
+	due := date("2025-03-03T09:00:00Z")
+	assert.NoError(t, s.Add(&Task{ID: "t", Title: "report", Deadline: &due, Reminders: []Reminder{{Before: "1h"}, {Before: "10m"}}}))
+
+	sched := NewScheduler(s, time.Minute)
+	events, stop := sched.Subscribe()
+	defer stop()
+
+	assert.Empty(t, sched.Tick(due.Add(-2*time.Hour)))
+	got := sched.Tick(due.Add(-30 * time.Minute))
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, EventReminder, got[0].Type)
+		assert.Equal(t, EventReminder, (<-events).Type)
+	}
+	// fired events are not repeated, the missed 10m reminder is folded into the due event
+	got = sched.Tick(due.Add(time.Minute))
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, EventDue, got[0].Type)
+	}
+	assert.Empty(t, sched.Tick(due.Add(2*time.Minute)))
+
+	// sent marks survive a restart
+	reopened, err := NewStorage(filepath.Dir(s.filePath))
+	assert.NoError(t, err)
+	assert.Empty(t, NewScheduler(reopened, time.Minute).Tick(due.Add(time.Hour)))
+}
+
+func TestLegacyDeadline(t *testing.T) {
+	dir := t.TempDir()
+	legacy := `{"id":"1","title":"a","deadline":"2025-03-03T09:00:00Z","created_at":"x"}` + "\n" +
+		`{"id":"2","title":"b","deadline":"2025-03-04","created_at":"x"}` + "\n" +
+		`{"id":"3","title":"c","content":"note","deadline":"next friday","created_at":"x"}` + "\n" +
+		`{"id":"4","title":"d","deadline":"","created_at":"x"}` + "\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "tasks.jsonl"), []byte(legacy), 0644))
+
+	s, err := NewStorage(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, date("2025-03-03T09:00:00Z"), *s.cache["1"].Deadline)
+	assert.Equal(t, 4, s.cache["2"].Deadline.Day())
+	assert.Nil(t, s.cache["3"].Deadline)
+	assert.Equal(t, "note\n(deadline: next friday)", s.cache["3"].Content)
+	assert.Nil(t, s.cache["4"].Deadline)
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package task
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+type EventType string
+
+const (
+	// EventReminder fires when a reminder of a task comes up before its deadline.
+	EventReminder EventType = "reminder"
+	// EventDue fires once when the deadline of an open task has passed.
+	EventDue EventType = "due"
+)
+
+type Event struct {
+	Type EventType `json:"type"`
+	Task *Task     `json:"task"`
+	At   time.Time `json:"at"`
+}
+
+// Scheduler periodically checks the storage for reminders and deadlines that came up and
+// fans the resulting events out to its subscribers.
+type Scheduler struct {
+	storage  *Storage
+	interval time.Duration
+
+	mu   sync.Mutex
+	subs map[chan Event]struct{}
+}
+
+func NewScheduler(storage *Storage, interval time.Duration) *Scheduler {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &Scheduler{
+		storage:  storage,
+		interval: interval,
+		subs:     make(map[chan Event]struct{}),
+	}
+}
+
+// Start runs the scheduler in the background until ctx is done.
+func (s *Scheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.Tick(time.Now())
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				s.Tick(now)
+			}
+		}
+	}()
+}
+
+// Tick checks the storage once and publishes the events that came up by now.
+func (s *Scheduler) Tick(now time.Time) []Event {
+	events, err := s.storage.dueEvents(now)
+	if err != nil {
+		log.Printf("[task] failed to check reminders: %v", err)
+	}
+	for _, e := range events {
+		s.publish(e)
+	}
+	return events
+}
+
+// Subscribe returns a channel receiving every event from now on, and a function to stop.
+// A subscriber that does not keep up misses events rather than blocking the scheduler.
+func (s *Scheduler) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, 16)
+	s.mu.Lock()
+	s.subs[ch] = struct{}{}
+	s.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			delete(s.subs, ch)
+			s.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+func (s *Scheduler) publish(e Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ch := range s.subs {
+		select {
+		case ch <- e:
+		default:
+			log.Printf("[task] dropped %s event of task %s for a slow subscriber", e.Type, e.Task.ID)
+		}
+	}
+}
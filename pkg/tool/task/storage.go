@@ -17,24 +17,87 @@
 package task
 
 import (
-	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 var defaultStorage *Storage
 
+// Storage keeps tasks in an operation log (see oplog.go) that several processes may share:
+// every call takes an advisory lock on the data directory and first replays what other
+// processes appended since, so the cache never serves stale tasks to a writer.
 type Storage struct {
 	filePath string
-	mu       sync.RWMutex
+	mu       sync.Mutex
 	cache    map[string]*Task
-	dirty    bool
+	lock     *fileLock
+
+	// how much of the log has been replayed: the file, the bytes read, its first line and
+	// the number of records, which drives compaction
+	info   os.FileInfo
+	offset int64
+	head   []byte
+	ops    int
+
+	compactMinOps int
+
+	hub     changeHub
+	pending []Change
+}
+
+var (
+	ErrTaskNotFound = errors.New("task not found")
+	// ErrETagMismatch is returned by conditional writes when the task was changed since the
+	// caller read it.
+	ErrETagMismatch = errors.New("task was modified concurrently")
+)
+
+type writeOptions struct {
+	ifMatch       string
+	keepCompleted bool
+}
+
+type WriteOption func(*writeOptions)
+
+// IfMatch makes a write fail with ErrETagMismatch unless the task still has this ETag.
+func IfMatch(etag string) WriteOption {
+	return func(o *writeOptions) {
+		o.ifMatch = etag
+	}
+}
+
+// KeepCompleted leaves the completed status alone, for updates that do not set it.
+func KeepCompleted() WriteOption {
+	return func(o *writeOptions) {
+		o.keepCompleted = true
+	}
+}
+
+func (o *writeOptions) check(t *Task) error {
+	if o.ifMatch != "" && o.ifMatch != "*" && o.ifMatch != t.ETag() {
+		return fmt.Errorf("%w: %s", ErrETagMismatch, t.ID)
+	}
+	return nil
+}
+
+func applyWriteOptions(opts []WriteOption) *writeOptions {
+	o := &writeOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
 }
 
 func GetDefaultStorage() *Storage {
@@ -57,77 +120,210 @@ func NewStorage(dataDir string) (*Storage, error) {
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create data directory: %v", err)
 	}
+	lock, err := openFileLock(filepath.Join(dataDir, "tasks.lock"))
+	if err != nil {
+		return nil, err
+	}
 	s := &Storage{
-		filePath: filepath.Join(dataDir, "tasks.jsonl"),
-		cache:    make(map[string]*Task),
+		filePath:      filepath.Join(dataDir, "tasks.jsonl"),
+		cache:         make(map[string]*Task),
+		lock:          lock,
+		compactMinOps: defaultCompactMinOps,
 	}
 
-	if err := s.loadFromDisk(); err != nil {
+	// replay the log, compacting it if it has grown since it was last compacted
+	if err := s.mutate(func() ([]opRecord, error) { return nil, nil }); err != nil {
+		lock.close()
 		return nil, fmt.Errorf("failed to load from disk: %v", err)
 	}
 
 	return s, nil
 }
 
-func (s *Storage) loadFromDisk() error {
-	file, err := os.OpenFile(s.filePath, os.O_CREATE|os.O_RDONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to open file: %v", err)
-	}
-	defer file.Close()
+// Close releases the lock file. The Storage must not be used afterwards.
+func (s *Storage) Close() error {
+	return s.lock.close()
+}
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		var task Task
-		if err := json.Unmarshal(scanner.Bytes(), &task); err != nil {
-			return fmt.Errorf("failed to unmarshal task: %v", err)
-		}
-		s.cache[task.ID] = &task
+// read runs fn on an up-to-date cache under a shared lock.
+func (s *Storage) read(fn func()) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.publishChanges()
+
+	if err := s.lock.lock(false); err != nil {
+		return fmt.Errorf("failed to lock storage: %v", err)
 	}
+	defer s.lock.unlock()
 
-	return scanner.Err()
+	if err := s.refreshLocked(); err != nil {
+		return err
+	}
+	fn()
+	return nil
 }
 
-func (s *Storage) Add(task *Task) error {
+// mutate runs fn on an up-to-date cache under the exclusive lock. fn applies its changes to
+// the cache and returns the records to log for them. The changes are rolled back when fn fails
+// or the records cannot be logged; fn must therefore replace cached tasks rather than modify them.
+func (s *Storage) mutate(fn func() ([]opRecord, error)) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	defer s.publishChanges()
 
-	task.CreatedAt = time.Now().Format(time.RFC3339)
-	task.IsDeleted = false
-	s.cache[task.ID] = task
+	if err := s.lock.lock(true); err != nil {
+		return fmt.Errorf("failed to lock storage: %v", err)
+	}
+	defer s.lock.unlock()
 
-	// 直接追加到文件末尾
-	file, err := os.OpenFile(s.filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err := s.refreshLocked(); err != nil {
+		return err
+	}
+	// tasks are replaced, never modified in place, so a shallow copy restores the cache
+	backup := maps.Clone(s.cache)
+	recs, err := fn()
 	if err != nil {
-		return fmt.Errorf("failed to open file: %v", err)
+		s.cache = backup
+		return err
 	}
-	defer file.Close()
+	if len(recs) > 0 {
+		if err := s.appendLocked(recs); err != nil {
+			// part of the records may have reached the log, replay it on the next call
+			s.cache = backup
+			s.info = nil
+			return err
+		}
+		for _, rec := range recs {
+			switch {
+			case rec.Op == opDelete:
+				s.recordChange(ChangeDeleted, rec.ID, nil)
+			case rec.created:
+				s.recordChange(ChangeCreated, rec.ID, rec.Task)
+			default:
+				s.recordChange(ChangeUpdated, rec.ID, rec.Task)
+			}
+		}
+	}
+	if s.needsCompactionLocked() {
+		if err := s.compactLocked(); err != nil {
+			// the log is still complete, compaction is retried on the next write
+			log.Printf("[task] failed to compact %s: %v", s.filePath, err)
+		}
+	}
+	return nil
+}
 
-	data, err := json.Marshal(task)
-	if err != nil {
-		return fmt.Errorf("failed to marshal task: %v", err)
+// Compact rewrites the log without deleted tasks and superseded versions.
+func (s *Storage) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.lock.lock(true); err != nil {
+		return fmt.Errorf("failed to lock storage: %v", err)
 	}
+	defer s.lock.unlock()
 
-	if _, err := file.Write(append(data, '\n')); err != nil {
-		return fmt.Errorf("failed to write task: %v", err)
+	if err := s.refreshLocked(); err != nil {
+		return err
 	}
+	return s.compactLocked()
+}
+
+// legacyTask matches tasks written when the deadline was a free-form string.
+type legacyTask struct {
+	Task
+	Deadline string `json:"deadline"`
+}
+
+var legacyDeadlineLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"}
 
-	if err := file.Sync(); err != nil {
-		return fmt.Errorf("failed to sync file: %v", err)
+func decodeTask(data []byte) (*Task, error) {
+	var task Task
+	err := json.Unmarshal(data, &task)
+	if err == nil {
+		return &task, nil
 	}
 
-	return nil
+	var legacy legacyTask
+	if json.Unmarshal(data, &legacy) != nil {
+		return nil, err
+	}
+	task = legacy.Task
+	task.Deadline = nil
+	for _, layout := range legacyDeadlineLayouts {
+		if t, err := time.ParseInLocation(layout, strings.TrimSpace(legacy.Deadline), time.Local); err == nil {
+			task.Deadline = &t
+			break
+		}
+	}
+	if task.Deadline == nil && legacy.Deadline != "" {
+		// keep what the user wrote rather than dropping it silently
+		task.Content = strings.TrimSpace(task.Content + "\n(deadline: " + legacy.Deadline + ")")
+	}
+	return &task, nil
+}
+
+func (s *Storage) Add(task *Task) error {
+	task.CreatedAt = time.Now().Format(time.RFC3339)
+	task.IsDeleted = false
+	task.Tags = normalizeTags(task.Tags)
+	task.Revision = 1
+	stored := *task
+
+	return s.mutate(func() ([]opRecord, error) {
+		s.cache[stored.ID] = &stored
+		return []opRecord{createOp(&stored)}, nil
+	})
+}
+
+// AddAll adds several tasks at once: either all of them are stored or none.
+func (s *Storage) AddAll(tasks []*Task) error {
+	now := time.Now().Format(time.RFC3339)
+	stored := make([]*Task, len(tasks))
+	for i, task := range tasks {
+		task.CreatedAt = now
+		task.IsDeleted = false
+		task.Tags = normalizeTags(task.Tags)
+		task.Revision = 1
+		cp := *task
+		stored[i] = &cp
+	}
+
+	return s.mutate(func() ([]opRecord, error) {
+		recs := make([]opRecord, 0, len(stored))
+		for _, task := range stored {
+			s.cache[task.ID] = task
+			recs = append(recs, createOp(task))
+		}
+		return recs, nil
+	})
+}
+
+// Get returns the task with the given id.
+func (s *Storage) Get(id string) (*Task, error) {
+	var task *Task
+	if err := s.read(func() { task = s.cache[id] }); err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, id)
+	}
+	return task, nil
 }
 
 func (s *Storage) List(params *ListParams) ([]*Task, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	var all []*Task
+	if err := s.read(func() {
+		all = make([]*Task, 0, len(s.cache))
+		for _, task := range s.cache {
+			all = append(all, task)
+		}
+	}); err != nil {
+		return nil, err
+	}
 
 	var activeTasks, completedTasks []*Task
-	for _, task := range s.cache {
-		if task.IsDeleted {
-			continue
-		}
+	for _, task := range all {
 
 		if params.Query != "" && !contains(task.Title, params.Query) && !contains(task.Content, params.Query) {
 			continue
@@ -139,6 +335,16 @@ func (s *Storage) List(params *ListParams) ([]*Task, error) {
 			}
 		}
 
+		if !matchDue(task, params.DueAfter, params.DueBefore) {
+			continue
+		}
+		if params.Tag != "" && !slices.Contains(task.Tags, strings.ToLower(params.Tag)) {
+			continue
+		}
+		if params.Priority != "" && task.Priority != params.Priority {
+			continue
+		}
+
 		if task.Completed {
 			completedTasks = append(completedTasks, task)
 		} else {
@@ -146,13 +352,8 @@ func (s *Storage) List(params *ListParams) ([]*Task, error) {
 		}
 	}
 
-	// 按创建时间排序（最新的在前面）
-	sort.Slice(activeTasks, func(i, j int) bool {
-		return activeTasks[i].CreatedAt > activeTasks[j].CreatedAt
-	})
-	sort.Slice(completedTasks, func(i, j int) bool {
-		return completedTasks[i].CreatedAt > completedTasks[j].CreatedAt
-	})
+	sortTasks(activeTasks, params.SortBy)
+	sortTasks(completedTasks, params.SortBy)
 
 	// 合并列表：未完成的在前，已完成的在后
 	tasks := append(activeTasks, completedTasks...)
@@ -164,13 +365,59 @@ func (s *Storage) List(params *ListParams) ([]*Task, error) {
 	return tasks, nil
 }
 
-func (s *Storage) Update(task *Task) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// Update applies the non-empty fields of task and returns the updated task. Completing a
+// recurring task also creates its next occurrence, which is returned second.
+func (s *Storage) Update(task *Task, opts ...WriteOption) ([]*Task, error) {
+	o := applyWriteOptions(opts)
+	var res []*Task
+	err := s.mutate(func() ([]opRecord, error) {
+		updated, recs, err := s.updateLocked(task, o)
+		res = updated
+		return recs, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CompleteAll marks several tasks completed at once: if any of them does not exist or cannot
+// be completed nothing is changed. It returns the completed tasks followed by the next
+// occurrences they created.
+func (s *Storage) CompleteAll(ids []string) ([]*Task, error) {
+	var completed, next []*Task
+	err := s.mutate(func() ([]opRecord, error) {
+		for _, id := range ids {
+			if _, ok := s.cache[id]; !ok {
+				return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, id)
+			}
+		}
+		var recs []opRecord
+		for _, id := range ids {
+			updated, r, err := s.updateLocked(&Task{ID: id, Completed: true}, &writeOptions{})
+			if err != nil {
+				// mutate rolls back the tasks completed so far
+				return nil, fmt.Errorf("%s: %w", id, err)
+			}
+			completed = append(completed, updated[0])
+			next = append(next, updated[1:]...)
+			recs = append(recs, r...)
+		}
+		return recs, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(completed, next...), nil
+}
 
+func (s *Storage) updateLocked(task *Task, o *writeOptions) ([]*Task, []opRecord, error) {
 	existing, exists := s.cache[task.ID]
-	if !exists || existing.IsDeleted {
-		return fmt.Errorf("task not found: %s", task.ID)
+	if !exists {
+		return nil, nil, fmt.Errorf("%w: %s", ErrTaskNotFound, task.ID)
+	}
+	if err := o.check(existing); err != nil {
+		return nil, nil, err
 	}
 
 	// 只更新非空字段
@@ -181,98 +428,203 @@ func (s *Storage) Update(task *Task) error {
 	if task.Content != "" {
 		updated.Content = task.Content
 	}
-	if task.Deadline != "" {
+	if task.Priority != "" {
+		updated.Priority = task.Priority
+	}
+	if task.Tags != nil {
+		updated.Tags = normalizeTags(task.Tags)
+	}
+	if task.Recurrence != "" {
+		updated.Recurrence = task.Recurrence
+	}
+	if task.Reminders != nil {
+		updated.Reminders = make([]Reminder, len(task.Reminders))
+		for i, rem := range task.Reminders {
+			updated.Reminders[i] = Reminder{Before: rem.Before}
+		}
+	}
+	if task.Deadline != nil && (existing.Deadline == nil || !task.Deadline.Equal(*existing.Deadline)) {
 		updated.Deadline = task.Deadline
+		// a moved deadline re-arms its reminders
+		updated.DueNotifiedAt = nil
+		updated.Reminders = append([]Reminder(nil), updated.Reminders...)
+		for i := range updated.Reminders {
+			updated.Reminders[i].SentAt = nil
+		}
+	}
+	if updated.Recurrence != "" && updated.Deadline == nil {
+		return nil, nil, fmt.Errorf("deadline is required for a recurring task")
 	}
 	// Completed 字段需要特殊处理，因为它是布尔值
-	if task.Completed != existing.Completed {
+	if task.Completed != existing.Completed && !o.keepCompleted {
 		updated.Completed = task.Completed
 	}
+	updated.Revision = existing.Revision + 1
 
-	s.cache[task.ID] = &updated
-	s.dirty = true
-
-	return s.syncToDisk()
-}
-
-func (s *Storage) Delete(id string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	task, exists := s.cache[id]
-	if !exists || task.IsDeleted {
-		return fmt.Errorf("task not found: %s", id)
+	res := []*Task{&updated}
+	recs := []opRecord{putOp(&updated)}
+	if updated.Completed && !existing.Completed {
+		next, err := s.scheduleNextLocked(&updated)
+		if err != nil {
+			return nil, nil, err
+		}
+		if next != nil {
+			res = append(res, next)
+			recs = append(recs, createOp(next))
+		}
 	}
 
-	// 标记删除
-	task.IsDeleted = true
-	s.dirty = true
-
-	return s.syncToDisk()
+	s.cache[task.ID] = &updated
+	return res, recs, nil
 }
 
-func (s *Storage) syncToDisk() error {
-	if !s.dirty {
-		return nil
+// scheduleNextLocked stores the occurrence following a task that has just been completed, or
+// returns nil when it does not recur. The caller stores t and logs both.
+func (s *Storage) scheduleNextLocked(t *Task) (*Task, error) {
+	next, err := nextOccurrence(t)
+	if err != nil || next == nil {
+		return nil, err
 	}
+	next.ID = uuid.New().String()
+	next.CreatedAt = time.Now().Format(time.RFC3339)
+	next.Revision = 1
+	// the series continues from the new instance, completing this one again must not fork it
+	t.Recurrence = ""
+	t.SeriesID = next.SeriesID
+	s.cache[next.ID] = next
+	return next, nil
+}
 
-	// 创建临时文件
-	tmpFile := s.filePath + ".tmp"
-	file, err := os.Create(tmpFile)
+// dueEvents returns the reminders and deadlines that have come up by now and marks them as
+// sent, so every event fires once even across restarts.
+func (s *Storage) dueEvents(now time.Time) ([]Event, error) {
+	var events []Event
+	err := s.mutate(func() ([]opRecord, error) {
+		events = nil
+		return s.dueEventsLocked(now, &events), nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create temp file: %v", err)
+		return nil, err
 	}
-	defer file.Close()
 
-	// 写入数据到临时文件
-	for _, task := range s.cache {
-		data, err := json.Marshal(task)
-		if err != nil {
-			os.Remove(tmpFile) // 清理临时文件
-			return fmt.Errorf("failed to marshal task: %v", err)
-		}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Task.Deadline.Before(*events[j].Task.Deadline)
+	})
+	return events, nil
+}
 
-		if _, err := file.Write(append(data, '\n')); err != nil {
-			os.Remove(tmpFile) // 清理临时文件
-			return fmt.Errorf("failed to write task: %v", err)
+func (s *Storage) dueEventsLocked(now time.Time, events *[]Event) []opRecord {
+	var recs []opRecord
+	for id, task := range s.cache {
+		if task.Completed || task.Deadline == nil {
+			continue
+		}
+		deadline := *task.Deadline
+
+		var updated *Task
+		mark := func() *Task {
+			if updated == nil {
+				cp := *task
+				cp.Reminders = append([]Reminder(nil), task.Reminders...)
+				updated = &cp
+			}
+			return updated
+		}
+		for i, rem := range task.Reminders {
+			before, err := time.ParseDuration(rem.Before)
+			if err != nil || rem.SentAt != nil || now.Before(deadline.Add(-before)) {
+				continue
+			}
+			sent := now
+			mark().Reminders[i].SentAt = &sent
+			// reminders that were missed until the deadline passed are folded into the due event
+			if now.Before(deadline) {
+				*events = append(*events, Event{Type: EventReminder, Task: updated, At: now})
+			}
+		}
+		if task.DueNotifiedAt == nil && !now.Before(deadline) {
+			sent := now
+			mark().DueNotifiedAt = &sent
+			*events = append(*events, Event{Type: EventDue, Task: updated, At: now})
+		}
+		if updated != nil {
+			updated.Revision++
+			s.cache[id] = updated
+			recs = append(recs, putOp(updated))
 		}
 	}
+	return recs
+}
 
-	// 确保所有数据都写入磁盘
-	if err := file.Sync(); err != nil {
-		os.Remove(tmpFile)
-		return fmt.Errorf("failed to sync file: %v", err)
-	}
+func (s *Storage) Delete(id string, opts ...WriteOption) error {
+	o := applyWriteOptions(opts)
+	return s.mutate(func() ([]opRecord, error) {
+		existing, exists := s.cache[id]
+		if !exists {
+			return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, id)
+		}
+		if err := o.check(existing); err != nil {
+			return nil, err
+		}
+		delete(s.cache, id)
+		return []opRecord{deleteOp(id)}, nil
+	})
+}
 
-	// 关闭文件
-	if err := file.Close(); err != nil {
-		os.Remove(tmpFile)
-		return fmt.Errorf("failed to close file: %v", err)
+func matchDue(task *Task, after, before *time.Time) bool {
+	if after == nil && before == nil {
+		return true
+	}
+	if task.Deadline == nil {
+		return false
 	}
+	if after != nil && task.Deadline.Before(*after) {
+		return false
+	}
+	if before != nil && !task.Deadline.Before(*before) {
+		return false
+	}
+	return true
+}
 
-	// 备份现有文件（如果存在）
-	if _, err := os.Stat(s.filePath); err == nil {
-		backupFile := s.filePath + ".bak"
-		if err := os.Rename(s.filePath, backupFile); err != nil {
-			os.Remove(tmpFile)
-			return fmt.Errorf("failed to backup file: %v", err)
+func sortTasks(tasks []*Task, by string) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		switch by {
+		case "deadline":
+			// earliest first, tasks without a deadline last
+			if (a.Deadline == nil) != (b.Deadline == nil) {
+				return a.Deadline != nil
+			}
+			if a.Deadline != nil && !a.Deadline.Equal(*b.Deadline) {
+				return a.Deadline.Before(*b.Deadline)
+			}
+		case "priority":
+			if a.Priority.rank() != b.Priority.rank() {
+				return a.Priority.rank() < b.Priority.rank()
+			}
 		}
-	}
+		// 按创建时间排序（最新的在前面）
+		if a.CreatedAt != b.CreatedAt {
+			return a.CreatedAt > b.CreatedAt
+		}
+		// tasks created within the same second keep a stable order
+		return a.ID < b.ID
+	})
+}
 
-	// 将临时文件重命名为正式文件
-	if err := os.Rename(tmpFile, s.filePath); err != nil {
-		// 如果重命名失败，尝试恢复备份
-		if backupErr := os.Rename(s.filePath+".bak", s.filePath); backupErr != nil {
-			return fmt.Errorf("failed to rename temp file and restore backup: %v, backup error: %v", err, backupErr)
+func normalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+	res := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !slices.Contains(res, tag) {
+			res = append(res, tag)
 		}
-		return fmt.Errorf("failed to rename temp file: %v", err)
 	}
-
-	// 删除备份文件
-	os.Remove(s.filePath + ".bak")
-
-	s.dirty = false
-	return nil
+	return res
 }
 
 func contains(s, substr string) bool {
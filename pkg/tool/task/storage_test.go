@@ -0,0 +1,260 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package task
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func countLines(t *testing.T, path string) int {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	n := 0
+	for sc := bufio.NewScanner(f); sc.Scan(); {
+		n++
+	}
+	return n
+}
+
+func TestStorageSeesOtherWriters(t *testing.T) {
+	dir := t.TempDir()
+	a, err := NewStorage(dir)
+	require.NoError(t, err)
+	b, err := NewStorage(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, a.Add(&Task{ID: "1", Title: "from a"}))
+	got, err := b.Get("1")
+	require.NoError(t, err)
+	assert.Equal(t, "from a", got.Title)
+
+	_, err = b.Update(&Task{ID: "1", Title: "edited by b"})
+	require.NoError(t, err)
+	got, err = a.Get("1")
+	require.NoError(t, err)
+	assert.Equal(t, "edited by b", got.Title)
+
+	// a compaction in one process replaces the file, the other one reloads it
+	require.NoError(t, a.Delete("1"))
+	require.NoError(t, a.Add(&Task{ID: "2", Title: "kept"}))
+	require.NoError(t, a.Compact())
+	assert.Equal(t, 2, countLines(t, a.filePath))
+	_, err = b.Get("1")
+	assert.Error(t, err)
+	require.NoError(t, b.Add(&Task{ID: "3", Title: "after compaction"}))
+	tasks, err := a.List(&ListParams{})
+	require.NoError(t, err)
+	assert.Len(t, tasks, 2)
+}
+
+func TestCompleteAllRollsBack(t *testing.T) {
+	s, err := NewStorage(t.TempDir())
+	require.NoError(t, err)
+	due := time.Now().Add(time.Hour)
+	require.NoError(t, s.Add(&Task{ID: "1", Title: "plain"}))
+	// a rule that was stored unchecked fails when the next occurrence is computed
+	require.NoError(t, s.Add(&Task{ID: "2", Title: "broken series", Deadline: &due, Recurrence: "FREQ=SOMETIMES"}))
+	lines := countLines(t, s.filePath)
+
+	_, err = s.CompleteAll([]string{"1", "2"})
+	assert.Error(t, err)
+	got, err := s.Get("1")
+	require.NoError(t, err)
+	assert.False(t, got.Completed, "the task completed before the failure is rolled back")
+	assert.EqualValues(t, 1, got.Revision)
+	assert.Equal(t, lines, countLines(t, s.filePath))
+
+	tasks, err := s.CompleteAll([]string{"1"})
+	require.NoError(t, err)
+	assert.True(t, tasks[0].Completed)
+}
+
+func TestStorageCompaction(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewStorage(dir)
+	require.NoError(t, err)
+	s.compactMinOps = 20
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, s.Add(&Task{ID: strconv.Itoa(i), Title: "t"}))
+	}
+	for i := 0; i < 10; i++ {
+		_, err := s.Update(&Task{ID: "0", Title: fmt.Sprint("v", i)})
+		require.NoError(t, err)
+	}
+	for i := 1; i < 10; i++ {
+		require.NoError(t, s.Delete(strconv.Itoa(i)))
+	}
+
+	// tombstones and superseded versions are gone, only the header and the live task remain
+	assert.LessOrEqual(t, countLines(t, s.filePath), 20)
+	reopened, err := NewStorage(dir)
+	require.NoError(t, err)
+	tasks, err := reopened.List(&ListParams{})
+	require.NoError(t, err)
+	if assert.Len(t, tasks, 1) {
+		assert.Equal(t, "v9", tasks[0].Title)
+	}
+}
+
+func TestStorageTornTail(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewStorage(dir)
+	require.NoError(t, err)
+	require.NoError(t, s.Add(&Task{ID: "1", Title: "a"}))
+
+	// a writer crashed halfway through a record
+	f, err := os.OpenFile(s.filePath, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"op":"put","task":{"id":"2","ti`)
+	require.NoError(t, err)
+	f.Close()
+
+	require.NoError(t, s.Add(&Task{ID: "3", Title: "c"}))
+	reopened, err := NewStorage(dir)
+	require.NoError(t, err)
+	tasks, err := reopened.List(&ListParams{})
+	require.NoError(t, err)
+	assert.Len(t, tasks, 2)
+}
+
+func TestStorageConcurrentWriters(t *testing.T) {
+	const writers, perWriter = 8, 40
+	dir := t.TempDir()
+
+	// every writer has its own Storage, so they only coordinate through the file lock
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		s, err := NewStorage(dir)
+		require.NoError(t, err)
+		s.compactMinOps = 50
+		wg.Add(1)
+		go func(w int, s *Storage) {
+			defer wg.Done()
+			defer s.Close()
+			for i := 0; i < perWriter; i++ {
+				id := fmt.Sprintf("w%d-%d", w, i)
+				if !assert.NoError(t, s.Add(&Task{ID: id, Title: id})) {
+					return
+				}
+				if i%2 == 0 {
+					_, err := s.Update(&Task{ID: id, Completed: true})
+					assert.NoError(t, err)
+				}
+				if i%5 == 0 {
+					assert.NoError(t, s.Delete(id))
+				}
+			}
+		}(w, s)
+	}
+	wg.Wait()
+
+	verifyStress(t, dir, writers, perWriter)
+}
+
+func TestStorageMultiProcess(t *testing.T) {
+	if testing.Short() {
+		t.Skip("spawns processes")
+	}
+	const writers, perWriter = 4, 40
+	dir := t.TempDir()
+
+	cmds := make([]*exec.Cmd, 0, writers)
+	for w := 0; w < writers; w++ {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestStorageHelperProcess$")
+		cmd.Env = append(os.Environ(),
+			"TASK_STORAGE_HELPER_DIR="+dir,
+			"TASK_STORAGE_HELPER_WRITER="+strconv.Itoa(w),
+			"TASK_STORAGE_HELPER_COUNT="+strconv.Itoa(perWriter),
+		)
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		require.NoError(t, cmd.Start())
+		cmds = append(cmds, cmd)
+	}
+	for _, cmd := range cmds {
+		assert.NoError(t, cmd.Wait())
+	}
+
+	verifyStress(t, dir, writers, perWriter)
+}
+
+// TestStorageHelperProcess is the writer process of TestStorageMultiProcess.
+func TestStorageHelperProcess(t *testing.T) {
+	dir := os.Getenv("TASK_STORAGE_HELPER_DIR")
+	if dir == "" {
+		t.Skip("helper process")
+	}
+	w, _ := strconv.Atoi(os.Getenv("TASK_STORAGE_HELPER_WRITER"))
+	n, _ := strconv.Atoi(os.Getenv("TASK_STORAGE_HELPER_COUNT"))
+
+	s, err := NewStorage(dir)
+	require.NoError(t, err)
+	s.compactMinOps = 50
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("w%d-%d", w, i)
+		require.NoError(t, s.Add(&Task{ID: id, Title: id}))
+		if i%2 == 0 {
+			_, err := s.Update(&Task{ID: id, Completed: true})
+			require.NoError(t, err)
+		}
+		if i%5 == 0 {
+			require.NoError(t, s.Delete(id))
+		}
+	}
+}
+
+func verifyStress(t *testing.T, dir string, writers, perWriter int) {
+	s, err := NewStorage(dir)
+	require.NoError(t, err)
+	tasks, err := s.List(&ListParams{})
+	require.NoError(t, err)
+
+	byID := make(map[string]*Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+	want := 0
+	for w := 0; w < writers; w++ {
+		for i := 0; i < perWriter; i++ {
+			id := fmt.Sprintf("w%d-%d", w, i)
+			task, ok := byID[id]
+			if i%5 == 0 {
+				assert.False(t, ok, id)
+				continue
+			}
+			want++
+			if assert.True(t, ok, id) {
+				assert.Equal(t, i%2 == 0, task.Completed, id)
+			}
+		}
+	}
+	assert.Len(t, tasks, want)
+	_, err = os.Stat(filepath.Join(dir, "tasks.jsonl.tmp"))
+	assert.True(t, os.IsNotExist(err))
+}
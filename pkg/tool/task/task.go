@@ -19,6 +19,7 @@ package task
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/components/tool/utils"
@@ -33,29 +34,135 @@ const (
 	ActionUpdate Action = "update"
 	ActionDelete Action = "delete"
 	ActionList   Action = "list"
+	ActionImport Action = "import"
+	ActionExport Action = "export"
 )
 
+type Priority string
+
+const (
+	PriorityLow    Priority = "low"
+	PriorityMedium Priority = "medium"
+	PriorityHigh   Priority = "high"
+)
+
+// rank orders priorities from high to low, tasks without a priority come last.
+func (p Priority) rank() int {
+	switch p {
+	case PriorityHigh:
+		return 0
+	case PriorityMedium:
+		return 1
+	case PriorityLow:
+		return 2
+	default:
+		return 3
+	}
+}
+
 type Task struct {
-	ID        string `json:"id" jsonschema_description:"id of the task"`
-	Title     string `json:"title" jsonschema_description:"title of the task"`
-	Content   string `json:"content" jsonschema_description:"content of the task"`
-	Completed bool   `json:"completed" jsonschema_description:"completed status of the task"`
-	Deadline  string `json:"deadline" jsonschema_description:"deadline of the task"`
-	IsDeleted bool   `json:"is_deleted" jsonschema:"-"`
+	ID         string     `json:"id" jsonschema_description:"id of the task"`
+	Title      string     `json:"title" jsonschema_description:"title of the task"`
+	Content    string     `json:"content" jsonschema_description:"content of the task"`
+	Completed  bool       `json:"completed" jsonschema_description:"completed status of the task"`
+	Deadline   *time.Time `json:"deadline,omitempty" jsonschema_description:"deadline of the task, RFC3339 with timezone, e.g. 2025-01-15T18:00:00+08:00"`
+	Priority   Priority   `json:"priority,omitempty" jsonschema_description:"priority of the task, enum:low,medium,high"`
+	Tags       []string   `json:"tags,omitempty" jsonschema_description:"tags of the task"`
+	Recurrence string     `json:"recurrence,omitempty" jsonschema_description:"RRULE of a recurring task, e.g. FREQ=WEEKLY;BYDAY=MO or FREQ=DAILY;INTERVAL=2;COUNT=5; requires a deadline, completing the task creates the next one"`
+	Reminders  []Reminder `json:"reminders,omitempty" jsonschema_description:"reminders before the deadline"`
+	SeriesID   string     `json:"series_id,omitempty" jsonschema_description:"id of the first task of a recurring series"`
+	IsDeleted  bool       `json:"is_deleted" jsonschema:"-"`
+	// Revision counts the writes of the task, see ETag.
+	Revision int64 `json:"revision" jsonschema:"-"`
+
+	// DueNotifiedAt is set once the scheduler has announced the deadline.
+	DueNotifiedAt *time.Time `json:"due_notified_at,omitempty" jsonschema:"-"`
+	// ExternalID is the id of the task in the calendar or file it was imported from.
+	ExternalID string `json:"external_id,omitempty" jsonschema:"-"`
 
 	CreatedAt string `json:"created_at" jsonschema_description:"created time of the task"`
 }
 
+// ETag identifies the current version of the task for conditional writes.
+func (t *Task) ETag() string {
+	return fmt.Sprintf(`"%d"`, t.Revision)
+}
+
+type Reminder struct {
+	Before string `json:"before" jsonschema_description:"how long before the deadline to remind, e.g. 15m, 2h, 24h"`
+	// SentAt is set once the scheduler has fired the reminder.
+	SentAt *time.Time `json:"sent_at,omitempty" jsonschema:"-"`
+}
+
+// ValidateNew checks a task about to be added.
+func (t *Task) ValidateNew() error {
+	if t.Title == "" {
+		return fmt.Errorf("title is required")
+	}
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	if t.Recurrence != "" && t.Deadline == nil {
+		return fmt.Errorf("deadline is required for a recurring task")
+	}
+	return nil
+}
+
+// Validate checks the typed fields of a task as added or patched by the caller.
+func (t *Task) Validate() error {
+	switch t.Priority {
+	case "", PriorityLow, PriorityMedium, PriorityHigh:
+	default:
+		return fmt.Errorf("invalid priority: %s", t.Priority)
+	}
+	for _, rem := range t.Reminders {
+		if d, err := time.ParseDuration(rem.Before); err != nil || d < 0 {
+			return fmt.Errorf("invalid reminder: %q", rem.Before)
+		}
+	}
+	if t.Recurrence != "" {
+		if _, err := ParseRRule(t.Recurrence); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type TaskRequest struct {
-	Action Action      `json:"action" jsonschema_description:"action to perform, enum:add,update,delete,list"`
-	Task   *Task       `json:"task" jsonschema_description:"task to add, update, or delete"`
-	List   *ListParams `json:"list" jsonschema_description:"list parameters"`
+	Action   Action          `json:"action" jsonschema_description:"action to perform, enum:add,update,delete,list,import,export"`
+	Task     *Task           `json:"task" jsonschema_description:"task to add, update, or delete"`
+	List     *ListParams     `json:"list" jsonschema_description:"list parameters, also select the tasks to export"`
+	Transfer *TransferParams `json:"transfer,omitempty" jsonschema_description:"import and export parameters"`
+}
+
+type TransferParams struct {
+	Format string `json:"format" jsonschema_description:"format of the data, enum:ical,csv,markdown"`
+	Data   string `json:"data,omitempty" jsonschema_description:"data to import: an iCalendar file of VTODOs, a CSV table with a title column, or a markdown checklist like '- [ ] title'"`
 }
 
 type ListParams struct {
-	Query  string `json:"query" jsonschema_description:"query to search"`
-	IsDone *bool  `json:"is_done" jsonschema_description:"filter by completed status"`
-	Limit  *int   `json:"limit" jsonschema_description:"limit the number of results"`
+	Query     string     `json:"query" jsonschema_description:"query to search"`
+	IsDone    *bool      `json:"is_done" jsonschema_description:"filter by completed status"`
+	Limit     *int       `json:"limit" jsonschema_description:"limit the number of results"`
+	DueBefore *time.Time `json:"due_before,omitempty" jsonschema_description:"only tasks with a deadline before this time, RFC3339"`
+	DueAfter  *time.Time `json:"due_after,omitempty" jsonschema_description:"only tasks with a deadline after this time, RFC3339"`
+	Tag       string     `json:"tag,omitempty" jsonschema_description:"only tasks with this tag"`
+	Priority  Priority   `json:"priority,omitempty" jsonschema_description:"only tasks with this priority, enum:low,medium,high"`
+	SortBy    string     `json:"sort_by,omitempty" jsonschema_description:"sort order, enum:created,deadline,priority; default created (newest first)"`
+}
+
+func (p *ListParams) Validate() error {
+	switch p.SortBy {
+	case "", "created", "deadline", "priority":
+	default:
+		return fmt.Errorf("invalid sort_by: %s", p.SortBy)
+	}
+	switch p.Priority {
+	case "", PriorityLow, PriorityMedium, PriorityHigh:
+	default:
+		return fmt.Errorf("invalid priority: %s", p.Priority)
+	}
+	return nil
 }
 
 type TaskResponse struct {
@@ -63,6 +170,9 @@ type TaskResponse struct {
 
 	TaskList []*Task `json:"task_list" jsonschema_description:"list of tasks"`
 
+	Data     string        `json:"data,omitempty" jsonschema_description:"exported data"`
+	Imported *ImportResult `json:"imported,omitempty" jsonschema_description:"created, updated and unchanged tasks of an import"`
+
 	Error string `json:"error" jsonschema_description:"error message"`
 }
 
@@ -120,7 +230,7 @@ func NewTaskTool(ctx context.Context, config *TaskToolConfig) (tn tool.BaseTool,
 }
 
 func (t *TaskToolImpl) ToEinoTool() (tool.BaseTool, error) {
-	return utils.InferTool("task_manager", "task manager tool, you can add, get, update, delete, list tasks", t.Invoke)
+	return utils.InferTool("task_manager", "task manager tool, you can add, get, update, delete, list tasks, and import or export them as iCalendar, CSV or a markdown checklist", t.Invoke)
 }
 
 func (t *TaskToolImpl) Invoke(ctx context.Context, req *TaskRequest) (res *TaskResponse, err error) {
@@ -133,9 +243,9 @@ func (t *TaskToolImpl) Invoke(ctx context.Context, req *TaskRequest) (res *TaskR
 			res.Error = "task is required for add action"
 			return res, nil
 		}
-		if req.Task.Title == "" {
+		if err := req.Task.ValidateNew(); err != nil {
 			res.Status = "error"
-			res.Error = "title is required"
+			res.Error = err.Error()
 			return res, nil
 		}
 		req.Task.ID = uuid.New().String()
@@ -157,12 +267,19 @@ func (t *TaskToolImpl) Invoke(ctx context.Context, req *TaskRequest) (res *TaskR
 			res.Error = "id is required"
 			return res, nil
 		}
-		if err := t.config.Storage.Update(req.Task); err != nil {
+		if err := req.Task.Validate(); err != nil {
+			res.Status = "error"
+			res.Error = err.Error()
+			return res, nil
+		}
+		// the updated task, followed by the next occurrence when a recurring task was completed
+		tasks, err := t.config.Storage.Update(req.Task)
+		if err != nil {
 			res.Status = "error"
 			res.Error = fmt.Sprintf("failed to update task: %v", err)
 			return res, nil
 		}
-		res.TaskList = []*Task{req.Task}
+		res.TaskList = tasks
 
 	case ActionDelete:
 		if req.Task == nil || req.Task.ID == "" {
@@ -180,6 +297,11 @@ func (t *TaskToolImpl) Invoke(ctx context.Context, req *TaskRequest) (res *TaskR
 		if req.List == nil {
 			req.List = &ListParams{}
 		}
+		if err := req.List.Validate(); err != nil {
+			res.Status = "error"
+			res.Error = err.Error()
+			return res, nil
+		}
 		tasks, err := t.config.Storage.List(req.List)
 		if err != nil {
 			res.Status = "error"
@@ -188,6 +310,65 @@ func (t *TaskToolImpl) Invoke(ctx context.Context, req *TaskRequest) (res *TaskR
 		}
 		res.TaskList = tasks
 
+	case ActionImport:
+		if req.Transfer == nil || req.Transfer.Data == "" {
+			res.Status = "error"
+			res.Error = "transfer data is required for import action"
+			return res, nil
+		}
+		format, err := ParseFormat(req.Transfer.Format)
+		if err != nil {
+			res.Status = "error"
+			res.Error = err.Error()
+			return res, nil
+		}
+		tasks, err := Parse(req.Transfer.Data, format)
+		if err != nil {
+			res.Status = "error"
+			res.Error = fmt.Sprintf("failed to parse tasks: %v", err)
+			return res, nil
+		}
+		imported, err := t.config.Storage.Import(tasks)
+		if err != nil {
+			res.Status = "error"
+			res.Error = fmt.Sprintf("failed to import tasks: %v", err)
+			return res, nil
+		}
+		res.Imported = imported
+
+	case ActionExport:
+		if req.Transfer == nil {
+			res.Status = "error"
+			res.Error = "transfer format is required for export action"
+			return res, nil
+		}
+		format, err := ParseFormat(req.Transfer.Format)
+		if err != nil {
+			res.Status = "error"
+			res.Error = err.Error()
+			return res, nil
+		}
+		if req.List == nil {
+			req.List = &ListParams{}
+		}
+		if err := req.List.Validate(); err != nil {
+			res.Status = "error"
+			res.Error = err.Error()
+			return res, nil
+		}
+		tasks, err := t.config.Storage.List(req.List)
+		if err != nil {
+			res.Status = "error"
+			res.Error = fmt.Sprintf("failed to list tasks: %v", err)
+			return res, nil
+		}
+		res.Data, err = Export(tasks, format)
+		if err != nil {
+			res.Status = "error"
+			res.Error = fmt.Sprintf("failed to export tasks: %v", err)
+			return res, nil
+		}
+
 	default:
 		res.Status = "error"
 		res.Error = fmt.Sprintf("unknown action: %s", req.Action)
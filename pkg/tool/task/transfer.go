@@ -0,0 +1,289 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package task
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Format is an interchange format for Import and Export.
+type Format string
+
+const (
+	// FormatICal is an iCalendar (RFC 5545) calendar of VTODO components.
+	FormatICal Format = "ical"
+	// FormatCSV is a CSV table with a header row, see csvColumns.
+	FormatCSV Format = "csv"
+	// FormatMarkdown is a GitHub-style "- [ ]" checklist.
+	FormatMarkdown Format = "markdown"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "ical", "ics", "icalendar":
+		return FormatICal, nil
+	case "csv":
+		return FormatCSV, nil
+	case "markdown", "md":
+		return FormatMarkdown, nil
+	default:
+		return "", fmt.Errorf("unsupported format: %s", s)
+	}
+}
+
+// ContentType is the MIME type of the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatICal:
+		return "text/calendar; charset=utf-8"
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	default:
+		return "text/markdown; charset=utf-8"
+	}
+}
+
+// Extension is the file extension of the format, without the dot.
+func (f Format) Extension() string {
+	switch f {
+	case FormatICal:
+		return "ics"
+	case FormatCSV:
+		return "csv"
+	default:
+		return "md"
+	}
+}
+
+// Export encodes tasks in the format. Every task carries its external id, or its own id when
+// it was not imported, so importing the output again updates the same tasks.
+func Export(tasks []*Task, format Format) (string, error) {
+	switch format {
+	case FormatICal:
+		return exportICal(tasks, time.Now()), nil
+	case FormatCSV:
+		return exportCSV(tasks)
+	case FormatMarkdown:
+		return exportMarkdown(tasks), nil
+	default:
+		return "", fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// Parse decodes tasks from the format. Every task gets an ExternalID: the one in the data, or
+// one derived from its title and deadline when the data has none.
+func Parse(data string, format Format) ([]*Task, error) {
+	var tasks []*Task
+	var err error
+	switch format {
+	case FormatICal:
+		tasks, err = parseICal(data)
+	case FormatCSV:
+		tasks, err = parseCSV(data)
+	case FormatMarkdown:
+		tasks, err = parseMarkdown(data)
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]int)
+	for _, t := range tasks {
+		if t.ExternalID == "" {
+			id := derivedExternalID(t)
+			// tasks that still look the same are told apart by their order in the source
+			if seen[id]++; seen[id] > 1 {
+				id = fmt.Sprintf("%s-%d", id, seen[id])
+			}
+			t.ExternalID = id
+		}
+	}
+	return tasks, nil
+}
+
+// derivedExternalID identifies a task from a source without ids by its title and deadline, so
+// importing the same list twice does not duplicate it while occurrences such as a weekly
+// review on different days stay apart.
+func derivedExternalID(t *Task) string {
+	key := strings.ToLower(strings.Join(strings.Fields(t.Title), " "))
+	if t.Deadline != nil {
+		key += "\x00" + t.Deadline.UTC().Format(time.RFC3339)
+	}
+	sum := sha1.Sum([]byte(key))
+	return "title-" + hex.EncodeToString(sum[:8])
+}
+
+func exportID(t *Task) string {
+	if t.ExternalID != "" {
+		return t.ExternalID
+	}
+	return t.ID
+}
+
+type ImportResult struct {
+	// Created includes the next occurrences scheduled for imported recurring tasks that are done.
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	// Unchanged counts tasks that matched a stored task without any difference.
+	Unchanged int     `json:"unchanged"`
+	Tasks     []*Task `json:"tasks"`
+}
+
+// Import stores parsed tasks, either all of them or none. A task whose ExternalID matches the
+// external id or the id of a stored task updates that task instead of creating a new one.
+func (s *Storage) Import(tasks []*Task) (*ImportResult, error) {
+	for i, t := range tasks {
+		if err := t.ValidateNew(); err != nil {
+			return nil, fmt.Errorf("task %d: %w", i+1, err)
+		}
+	}
+
+	res := &ImportResult{}
+	err := s.mutate(func() ([]opRecord, error) {
+		*res = ImportResult{}
+		byExternal := make(map[string]*Task, len(s.cache))
+		for _, t := range s.cache {
+			if t.ExternalID != "" {
+				byExternal[t.ExternalID] = t
+			}
+		}
+
+		now := time.Now().Format(time.RFC3339)
+		var recs []opRecord
+		for _, in := range tasks {
+			existing := byExternal[in.ExternalID]
+			if existing == nil {
+				existing = s.cache[in.ExternalID]
+			}
+
+			if existing == nil {
+				created := *in
+				created.ID = uuid.New().String()
+				created.CreatedAt = now
+				created.IsDeleted = false
+				created.Tags = normalizeTags(created.Tags)
+				created.Revision = 1
+				// a recurring task imported as done continues its series, as completing it would
+				var next *Task
+				if created.Completed {
+					var err error
+					if next, err = s.scheduleNextLocked(&created); err != nil {
+						return nil, fmt.Errorf("task %s: %w", in.ExternalID, err)
+					}
+				}
+				s.cache[created.ID] = &created
+				byExternal[created.ExternalID] = &created
+				recs = append(recs, createOp(&created))
+				res.Created++
+				res.Tasks = append(res.Tasks, &created)
+				if next != nil {
+					recs = append(recs, createOp(next))
+					res.Created++
+					res.Tasks = append(res.Tasks, next)
+				}
+				continue
+			}
+
+			updated := mergeImported(existing, in)
+			if updated == nil {
+				res.Unchanged++
+				res.Tasks = append(res.Tasks, existing)
+				continue
+			}
+			var next *Task
+			if updated.Completed && !existing.Completed {
+				var err error
+				if next, err = s.scheduleNextLocked(updated); err != nil {
+					return nil, fmt.Errorf("task %s: %w", in.ExternalID, err)
+				}
+			}
+			s.cache[updated.ID] = updated
+			if updated.ExternalID != "" {
+				byExternal[updated.ExternalID] = updated
+			}
+			recs = append(recs, putOp(updated))
+			res.Updated++
+			res.Tasks = append(res.Tasks, updated)
+			if next != nil {
+				recs = append(recs, createOp(next))
+				res.Created++
+				res.Tasks = append(res.Tasks, next)
+			}
+		}
+		return recs, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// mergeImported applies the imported fields to a stored task, or returns nil when nothing
+// changes. Bookkeeping such as the id, the series and sent reminders is kept.
+func mergeImported(existing, in *Task) *Task {
+	updated := *existing
+	updated.Title = in.Title
+	updated.Content = in.Content
+	updated.Completed = in.Completed
+	updated.Priority = in.Priority
+	updated.Tags = normalizeTags(in.Tags)
+	updated.Recurrence = in.Recurrence
+	if existing.Completed && existing.Recurrence == "" && existing.SeriesID != "" {
+		// the series went on from the next occurrence when this one was completed
+		updated.Recurrence = ""
+	}
+	if existing.ExternalID == "" && in.ExternalID != existing.ID {
+		updated.ExternalID = in.ExternalID
+	}
+
+	sameDeadline := (in.Deadline == nil && existing.Deadline == nil) ||
+		(in.Deadline != nil && existing.Deadline != nil && in.Deadline.Equal(*existing.Deadline))
+	sameReminders := slices.EqualFunc(in.Reminders, existing.Reminders, func(a, b Reminder) bool {
+		return a.Before == b.Before
+	})
+	if !sameDeadline {
+		updated.Deadline = in.Deadline
+		updated.DueNotifiedAt = nil
+	}
+	if !sameDeadline || !sameReminders {
+		updated.Reminders = make([]Reminder, len(in.Reminders))
+		for i, rem := range in.Reminders {
+			updated.Reminders[i] = Reminder{Before: rem.Before}
+			if sameDeadline && i < len(existing.Reminders) && existing.Reminders[i].Before == rem.Before {
+				updated.Reminders[i].SentAt = existing.Reminders[i].SentAt
+			}
+		}
+	}
+
+	if sameDeadline && sameReminders &&
+		updated.Title == existing.Title && updated.Content == existing.Content &&
+		updated.Completed == existing.Completed && updated.Priority == existing.Priority &&
+		slices.Equal(updated.Tags, existing.Tags) && updated.Recurrence == existing.Recurrence &&
+		updated.ExternalID == existing.ExternalID {
+		return nil
+	}
+	updated.Revision = existing.Revision + 1
+	return &updated
+}
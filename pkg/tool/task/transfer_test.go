@@ -0,0 +1,276 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package task
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleTasks() []*Task {
+	due := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	return []*Task{
+		{
+			ID:         "a",
+			Title:      "write report, part 1; draft",
+			Content:    "first line\n\nsecond line with a very long text that has to be folded in an iCalendar file 中文也可以",
+			Deadline:   &due,
+			Priority:   PriorityHigh,
+			Tags:       []string{"work", "q1"},
+			Recurrence: "FREQ=WEEKLY;BYDAY=MO",
+			Reminders:  []Reminder{{Before: "15m"}, {Before: "24h"}},
+		},
+		{ID: "b", Title: "buy milk", Completed: true, Priority: PriorityLow},
+		{ID: "c", Title: "call mom", ExternalID: "uid-from-phone"},
+	}
+}
+
+func TestTransferRoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatICal, FormatCSV, FormatMarkdown} {
+		t.Run(string(format), func(t *testing.T) {
+			want := sampleTasks()
+			data, err := Export(want, format)
+			require.NoError(t, err)
+			got, err := Parse(data, format)
+			require.NoError(t, err, data)
+			require.Len(t, got, len(want), data)
+
+			for i, w := range want {
+				g := got[i]
+				assert.Equal(t, exportID(w), g.ExternalID)
+				assert.Equal(t, w.Title, g.Title)
+				assert.Equal(t, w.Content, g.Content)
+				assert.Equal(t, w.Completed, g.Completed)
+				assert.Equal(t, w.Priority, g.Priority)
+				assert.Equal(t, w.Tags, g.Tags)
+				assert.Equal(t, w.Recurrence, g.Recurrence)
+				assert.Equal(t, w.Reminders, g.Reminders)
+				if w.Deadline == nil {
+					assert.Nil(t, g.Deadline)
+				} else if assert.NotNil(t, g.Deadline) {
+					assert.True(t, w.Deadline.Equal(*g.Deadline))
+				}
+			}
+		})
+	}
+}
+
+func TestParseICal(t *testing.T) {
+	data := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\nUID:event\r\nSUMMARY:not a task\r\nEND:VEVENT\r\n" +
+		"BEGIN:VTODO\r\nUID:1\r\nSUMMARY:folded\r\n  title\r\n" +
+		"DUE;TZID=Asia/Shanghai:20250303T090000\r\nPRIORITY:5\r\nCATEGORIES:a\\,b,c\r\n" +
+		"BEGIN:VALARM\r\nTRIGGER:-PT1H30M\r\nEND:VALARM\r\n" +
+		"BEGIN:VALARM\r\nTRIGGER;VALUE=DATE-TIME:20250303T000000Z\r\nEND:VALARM\r\n" +
+		"END:VTODO\r\nEND:VCALENDAR\r\n"
+	tasks, err := parseICal(data)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	got := tasks[0]
+	assert.Equal(t, "folded title", got.Title)
+	assert.Equal(t, PriorityMedium, got.Priority)
+	assert.Equal(t, []string{"a,b", "c"}, got.Tags)
+	assert.Equal(t, []Reminder{{Before: "1h30m"}}, got.Reminders)
+	assert.True(t, time.Date(2025, 3, 3, 1, 0, 0, 0, time.UTC).Equal(*got.Deadline))
+
+	for _, line := range strings.Split(exportICal(sampleTasks(), time.Now()), "\r\n") {
+		assert.LessOrEqual(t, len(line), icalLineOctets)
+	}
+
+	_, err = parseICal("BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nSUMMARY:x\r\n")
+	assert.Error(t, err)
+}
+
+func TestParseMarkdown(t *testing.T) {
+	data := "# Shopping\n\n" +
+		"- [ ] buy #1 milk !high #home\n" +
+		"  two bottles\n" +
+		"* [X] pay rent (due: 2025-03-01)\n" +
+		"Some paragraph.\n" +
+		"- not a task\n"
+	tasks, err := Parse(data, FormatMarkdown)
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+	assert.Equal(t, "buy #1 milk", tasks[0].Title)
+	assert.Equal(t, PriorityHigh, tasks[0].Priority)
+	assert.Equal(t, []string{"home"}, tasks[0].Tags)
+	assert.Equal(t, "two bottles", tasks[0].Content)
+	assert.True(t, tasks[1].Completed)
+	assert.Equal(t, "", tasks[1].Content)
+	require.NotNil(t, tasks[1].Deadline)
+
+	// without ids, the same title maps to the same external id
+	again, err := Parse("- [x] Buy #1  milk\n", FormatMarkdown)
+	require.NoError(t, err)
+	assert.Equal(t, tasks[0].ExternalID, again[0].ExternalID)
+}
+
+func TestParseCSV(t *testing.T) {
+	data := "Title,Tags,Deadline,Done\n" +
+		"water plants,home; garden,2025-03-03 18:00,\n" +
+		",,,\n"
+	tasks, err := Parse(data, FormatCSV)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, []string{"home", "garden"}, tasks[0].Tags)
+	assert.Equal(t, 18, tasks[0].Deadline.Hour())
+
+	_, err = Parse("name\nx\n", FormatCSV)
+	assert.Error(t, err)
+	_, err = Parse("title,completed\nx,maybe\n", FormatCSV)
+	assert.ErrorContains(t, err, "line 2")
+}
+
+func TestImportDeduplicates(t *testing.T) {
+	s, err := NewStorage(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, s.Add(&Task{ID: "local", Title: "local task"}))
+
+	data, err := Export(sampleTasks(), FormatICal)
+	require.NoError(t, err)
+	tasks, err := Parse(data, FormatICal)
+	require.NoError(t, err)
+	res, err := s.Import(tasks)
+	require.NoError(t, err)
+	assert.Equal(t, 3, res.Created)
+
+	// the same file again changes nothing
+	tasks, err = Parse(data, FormatICal)
+	require.NoError(t, err)
+	res, err = s.Import(tasks)
+	require.NoError(t, err)
+	assert.Equal(t, 0, res.Created)
+	assert.Equal(t, 3, res.Unchanged)
+
+	// an edited export updates the tasks it came from, including local ones
+	all, err := s.List(&ListParams{})
+	require.NoError(t, err)
+	edited := make([]*Task, len(all))
+	for i, task := range all {
+		c := *task
+		c.Completed = true
+		edited[i] = &c
+	}
+	data, err = Export(edited, FormatMarkdown)
+	require.NoError(t, err)
+	tasks, err = Parse(data, FormatMarkdown)
+	require.NoError(t, err)
+	res, err = s.Import(tasks)
+	require.NoError(t, err)
+	// completing the weekly report schedules its next occurrence
+	assert.Equal(t, 1, res.Created)
+	assert.Equal(t, 3, res.Updated)
+	assert.Equal(t, 1, res.Unchanged)
+
+	done := true
+	completed, err := s.List(&ListParams{IsDone: &done})
+	require.NoError(t, err)
+	assert.Len(t, completed, 4)
+	notDone := false
+	open, err := s.List(&ListParams{IsDone: &notDone})
+	require.NoError(t, err)
+	require.Len(t, open, 1)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO", open[0].Recurrence)
+	assert.Equal(t, time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC), open[0].Deadline.UTC())
+
+	// importing the file once more neither revives the finished occurrence nor forks the series
+	tasks, err = Parse(data, FormatMarkdown)
+	require.NoError(t, err)
+	res, err = s.Import(tasks)
+	require.NoError(t, err)
+	assert.Equal(t, 0, res.Created)
+	assert.Equal(t, 4, res.Unchanged)
+
+	// an invalid task rejects the whole import
+	_, err = s.Import([]*Task{{ExternalID: "x", Title: "ok"}, {ExternalID: "y"}})
+	assert.Error(t, err)
+	all, err = s.List(&ListParams{})
+	require.NoError(t, err)
+	assert.Len(t, all, 5)
+}
+
+func TestImportSameTitles(t *testing.T) {
+	s, err := NewStorage(t.TempDir())
+	require.NoError(t, err)
+
+	data := "- [ ] Weekly review (due: 2025-03-03)\n" +
+		"- [ ] Weekly review (due: 2025-03-10)\n" +
+		"- [ ] stretch\n" +
+		"- [ ] stretch\n"
+	tasks, err := Parse(data, FormatMarkdown)
+	require.NoError(t, err)
+	res, err := s.Import(tasks)
+	require.NoError(t, err)
+	assert.Equal(t, 4, res.Created)
+
+	tasks, err = Parse(data, FormatMarkdown)
+	require.NoError(t, err)
+	res, err = s.Import(tasks)
+	require.NoError(t, err)
+	assert.Equal(t, 4, res.Unchanged)
+}
+
+func TestImportCompletedRecurringTask(t *testing.T) {
+	s, err := NewStorage(t.TempDir())
+	require.NoError(t, err)
+	due := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+
+	res, err := s.Import([]*Task{{ExternalID: "uid-1", Title: "water plants", Deadline: &due, Recurrence: "FREQ=DAILY;COUNT=3", Completed: true}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Created)
+	require.Len(t, res.Tasks, 2)
+	assert.Empty(t, res.Tasks[0].Recurrence)
+	next := res.Tasks[1]
+	assert.False(t, next.Completed)
+	assert.Equal(t, "FREQ=DAILY;COUNT=2", next.Recurrence)
+	assert.Equal(t, res.Tasks[0].ID, next.SeriesID)
+	assert.Equal(t, due.AddDate(0, 0, 1), next.Deadline.UTC())
+}
+
+func TestTransferActions(t *testing.T) {
+	s, err := NewStorage(t.TempDir())
+	require.NoError(t, err)
+	tool, err := NewTaskToolImpl(context.Background(), &TaskToolConfig{Storage: s})
+	require.NoError(t, err)
+
+	res, err := tool.Invoke(context.Background(), &TaskRequest{
+		Action:   ActionImport,
+		Transfer: &TransferParams{Format: "md", Data: "- [ ] one\n- [ ] two #x\n"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "success", res.Status, res.Error)
+	assert.Equal(t, 2, res.Imported.Created)
+
+	res, err = tool.Invoke(context.Background(), &TaskRequest{
+		Action:   ActionExport,
+		List:     &ListParams{Tag: "x"},
+		Transfer: &TransferParams{Format: "csv"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "success", res.Status, res.Error)
+	assert.Equal(t, 2, strings.Count(res.Data, "\n"))
+	assert.Contains(t, res.Data, "two")
+
+	res, err = tool.Invoke(context.Background(), &TaskRequest{Action: ActionExport, Transfer: &TransferParams{Format: "pdf"}})
+	require.NoError(t, err)
+	assert.Equal(t, "error", res.Status)
+}
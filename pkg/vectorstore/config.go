@@ -0,0 +1,169 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vectorstore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"likeeino/pkg/retriever/hybrid"
+
+	"github.com/cloudwego/eino/components/embedding"
+)
+
+const (
+	BackendRedis  = "redis"
+	BackendMilvus = "milvus"
+	BackendMemory = "memory"
+
+	defaultTopK = 8
+)
+
+type Config struct {
+	// Backend is redis (the default), milvus or memory.
+	Backend string
+	// Embedding embeds documents when indexing and queries when retrieving.
+	Embedding embedding.Embedder
+
+	// TopK is the number of documents retrieved, 8 by default.
+	TopK int
+	// ScoreThreshold drops retrieved documents scoring below it.
+	ScoreThreshold float64
+
+	Redis  RedisConfig
+	Milvus MilvusConfig
+	// Memory is the store of the memory backend. When nil the process-wide DefaultMemory is
+	// used, so graphs built separately still see each other's documents.
+	Memory *Memory
+}
+
+type RedisConfig struct {
+	// Addr defaults to localhost:6379.
+	Addr string
+	// Dimension of the embeddings, used to create the index when missing. 4096 by default.
+	Dimension int
+	// Retriever tunes the hybrid retriever. Client and Embedding are set by the store, and
+	// TopK and ScoreThreshold default to those of Config.
+	Retriever hybrid.Config
+}
+
+type MilvusConfig struct {
+	// Addr defaults to localhost:19530.
+	Addr     string
+	Username string
+	Password string
+	// Collection defaults to eino_collection.
+	Collection string
+}
+
+func (c *Config) withDefaults() (*Config, error) {
+	if c.Embedding == nil {
+		return nil, errors.New("embedding is required")
+	}
+	res := *c
+	res.Backend = strings.ToLower(strings.TrimSpace(res.Backend))
+	if res.Backend == "" {
+		res.Backend = BackendRedis
+	}
+	if res.TopK <= 0 {
+		res.TopK = defaultTopK
+	}
+	if res.Redis.Addr == "" {
+		res.Redis.Addr = "localhost:6379"
+	}
+	if res.Redis.Dimension <= 0 {
+		res.Redis.Dimension = 4096
+	}
+	if res.Milvus.Addr == "" {
+		res.Milvus.Addr = "localhost:19530"
+	}
+	if res.Milvus.Collection == "" {
+		res.Milvus.Collection = "eino_collection"
+	}
+	if res.Memory == nil {
+		res.Memory = DefaultMemory
+	}
+	return &res, nil
+}
+
+// ConfigFromEnv reads the backend from VECTOR_STORE and its settings from:
+//
+//	RETRIEVER_TOP_K, RETRIEVER_SCORE_THRESHOLD
+//	REDIS_ADDR, VECTOR_DIMENSION
+//	RETRIEVER_MIN_SIMILARITY, RETRIEVER_TEXT_WEIGHT, RETRIEVER_VECTOR_WEIGHT (redis only)
+//	MILVUS_ADDR, MILVUS_USERNAME, MILVUS_PASSWORD, MILVUS_COLLECTION
+//
+// The embedder is left to the caller.
+func ConfigFromEnv() (*Config, error) {
+	conf := &Config{
+		Backend: os.Getenv("VECTOR_STORE"),
+		Redis: RedisConfig{
+			Addr: os.Getenv("REDIS_ADDR"),
+		},
+		Milvus: MilvusConfig{
+			Addr:       os.Getenv("MILVUS_ADDR"),
+			Username:   os.Getenv("MILVUS_USERNAME"),
+			Password:   os.Getenv("MILVUS_PASSWORD"),
+			Collection: os.Getenv("MILVUS_COLLECTION"),
+		},
+	}
+	var err error
+	if conf.TopK, err = envInt("RETRIEVER_TOP_K"); err != nil {
+		return nil, err
+	}
+	if conf.Redis.Dimension, err = envInt("VECTOR_DIMENSION"); err != nil {
+		return nil, err
+	}
+	for name, v := range map[string]*float64{
+		"RETRIEVER_SCORE_THRESHOLD": &conf.ScoreThreshold,
+		"RETRIEVER_MIN_SIMILARITY":  &conf.Redis.Retriever.MinSimilarity,
+		"RETRIEVER_TEXT_WEIGHT":     &conf.Redis.Retriever.TextWeight,
+		"RETRIEVER_VECTOR_WEIGHT":   &conf.Redis.Retriever.VectorWeight,
+	} {
+		if *v, err = envFloat(name); err != nil {
+			return nil, err
+		}
+	}
+	return conf, nil
+}
+
+func envInt(name string) (int, error) {
+	s := os.Getenv(name)
+	if s == "" {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return v, nil
+}
+
+func envFloat(name string) (float64, error) {
+	s := os.Getenv(name)
+	if s == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,209 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vectorstore
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/indexer"
+	"github.com/cloudwego/eino/components/retriever"
+	"github.com/cloudwego/eino/schema"
+	"github.com/google/uuid"
+)
+
+func init() {
+	Register(BackendMemory, func(ctx context.Context, conf *Config) (*Store, error) {
+		return NewStore(
+			&memoryIndexer{mem: conf.Memory, emb: conf.Embedding},
+			&memoryRetriever{mem: conf.Memory, emb: conf.Embedding, topK: conf.TopK, threshold: conf.ScoreThreshold},
+			func(ctx context.Context, ids []string) error {
+				conf.Memory.delete(ids)
+				return nil
+			},
+			nil,
+		), nil
+	})
+}
+
+// Memory keeps documents and their vectors in process and searches them by brute force.
+// It is meant for tests and small corpora.
+type Memory struct {
+	mu   sync.RWMutex
+	docs map[string]*memoryDoc
+}
+
+type memoryDoc struct {
+	doc    *schema.Document
+	vector []float64
+	norm   float64
+}
+
+func NewMemory() *Memory {
+	return &Memory{docs: make(map[string]*memoryDoc)}
+}
+
+// DefaultMemory is the store of memory backends configured without one.
+var DefaultMemory = NewMemory()
+
+// Len returns the number of documents stored.
+func (m *Memory) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.docs)
+}
+
+func (m *Memory) put(docs []*schema.Document, vectors [][]float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, doc := range docs {
+		cp := *doc
+		cp.MetaData = maps.Clone(doc.MetaData)
+		m.docs[doc.ID] = &memoryDoc{doc: &cp, vector: vectors[i], norm: norm(vectors[i])}
+	}
+}
+
+func (m *Memory) delete(ids []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range ids {
+		delete(m.docs, id)
+	}
+}
+
+// search returns the topK documents most similar to vector by cosine similarity, with at
+// least threshold.
+func (m *Memory) search(vector []float64, topK int, threshold float64) []*schema.Document {
+	qnorm := norm(vector)
+	m.mu.RLock()
+	res := make([]*schema.Document, 0, len(m.docs))
+	for _, d := range m.docs {
+		if len(d.vector) != len(vector) || d.norm == 0 || qnorm == 0 {
+			continue
+		}
+		var dot float64
+		for i, v := range vector {
+			dot += v * d.vector[i]
+		}
+		score := dot / (d.norm * qnorm)
+		if score < threshold {
+			continue
+		}
+		cp := *d.doc
+		cp.MetaData = maps.Clone(d.doc.MetaData)
+		if cp.MetaData == nil {
+			cp.MetaData = map[string]any{}
+		}
+		res = append(res, cp.WithScore(score))
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Score() != res[j].Score() {
+			return res[i].Score() > res[j].Score()
+		}
+		return res[i].ID < res[j].ID
+	})
+	if len(res) > topK {
+		res = res[:topK]
+	}
+	return res
+}
+
+func norm(v []float64) float64 {
+	var sum float64
+	for _, x := range v {
+		sum += x * x
+	}
+	return math.Sqrt(sum)
+}
+
+type memoryIndexer struct {
+	mem *Memory
+	emb embedding.Embedder
+}
+
+func (i *memoryIndexer) Store(ctx context.Context, docs []*schema.Document, opts ...indexer.Option) ([]string, error) {
+	options := indexer.GetCommonOptions(&indexer.Options{Embedding: i.emb}, opts...)
+	if options.Embedding == nil {
+		return nil, fmt.Errorf("embedding is required")
+	}
+	texts := make([]string, len(docs))
+	ids := make([]string, len(docs))
+	for j, doc := range docs {
+		if doc.ID == "" {
+			doc.ID = uuid.New().String()
+		}
+		texts[j] = doc.Content
+		ids[j] = doc.ID
+	}
+	vectors, err := options.Embedding.EmbedStrings(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed documents: %w", err)
+	}
+	if len(vectors) != len(docs) {
+		return nil, fmt.Errorf("expected %d vectors, got %d", len(docs), len(vectors))
+	}
+	i.mem.put(docs, vectors)
+	return ids, nil
+}
+
+func (i *memoryIndexer) GetType() string {
+	return "Memory"
+}
+
+type memoryRetriever struct {
+	mem       *Memory
+	emb       embedding.Embedder
+	topK      int
+	threshold float64
+}
+
+func (r *memoryRetriever) Retrieve(ctx context.Context, query string, opts ...retriever.Option) ([]*schema.Document, error) {
+	topK, threshold := r.topK, r.threshold
+	options := retriever.GetCommonOptions(&retriever.Options{
+		TopK:           &topK,
+		ScoreThreshold: &threshold,
+		Embedding:      r.emb,
+	}, opts...)
+	if options.TopK != nil {
+		topK = *options.TopK
+	}
+	if options.ScoreThreshold != nil {
+		threshold = *options.ScoreThreshold
+	}
+	if options.Embedding == nil {
+		return nil, fmt.Errorf("embedding is required")
+	}
+	vectors, err := options.Embedding.EmbedStrings(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("expected 1 query vector, got %d", len(vectors))
+	}
+	return r.mem.search(vectors[0], topK, threshold), nil
+}
+
+func (r *memoryRetriever) GetType() string {
+	return "Memory"
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package milvus registers the milvus backend of vectorstore. Import it for its side effect.
+package milvus
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"likeeino/pkg/vectorstore"
+
+	milvusindexer "github.com/cloudwego/eino-ext/components/indexer/milvus"
+	milvusretriever "github.com/cloudwego/eino-ext/components/retriever/milvus"
+	"github.com/milvus-io/milvus-sdk-go/v2/client"
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+// vectorField is the vector field of the schema the indexer creates by default.
+const vectorField = "vector"
+
+func init() {
+	vectorstore.Register(vectorstore.BackendMilvus, newStore)
+}
+
+// newStore keeps the client open for as long as the store: it is closed by Store.Close.
+func newStore(ctx context.Context, conf *vectorstore.Config) (*vectorstore.Store, error) {
+	cli, err := client.NewClient(ctx, client.Config{
+		Address:  conf.Milvus.Addr,
+		Username: conf.Milvus.Username,
+		Password: conf.Milvus.Password,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	idr, err := milvusindexer.NewIndexer(ctx, &milvusindexer.IndexerConfig{
+		Client:     cli,
+		Collection: conf.Milvus.Collection,
+		Embedding:  conf.Embedding,
+	})
+	if err != nil {
+		cli.Close()
+		return nil, err
+	}
+
+	rtr, err := milvusretriever.NewRetriever(ctx, &milvusretriever.RetrieverConfig{
+		Client:      cli,
+		Collection:  conf.Milvus.Collection,
+		VectorField: vectorField,
+		OutputFields: []string{
+			"id",
+			"content",
+			"metadata",
+		},
+		// the default schema stores binary vectors, which the indexer compares by hamming distance
+		MetricType:     entity.HAMMING,
+		TopK:           conf.TopK,
+		ScoreThreshold: conf.ScoreThreshold,
+		Embedding:      conf.Embedding,
+	})
+	if err != nil {
+		cli.Close()
+		return nil, err
+	}
+	collection := conf.Milvus.Collection
+	del := func(ctx context.Context, ids []string) error {
+		quoted := make([]string, len(ids))
+		for i, id := range ids {
+			quoted[i] = strconv.Quote(id)
+		}
+		return cli.Delete(ctx, collection, "", fmt.Sprintf("id in [%s]", strings.Join(quoted, ",")))
+	}
+	return vectorstore.NewStore(idr, rtr, del, cli.Close), nil
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vectorstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	redispkg "likeeino/pkg/redis"
+	"likeeino/pkg/retriever/hybrid"
+
+	"github.com/cloudwego/eino-ext/components/indexer/redis"
+	"github.com/cloudwego/eino/schema"
+	"github.com/google/uuid"
+)
+
+func init() {
+	Register(BackendRedis, newRedisStore)
+}
+
+// newRedisStore creates the index if missing, stores documents as hashes under
+// redispkg.RedisPrefix and retrieves them by hybrid BM25 and vector search.
+func newRedisStore(ctx context.Context, conf *Config) (*Store, error) {
+	if err := redispkg.InitRedisIndex(ctx, &redispkg.Config{
+		RedisAddr: conf.Redis.Addr,
+		Dimension: conf.Redis.Dimension,
+	}); err != nil {
+		return nil, err
+	}
+	client := redispkg.NewClient(conf.Redis.Addr)
+
+	idr, err := redis.NewIndexer(ctx, &redis.IndexerConfig{
+		Client:           client,
+		KeyPrefix:        redispkg.RedisPrefix,
+		BatchSize:        1,
+		DocumentToHashes: documentToHashes,
+		Embedding:        conf.Embedding,
+	})
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	rc := conf.Redis.Retriever
+	rc.Client = client
+	rc.Embedding = conf.Embedding
+	if rc.TopK <= 0 {
+		rc.TopK = conf.TopK
+	}
+	if rc.ScoreThreshold == 0 {
+		rc.ScoreThreshold = conf.ScoreThreshold
+	}
+	rtr, err := hybrid.NewRetriever(ctx, &rc)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	del := func(ctx context.Context, ids []string) error {
+		keys := make([]string, len(ids))
+		for i, id := range ids {
+			keys[i] = redispkg.RedisPrefix + id
+		}
+		return client.Del(ctx, keys...).Err()
+	}
+	return NewStore(idr, rtr, del, client.Close), nil
+}
+
+// documentToHashes stores the content, which is embedded into redispkg.VectorField, and the
+// metadata as JSON, which the hybrid retriever decodes.
+func documentToHashes(ctx context.Context, doc *schema.Document) (*redis.Hashes, error) {
+	if doc.ID == "" {
+		doc.ID = uuid.New().String()
+	}
+	metadataBytes, err := json.Marshal(doc.MetaData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	return &redis.Hashes{
+		Key: doc.ID,
+		Field2Value: map[string]redis.FieldValue{
+			redispkg.ContentField:  {Value: doc.Content, EmbedKey: redispkg.VectorField},
+			redispkg.MetadataField: {Value: metadataBytes},
+		},
+	}, nil
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package vectorstore builds an indexer and a retriever that agree on where and how documents
+// are stored, for one of several backends picked by config.
+//
+// The redis and memory backends are built in. Others register themselves when imported, the
+// way database/sql drivers do:
+//
+//	import _ "likeeino/pkg/vectorstore/milvus"
+package vectorstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/cloudwego/eino/components/indexer"
+	"github.com/cloudwego/eino/components/retriever"
+)
+
+var (
+	ErrUnknownBackend    = errors.New("unknown vector store backend")
+	ErrDeleteUnsupported = errors.New("vector store does not support deletion")
+)
+
+// Store is a matched indexer/retriever pair: what Indexer stores, Retriever finds.
+type Store struct {
+	Indexer   indexer.Indexer
+	Retriever retriever.Retriever
+
+	deleter DeleteFunc
+	closer  func() error
+}
+
+// DeleteFunc removes documents by the ids Indexer.Store returned for them.
+type DeleteFunc func(ctx context.Context, ids []string) error
+
+// NewStore pairs an indexer and a retriever. del removes what the indexer stored and close
+// releases what they share; either may be nil.
+func NewStore(idr indexer.Indexer, rtr retriever.Retriever, del DeleteFunc, close func() error) *Store {
+	return &Store{Indexer: idr, Retriever: rtr, deleter: del, closer: close}
+}
+
+// Delete removes documents by id. Unknown ids are ignored.
+func (s *Store) Delete(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if s.deleter == nil {
+		return ErrDeleteUnsupported
+	}
+	return s.deleter(ctx, ids)
+}
+
+// Close releases the connection of the backend. Neither component may be used afterwards.
+func (s *Store) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer()
+}
+
+// Factory creates the store of a backend. conf has been validated and has defaults applied.
+type Factory func(ctx context.Context, conf *Config) (*Store, error)
+
+var (
+	mu        sync.RWMutex
+	factories = make(map[string]Factory)
+)
+
+// Register adds a backend, replacing one of the same name.
+func Register(backend string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[backend] = factory
+}
+
+// Backends lists the registered backend names.
+func Backends() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates the store of conf.Backend.
+func New(ctx context.Context, conf *Config) (*Store, error) {
+	c, err := conf.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	mu.RLock()
+	factory, ok := factories[c.Backend]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q, registered: %v", ErrUnknownBackend, c.Backend, Backends())
+	}
+	store, err := factory(ctx, c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s vector store: %w", c.Backend, err)
+	}
+	return store, nil
+}
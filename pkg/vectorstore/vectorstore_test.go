@@ -0,0 +1,160 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package vectorstore
+
+import (
+	"context"
+	"hash/fnv"
+	"strings"
+	"testing"
+
+	redispkg "likeeino/pkg/redis"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alicebob/miniredis/v2/server"
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/retriever"
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wordEmbedder hashes every word of a text into one of 32 dimensions.
+type wordEmbedder struct{}
+
+func (wordEmbedder) EmbedStrings(ctx context.Context, texts []string, opts ...embedding.Option) ([][]float64, error) {
+	res := make([][]float64, len(texts))
+	for i, text := range texts {
+		v := make([]float64, 32)
+		for _, w := range strings.Fields(strings.ToLower(text)) {
+			h := fnv.New32a()
+			h.Write([]byte(strings.Trim(w, ".,?!")))
+			v[h.Sum32()%32]++
+		}
+		res[i] = v
+	}
+	return res, nil
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	mem := NewMemory()
+	store, err := New(ctx, &Config{Backend: "Memory", Embedding: wordEmbedder{}, Memory: mem, TopK: 2})
+	require.NoError(t, err)
+	defer store.Close()
+
+	docs := []*schema.Document{
+		{ID: "graph", Content: "compose graph nodes and edges", MetaData: map[string]any{"source": "graph.md"}},
+		{ID: "chain", Content: "compose chain of nodes"},
+		{Content: "agent calls tools"},
+	}
+	ids, err := store.Indexer.Store(ctx, docs)
+	require.NoError(t, err)
+	require.Len(t, ids, 3)
+	assert.NotEmpty(t, ids[2], "a missing id is generated")
+	assert.Equal(t, 3, mem.Len())
+
+	// storing the same id again replaces the document
+	_, err = store.Indexer.Store(ctx, []*schema.Document{{ID: "chain", Content: "compose chain of nodes"}})
+	require.NoError(t, err)
+	assert.Equal(t, 3, mem.Len())
+
+	res, err := store.Retriever.Retrieve(ctx, "graph edges")
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	assert.Equal(t, "graph", res[0].ID)
+	assert.Equal(t, "graph.md", res[0].MetaData["source"])
+	assert.Greater(t, res[0].Score(), res[1].Score())
+
+	res[0].MetaData["source"] = "changed"
+	res, err = store.Retriever.Retrieve(ctx, "graph edges", retriever.WithTopK(1), retriever.WithScoreThreshold(0.5))
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, "graph.md", res[0].MetaData["source"], "results are copies")
+
+	// a store on the same memory sees the documents
+	other, err := New(ctx, &Config{Backend: BackendMemory, Embedding: wordEmbedder{}, Memory: mem})
+	require.NoError(t, err)
+	res, err = other.Retriever.Retrieve(ctx, "agent tools")
+	require.NoError(t, err)
+	assert.Equal(t, ids[2], res[0].ID)
+}
+
+func TestNew(t *testing.T) {
+	ctx := context.Background()
+	_, err := New(ctx, &Config{Backend: "nope", Embedding: wordEmbedder{}})
+	assert.ErrorIs(t, err, ErrUnknownBackend)
+	_, err = New(ctx, &Config{Backend: BackendMemory})
+	assert.Error(t, err, "an embedder is required")
+	assert.Contains(t, Backends(), BackendRedis)
+	assert.Contains(t, Backends(), BackendMemory)
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("VECTOR_STORE", "milvus")
+	t.Setenv("MILVUS_ADDR", "milvus:19530")
+	t.Setenv("RETRIEVER_TOP_K", "3")
+	t.Setenv("RETRIEVER_MIN_SIMILARITY", "0.4")
+	conf, err := ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, BackendMilvus, conf.Backend)
+	assert.Equal(t, "milvus:19530", conf.Milvus.Addr)
+	assert.Equal(t, 3, conf.TopK)
+	assert.Equal(t, 0.4, conf.Redis.Retriever.MinSimilarity)
+
+	conf.Embedding = wordEmbedder{}
+	c, err := conf.withDefaults()
+	require.NoError(t, err)
+	assert.Equal(t, "eino_collection", c.Milvus.Collection)
+	assert.Equal(t, "localhost:6379", c.Redis.Addr)
+
+	t.Setenv("RETRIEVER_SCORE_THRESHOLD", "high")
+	_, err = ConfigFromEnv()
+	assert.Error(t, err)
+}
+
+func TestDelete(t *testing.T) {
+	ctx := context.Background()
+	mem := NewMemory()
+	store, err := New(ctx, &Config{Backend: BackendMemory, Embedding: wordEmbedder{}, Memory: mem})
+	require.NoError(t, err)
+	_, err = store.Indexer.Store(ctx, []*schema.Document{{ID: "a", Content: "a"}, {ID: "b", Content: "b"}})
+	require.NoError(t, err)
+
+	require.NoError(t, store.Delete(ctx, "a", "missing"))
+	assert.Equal(t, 1, mem.Len())
+	assert.NoError(t, NewStore(nil, nil, nil, nil).Delete(ctx))
+	assert.ErrorIs(t, NewStore(nil, nil, nil, nil).Delete(ctx, "b"), ErrDeleteUnsupported)
+}
+
+func TestRedisDelete(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	// the index exists
+	require.NoError(t, mr.Server().Register("FT.INFO", func(c *server.Peer, cmd string, args []string) {
+		c.WriteOK()
+	}))
+	store, err := New(ctx, &Config{Embedding: wordEmbedder{}, Redis: RedisConfig{Addr: mr.Addr()}})
+	require.NoError(t, err)
+	defer store.Close()
+
+	mr.HSet(redispkg.RedisPrefix+"a", redispkg.ContentField, "a")
+	mr.HSet(redispkg.RedisPrefix+"b", redispkg.ContentField, "b")
+	require.NoError(t, store.Delete(ctx, "a"))
+	assert.False(t, mr.Exists(redispkg.RedisPrefix+"a"))
+	assert.True(t, mr.Exists(redispkg.RedisPrefix+"b"))
+}